@@ -27,8 +27,8 @@ var _ = BeforeSuite(func() {
 	Expect(err).NotTo(HaveOccurred())
 })
 
-// Cleaning up network launched from BeforeSuite and removing all chaincode containers
-// and chaincode container images using AfterSuite
+// Cleaning up network launched from BeforeSuite and removing its chaincode containers
+// and chaincode container images using AfterSuite, leaving the networks of other suites alone
 var _ = AfterSuite(func() {
 
 	// Use input "command" to print peer logs
@@ -41,7 +41,7 @@ var _ = AfterSuite(func() {
 	err = launcher.Launcher("down", "docker", "", networkSpecPath)
 	Expect(err).NotTo(HaveOccurred())
 
-	dockerList := []string{"ps", "-aq", "-f", "status=exited"}
+	dockerList := []string{"ps", "-aq", "-f", "status=exited", "-f", "name=smoke-"}
 	containerList, _ := networkclient.ExecuteCommand("docker", dockerList, false)
 	if containerList != "" {
 		list := strings.Split(containerList, "\n")
@@ -49,7 +49,7 @@ var _ = AfterSuite(func() {
 		containerArgs = append(containerArgs, list...)
 		networkclient.ExecuteCommand("docker", containerArgs, true)
 	}
-	ccimagesList := []string{"images", "-q", "--filter=reference=dev*"}
+	ccimagesList := []string{"images", "-q", "--filter=reference=smoke-*"}
 	images, _ := networkclient.ExecuteCommand("docker", ccimagesList, false)
 	if images != "" {
 		list := strings.Split(images, "\n")
@@ -0,0 +1,533 @@
+package channelconfig
+
+import (
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-config/configtx"
+	ordererconfig "github.com/hyperledger/fabric-config/configtx/orderer"
+	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-protos-go/orderer"
+	"github.com/hyperledger/fabric-protos-go/orderer/etcdraft"
+	"github.com/hyperledger/fabric-test/tools/operator/smartbft"
+	"github.com/pkg/errors"
+)
+
+const (
+	//ChannelGroup -- path of the channel group
+	ChannelGroup = "/Channel"
+	//OrdererGroup -- path of the orderer group
+	OrdererGroup = "/Channel/Orderer"
+	//ApplicationGroup -- path of the application group
+	ApplicationGroup = "/Channel/Application"
+	//ConsortiumsGroup -- path of the consortiums group of the system channel
+	ConsortiumsGroup = "/Channel/Consortiums"
+)
+
+//lifecycleACLs -- the _lifecycle ACLs added to channels created before v2.0
+var lifecycleACLs = map[string]string{
+	"_lifecycle/CommitChaincodeDefinition": "/Channel/Application/Writers",
+	"_lifecycle/QueryChaincodeDefinition":  "/Channel/Application/Readers",
+	"_lifecycle/QueryNamespaceDefinitions": "/Channel/Application/Readers",
+}
+
+//AddCapability -- enables a capability in the channel, orderer or application group
+func AddCapability(group, capability string) Change {
+
+	switch group {
+	case "channel":
+		return Change{Group: ChannelGroup, Modify: func(c *configtx.ConfigTx) error {
+			return c.Channel().AddCapability(capability)
+		}}
+	case "orderer":
+		return Change{Group: OrdererGroup, Modify: func(c *configtx.ConfigTx) error {
+			if err := requireGroup(c, configtx.OrdererGroupKey); err != nil {
+				return err
+			}
+			return c.Orderer().AddCapability(capability)
+		}}
+	case "application":
+		return Change{Group: ApplicationGroup, Modify: func(c *configtx.ConfigTx) error {
+			if err := requireGroup(c, configtx.ApplicationGroupKey); err != nil {
+				return err
+			}
+			return c.Application().AddCapability(capability)
+		}}
+	}
+	return Change{Group: group, Modify: func(c *configtx.ConfigTx) error {
+		return errors.Errorf("unknown capability group %s", group)
+	}}
+}
+
+//SetConsortiumOrgEndorsement -- sets the Endorsement policy of an organization of a consortium to any member of the organization
+func SetConsortiumOrgEndorsement(consortium, orgName, mspID string) Change {
+
+	return Change{Group: fmt.Sprintf("%s/%s/%s", ConsortiumsGroup, consortium, orgName), Modify: func(c *configtx.ConfigTx) error {
+		if err := requireGroup(c, configtx.ConsortiumsGroupKey); err != nil {
+			return err
+		}
+		consortiumGroup := c.Consortium(consortium)
+		if consortiumGroup == nil {
+			return errors.Errorf("consortium %s not found", consortium)
+		}
+		org := consortiumGroup.Organization(orgName)
+		if org == nil {
+			return errors.Errorf("organization %s not found in consortium %s", orgName, consortium)
+		}
+		return org.SetPolicy(configtx.EndorsementPolicyKey, memberPolicy(mspID))
+	}}
+}
+
+//SetApplicationOrgEndorsement -- sets the Endorsement policy of an application organization to any member of the organization
+func SetApplicationOrgEndorsement(orgName, mspID string) Change {
+
+	return Change{Group: fmt.Sprintf("%s/%s", ApplicationGroup, orgName), Modify: func(c *configtx.ConfigTx) error {
+		if err := requireGroup(c, configtx.ApplicationGroupKey); err != nil {
+			return err
+		}
+		org := c.Application().Organization(orgName)
+		if org == nil {
+			return errors.Errorf("organization %s not found", orgName)
+		}
+		return org.SetPolicy(configtx.AdminsPolicyKey, configtx.EndorsementPolicyKey, memberPolicy(mspID))
+	}}
+}
+
+//SetApplicationEndorsementPolicies -- sets the Endorsement and LifecycleEndorsement policies of the application group
+func SetApplicationEndorsementPolicies() Change {
+
+	return Change{Group: ApplicationGroup, Modify: func(c *configtx.ConfigTx) error {
+		if err := requireGroup(c, configtx.ApplicationGroupKey); err != nil {
+			return err
+		}
+		policy := configtx.Policy{Type: configtx.ImplicitMetaPolicyType, Rule: "ANY Endorsement"}
+		for _, policyName := range []string{configtx.EndorsementPolicyKey, configtx.LifecycleEndorsementPolicyKey} {
+			if err := c.Application().SetPolicy(configtx.AdminsPolicyKey, policyName, policy); err != nil {
+				return err
+			}
+		}
+		return nil
+	}}
+}
+
+//AddLifecycleACLs -- adds the _lifecycle ACLs to the ACLs of the application group
+func AddLifecycleACLs() Change {
+
+	return Change{Group: ApplicationGroup + "/" + configtx.ACLsKey, Modify: func(c *configtx.ConfigTx) error {
+		if err := requireGroup(c, configtx.ApplicationGroupKey); err != nil {
+			return err
+		}
+		acls := make(map[string]string)
+		if _, ok := c.UpdatedConfig().ChannelGroup.Groups[configtx.ApplicationGroupKey].Values[configtx.ACLsKey]; ok {
+			existing, err := c.Application().ACLs()
+			if err != nil {
+				return err
+			}
+			acls = existing
+		}
+		for resource, policyRef := range lifecycleACLs {
+			acls[resource] = policyRef
+		}
+		return c.Application().SetACLs(acls)
+	}}
+}
+
+//AddConsortiumOrg -- adds an organization to a consortium of the system channel, unless it is already a member
+func AddConsortiumOrg(consortium string, org configtx.Organization) Change {
+
+	return Change{Group: fmt.Sprintf("%s/%s/%s", ConsortiumsGroup, consortium, org.Name), Modify: func(c *configtx.ConfigTx) error {
+		if err := requireGroup(c, configtx.ConsortiumsGroupKey); err != nil {
+			return err
+		}
+		consortiumGroup := c.Consortium(consortium)
+		if consortiumGroup == nil {
+			return errors.Errorf("consortium %s not found", consortium)
+		}
+		if consortiumGroup.Organization(org.Name) != nil {
+			return nil
+		}
+		return consortiumGroup.SetOrganization(org)
+	}}
+}
+
+//RemoveConsortiumOrg -- removes an organization from a consortium of the system channel
+func RemoveConsortiumOrg(consortium, orgName string) Change {
+
+	return Change{Group: fmt.Sprintf("%s/%s/%s", ConsortiumsGroup, consortium, orgName), Modify: func(c *configtx.ConfigTx) error {
+		if err := requireGroup(c, configtx.ConsortiumsGroupKey); err != nil {
+			return err
+		}
+		consortiumGroup := c.Consortium(consortium)
+		if consortiumGroup == nil {
+			return errors.Errorf("consortium %s not found", consortium)
+		}
+		consortiumGroup.RemoveOrganization(orgName)
+		return nil
+	}}
+}
+
+//AddApplicationOrg -- adds an organization to the application group of a channel, unless it is already a member
+func AddApplicationOrg(org configtx.Organization) Change {
+
+	return Change{Group: fmt.Sprintf("%s/%s", ApplicationGroup, org.Name), Modify: func(c *configtx.ConfigTx) error {
+		if err := requireGroup(c, configtx.ApplicationGroupKey); err != nil {
+			return err
+		}
+		if c.Application().Organization(org.Name) != nil {
+			return nil
+		}
+		return c.Application().SetOrganization(org)
+	}}
+}
+
+//RemoveApplicationOrg -- removes an organization from the application group of a channel
+func RemoveApplicationOrg(orgName string) Change {
+
+	return Change{Group: fmt.Sprintf("%s/%s", ApplicationGroup, orgName), Modify: func(c *configtx.ConfigTx) error {
+		if err := requireGroup(c, configtx.ApplicationGroupKey); err != nil {
+			return err
+		}
+		c.Application().RemoveOrganization(orgName)
+		return nil
+	}}
+}
+
+//SetConsensusState -- switches the ordering service of the channel into or out of maintenance mode
+func SetConsensusState(state orderer.ConsensusType_State) Change {
+
+	return Change{Group: OrdererGroup, Modify: func(c *configtx.ConfigTx) error {
+		return modifyConsensusType(c, func(consensusType *orderer.ConsensusType) error {
+			consensusType.State = state
+			return nil
+		})
+	}}
+}
+
+//MigrateToEtcdRaft -- changes the consensus type of a channel in maintenance mode to etcdraft
+func MigrateToEtcdRaft(consenters []*etcdraft.Consenter, options *etcdraft.Options) Change {
+
+	return Change{Group: OrdererGroup, Modify: func(c *configtx.ConfigTx) error {
+		return modifyConsensusType(c, func(consensusType *orderer.ConsensusType) error {
+			if consensusType.State != orderer.ConsensusType_STATE_MAINTENANCE {
+				return errors.Errorf("consensus type can only be changed in maintenance mode, state is %s", consensusType.State)
+			}
+			metadata, err := proto.Marshal(&etcdraft.ConfigMetadata{Consenters: consenters, Options: options})
+			if err != nil {
+				return errors.Wrap(err, "failed to marshal etcdraft metadata")
+			}
+			consensusType.Type = ordererconfig.ConsensusTypeEtcdRaft
+			consensusType.Metadata = metadata
+			return nil
+		})
+	}}
+}
+
+//SetBFTConsensus -- sets the consensus type of a channel to BFT with the consenter mapping and SmartBFT options and
+//removes the legacy orderer addresses of the channel group, which BFT channels must not have
+func SetBFTConsensus(consenters []*smartbft.Consenter, options *smartbft.Options) Change {
+
+	return Change{Group: OrdererGroup, Modify: func(c *configtx.ConfigTx) error {
+		err := modifyConsensusType(c, func(consensusType *orderer.ConsensusType) error {
+			metadata, err := proto.Marshal(options)
+			if err != nil {
+				return errors.Wrap(err, "failed to marshal SmartBFT options")
+			}
+			consensusType.Type = smartbft.ConsensusType
+			consensusType.Metadata = metadata
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		orderers := &smartbft.Orderers{}
+		err = modifyValue(c, []string{configtx.OrdererGroupKey}, smartbft.OrderersKey, orderers, func() error {
+			orderers.ConsenterMapping = consenters
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		c.Channel().RemoveLegacyOrdererAddresses()
+		return nil
+	}}
+}
+
+//Consenter -- an orderer to add to the consenters of a channel. Its MSPID and Identity, the enrollment certificate of
+//the orderer, are only part of the consenter mapping of BFT channels
+type Consenter struct {
+	Host     string
+	Port     uint32
+	MSPID    string
+	Identity []byte
+	TLSCert  []byte
+}
+
+//AddConsenter -- adds an orderer to the etcdraft consenters or the BFT consenter mapping of a channel, with the next
+//free id, and its address to the endpoints of its organization, unless it is already a consenter
+func AddConsenter(orgName string, consenter Consenter) Change {
+
+	return Change{Group: OrdererGroup, Modify: func(c *configtx.ConfigTx) error {
+		bft, err := isBFT(c)
+		if err != nil {
+			return err
+		}
+		if bft {
+			err = modifyBFTConsenters(c, func(orderers *smartbft.Orderers) error {
+				var id uint32
+				for _, existing := range orderers.ConsenterMapping {
+					if existing.Host == consenter.Host {
+						return nil
+					}
+					if existing.Id > id {
+						id = existing.Id
+					}
+				}
+				orderers.ConsenterMapping = append(orderers.ConsenterMapping, &smartbft.Consenter{
+					Id:            id + 1,
+					Host:          consenter.Host,
+					Port:          consenter.Port,
+					MspId:         consenter.MSPID,
+					Identity:      consenter.Identity,
+					ClientTlsCert: consenter.TLSCert,
+					ServerTlsCert: consenter.TLSCert,
+				})
+				return nil
+			})
+		} else {
+			err = modifyRaftMetadata(c, func(metadata *etcdraft.ConfigMetadata) error {
+				if findConsenter(metadata.Consenters, consenter.Host) >= 0 {
+					return nil
+				}
+				metadata.Consenters = append(metadata.Consenters, &etcdraft.Consenter{
+					Host:          consenter.Host,
+					Port:          consenter.Port,
+					ClientTlsCert: consenter.TLSCert,
+					ServerTlsCert: consenter.TLSCert,
+				})
+				return nil
+			})
+		}
+		if err != nil {
+			return err
+		}
+		org := c.Orderer().Organization(orgName)
+		if org == nil {
+			return errors.Errorf("organization %s not found in group Orderer", orgName)
+		}
+		return org.SetEndpoint(configtx.Address{Host: consenter.Host, Port: int(consenter.Port)})
+	}}
+}
+
+//RemoveConsenter -- removes an orderer from the etcdraft consenters or the BFT consenter mapping of a channel and its
+//address from the endpoints of its organization
+func RemoveConsenter(orgName, host string) Change {
+
+	return Change{Group: OrdererGroup, Modify: func(c *configtx.ConfigTx) error {
+		bft, err := isBFT(c)
+		if err != nil {
+			return err
+		}
+		var port uint32
+		if bft {
+			err = modifyBFTConsenters(c, func(orderers *smartbft.Orderers) error {
+				i := findBFTConsenter(orderers.ConsenterMapping, host)
+				if i < 0 {
+					return nil
+				}
+				if len(orderers.ConsenterMapping) == 1 {
+					return errors.Errorf("cannot remove %s, the last consenter of the channel", host)
+				}
+				port = orderers.ConsenterMapping[i].Port
+				orderers.ConsenterMapping = append(orderers.ConsenterMapping[:i], orderers.ConsenterMapping[i+1:]...)
+				return nil
+			})
+		} else {
+			err = modifyRaftMetadata(c, func(metadata *etcdraft.ConfigMetadata) error {
+				i := findConsenter(metadata.Consenters, host)
+				if i < 0 {
+					return nil
+				}
+				if len(metadata.Consenters) == 1 {
+					return errors.Errorf("cannot remove %s, the last consenter of the channel", host)
+				}
+				port = metadata.Consenters[i].Port
+				metadata.Consenters = append(metadata.Consenters[:i], metadata.Consenters[i+1:]...)
+				return nil
+			})
+		}
+		if err != nil || port == 0 {
+			return err
+		}
+		org := c.Orderer().Organization(orgName)
+		if org == nil {
+			return errors.Errorf("organization %s not found in group Orderer", orgName)
+		}
+		return org.RemoveEndpoint(configtx.Address{Host: host, Port: int(port)})
+	}}
+}
+
+//SetConsenterCert -- replaces the client and server tls certificates of an etcdraft or BFT consenter of a channel
+func SetConsenterCert(host string, cert []byte) Change {
+
+	return Change{Group: OrdererGroup, Modify: func(c *configtx.ConfigTx) error {
+		bft, err := isBFT(c)
+		if err != nil {
+			return err
+		}
+		if bft {
+			return modifyBFTConsenters(c, func(orderers *smartbft.Orderers) error {
+				i := findBFTConsenter(orderers.ConsenterMapping, host)
+				if i < 0 {
+					return errors.Errorf("%s is not a consenter of the channel", host)
+				}
+				orderers.ConsenterMapping[i].ClientTlsCert = cert
+				orderers.ConsenterMapping[i].ServerTlsCert = cert
+				return nil
+			})
+		}
+		return modifyRaftMetadata(c, func(metadata *etcdraft.ConfigMetadata) error {
+			i := findConsenter(metadata.Consenters, host)
+			if i < 0 {
+				return errors.Errorf("%s is not a consenter of the channel", host)
+			}
+			metadata.Consenters[i].ClientTlsCert = cert
+			metadata.Consenters[i].ServerTlsCert = cert
+			return nil
+		})
+	}}
+}
+
+//Consenters -- returns the etcdraft consenters of a channel
+func Consenters(config *common.Config) ([]*etcdraft.Consenter, error) {
+
+	consensusType, err := ConsensusType(config)
+	if err != nil {
+		return nil, err
+	}
+	if consensusType.Type != ordererconfig.ConsensusTypeEtcdRaft {
+		return nil, errors.Errorf("consensus type is %s, not etcdraft", consensusType.Type)
+	}
+	metadata := &etcdraft.ConfigMetadata{}
+	if err := proto.Unmarshal(consensusType.Metadata, metadata); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal etcdraft metadata")
+	}
+	return metadata.Consenters, nil
+}
+
+//BFTConsenters -- returns the consenter mapping of a BFT channel
+func BFTConsenters(config *common.Config) ([]*smartbft.Consenter, error) {
+
+	consensusType, err := ConsensusType(config)
+	if err != nil {
+		return nil, err
+	}
+	if consensusType.Type != smartbft.ConsensusType {
+		return nil, errors.Errorf("consensus type is %s, not %s", consensusType.Type, smartbft.ConsensusType)
+	}
+	value, ok := config.ChannelGroup.Groups[configtx.OrdererGroupKey].Values[smartbft.OrderersKey]
+	if !ok {
+		return nil, errors.Errorf("value %s not found in group %s", smartbft.OrderersKey, configtx.OrdererGroupKey)
+	}
+	orderers := &smartbft.Orderers{}
+	if err := proto.Unmarshal(value.Value, orderers); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal %s", smartbft.OrderersKey)
+	}
+	return orderers.ConsenterMapping, nil
+}
+
+//ConsensusType -- returns the consensus type, metadata and state of the ordering service of a channel
+func ConsensusType(config *common.Config) (*orderer.ConsensusType, error) {
+
+	ordererGroup, ok := config.ChannelGroup.Groups[configtx.OrdererGroupKey]
+	if !ok {
+		return nil, errors.Errorf("group %s not found", configtx.OrdererGroupKey)
+	}
+	value, ok := ordererGroup.Values[ordererconfig.ConsensusTypeKey]
+	if !ok {
+		return nil, errors.Errorf("value %s not found in group %s", ordererconfig.ConsensusTypeKey, configtx.OrdererGroupKey)
+	}
+	consensusType := &orderer.ConsensusType{}
+	if err := proto.Unmarshal(value.Value, consensusType); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal %s", ordererconfig.ConsensusTypeKey)
+	}
+	return consensusType, nil
+}
+
+func modifyConsensusType(c *configtx.ConfigTx, modify func(*orderer.ConsensusType) error) error {
+
+	consensusType := &orderer.ConsensusType{}
+	return modifyValue(c, []string{configtx.OrdererGroupKey}, ordererconfig.ConsensusTypeKey, consensusType, func() error {
+		return modify(consensusType)
+	})
+}
+
+//modifyRaftMetadata -- modifies the etcdraft metadata of the consensus type of a channel
+func modifyRaftMetadata(c *configtx.ConfigTx, modify func(*etcdraft.ConfigMetadata) error) error {
+
+	return modifyConsensusType(c, func(consensusType *orderer.ConsensusType) error {
+		if consensusType.Type != ordererconfig.ConsensusTypeEtcdRaft {
+			return errors.Errorf("etcdraft metadata cannot be changed for consensus type %s", consensusType.Type)
+		}
+		metadata := &etcdraft.ConfigMetadata{}
+		if err := proto.Unmarshal(consensusType.Metadata, metadata); err != nil {
+			return errors.Wrap(err, "failed to unmarshal etcdraft metadata")
+		}
+		if err := modify(metadata); err != nil {
+			return err
+		}
+		raw, err := proto.Marshal(metadata)
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal etcdraft metadata")
+		}
+		consensusType.Metadata = raw
+		return nil
+	})
+}
+
+//isBFT -- whether the consensus type of the channel being updated is BFT
+func isBFT(c *configtx.ConfigTx) (bool, error) {
+
+	consensusType, err := ConsensusType(c.UpdatedConfig())
+	if err != nil {
+		return false, err
+	}
+	return consensusType.Type == smartbft.ConsensusType, nil
+}
+
+//modifyBFTConsenters -- modifies the consenter mapping of a BFT channel
+func modifyBFTConsenters(c *configtx.ConfigTx, modify func(*smartbft.Orderers) error) error {
+
+	orderers := &smartbft.Orderers{}
+	return modifyValue(c, []string{configtx.OrdererGroupKey}, smartbft.OrderersKey, orderers, func() error {
+		return modify(orderers)
+	})
+}
+
+func findBFTConsenter(consenters []*smartbft.Consenter, host string) int {
+	for i, consenter := range consenters {
+		if consenter.Host == host {
+			return i
+		}
+	}
+	return -1
+}
+
+func findConsenter(consenters []*etcdraft.Consenter, host string) int {
+	for i, consenter := range consenters {
+		if consenter.Host == host {
+			return i
+		}
+	}
+	return -1
+}
+
+func requireGroup(c *configtx.ConfigTx, group string) error {
+	if _, ok := c.UpdatedConfig().ChannelGroup.Groups[group]; !ok {
+		return errors.Errorf("group %s not found", group)
+	}
+	return nil
+}
+
+func memberPolicy(mspID string) configtx.Policy {
+	return configtx.Policy{Type: configtx.SignaturePolicyType, Rule: fmt.Sprintf("OR('%s.member')", mspID)}
+}
@@ -0,0 +1,144 @@
+package channelconfig
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-config/configtx"
+	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-test/tools/operator/fabricclient"
+	"github.com/hyperledger/fabric-test/tools/operator/ledger"
+	"github.com/hyperledger/fabric/protoutil"
+	"github.com/pkg/errors"
+)
+
+//UpdateError -- a config update that failed, naming the channel and the config group
+type UpdateError struct {
+	Channel string
+	Group   string
+	Err     error
+}
+
+func (e *UpdateError) Error() string {
+	return fmt.Sprintf("failed to update %s of channel %s: %s", e.Group, e.Channel, e.Err)
+}
+
+//Unwrap -- returns the underlying error
+func (e *UpdateError) Unwrap() error {
+	return e.Err
+}
+
+//Change -- a modification of one group of a channel config
+type Change struct {
+	Group  string
+	Modify func(c *configtx.ConfigTx) error
+}
+
+//Groups -- names of the groups modified by the changes
+func Groups(changes []Change) string {
+	var groups []string
+	for _, change := range changes {
+		groups = append(groups, change.Group)
+	}
+	return strings.Join(groups, ", ")
+}
+
+//ConfigFromBlock -- extracts the channel config from a config block
+func ConfigFromBlock(block *common.Block) (*common.Config, error) {
+
+	envelope, err := protoutil.ExtractEnvelope(block, 0)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to extract envelope from config block")
+	}
+	payload, err := protoutil.UnmarshalPayload(envelope.Payload)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal payload of config block")
+	}
+	configEnvelope := &common.ConfigEnvelope{}
+	if err := proto.Unmarshal(payload.Data, configEnvelope); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal config envelope")
+	}
+	if configEnvelope.Config == nil {
+		return nil, errors.Errorf("block %d is not a config block", block.Header.Number)
+	}
+	return configEnvelope.Config, nil
+}
+
+//Fetch -- fetches the latest config of a channel from a node
+func Fetch(source ledger.BlockSource, channel string) (*common.Config, error) {
+
+	block, err := FetchBlock(source, channel)
+	if err != nil {
+		return nil, err
+	}
+	return ConfigFromBlock(block)
+}
+
+//FetchBlock -- fetches the latest config block of a channel from a node
+func FetchBlock(source ledger.BlockSource, channel string) (*common.Block, error) {
+
+	height, err := source.Height(channel)
+	if err != nil {
+		return nil, err
+	}
+	var lastConfig uint64
+	err = source.Blocks(channel, height-1, height-1, func(block *common.Block) error {
+		lastConfig, err = protoutil.GetLastConfigIndexFromBlock(block)
+		return err
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get last config index of channel %s", channel)
+	}
+	var configBlock *common.Block
+	err = source.Blocks(channel, lastConfig, lastConfig, func(block *common.Block) error {
+		configBlock = block
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to fetch config block of channel %s", channel)
+	}
+	return configBlock, nil
+}
+
+//Apply -- applies the changes to the config of a channel, failing with an UpdateError on the first change that fails
+func Apply(channel string, config *common.Config, changes ...Change) (configtx.ConfigTx, error) {
+
+	c := configtx.New(config)
+	for _, change := range changes {
+		if err := change.Modify(&c); err != nil {
+			return c, &UpdateError{Channel: channel, Group: change.Group, Err: err}
+		}
+	}
+	return c, nil
+}
+
+//Changed -- whether the updated config differs from the original one
+func Changed(c configtx.ConfigTx) bool {
+	return !proto.Equal(c.OriginalConfig().ChannelGroup, c.UpdatedConfig().ChannelGroup)
+}
+
+//Envelope -- computes the config update, collects the signatures of the signers and signs the envelope with the submitter
+func Envelope(channel string, c configtx.ConfigTx, submitter *fabricclient.Identity, signers ...*fabricclient.Identity) (*common.Envelope, error) {
+
+	update, err := c.ComputeMarshaledUpdate(channel)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to compute config update of channel %s", channel)
+	}
+	var signatures []*common.ConfigSignature
+	for _, signer := range append(signers, submitter) {
+		signature, err := signer.SigningIdentity().CreateConfigSignature(update)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to sign config update of channel %s as %s", channel, signer.MSPID())
+		}
+		signatures = append(signatures, signature)
+	}
+	envelope, err := configtx.NewEnvelope(update, signatures...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create config update envelope of channel %s", channel)
+	}
+	if err := submitter.SigningIdentity().SignEnvelope(envelope); err != nil {
+		return nil, errors.Wrapf(err, "failed to sign config update envelope of channel %s", channel)
+	}
+	return envelope, nil
+}
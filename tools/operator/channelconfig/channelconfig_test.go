@@ -0,0 +1,396 @@
+package channelconfig
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-config/configtx"
+	ordererconfig "github.com/hyperledger/fabric-config/configtx/orderer"
+	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-protos-go/orderer"
+	"github.com/hyperledger/fabric-protos-go/orderer/etcdraft"
+	"github.com/hyperledger/fabric-test/tools/operator/fabricclient"
+	"github.com/hyperledger/fabric-test/tools/operator/smartbft"
+	"github.com/hyperledger/fabric/protoutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//genesisBlock -- builds the genesis block of a kafka based system channel or of an application channel with org1
+func genesisBlock(t *testing.T, systemChannel bool) *common.Block {
+
+	cert, _ := caCertificate(t)
+	msp := func(mspID string) configtx.MSP {
+		return configtx.MSP{
+			Name:         mspID,
+			RootCerts:    []*x509.Certificate{cert},
+			Admins:       []*x509.Certificate{cert},
+			TLSRootCerts: []*x509.Certificate{cert},
+		}
+	}
+	policies := func(mspID string) map[string]configtx.Policy {
+		return map[string]configtx.Policy{
+			configtx.ReadersPolicyKey:     {Type: configtx.SignaturePolicyType, Rule: "OR('" + mspID + ".member')"},
+			configtx.WritersPolicyKey:     {Type: configtx.SignaturePolicyType, Rule: "OR('" + mspID + ".member')"},
+			configtx.AdminsPolicyKey:      {Type: configtx.SignaturePolicyType, Rule: "OR('" + mspID + ".admin')"},
+			configtx.EndorsementPolicyKey: {Type: configtx.SignaturePolicyType, Rule: "OR('" + mspID + ".peer')"},
+		}
+	}
+	channelPolicies := map[string]configtx.Policy{
+		configtx.ReadersPolicyKey: {Type: configtx.ImplicitMetaPolicyType, Rule: "ANY Readers"},
+		configtx.WritersPolicyKey: {Type: configtx.ImplicitMetaPolicyType, Rule: "ANY Writers"},
+		configtx.AdminsPolicyKey:  {Type: configtx.ImplicitMetaPolicyType, Rule: "ANY Admins"},
+	}
+	ordererPolicies := map[string]configtx.Policy{configtx.BlockValidationPolicyKey: {Type: configtx.ImplicitMetaPolicyType, Rule: "ANY Writers"}}
+	for name, policy := range channelPolicies {
+		ordererPolicies[name] = policy
+	}
+	org1 := configtx.Organization{Name: "org1", MSP: msp("org1-mspid"), Policies: policies("org1-mspid")}
+	channel := configtx.Channel{
+		Orderer: configtx.Orderer{
+			OrdererType:  ordererconfig.ConsensusTypeKafka,
+			BatchTimeout: 2 * time.Second,
+			BatchSize:    ordererconfig.BatchSize{MaxMessageCount: 500, AbsoluteMaxBytes: 10 * 1024 * 1024, PreferredMaxBytes: 2 * 1024 * 1024},
+			Kafka:        ordererconfig.Kafka{Brokers: []string{"kafka0:9092"}},
+			Organizations: []configtx.Organization{
+				{Name: "ordererorg", MSP: msp("ordererorg-mspid"), Policies: policies("ordererorg-mspid"), OrdererEndpoints: []string{"orderer0-ordererorg:30000"}},
+			},
+			Capabilities: []string{"V1_4_2"},
+			Policies:     ordererPolicies,
+			State:        ordererconfig.ConsensusStateNormal,
+		},
+		Capabilities: []string{"V1_4_3"},
+		Policies:     channelPolicies,
+	}
+	if systemChannel {
+		channel.Consortiums = []configtx.Consortium{{Name: "FabricConsortium", Organizations: []configtx.Organization{org1}}}
+		block, err := configtx.NewSystemChannelGenesisBlock(channel, "orderersystemchannel")
+		require.NoError(t, err)
+		return block
+	}
+	applicationPolicies := map[string]configtx.Policy{configtx.EndorsementPolicyKey: {Type: configtx.ImplicitMetaPolicyType, Rule: "MAJORITY Endorsement"}}
+	for name, policy := range channelPolicies {
+		applicationPolicies[name] = policy
+	}
+	channel.Application = configtx.Application{
+		Organizations: []configtx.Organization{org1},
+		Capabilities:  []string{"V1_4_2"},
+		Policies:      applicationPolicies,
+		ACLs:          map[string]string{"peer/Propose": "/Channel/Application/Writers"},
+	}
+	block, err := configtx.NewApplicationChannelGenesisBlock(channel, "testorgschannel0")
+	require.NoError(t, err)
+	return block
+}
+
+func caCertificate(t *testing.T) (*x509.Certificate, *ecdsa.PrivateKey) {
+
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "ca.org1"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &privateKey.PublicKey, privateKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return cert, privateKey
+}
+
+func fixtureConfig(t *testing.T, systemChannel bool) *common.Config {
+	config, err := ConfigFromBlock(genesisBlock(t, systemChannel))
+	require.NoError(t, err)
+	return config
+}
+
+func TestAddCapability(t *testing.T) {
+
+	config := fixtureConfig(t, true)
+	c, err := Apply("orderersystemchannel", config, AddCapability("channel", "V2_0"), AddCapability("orderer", "V2_0"))
+	require.NoError(t, err)
+	assert.True(t, Changed(c))
+	capabilities, err := c.Channel().Capabilities()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"V1_4_3", "V2_0"}, capabilities)
+	capabilities, err = c.Orderer().Capabilities()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"V1_4_2", "V2_0"}, capabilities)
+
+	c, err = Apply("orderersystemchannel", config, AddCapability("channel", "V1_4_3"))
+	require.NoError(t, err)
+	assert.False(t, Changed(c))
+}
+
+func TestApplyReportsChannelAndGroup(t *testing.T) {
+
+	config := fixtureConfig(t, true)
+	_, err := Apply("orderersystemchannel", config, AddCapability("channel", "V2_0"), AddCapability("application", "V2_0"))
+	var updateErr *UpdateError
+	require.True(t, errors.As(err, &updateErr))
+	assert.Equal(t, "orderersystemchannel", updateErr.Channel)
+	assert.Equal(t, ApplicationGroup, updateErr.Group)
+	assert.EqualError(t, err, "failed to update /Channel/Application of channel orderersystemchannel: group Application not found")
+
+	_, err = Apply("orderersystemchannel", config, SetConsortiumOrgEndorsement("FabricConsortium", "org2", "org2-mspid"))
+	assert.EqualError(t, err, "failed to update /Channel/Consortiums/FabricConsortium/org2 of channel orderersystemchannel: organization org2 not found in consortium FabricConsortium")
+}
+
+func TestSetConsortiumOrgEndorsement(t *testing.T) {
+
+	config := fixtureConfig(t, true)
+	c, err := Apply("orderersystemchannel", config, SetConsortiumOrgEndorsement("FabricConsortium", "org1", "org1-mspid"))
+	require.NoError(t, err)
+	policies, err := c.Consortium("FabricConsortium").Organization("org1").Policies()
+	require.NoError(t, err)
+	assert.Equal(t, configtx.Policy{Type: configtx.SignaturePolicyType, Rule: "AND('org1-mspid.member')"}, policies[configtx.EndorsementPolicyKey])
+}
+
+func TestOrganizations(t *testing.T) {
+
+	config := fixtureConfig(t, true)
+	c, err := Apply("orderersystemchannel", config)
+	require.NoError(t, err)
+	org2, err := c.Consortium("FabricConsortium").Organization("org1").Configuration()
+	require.NoError(t, err)
+	org2.Name = "org2"
+
+	c, err = Apply("orderersystemchannel", config, AddConsortiumOrg("FabricConsortium", org2))
+	require.NoError(t, err)
+	assert.True(t, Changed(c))
+	assert.NotNil(t, c.Consortium("FabricConsortium").Organization("org2"))
+	c, err = Apply("orderersystemchannel", c.UpdatedConfig(), AddConsortiumOrg("FabricConsortium", org2))
+	require.NoError(t, err)
+	assert.False(t, Changed(c))
+	c, err = Apply("orderersystemchannel", c.UpdatedConfig(), RemoveConsortiumOrg("FabricConsortium", "org2"))
+	require.NoError(t, err)
+	assert.Nil(t, c.Consortium("FabricConsortium").Organization("org2"))
+	_, err = Apply("orderersystemchannel", config, AddConsortiumOrg("SampleConsortium", org2))
+	assert.EqualError(t, err, "failed to update /Channel/Consortiums/SampleConsortium/org2 of channel orderersystemchannel: consortium SampleConsortium not found")
+
+	config = fixtureConfig(t, false)
+	c, err = Apply("testorgschannel0", config, AddApplicationOrg(org2))
+	require.NoError(t, err)
+	assert.True(t, Changed(c))
+	assert.NotNil(t, c.Application().Organization("org2"))
+	c, err = Apply("testorgschannel0", c.UpdatedConfig(), RemoveApplicationOrg("org2"))
+	require.NoError(t, err)
+	assert.Nil(t, c.Application().Organization("org2"))
+}
+
+func TestApplicationPolicies(t *testing.T) {
+
+	config := fixtureConfig(t, false)
+	c, err := Apply("testorgschannel0", config,
+		AddCapability("application", "V2_0"),
+		SetApplicationOrgEndorsement("org1", "org1-mspid"),
+		SetApplicationEndorsementPolicies(),
+		AddLifecycleACLs(),
+	)
+	require.NoError(t, err)
+
+	policies, err := c.Application().Policies()
+	require.NoError(t, err)
+	assert.Equal(t, "ANY Endorsement", policies[configtx.EndorsementPolicyKey].Rule)
+	assert.Equal(t, "ANY Endorsement", policies[configtx.LifecycleEndorsementPolicyKey].Rule)
+	policies, err = c.Application().Organization("org1").Policies()
+	require.NoError(t, err)
+	assert.Equal(t, "AND('org1-mspid.member')", policies[configtx.EndorsementPolicyKey].Rule)
+	acls, err := c.Application().ACLs()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"peer/Propose":                         "/Channel/Application/Writers",
+		"_lifecycle/CommitChaincodeDefinition": "/Channel/Application/Writers",
+		"_lifecycle/QueryChaincodeDefinition":  "/Channel/Application/Readers",
+		"_lifecycle/QueryNamespaceDefinitions": "/Channel/Application/Readers",
+	}, acls)
+}
+
+func TestMigrateToEtcdRaft(t *testing.T) {
+
+	config := fixtureConfig(t, false)
+	consenters := []*etcdraft.Consenter{{Host: "orderer0-ordererorg", Port: 30000, ClientTlsCert: []byte("cert"), ServerTlsCert: []byte("cert")}}
+	options := &etcdraft.Options{TickInterval: "500ms", ElectionTick: 10, HeartbeatTick: 1, MaxInflightBlocks: 5, SnapshotIntervalSize: 100}
+
+	_, err := Apply("testorgschannel0", config, MigrateToEtcdRaft(consenters, options))
+	assert.EqualError(t, err, "failed to update /Channel/Orderer of channel testorgschannel0: consensus type can only be changed in maintenance mode, state is STATE_NORMAL")
+
+	c, err := Apply("testorgschannel0", config, SetConsensusState(orderer.ConsensusType_STATE_MAINTENANCE))
+	require.NoError(t, err)
+	c, err = Apply("testorgschannel0", c.UpdatedConfig(), MigrateToEtcdRaft(consenters, options))
+	require.NoError(t, err)
+	consensusType, err := ConsensusType(c.UpdatedConfig())
+	require.NoError(t, err)
+	assert.Equal(t, "etcdraft", consensusType.Type)
+	assert.Equal(t, orderer.ConsensusType_STATE_MAINTENANCE, consensusType.State)
+	metadata := &etcdraft.ConfigMetadata{}
+	require.NoError(t, proto.Unmarshal(consensusType.Metadata, metadata))
+	assert.True(t, proto.Equal(&etcdraft.ConfigMetadata{Consenters: consenters, Options: options}, metadata))
+	original, err := ConsensusType(c.OriginalConfig())
+	require.NoError(t, err)
+	assert.Equal(t, "kafka", original.Type)
+}
+
+func TestSetBFTConsensus(t *testing.T) {
+
+	config := fixtureConfig(t, false)
+	_, err := BFTConsenters(config)
+	assert.EqualError(t, err, "consensus type is kafka, not BFT")
+
+	consenters := []*smartbft.Consenter{{Id: 1, Host: "orderer0-ordererorg", Port: 30000, MspId: "ordererorg-mspid", Identity: []byte("cert")}}
+	options := &smartbft.Options{RequestBatchMaxCount: 100, RequestBatchMaxInterval: "50ms", LeaderRotation: smartbft.RotationOn}
+	c, err := Apply("testorgschannel0", config, SetBFTConsensus(consenters, options))
+	require.NoError(t, err)
+	consensusType, err := ConsensusType(c.UpdatedConfig())
+	require.NoError(t, err)
+	assert.Equal(t, "BFT", consensusType.Type)
+	assert.Equal(t, orderer.ConsensusType_STATE_NORMAL, consensusType.State)
+	metadata := &smartbft.Options{}
+	require.NoError(t, proto.Unmarshal(consensusType.Metadata, metadata))
+	assert.True(t, proto.Equal(options, metadata))
+	mapping, err := BFTConsenters(c.UpdatedConfig())
+	require.NoError(t, err)
+	require.Len(t, mapping, 1)
+	assert.True(t, proto.Equal(consenters[0], mapping[0]))
+	assert.Equal(t, configtx.AdminsPolicyKey, c.UpdatedConfig().ChannelGroup.Groups[configtx.OrdererGroupKey].Values[smartbft.OrderersKey].ModPolicy)
+	assert.NotContains(t, c.UpdatedConfig().ChannelGroup.Values, configtx.OrdererAddressesKey)
+}
+
+func TestConsenters(t *testing.T) {
+
+	_, err := Apply("testorgschannel0", fixtureConfig(t, false), AddConsenter("ordererorg", Consenter{Host: "orderer1-ordererorg", Port: 30001}))
+	assert.EqualError(t, err, "failed to update /Channel/Orderer of channel testorgschannel0: etcdraft metadata cannot be changed for consensus type kafka")
+
+	c, err := Apply("testorgschannel0", fixtureConfig(t, false), SetConsensusState(orderer.ConsensusType_STATE_MAINTENANCE))
+	require.NoError(t, err)
+	c, err = Apply("testorgschannel0", c.UpdatedConfig(), MigrateToEtcdRaft([]*etcdraft.Consenter{{Host: "orderer0-ordererorg", Port: 30000, ClientTlsCert: []byte("cert0"), ServerTlsCert: []byte("cert0")}}, &etcdraft.Options{}))
+	require.NoError(t, err)
+	config := c.UpdatedConfig()
+
+	added := Consenter{Host: "orderer1-ordererorg", Port: 30001, MSPID: "ordererorg-mspid", Identity: []byte("identity1"), TLSCert: []byte("cert1")}
+	c, err = Apply("testorgschannel0", config, AddConsenter("ordererorg", added))
+	require.NoError(t, err)
+	consenters, err := Consenters(c.UpdatedConfig())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"orderer0-ordererorg", "orderer1-ordererorg"}, []string{consenters[0].Host, consenters[1].Host})
+	assert.True(t, proto.Equal(&etcdraft.Consenter{Host: "orderer1-ordererorg", Port: 30001, ClientTlsCert: []byte("cert1"), ServerTlsCert: []byte("cert1")}, consenters[1]))
+	ordererOrg, err := c.Orderer().Organization("ordererorg").Configuration()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"orderer0-ordererorg:30000", "orderer1-ordererorg:30001"}, ordererOrg.OrdererEndpoints)
+	c, err = Apply("testorgschannel0", c.UpdatedConfig(), AddConsenter("ordererorg", added))
+	require.NoError(t, err)
+	assert.False(t, Changed(c))
+
+	c, err = Apply("testorgschannel0", c.UpdatedConfig(), SetConsenterCert("orderer1-ordererorg", []byte("cert2")))
+	require.NoError(t, err)
+	consenters, err = Consenters(c.UpdatedConfig())
+	require.NoError(t, err)
+	assert.Equal(t, []byte("cert2"), consenters[1].ClientTlsCert)
+	assert.Equal(t, []byte("cert2"), consenters[1].ServerTlsCert)
+	_, err = Apply("testorgschannel0", c.UpdatedConfig(), SetConsenterCert("orderer2-ordererorg", []byte("cert2")))
+	assert.EqualError(t, err, "failed to update /Channel/Orderer of channel testorgschannel0: orderer2-ordererorg is not a consenter of the channel")
+
+	c, err = Apply("testorgschannel0", c.UpdatedConfig(), RemoveConsenter("ordererorg", "orderer0-ordererorg"))
+	require.NoError(t, err)
+	consenters, err = Consenters(c.UpdatedConfig())
+	require.NoError(t, err)
+	assert.Len(t, consenters, 1)
+	assert.Equal(t, "orderer1-ordererorg", consenters[0].Host)
+	ordererOrg, err = c.Orderer().Organization("ordererorg").Configuration()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"orderer1-ordererorg:30001"}, ordererOrg.OrdererEndpoints)
+	_, err = Apply("testorgschannel0", c.UpdatedConfig(), RemoveConsenter("ordererorg", "orderer1-ordererorg"))
+	assert.EqualError(t, err, "failed to update /Channel/Orderer of channel testorgschannel0: cannot remove orderer1-ordererorg, the last consenter of the channel")
+}
+
+func TestBFTConsenters(t *testing.T) {
+
+	first := &smartbft.Consenter{Id: 1, Host: "orderer0-ordererorg", Port: 30000, MspId: "ordererorg-mspid", Identity: []byte("identity0"), ClientTlsCert: []byte("cert0"), ServerTlsCert: []byte("cert0")}
+	c, err := Apply("testorgschannel0", fixtureConfig(t, false), SetBFTConsensus([]*smartbft.Consenter{first}, &smartbft.Options{}))
+	require.NoError(t, err)
+	config := c.UpdatedConfig()
+
+	added := Consenter{Host: "orderer1-ordererorg", Port: 30001, MSPID: "ordererorg-mspid", Identity: []byte("identity1"), TLSCert: []byte("cert1")}
+	c, err = Apply("testorgschannel0", config, AddConsenter("ordererorg", added))
+	require.NoError(t, err)
+	mapping, err := BFTConsenters(c.UpdatedConfig())
+	require.NoError(t, err)
+	require.Len(t, mapping, 2)
+	assert.True(t, proto.Equal(&smartbft.Consenter{Id: 2, Host: "orderer1-ordererorg", Port: 30001, MspId: "ordererorg-mspid", Identity: []byte("identity1"), ClientTlsCert: []byte("cert1"), ServerTlsCert: []byte("cert1")}, mapping[1]))
+	ordererOrg, err := c.Orderer().Organization("ordererorg").Configuration()
+	require.NoError(t, err)
+	assert.Contains(t, ordererOrg.OrdererEndpoints, "orderer1-ordererorg:30001")
+	consensusType, err := ConsensusType(c.UpdatedConfig())
+	require.NoError(t, err)
+	assert.Equal(t, "BFT", consensusType.Type)
+	c, err = Apply("testorgschannel0", c.UpdatedConfig(), AddConsenter("ordererorg", added))
+	require.NoError(t, err)
+	assert.False(t, Changed(c))
+
+	c, err = Apply("testorgschannel0", c.UpdatedConfig(), SetConsenterCert("orderer1-ordererorg", []byte("cert2")))
+	require.NoError(t, err)
+	mapping, err = BFTConsenters(c.UpdatedConfig())
+	require.NoError(t, err)
+	assert.Equal(t, []byte("cert2"), mapping[1].ClientTlsCert)
+	assert.Equal(t, []byte("cert2"), mapping[1].ServerTlsCert)
+	assert.Equal(t, []byte("identity1"), mapping[1].Identity)
+	_, err = Apply("testorgschannel0", c.UpdatedConfig(), SetConsenterCert("orderer2-ordererorg", []byte("cert2")))
+	assert.EqualError(t, err, "failed to update /Channel/Orderer of channel testorgschannel0: orderer2-ordererorg is not a consenter of the channel")
+
+	c, err = Apply("testorgschannel0", c.UpdatedConfig(), RemoveConsenter("ordererorg", "orderer0-ordererorg"))
+	require.NoError(t, err)
+	mapping, err = BFTConsenters(c.UpdatedConfig())
+	require.NoError(t, err)
+	require.Len(t, mapping, 1)
+	assert.Equal(t, uint32(2), mapping[0].Id)
+	ordererOrg, err = c.Orderer().Organization("ordererorg").Configuration()
+	require.NoError(t, err)
+	assert.NotContains(t, ordererOrg.OrdererEndpoints, "orderer0-ordererorg:30000")
+	c, err = Apply("testorgschannel0", c.UpdatedConfig(), AddConsenter("ordererorg", Consenter{Host: "orderer2-ordererorg", Port: 30002, MSPID: "ordererorg-mspid"}))
+	require.NoError(t, err)
+	mapping, err = BFTConsenters(c.UpdatedConfig())
+	require.NoError(t, err)
+	assert.Equal(t, uint32(3), mapping[1].Id, "the next id follows the highest one")
+	_, err = Apply("testorgschannel0", config, RemoveConsenter("ordererorg", "orderer0-ordererorg"))
+	assert.EqualError(t, err, "failed to update /Channel/Orderer of channel testorgschannel0: cannot remove orderer0-ordererorg, the last consenter of the channel")
+}
+
+func TestEnvelope(t *testing.T) {
+
+	cert, privateKey := caCertificate(t)
+	keyBytes, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	require.NoError(t, err)
+	identity, err := fabricclient.NewIdentity("ordererorg-mspid",
+		string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyBytes})))
+	require.NoError(t, err)
+
+	c, err := Apply("testorgschannel0", fixtureConfig(t, false), AddCapability("channel", "V2_0"))
+	require.NoError(t, err)
+	envelope, err := Envelope("testorgschannel0", c, identity, identity)
+	require.NoError(t, err)
+	assert.NotEmpty(t, envelope.Signature)
+	payload, err := protoutil.UnmarshalPayload(envelope.Payload)
+	require.NoError(t, err)
+	channelHeader, err := protoutil.UnmarshalChannelHeader(payload.Header.ChannelHeader)
+	require.NoError(t, err)
+	assert.Equal(t, "testorgschannel0", channelHeader.ChannelId)
+	assert.Equal(t, int32(common.HeaderType_CONFIG_UPDATE), channelHeader.Type)
+	configUpdateEnvelope := &common.ConfigUpdateEnvelope{}
+	require.NoError(t, proto.Unmarshal(payload.Data, configUpdateEnvelope))
+	assert.Len(t, configUpdateEnvelope.Signatures, 2)
+}
@@ -0,0 +1,379 @@
+package channelconfig
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-config/configtx"
+	ordererconfig "github.com/hyperledger/fabric-config/configtx/orderer"
+	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-protos-go/msp"
+	"github.com/hyperledger/fabric-protos-go/orderer"
+	"github.com/hyperledger/fabric-protos-go/orderer/etcdraft"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	mspConfigBuilder "github.com/hyperledger/fabric/msp"
+	"github.com/pkg/errors"
+)
+
+//editor -- builds the change of one kind of edit from the keys of its path and the new value
+type editor func(keys []string, value interface{}) (Change, error)
+
+//editors -- the supported edit paths, with "[]" in place of the keys
+var editors = map[string]editor{
+	"orderer.batchSize.maxMessageCount":             editBatchSize(func(b *orderer.BatchSize, v uint32) { b.MaxMessageCount = v }, toUint32),
+	"orderer.batchSize.absoluteMaxBytes":            editBatchSize(func(b *orderer.BatchSize, v uint32) { b.AbsoluteMaxBytes = v }, toBytes),
+	"orderer.batchSize.preferredMaxBytes":           editBatchSize(func(b *orderer.BatchSize, v uint32) { b.PreferredMaxBytes = v }, toBytes),
+	"orderer.batchTimeout":                          editBatchTimeout,
+	"orderer.etcdraft.options.tickInterval":         editRaftOptions(editTickInterval),
+	"orderer.etcdraft.options.electionTick":         editRaftOptions(editUint32(func(o *etcdraft.Options, v uint32) { o.ElectionTick = v }, toUint32)),
+	"orderer.etcdraft.options.heartbeatTick":        editRaftOptions(editUint32(func(o *etcdraft.Options, v uint32) { o.HeartbeatTick = v }, toUint32)),
+	"orderer.etcdraft.options.maxInflightBlocks":    editRaftOptions(editUint32(func(o *etcdraft.Options, v uint32) { o.MaxInflightBlocks = v }, toUint32)),
+	"orderer.etcdraft.options.snapshotIntervalSize": editRaftOptions(editUint32(func(o *etcdraft.Options, v uint32) { o.SnapshotIntervalSize = v }, toBytes)),
+	"application.acls[]":                            editACL,
+	"application.orgs[].anchorPeers":                editAnchorPeers,
+	"application.orgs[].msp":                        editMSP(configtx.ApplicationGroupKey),
+	"orderer.orgs[].msp":                            editMSP(configtx.OrdererGroupKey),
+}
+
+//Edit -- a change setting the config element at a dotted path, such as orderer.batchSize.maxMessageCount
+//or application.acls["qscc/GetBlockByNumber"], to value
+func Edit(path string, value interface{}) (Change, error) {
+
+	pattern, keys, err := parsePath(path)
+	if err != nil {
+		return Change{}, err
+	}
+	edit, ok := editors[pattern]
+	if !ok {
+		return Change{}, errors.Errorf("unsupported config path %s, supported paths are %s", path, strings.Join(EditPaths(), ", "))
+	}
+	change, err := edit(keys, value)
+	if err != nil {
+		return Change{}, errors.Wrapf(err, "invalid value for %s", path)
+	}
+	return change, nil
+}
+
+//EditPaths -- the supported edit paths, with "[]" in place of the keys
+func EditPaths() []string {
+	var paths []string
+	for pattern := range editors {
+		paths = append(paths, pattern)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+//parsePath -- splits a path such as application.orgs["org1"].msp into the pattern application.orgs[].msp and the keys
+func parsePath(path string) (string, []string, error) {
+
+	var pattern strings.Builder
+	var keys []string
+	rest := path
+	for rest != "" {
+		start := strings.Index(rest, "[")
+		if start < 0 {
+			pattern.WriteString(rest)
+			break
+		}
+		pattern.WriteString(rest[:start])
+		end := strings.Index(rest[start:], "]")
+		if end < 0 {
+			return "", nil, errors.Errorf("unterminated key in config path %s", path)
+		}
+		key, err := strconv.Unquote(rest[start+1 : start+end])
+		if err != nil || key == "" {
+			return "", nil, errors.Errorf("key %s of config path %s must be a non-empty quoted string", rest[start+1:start+end], path)
+		}
+		keys = append(keys, key)
+		pattern.WriteString("[]")
+		rest = rest[start+end+1:]
+	}
+	return pattern.String(), keys, nil
+}
+
+func editBatchSize(set func(*orderer.BatchSize, uint32), convert func(interface{}) (uint32, error)) editor {
+
+	return func(keys []string, value interface{}) (Change, error) {
+		v, err := convert(value)
+		if err != nil {
+			return Change{}, err
+		}
+		return Change{Group: OrdererGroup + "/" + ordererconfig.BatchSizeKey, Modify: func(c *configtx.ConfigTx) error {
+			batchSize := &orderer.BatchSize{}
+			return modifyValue(c, []string{configtx.OrdererGroupKey}, ordererconfig.BatchSizeKey, batchSize, func() error {
+				set(batchSize, v)
+				return nil
+			})
+		}}, nil
+	}
+}
+
+func editBatchTimeout(keys []string, value interface{}) (Change, error) {
+
+	timeout, err := toDuration(value)
+	if err != nil {
+		return Change{}, err
+	}
+	return Change{Group: OrdererGroup + "/" + ordererconfig.BatchTimeoutKey, Modify: func(c *configtx.ConfigTx) error {
+		batchTimeout := &orderer.BatchTimeout{}
+		return modifyValue(c, []string{configtx.OrdererGroupKey}, ordererconfig.BatchTimeoutKey, batchTimeout, func() error {
+			batchTimeout.Timeout = timeout.String()
+			return nil
+		})
+	}}, nil
+}
+
+func editRaftOptions(edit func(value interface{}) (func(*etcdraft.Options), error)) editor {
+
+	return func(keys []string, value interface{}) (Change, error) {
+		set, err := edit(value)
+		if err != nil {
+			return Change{}, err
+		}
+		return Change{Group: OrdererGroup + "/" + ordererconfig.ConsensusTypeKey, Modify: func(c *configtx.ConfigTx) error {
+			return modifyConsensusType(c, func(consensusType *orderer.ConsensusType) error {
+				if consensusType.Type != ordererconfig.ConsensusTypeEtcdRaft {
+					return errors.Errorf("etcdraft options cannot be set for consensus type %s", consensusType.Type)
+				}
+				metadata := &etcdraft.ConfigMetadata{}
+				if err := proto.Unmarshal(consensusType.Metadata, metadata); err != nil {
+					return errors.Wrap(err, "failed to unmarshal etcdraft metadata")
+				}
+				if metadata.Options == nil {
+					metadata.Options = &etcdraft.Options{}
+				}
+				set(metadata.Options)
+				raw, err := proto.Marshal(metadata)
+				if err != nil {
+					return errors.Wrap(err, "failed to marshal etcdraft metadata")
+				}
+				consensusType.Metadata = raw
+				return nil
+			})
+		}}, nil
+	}
+}
+
+func editTickInterval(value interface{}) (func(*etcdraft.Options), error) {
+
+	tickInterval, err := toDuration(value)
+	if err != nil {
+		return nil, err
+	}
+	return func(options *etcdraft.Options) { options.TickInterval = tickInterval.String() }, nil
+}
+
+func editUint32(set func(*etcdraft.Options, uint32), convert func(interface{}) (uint32, error)) func(interface{}) (func(*etcdraft.Options), error) {
+
+	return func(value interface{}) (func(*etcdraft.Options), error) {
+		v, err := convert(value)
+		if err != nil {
+			return nil, err
+		}
+		return func(options *etcdraft.Options) { set(options, v) }, nil
+	}
+}
+
+//editACL -- sets the policy of a resource in the ACLs of the application group, a nil value removes the resource
+func editACL(keys []string, value interface{}) (Change, error) {
+
+	resource := keys[0]
+	var policyRef string
+	if value != nil {
+		var ok bool
+		if policyRef, ok = value.(string); !ok || policyRef == "" {
+			return Change{}, errors.Errorf("policy reference must be a non-empty string, got %v", value)
+		}
+	}
+	return Change{Group: ApplicationGroup + "/" + configtx.ACLsKey, Modify: func(c *configtx.ConfigTx) error {
+		if err := requireGroup(c, configtx.ApplicationGroupKey); err != nil {
+			return err
+		}
+		acls := &peer.ACLs{}
+		return modifyValue(c, []string{configtx.ApplicationGroupKey}, configtx.ACLsKey, acls, func() error {
+			if acls.Acls == nil {
+				acls.Acls = make(map[string]*peer.APIResource)
+			}
+			if value == nil {
+				delete(acls.Acls, resource)
+				return nil
+			}
+			acls.Acls[resource] = &peer.APIResource{PolicyRef: policyRef}
+			return nil
+		})
+	}}, nil
+}
+
+//editAnchorPeers -- replaces the anchor peers of an application organization with a list of host:port endpoints
+func editAnchorPeers(keys []string, value interface{}) (Change, error) {
+
+	orgName := keys[0]
+	var endpoints []interface{}
+	if value != nil {
+		var ok bool
+		if endpoints, ok = value.([]interface{}); !ok {
+			return Change{}, errors.Errorf("anchor peers must be a list of host:port endpoints, got %v", value)
+		}
+	}
+	var anchorPeers []*peer.AnchorPeer
+	for _, endpoint := range endpoints {
+		host, portString, err := net.SplitHostPort(fmt.Sprint(endpoint))
+		if err != nil {
+			return Change{}, errors.Wrapf(err, "invalid anchor peer %v", endpoint)
+		}
+		port, err := strconv.ParseUint(portString, 10, 16)
+		if err != nil {
+			return Change{}, errors.Wrapf(err, "invalid port of anchor peer %v", endpoint)
+		}
+		anchorPeers = append(anchorPeers, &peer.AnchorPeer{Host: host, Port: int32(port)})
+	}
+	return Change{Group: fmt.Sprintf("%s/%s/%s", ApplicationGroup, orgName, configtx.AnchorPeersKey), Modify: func(c *configtx.ConfigTx) error {
+		if err := requireOrg(c, configtx.ApplicationGroupKey, orgName); err != nil {
+			return err
+		}
+		config := &peer.AnchorPeers{}
+		return modifyValue(c, []string{configtx.ApplicationGroupKey, orgName}, configtx.AnchorPeersKey, config, func() error {
+			config.AnchorPeers = anchorPeers
+			return nil
+		})
+	}}, nil
+}
+
+//editMSP -- reloads the MSP of an organization from an MSP directory, keeping the MSP ID of the organization
+func editMSP(groupKey string) editor {
+
+	return func(keys []string, value interface{}) (Change, error) {
+		orgName := keys[0]
+		dir, ok := value.(string)
+		if !ok || dir == "" {
+			return Change{}, errors.Errorf("msp must be the path of an msp directory, got %v", value)
+		}
+		return Change{Group: fmt.Sprintf("/Channel/%s/%s/%s", groupKey, orgName, configtx.MSPKey), Modify: func(c *configtx.ConfigTx) error {
+			if err := requireOrg(c, groupKey, orgName); err != nil {
+				return err
+			}
+			mspConfig := &msp.MSPConfig{}
+			return modifyValue(c, []string{groupKey, orgName}, configtx.MSPKey, mspConfig, func() error {
+				fabricMSPConfig := &msp.FabricMSPConfig{}
+				if err := proto.Unmarshal(mspConfig.Config, fabricMSPConfig); err != nil {
+					return errors.Wrapf(err, "failed to unmarshal msp of organization %s", orgName)
+				}
+				loaded, err := mspConfigBuilder.GetVerifyingMspConfig(dir, fabricMSPConfig.Name, "bccsp")
+				if err != nil {
+					return errors.Wrapf(err, "failed to load msp of organization %s from %s", orgName, dir)
+				}
+				*mspConfig = *loaded
+				return nil
+			})
+		}}, nil
+	}
+}
+
+//modifyValue -- unmarshals a config value of the group at groupPath into message, modifies it and marshals it back,
+//creating the value with the Admins mod policy if the group does not have it yet
+func modifyValue(c *configtx.ConfigTx, groupPath []string, key string, message proto.Message, modify func() error) error {
+
+	group := c.UpdatedConfig().ChannelGroup
+	for _, groupKey := range groupPath {
+		var ok bool
+		if group, ok = group.Groups[groupKey]; !ok {
+			return errors.Errorf("group %s not found", groupKey)
+		}
+	}
+	value, ok := group.Values[key]
+	if !ok {
+		value = &common.ConfigValue{ModPolicy: configtx.AdminsPolicyKey}
+	}
+	if err := proto.Unmarshal(value.Value, message); err != nil {
+		return errors.Wrapf(err, "failed to unmarshal %s", key)
+	}
+	if err := modify(); err != nil {
+		return err
+	}
+	raw, err := proto.Marshal(message)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal %s", key)
+	}
+	value.Value = raw
+	group.Values[key] = value
+	return nil
+}
+
+func requireOrg(c *configtx.ConfigTx, groupKey, orgName string) error {
+	if err := requireGroup(c, groupKey); err != nil {
+		return err
+	}
+	if _, ok := c.UpdatedConfig().ChannelGroup.Groups[groupKey].Groups[orgName]; !ok {
+		return errors.Errorf("organization %s not found in group %s", orgName, groupKey)
+	}
+	return nil
+}
+
+func toUint32(value interface{}) (uint32, error) {
+
+	var v uint64
+	var err error
+	switch value := value.(type) {
+	case int:
+		if value < 0 {
+			return 0, errors.Errorf("%d is negative", value)
+		}
+		v = uint64(value)
+	case string:
+		v, err = strconv.ParseUint(strings.TrimSpace(value), 10, 32)
+		if err != nil {
+			return 0, errors.Errorf("%s is not a number", value)
+		}
+	default:
+		return 0, errors.Errorf("%v is not a number", value)
+	}
+	if v > 1<<32-1 {
+		return 0, errors.Errorf("%d is out of range", v)
+	}
+	return uint32(v), nil
+}
+
+//toBytes -- converts a number of bytes or a size such as "10 MB" or "512 KB" to bytes
+func toBytes(value interface{}) (uint32, error) {
+
+	size, ok := value.(string)
+	if !ok {
+		return toUint32(value)
+	}
+	units := map[string]uint64{"MB": 1024 * 1024, "KB": 1024}
+	for suffix, unit := range units {
+		if strings.HasSuffix(size, suffix) {
+			v, err := toUint32(strings.TrimSpace(strings.TrimSuffix(size, suffix)))
+			if err != nil {
+				return 0, err
+			}
+			if uint64(v)*unit > 1<<32-1 {
+				return 0, errors.Errorf("%s is out of range", size)
+			}
+			return uint32(uint64(v) * unit), nil
+		}
+	}
+	return toUint32(size)
+}
+
+func toDuration(value interface{}) (time.Duration, error) {
+
+	s, ok := value.(string)
+	if !ok {
+		return 0, errors.Errorf("%v is not a duration", value)
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, errors.Errorf("%s is not a duration", s)
+	}
+	if d <= 0 {
+		return 0, errors.Errorf("%s is not positive", s)
+	}
+	return d, nil
+}
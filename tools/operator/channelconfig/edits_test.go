@@ -0,0 +1,170 @@
+package channelconfig
+
+import (
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-config/configtx"
+	"github.com/hyperledger/fabric-protos-go/msp"
+	"github.com/hyperledger/fabric-protos-go/orderer"
+	"github.com/hyperledger/fabric-protos-go/orderer/etcdraft"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePath(t *testing.T) {
+
+	tests := []struct {
+		path    string
+		pattern string
+		keys    []string
+		err     string
+	}{
+		{path: "orderer.batchTimeout", pattern: "orderer.batchTimeout"},
+		{path: `application.acls["qscc/GetBlockByNumber"]`, pattern: "application.acls[]", keys: []string{"qscc/GetBlockByNumber"}},
+		{path: `application.orgs["org1"].anchorPeers`, pattern: "application.orgs[].anchorPeers", keys: []string{"org1"}},
+		{path: `application.orgs[org1].msp`, err: "key org1 of config path application.orgs[org1].msp must be a non-empty quoted string"},
+		{path: `application.acls["peer/Propose"`, err: `unterminated key in config path application.acls["peer/Propose"`},
+	}
+	for _, test := range tests {
+		pattern, keys, err := parsePath(test.path)
+		if test.err != "" {
+			assert.EqualError(t, err, test.err, test.path)
+			continue
+		}
+		require.NoError(t, err, test.path)
+		assert.Equal(t, test.pattern, pattern, test.path)
+		assert.Equal(t, test.keys, keys, test.path)
+	}
+}
+
+func TestEditValues(t *testing.T) {
+
+	tests := []struct {
+		path  string
+		value interface{}
+		err   string
+	}{
+		{path: "orderer.batchSize.maxMessageCount", value: 100},
+		{path: "orderer.batchSize.maxMessageCount", value: -1, err: "invalid value for orderer.batchSize.maxMessageCount: -1 is negative"},
+		{path: "orderer.batchSize.absoluteMaxBytes", value: "99 MB"},
+		{path: "orderer.batchSize.preferredMaxBytes", value: "5000 MB", err: "invalid value for orderer.batchSize.preferredMaxBytes: 5000 MB is out of range"},
+		{path: "orderer.batchTimeout", value: "1s"},
+		{path: "orderer.batchTimeout", value: 1, err: "invalid value for orderer.batchTimeout: 1 is not a duration"},
+		{path: `application.acls["qscc/GetBlockByNumber"]`, value: 5, err: `invalid value for application.acls["qscc/GetBlockByNumber"]: policy reference must be a non-empty string, got 5`},
+		{path: `application.orgs["org1"].anchorPeers`, value: []interface{}{"peer0-org1"}, err: `invalid value for application.orgs["org1"].anchorPeers: invalid anchor peer peer0-org1: address peer0-org1: missing port in address`},
+		{path: "orderer.batchSize", value: 100, err: "unsupported config path orderer.batchSize"},
+	}
+	for _, test := range tests {
+		_, err := Edit(test.path, test.value)
+		if test.err != "" {
+			require.Error(t, err, test.path)
+			assert.Contains(t, err.Error(), test.err, test.path)
+			continue
+		}
+		assert.NoError(t, err, test.path)
+	}
+}
+
+func TestEditOrderer(t *testing.T) {
+
+	config := fixtureConfig(t, false)
+	changes := editChanges(t, map[string]interface{}{
+		"orderer.batchSize.maxMessageCount":  100,
+		"orderer.batchSize.absoluteMaxBytes": "99 MB",
+		"orderer.batchTimeout":               "500ms",
+	})
+	c, err := Apply("testorgschannel0", config, changes...)
+	require.NoError(t, err)
+	assert.True(t, Changed(c))
+	ordererConfig, err := c.Orderer().Configuration()
+	require.NoError(t, err)
+	assert.Equal(t, uint32(100), ordererConfig.BatchSize.MaxMessageCount)
+	assert.Equal(t, uint32(99*1024*1024), ordererConfig.BatchSize.AbsoluteMaxBytes)
+	assert.Equal(t, uint32(2*1024*1024), ordererConfig.BatchSize.PreferredMaxBytes)
+	assert.Equal(t, "500ms", ordererConfig.BatchTimeout.String())
+
+	c, err = Apply("testorgschannel0", config, editChanges(t, map[string]interface{}{"orderer.batchTimeout": "2s"})...)
+	require.NoError(t, err)
+	assert.False(t, Changed(c))
+
+	_, err = Apply("testorgschannel0", config, editChanges(t, map[string]interface{}{"orderer.etcdraft.options.electionTick": 20})...)
+	assert.EqualError(t, err, "failed to update /Channel/Orderer/ConsensusType of channel testorgschannel0: etcdraft options cannot be set for consensus type kafka")
+
+	c, err = Apply("testorgschannel0", config, SetConsensusState(orderer.ConsensusType_STATE_MAINTENANCE))
+	require.NoError(t, err)
+	options := &etcdraft.Options{TickInterval: "500ms", ElectionTick: 10, HeartbeatTick: 1, MaxInflightBlocks: 5}
+	c, err = Apply("testorgschannel0", c.UpdatedConfig(), MigrateToEtcdRaft([]*etcdraft.Consenter{{Host: "orderer0-ordererorg", Port: 30000}}, options))
+	require.NoError(t, err)
+	c, err = Apply("testorgschannel0", c.UpdatedConfig(), editChanges(t, map[string]interface{}{
+		"orderer.etcdraft.options.electionTick":         20,
+		"orderer.etcdraft.options.tickInterval":         "1s",
+		"orderer.etcdraft.options.snapshotIntervalSize": "16 MB",
+	})...)
+	require.NoError(t, err)
+	consensusType, err := ConsensusType(c.UpdatedConfig())
+	require.NoError(t, err)
+	metadata := &etcdraft.ConfigMetadata{}
+	require.NoError(t, proto.Unmarshal(consensusType.Metadata, metadata))
+	assert.True(t, proto.Equal(&etcdraft.Options{TickInterval: "1s", ElectionTick: 20, HeartbeatTick: 1, MaxInflightBlocks: 5, SnapshotIntervalSize: 16 * 1024 * 1024}, metadata.Options))
+	assert.Len(t, metadata.Consenters, 1)
+}
+
+func TestEditApplication(t *testing.T) {
+
+	config := fixtureConfig(t, false)
+	c, err := Apply("testorgschannel0", config, editChanges(t, map[string]interface{}{
+		`application.acls["qscc/GetBlockByNumber"]`: "/Channel/Application/Writers",
+		`application.acls["peer/Propose"]`:          nil,
+		`application.orgs["org1"].anchorPeers`:      []interface{}{"peer0-org1:31000", "peer1-org1:31001"},
+	})...)
+	require.NoError(t, err)
+	acls, err := c.Application().ACLs()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"qscc/GetBlockByNumber": "/Channel/Application/Writers"}, acls)
+	anchorPeers, err := c.Application().Organization("org1").AnchorPeers()
+	require.NoError(t, err)
+	assert.Equal(t, []configtx.Address{{Host: "peer0-org1", Port: 31000}, {Host: "peer1-org1", Port: 31001}}, anchorPeers)
+
+	_, err = Apply("testorgschannel0", config, editChanges(t, map[string]interface{}{`application.orgs["org2"].anchorPeers`: []interface{}{"peer0-org2:31002"}})...)
+	assert.EqualError(t, err, "failed to update /Channel/Application/org2/AnchorPeers of channel testorgschannel0: organization org2 not found in group Application")
+	_, err = Apply("orderersystemchannel", fixtureConfig(t, true), editChanges(t, map[string]interface{}{`application.acls["peer/Propose"]`: nil})...)
+	assert.EqualError(t, err, "failed to update /Channel/Application/ACLs of channel orderersystemchannel: group Application not found")
+}
+
+func TestEditMSP(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "msp")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	cert, _ := caCertificate(t)
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "cacerts"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "cacerts", "ca.pem"), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}), 0644))
+
+	c, err := Apply("testorgschannel0", fixtureConfig(t, false), editChanges(t, map[string]interface{}{`orderer.orgs["ordererorg"].msp`: dir})...)
+	require.NoError(t, err)
+	mspConfig := &msp.MSPConfig{}
+	require.NoError(t, proto.Unmarshal(c.UpdatedConfig().ChannelGroup.Groups[configtx.OrdererGroupKey].Groups["ordererorg"].Values[configtx.MSPKey].Value, mspConfig))
+	fabricMSPConfig := &msp.FabricMSPConfig{}
+	require.NoError(t, proto.Unmarshal(mspConfig.Config, fabricMSPConfig))
+	assert.Equal(t, "ordererorg-mspid", fabricMSPConfig.Name)
+	assert.Equal(t, [][]byte{pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})}, fabricMSPConfig.RootCerts)
+
+	_, err = Apply("testorgschannel0", fixtureConfig(t, false), editChanges(t, map[string]interface{}{`application.orgs["org1"].msp`: filepath.Join(dir, "missing")})...)
+	assert.Error(t, err)
+}
+
+func editChanges(t *testing.T, edits map[string]interface{}) []Change {
+
+	var changes []Change
+	for path, value := range edits {
+		change, err := Edit(path, value)
+		require.NoError(t, err, path)
+		changes = append(changes, change)
+	}
+	return changes
+}
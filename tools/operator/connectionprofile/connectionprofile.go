@@ -100,6 +100,76 @@ func (c ConnProfile) GenerateConnProfilePerOrg(orgName string) error {
 	return nil
 }
 
+//RemoveConnProfilePerOrg -- removes the connection profile and the caliper connection profile of an organization
+func (c ConnProfile) RemoveConnProfilePerOrg(orgName string) error {
+
+	fileNames := []string{
+		paths.JoinPath(paths.ConnectionProfilesDir(c.Config.ArtifactsLocation), fmt.Sprintf("connection_profile_%s.yaml", orgName)),
+		paths.JoinPath(paths.CaliperConnectionProfilesDir(c.Config.ArtifactsLocation), fmt.Sprintf("caliper_connection_profile_%s.yaml", orgName)),
+	}
+	for _, fileName := range fileNames {
+		err := os.Remove(fileName)
+		if err != nil && !os.IsNotExist(err) {
+			logger.ERROR("Failed to remove ", fileName)
+			return err
+		}
+	}
+	logger.INFO("Successfully removed connection profiles of ", orgName)
+	return nil
+}
+
+//UpdateOrderers -- adds orderers to and removes orderers from the connection profiles of all peer organizations
+func (c ConnProfile) UpdateOrderers(orderers map[string]networkspec.Orderer, removed []string) error {
+
+	path := paths.ConnectionProfilesDir(c.Config.ArtifactsLocation)
+	for _, peerOrg := range c.Config.PeerOrganizations {
+		var connectionProfileObject networkspec.ConnectionProfile
+		fileName := paths.JoinPath(path, fmt.Sprintf("connection_profile_%s.yaml", peerOrg.Name))
+		yamlFile, err := ioutil.ReadFile(fileName)
+		if err != nil {
+			logger.ERROR("Failed to read connection profile")
+			return err
+		}
+		err = yaml.Unmarshal(yamlFile, &connectionProfileObject)
+		if err != nil {
+			logger.ERROR("Failed to unmarshall yaml file")
+			return err
+		}
+		if connectionProfileObject.Orderers == nil {
+			connectionProfileObject.Orderers = make(map[string]networkspec.Orderer)
+		}
+		for ordererName, orderer := range orderers {
+			connectionProfileObject.Orderers[ordererName] = orderer
+		}
+		for _, ordererName := range removed {
+			delete(connectionProfileObject.Orderers, ordererName)
+			for channelName, channel := range connectionProfileObject.Channels {
+				var channelOrderers []string
+				for _, name := range channel.Orderers {
+					if name != ordererName {
+						channelOrderers = append(channelOrderers, name)
+					}
+				}
+				channel.Orderers = channelOrderers
+				connectionProfileObject.Channels[channelName] = channel
+			}
+		}
+		yamlBytes, err := yaml.Marshal(connectionProfileObject)
+		if err != nil {
+			logger.ERROR("Failed to convert the connection profile struct to bytes")
+			return err
+		}
+		yamlBytes = append([]byte("version: 1.0 \nname: My network \ndescription: Connection Profile for Blockchain Network \n"), yamlBytes...)
+		err = ioutil.WriteFile(fileName, yamlBytes, 0644)
+		if err != nil {
+			logger.ERROR("Failed to write content to ", fileName)
+			return err
+		}
+		logger.INFO("Successfully updated ", fileName)
+	}
+	return nil
+}
+
 func (c ConnProfile) orderers2caliper(ordererMap map[string]networkspec.Orderer) map[string]networkspec.CaliperOrderer {
 	caliperOrderers := make(map[string]networkspec.CaliperOrderer)
 	for name, orderer := range ordererMap {
@@ -0,0 +1,240 @@
+package fabricclient
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-config/configtx"
+	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-protos-go/msp"
+	"github.com/hyperledger/fabric-protos-go/orderer"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-test/tools/operator/logger"
+	"github.com/hyperledger/fabric-test/tools/operator/networkspec"
+	"github.com/hyperledger/fabric-test/tools/operator/paths"
+	"github.com/hyperledger/fabric/protoutil"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	yaml "gopkg.in/yaml.v2"
+)
+
+const dialTimeout = 30 * time.Second
+
+//Identity -- signing identity used to sign proposals, transactions and deliver requests
+type Identity struct {
+	signingIdentity *configtx.SigningIdentity
+	serialized      []byte
+}
+
+//Sign -- signs msg with the identity's private key
+func (i *Identity) Sign(msg []byte) ([]byte, error) {
+	return i.signingIdentity.Sign(rand.Reader, msg, nil)
+}
+
+//Serialize -- returns the serialized identity that goes into the signature headers
+func (i *Identity) Serialize() ([]byte, error) {
+	return i.serialized, nil
+}
+
+//MSPID -- returns the MSP ID of the identity
+func (i *Identity) MSPID() string {
+	return i.signingIdentity.MSPID
+}
+
+//SigningIdentity -- returns the identity in the form used by fabric-config to sign config updates
+func (i *Identity) SigningIdentity() *configtx.SigningIdentity {
+	return i.signingIdentity
+}
+
+//NewIdentity -- builds a signing identity from a pem encoded certificate and pkcs8 private key
+func NewIdentity(mspID, certPem, keyPem string) (*Identity, error) {
+
+	certBlock, _ := pem.Decode([]byte(certPem))
+	if certBlock == nil {
+		return nil, errors.Errorf("no certificate found for %s", mspID)
+	}
+	cert, err := x509.ParseCertificate(certBlock.Bytes)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse certificate of %s", mspID)
+	}
+	keyBlock, _ := pem.Decode([]byte(keyPem))
+	if keyBlock == nil {
+		return nil, errors.Errorf("no private key found for %s", mspID)
+	}
+	privateKey, err := x509.ParsePKCS8PrivateKey(keyBlock.Bytes)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse private key of %s", mspID)
+	}
+	serialized, err := proto.Marshal(&msp.SerializedIdentity{
+		Mspid:   mspID,
+		IdBytes: []byte(certPem),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Identity{
+		signingIdentity: &configtx.SigningIdentity{
+			Certificate: cert,
+			PrivateKey:  privateKey,
+			MSPID:       mspID,
+		},
+		serialized: serialized,
+	}, nil
+}
+
+//OrganizationIdentity -- builds the admin identity of an organization in the connection profile
+func OrganizationIdentity(organization networkspec.Organization) (*Identity, error) {
+	return NewIdentity(organization.MSPID, organization.SignedCert.Pem, organization.AdminPrivateKey.Pem)
+}
+
+//OrdererIdentity -- builds the admin identity of the organization of an orderer in the connection profile
+func OrdererIdentity(orderer networkspec.Orderer) (*Identity, error) {
+	return NewIdentity(orderer.MSPID, orderer.AdminCert, orderer.PrivateKey)
+}
+
+//ConnectionProfile -- reads the complete connection profile of an organization
+func ConnectionProfile(connProfilePath, orgName string) (networkspec.ConnectionProfile, error) {
+
+	var connProfile networkspec.ConnectionProfile
+	if !(strings.HasSuffix(connProfilePath, "yaml") || strings.HasSuffix(connProfilePath, "yml")) {
+		files, err := ioutil.ReadDir(connProfilePath)
+		if err != nil {
+			return connProfile, err
+		}
+		for _, file := range files {
+			if strings.Contains(file.Name(), orgName) {
+				connProfilePath = paths.JoinPath(connProfilePath, file.Name())
+				break
+			}
+		}
+	}
+	yamlFile, err := ioutil.ReadFile(connProfilePath)
+	if err != nil {
+		logger.ERROR("Failed to read connection profile ", connProfilePath)
+		return connProfile, err
+	}
+	err = yaml.Unmarshal(yamlFile, &connProfile)
+	if err != nil {
+		logger.ERROR("Failed to create ConnectionProfile object")
+		return connProfile, err
+	}
+	return connProfile, nil
+}
+
+//ClientTLSCertificate -- loads client.crt and client.key from the tls directory of a user if they exist
+func ClientTLSCertificate(userTLSDir string) ([]tls.Certificate, error) {
+
+	certPath := paths.JoinPath(userTLSDir, "client.crt")
+	keyPath := paths.JoinPath(userTLSDir, "client.key")
+	if _, err := os.Stat(certPath); os.IsNotExist(err) {
+		return nil, nil
+	}
+	certificate, err := tls.LoadX509KeyPair(certPath, keyPath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load tls client certificate from %s", userTLSDir)
+	}
+	return []tls.Certificate{certificate}, nil
+}
+
+//TLSCertHash -- hash of the client certificate that nodes requiring mutual tls expect in deliver requests
+func TLSCertHash(certificates []tls.Certificate) []byte {
+	if len(certificates) == 0 {
+		return nil
+	}
+	hash := sha256.Sum256(certificates[0].Certificate[0])
+	return hash[:]
+}
+
+//Dial -- opens a grpc connection to a peer or orderer listed in the connection profile
+func Dial(nodeURL, sslTarget, tlsCACertPem string, clientCertificates []tls.Certificate) (*grpc.ClientConn, error) {
+
+	address, err := url.Parse(nodeURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse url %s", nodeURL)
+	}
+	dialOpts := []grpc.DialOption{grpc.WithBlock()}
+	if address.Scheme == "grpcs" {
+		certPool := x509.NewCertPool()
+		if !certPool.AppendCertsFromPEM([]byte(tlsCACertPem)) {
+			return nil, errors.Errorf("failed to load tls ca certificate for %s", nodeURL)
+		}
+		tlsConfig := &tls.Config{RootCAs: certPool, ServerName: sslTarget, Certificates: clientCertificates}
+		dialOpts = append(dialOpts, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+	} else {
+		dialOpts = append(dialOpts, grpc.WithInsecure())
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), dialTimeout)
+	defer cancel()
+	conn, err := grpc.DialContext(ctx, address.Host, dialOpts...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to connect to %s", address.Host)
+	}
+	return conn, nil
+}
+
+//Broadcast -- sends an envelope to an orderer and waits for it to be accepted
+func Broadcast(conn *grpc.ClientConn, envelope *common.Envelope) error {
+
+	ctx, cancel := context.WithTimeout(context.Background(), dialTimeout)
+	defer cancel()
+	stream, err := orderer.NewAtomicBroadcastClient(conn).Broadcast(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to broadcast service")
+	}
+	if err := stream.Send(envelope); err != nil {
+		return errors.Wrap(err, "failed to send envelope to orderer")
+	}
+	response, err := stream.Recv()
+	if err != nil {
+		return errors.Wrap(err, "failed to receive broadcast response")
+	}
+	if response.Status != common.Status_SUCCESS {
+		return errors.Errorf("orderer returned %s: %s", response.Status, response.Info)
+	}
+	return nil
+}
+
+//InvokeSystemChaincode -- sends a signed proposal for a system chaincode such as cscc to a peer and returns the payload of its response
+func InvokeSystemChaincode(conn *grpc.ClientConn, identity *Identity, channel, chaincode string, args ...[]byte) ([]byte, error) {
+
+	creator, err := identity.Serialize()
+	if err != nil {
+		return nil, err
+	}
+	spec := &peer.ChaincodeInvocationSpec{
+		ChaincodeSpec: &peer.ChaincodeSpec{
+			Type:        peer.ChaincodeSpec_GOLANG,
+			ChaincodeId: &peer.ChaincodeID{Name: chaincode},
+			Input:       &peer.ChaincodeInput{Args: args},
+		},
+	}
+	proposal, _, err := protoutil.CreateProposalFromCIS(common.HeaderType_CONFIG, channel, spec, creator)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create proposal for %s", chaincode)
+	}
+	signedProposal, err := protoutil.GetSignedProposal(proposal, identity)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to sign proposal for %s", chaincode)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), dialTimeout)
+	defer cancel()
+	response, err := peer.NewEndorserClient(conn).ProcessProposal(ctx, signedProposal)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to send proposal for %s", chaincode)
+	}
+	if response.Response == nil || response.Response.Status != 200 {
+		return nil, errors.Errorf("%s returned %d: %s", chaincode, response.GetResponse().GetStatus(), response.GetResponse().GetMessage())
+	}
+	return response.Response.Payload, nil
+}
@@ -11,9 +11,9 @@ import (
 	"io/ioutil"
 	"time"
 
+	"github.com/pkg/errors"
 	"gopkg.in/yaml.v2"
 
-	"github.com/hyperledger/fabric-test/tools/operator/launcher/nl"
 	"github.com/hyperledger/fabric-test/tools/operator/networkspec"
 	"github.com/hyperledger/fabric-test/tools/operator/paths"
 
@@ -278,6 +278,7 @@ type Node struct {
 
 type ExternalBuilder struct {
 	EnvironmentWhitelist []string `yaml:"environmentWhitelist,omitempty"`
+	PropagateEnvironment []string `yaml:"propagateEnvironment,omitempty"`
 	Name                 string   `yaml:"name,omitempty"`
 	Path                 string   `yaml:"path,omitempty"`
 }
@@ -349,6 +350,15 @@ func CoreConfig(nsConfig networkspec.Config) (Core, error) {
 		return coreConfig, err
 	}
 	coreConfig.VM.Endpoint = "localhost:2375"
+	if nsConfig.ChaincodeAsAService() {
+		// no dind next to the peers: chaincode only runs as a service, through the ccaas_builder of the peer image
+		coreConfig.VM.Endpoint = ""
+		coreConfig.Chaincode.ExternalBuilders = append(coreConfig.Chaincode.ExternalBuilders, ExternalBuilder{
+			Name:                 "ccaas_builder",
+			Path:                 "/opt/hyperledger/ccaas_builder",
+			PropagateEnvironment: []string{"CHAINCODE_AS_A_SERVICE_BUILDER_CONFIG"},
+		})
+	}
 	coreConfig.Peer.ChaincodeListenAddress = "0.0.0.0:7052"
 	if nsConfig.GossipEnable {
 		coreConfig.Peer.Gossip.State.Enabled = true
@@ -376,15 +386,22 @@ func CoreConfig(nsConfig networkspec.Config) (Core, error) {
 	if nsConfig.DBType == "couchdb" {
 		coreConfig.Ledger.State.StateDatabase = "CouchDB"
 	}
-	coreConfig.Chaincode.Builder = nl.DockerImage("ccenv", nsConfig.DockerOrg, nsConfig.DockerTag, nsConfig.DockerImages.Ccenv)
-	coreConfig.Chaincode.Golang.Runtime = nl.DockerImage("baseos", nsConfig.DockerOrg, nsConfig.DockerTag, nsConfig.DockerImages.Baseos)
-	coreConfig.Chaincode.Java.Runtime = nl.DockerImage("javaenv", nsConfig.DockerOrg, nsConfig.DockerTag, nsConfig.DockerImages.Javaenv)
-	coreConfig.Chaincode.Node.Runtime = nl.DockerImage("nodeenv", nsConfig.DockerOrg, nsConfig.DockerTag, nsConfig.DockerImages.Nodeenv)
 	return coreConfig, nil
 }
 
-//GenerateCorePeerConfig --
-func GenerateCorePeerConfig(name, orgName, mspID, artifactsLocation string, port int32, metricsPort int32, coreConfig Core) error {
+//GenerateCorePeerConfig -- writes the core.yaml of a peer with the chaincode images of the peer, after applying its
+//overrides in order
+func GenerateCorePeerConfig(name, orgName, mspID string, port int32, metricsPort int32, coreConfig Core, nsConfig networkspec.Config) error {
+
+	inputPath, d, err := CorePeerConfigFile(name, orgName, mspID, port, metricsPort, coreConfig, nsConfig)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(inputPath, d, 0644)
+}
+
+//CorePeerConfigFile -- the path and contents of the core.yaml GenerateCorePeerConfig writes
+func CorePeerConfigFile(name, orgName, mspID string, port int32, metricsPort int32, coreConfig Core, nsConfig networkspec.Config) (string, []byte, error) {
 
 	coreConfig.Peer.ListenAddress = fmt.Sprintf("0.0.0.0:%d", port)
 	coreConfig.Peer.TLS.RootCert.File = fmt.Sprintf("/etc/hyperledger/fabric/artifacts/msp/tlscacerts/tlsca.%s-cert.pem", orgName)
@@ -397,16 +414,19 @@ func GenerateCorePeerConfig(name, orgName, mspID, artifactsLocation string, port
 	coreConfig.Ledger.State.CouchDBConfig.Username = "admin"
 	coreConfig.Ledger.State.CouchDBConfig.Password = "adminpw"
 	coreConfig.Operations.ListenAddress = fmt.Sprintf(":%d", metricsPort)
-	d, err := yaml.Marshal(&coreConfig)
+	coreConfig.Chaincode.Builder = nsConfig.Image("ccenv", orgName, name)
+	coreConfig.Chaincode.Golang.Runtime = nsConfig.Image("baseos", orgName, name)
+	coreConfig.Chaincode.Java.Runtime = nsConfig.Image("javaenv", orgName, name)
+	coreConfig.Chaincode.Node.Runtime = nsConfig.Image("nodeenv", orgName, name)
+	err := applyOverrides(&coreConfig, nsConfig.PeerOverrides(orgName, name)...)
 	if err != nil {
-		return err
+		return "", nil, errors.Wrapf(err, "invalid core.yaml overrides of %s", name)
 	}
-	cryptoConfigPath := paths.CryptoConfigDir(artifactsLocation)
-	path := paths.JoinPath(cryptoConfigPath, fmt.Sprintf("peerOrganizations/%s/peers/%s.%s", orgName, name, orgName))
-	inputPath := paths.JoinPath(path, fmt.Sprintf("core-%s.yaml", name))
-	err = ioutil.WriteFile(inputPath, d, 0644)
+	d, err := yaml.Marshal(&coreConfig)
 	if err != nil {
-		return err
+		return "", nil, err
 	}
-	return nil
+	cryptoConfigPath := paths.CryptoConfigDir(nsConfig.ArtifactsLocation)
+	path := paths.JoinPath(cryptoConfigPath, fmt.Sprintf("peerOrganizations/%s/peers/%s.%s", orgName, name, orgName))
+	return paths.JoinPath(path, fmt.Sprintf("core-%s.yaml", name)), d, nil
 }
@@ -0,0 +1,103 @@
+package fabricconfig
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/hyperledger/fabric-test/tools/operator/networkspec"
+	"github.com/hyperledger/fabric-test/tools/operator/paths"
+)
+
+const localIP = "127.0.0.1"
+
+//clientRootCAs -- the CA certificates of every organization of the network, trusted for mutual TLS
+func clientRootCAs(nsConfig networkspec.Config) []string {
+
+	var output []string
+	for _, org := range nsConfig.PeerOrganizations {
+		output = append(output, paths.JoinPath(paths.PeerOrgsDir(nsConfig.ArtifactsLocation), fmt.Sprintf("%[1]s/ca/ca.%[1]s-cert.pem", org.Name)))
+	}
+	for _, org := range nsConfig.OrdererOrganizations {
+		output = append(output, paths.JoinPath(paths.OrdererOrgsDir(nsConfig.ArtifactsLocation), fmt.Sprintf("%[1]s/ca/ca.%[1]s-cert.pem", org.Name)))
+	}
+	return output
+}
+
+//LocalPeerConfig -- the core.yaml of a peer run as a local process: it listens on the given ports of 127.0.0.1, reads
+//its msp and tls directories from the crypto-config directory and keeps its ledger in dataDir. Chaincodes have no
+//docker daemon to build in, so they need external builders or to run as a service. Overrides of the peer apply last
+func LocalPeerConfig(name, orgName, mspID string, port, chaincodePort, metricsPort int32, dataDir string, coreConfig Core, nsConfig networkspec.Config) (Core, error) {
+
+	peerDir := paths.JoinPath(paths.PeerOrgsDir(nsConfig.ArtifactsLocation), fmt.Sprintf("%s/peers/%s.%s", orgName, name, orgName))
+	address := fmt.Sprintf("%s:%d", localIP, port)
+	coreConfig.VM.Endpoint = ""
+	coreConfig.Peer.ID = name
+	coreConfig.Peer.LocalMSPID = mspID
+	coreConfig.Peer.MSPConfigPath = paths.JoinPath(peerDir, "msp")
+	coreConfig.Peer.ListenAddress = address
+	coreConfig.Peer.Address = address
+	coreConfig.Peer.ChaincodeListenAddress = fmt.Sprintf("%s:%d", localIP, chaincodePort)
+	coreConfig.Peer.ChaincodeAddress = coreConfig.Peer.ChaincodeListenAddress
+	coreConfig.Peer.Gossip.Bootstrap = address
+	coreConfig.Peer.Gossip.Endpoint = address
+	coreConfig.Peer.Gossip.ExternalEndpoint = address
+	coreConfig.Peer.TLS.Cert.File = paths.JoinPath(peerDir, "tls/server.crt")
+	coreConfig.Peer.TLS.Key.File = paths.JoinPath(peerDir, "tls/server.key")
+	coreConfig.Peer.TLS.RootCert.File = paths.JoinPath(peerDir, "tls/ca.crt")
+	if nsConfig.TLS == "mutual" {
+		coreConfig.Peer.TLS.ClientAuthRequired = true
+		coreConfig.Peer.TLS.ClientRootCAs = &FilesRef{Files: clientRootCAs(nsConfig)}
+		coreConfig.Peer.TLS.ClientCert = &FileRef{File: coreConfig.Peer.TLS.Cert.File}
+		coreConfig.Peer.TLS.ClientKey = &FileRef{File: coreConfig.Peer.TLS.Key.File}
+	}
+	coreConfig.Peer.FileSystemPath = dataDir
+	coreConfig.Ledger.State.StateDatabase = "goleveldb"
+	coreConfig.Operations.ListenAddress = fmt.Sprintf("%s:%d", localIP, metricsPort)
+	err := applyOverrides(&coreConfig, nsConfig.PeerOverrides(orgName, name)...)
+	if err != nil {
+		return coreConfig, errors.Wrapf(err, "invalid core.yaml overrides of %s", name)
+	}
+	return coreConfig, nil
+}
+
+//LocalOrdererConfig -- the orderer.yaml of an orderer run as a local process: it listens on the given ports of
+//127.0.0.1, reads its msp and tls directories from the crypto-config directory and its genesis block from the
+//channel-artifacts directory, and keeps its ledger in dataDir. Overrides of the orderer apply last
+func LocalOrdererConfig(name, orgName, mspID string, port, metricsPort, adminPort int32, dataDir string, ordererConfig Orderer, nsConfig networkspec.Config) (Orderer, error) {
+
+	ordererDir := paths.JoinPath(paths.OrdererOrgsDir(nsConfig.ArtifactsLocation), fmt.Sprintf("%s/orderers/%s.%s", orgName, name, orgName))
+	serverCert := paths.JoinPath(ordererDir, "tls/server.crt")
+	serverKey := paths.JoinPath(ordererDir, "tls/server.key")
+	rootCA := paths.JoinPath(ordererDir, fmt.Sprintf("msp/tlscacerts/tlsca.%s-cert.pem", orgName))
+	ordererConfig.General.ListenAddress = localIP
+	ordererConfig.General.ListenPort = int(port)
+	ordererConfig.General.LocalMSPID = mspID
+	ordererConfig.General.LocalMSPDir = paths.JoinPath(ordererDir, "msp")
+	if ordererConfig.General.BootstrapMethod == "file" {
+		ordererConfig.General.GenesisFile = paths.JoinPath(paths.ChannelArtifactsDir(nsConfig.ArtifactsLocation), "genesis.block")
+	}
+	ordererConfig.General.TLS.Certificate = serverCert
+	ordererConfig.General.TLS.PrivateKey = serverKey
+	ordererConfig.General.TLS.RootCAs = []string{rootCA}
+	if nsConfig.TLS == "mutual" {
+		ordererConfig.General.TLS.ClientAuthRequired = true
+		ordererConfig.General.TLS.ClientRootCAs = clientRootCAs(nsConfig)
+	}
+	ordererConfig.General.Cluster.ClientCertificate = serverCert
+	ordererConfig.General.Cluster.ClientPrivateKey = serverKey
+	ordererConfig.FileLedger.Location = dataDir
+	ordererConfig.Consensus.WALDir = strings.Replace(ordererConfig.Consensus.WALDir, "/shared/data", dataDir, 1)
+	ordererConfig.Consensus.SnapDir = strings.Replace(ordererConfig.Consensus.SnapDir, "/shared/data", dataDir, 1)
+	ordererConfig.Operations.ListenAddress = fmt.Sprintf("%s:%d", localIP, metricsPort)
+	ordererConfig.Admin.ListenAddress = fmt.Sprintf("%s:%d", localIP, adminPort)
+	ordererConfig.Admin.TLS.Certificate = serverCert
+	ordererConfig.Admin.TLS.PrivateKey = serverKey
+	ordererConfig.Admin.TLS.ClientRootCAs = []string{rootCA}
+	err := applyOverrides(&ordererConfig, nsConfig.OrdererOverrides(orgName, name)...)
+	if err != nil {
+		return ordererConfig, errors.Wrapf(err, "invalid orderer.yaml overrides of %s", name)
+	}
+	return ordererConfig, nil
+}
@@ -11,6 +11,7 @@ import (
 	"io/ioutil"
 	"time"
 
+	"github.com/pkg/errors"
 	"gopkg.in/yaml.v2"
 
 	"github.com/hyperledger/fabric-test/tools/operator/networkspec"
@@ -87,6 +88,7 @@ type OrdererCluster struct {
 	ListenAddress     string `yaml:"ListenAddress,omitempty"`
 	ServerCertificate string `yaml:"ServerCertificate,omitempty"`
 	ServerPrivateKey  string `yaml:"ServerPrivateKey,omitempty"`
+	ReplicationPolicy string `yaml:"ReplicationPolicy,omitempty"`
 }
 
 type OrdererTopic struct {
@@ -160,7 +162,7 @@ type OrdererAdmin struct {
 	//TLS				*OrdererTLS	`yaml:"TLS"`
 	TLS struct {
 		Enabled            bool     `yaml:"Enabled"`
-		Certificate        string   `yaml:"Certicate,omitempty"`
+		Certificate        string   `yaml:"Certificate,omitempty"`
 		PrivateKey         string   `yaml:"PrivateKey,omitempty"`
 		ClientAuthRequired bool     `yaml:"ClientAuthRequired"`
 		ClientRootCAs      []string `yaml:"ClientRootCAs"`
@@ -182,6 +184,11 @@ func OrdererConfig(nsConfig networkspec.Config) (Orderer, error) {
 	ordererConfig.General.ListenAddress = "0.0.0.0"
 	ordererConfig.General.BootstrapMethod = "file"
 	ordererConfig.General.GenesisFile = "/etc/hyperledger/fabric/genesisblock/genesis.block"
+	if nsConfig.Orderer.BootstrapMethod == "none" {
+		ordererConfig.General.BootstrapMethod = "none"
+		ordererConfig.General.GenesisFile = ""
+		ordererConfig.General.BootstrapFile = ""
+	}
 	if nsConfig.TLS == "true" || nsConfig.TLS == "mutual" {
 		ordererConfig.General.TLS.Enabled = true
 	} else {
@@ -195,16 +202,34 @@ func OrdererConfig(nsConfig networkspec.Config) (Orderer, error) {
 	ordererConfig.FileLedger.Location = "/shared/data"
 	ordererConfig.Consensus.WALDir = "/shared/data/etcdraft/wal"
 	ordererConfig.Consensus.SnapDir = "/shared/data/etcdraft/snapshot"
+	if nsConfig.Orderer.OrdererType == networkspec.BFT {
+		ordererConfig.General.Cluster.ReplicationPolicy = "consensus"
+		ordererConfig.Consensus.WALDir = "/shared/data/smartbft/wal"
+		ordererConfig.Consensus.SnapDir = ""
+	}
 	ordererConfig.ChannelParticipation.Enabled = true
 	ordererConfig.ChannelParticipation.MaxRequestBodySize = "1 MB"
 	ordererConfig.Operations.TLS.Enabled = false
-	ordererConfig.Admin.TLS.Enabled = false
+	ordererConfig.Admin.TLS.Enabled = ordererConfig.General.TLS.Enabled
+	ordererConfig.Admin.TLS.ClientAuthRequired = ordererConfig.General.TLS.Enabled
+	ordererConfig.Admin.TLS.Certificate = "/etc/hyperledger/fabric/artifacts/tls/server.crt"
+	ordererConfig.Admin.TLS.PrivateKey = "/etc/hyperledger/fabric/artifacts/tls/server.key"
 	ordererConfig.Metrics.Provider = "prometheus"
 	return ordererConfig, nil
 }
 
-//GenerateOrdererConfig --
-func GenerateOrdererConfig(name, orgName, mspID, artifactsLocation string, port, metricsPort, adminPort int32, ordererConfig Orderer) error {
+//GenerateOrdererConfig -- writes the orderer.yaml of an orderer, after applying its overrides in order
+func GenerateOrdererConfig(name, orgName, mspID, artifactsLocation string, port, metricsPort, adminPort int32, ordererConfig Orderer, overrides []map[string]interface{}) error {
+
+	inputPath, d, err := OrdererConfigFile(name, orgName, mspID, artifactsLocation, port, metricsPort, adminPort, ordererConfig, overrides)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(inputPath, d, 0644)
+}
+
+//OrdererConfigFile -- the path and contents of the orderer.yaml GenerateOrdererConfig writes
+func OrdererConfigFile(name, orgName, mspID, artifactsLocation string, port, metricsPort, adminPort int32, ordererConfig Orderer, overrides []map[string]interface{}) (string, []byte, error) {
 
 	ordererConfig.General.LocalMSPID = mspID
 	var rootCAs []string
@@ -213,16 +238,16 @@ func GenerateOrdererConfig(name, orgName, mspID, artifactsLocation string, port,
 	ordererConfig.General.ListenPort = int(port)
 	ordererConfig.Operations.ListenAddress = fmt.Sprintf(":%d", metricsPort)
 	ordererConfig.Admin.ListenAddress = fmt.Sprintf("0.0.0.0:%d", adminPort)
+	ordererConfig.Admin.TLS.ClientRootCAs = []string{rootCA}
+	err := applyOverrides(&ordererConfig, overrides...)
+	if err != nil {
+		return "", nil, errors.Wrapf(err, "invalid orderer.yaml overrides of %s", name)
+	}
 	d, err := yaml.Marshal(&ordererConfig)
 	if err != nil {
-		return err
+		return "", nil, err
 	}
 	cryptoConfigPath := paths.CryptoConfigDir(artifactsLocation)
 	path := paths.JoinPath(cryptoConfigPath, fmt.Sprintf("ordererOrganizations/%s/orderers/%s.%s", orgName, name, orgName))
-	inputPath := paths.JoinPath(path, fmt.Sprintf("orderer-%s.yaml", name))
-	err = ioutil.WriteFile(inputPath, d, 0644)
-	if err != nil {
-		return err
-	}
-	return nil
+	return paths.JoinPath(path, fmt.Sprintf("orderer-%s.yaml", name)), d, nil
 }
@@ -0,0 +1,231 @@
+package fabricconfig
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+
+	"github.com/hyperledger/fabric-test/tools/operator/networkspec"
+)
+
+//applyOverrides -- sets the dotted path keys of overrides in config, a pointer to a Core or an Orderer. A key has to
+//name a field of the config structs, or a property of the sample config kept in their ExtraProperties; keys match
+//case-insensitively, as they do in fabric
+func applyOverrides(config interface{}, overrides ...map[string]interface{}) error {
+
+	configType := reflect.TypeOf(config).Elem()
+	raw, err := yaml.Marshal(config)
+	if err != nil {
+		return err
+	}
+	tree := make(map[interface{}]interface{})
+	err = yaml.Unmarshal(raw, &tree)
+	if err != nil {
+		return err
+	}
+	applied := false
+	for _, levelOverrides := range overrides {
+		keys := make([]string, 0, len(levelOverrides))
+		for key := range levelOverrides {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			path, err := resolveKey(configType, tree, key)
+			if err != nil {
+				return err
+			}
+			setPath(tree, path, levelOverrides[key])
+			applied = true
+		}
+	}
+	if !applied {
+		return nil
+	}
+	raw, err = yaml.Marshal(tree)
+	if err != nil {
+		return err
+	}
+	output := reflect.New(configType)
+	err = yaml.Unmarshal(raw, output.Interface())
+	if err != nil {
+		return errors.Wrap(err, "failed to apply overrides")
+	}
+	reflect.ValueOf(config).Elem().Set(output.Elem())
+	return nil
+}
+
+//resolveKey -- the path of the yaml keys named by a dotted path key, spelled as in the config
+func resolveKey(configType reflect.Type, tree map[interface{}]interface{}, key string) ([]string, error) {
+
+	var path []string
+	var node interface{} = tree
+	segmentType := configType
+	for _, segment := range strings.Split(key, ".") {
+		for segmentType != nil && segmentType.Kind() == reflect.Ptr {
+			segmentType = segmentType.Elem()
+		}
+		name := ""
+		var nextType reflect.Type
+		switch {
+		case segmentType == nil || segmentType.Kind() == reflect.Interface:
+			name = treeKey(node, segment)
+		case segmentType.Kind() == reflect.Struct:
+			field, inline, ok := yamlField(segmentType, segment)
+			if ok {
+				name, nextType = field.name, field.fieldType
+			} else if inline {
+				name = treeKey(node, segment)
+			}
+		case segmentType.Kind() == reflect.Map && segmentType.Elem().Kind() != reflect.Interface:
+			name, nextType = segment, segmentType.Elem()
+		case segmentType.Kind() == reflect.Map:
+			name = treeKey(node, segment)
+		}
+		if name == "" {
+			return nil, errors.Errorf("unknown key %s", key)
+		}
+		path = append(path, name)
+		if children, ok := node.(map[interface{}]interface{}); ok {
+			node = children[name]
+		} else {
+			node = nil
+		}
+		segmentType = nextType
+	}
+	return path, nil
+}
+
+type namedField struct {
+	name      string
+	fieldType reflect.Type
+}
+
+//yamlField -- the field of a struct with the yaml key segment, and whether the struct inlines extra properties
+func yamlField(structType reflect.Type, segment string) (namedField, bool, bool) {
+
+	inline := false
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		tag := strings.Split(field.Tag.Get("yaml"), ",")
+		name := tag[0]
+		if len(tag) > 1 && tag[1] == "inline" {
+			inline = true
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		if name != "-" && strings.EqualFold(name, segment) {
+			return namedField{name: name, fieldType: field.Type}, inline, true
+		}
+	}
+	return namedField{}, inline, false
+}
+
+//treeKey -- the key of node matching segment, or "" if node has none
+func treeKey(node interface{}, segment string) string {
+
+	children, ok := node.(map[interface{}]interface{})
+	if !ok {
+		return ""
+	}
+	if _, ok := children[segment]; ok {
+		return segment
+	}
+	var matches []string
+	for key := range children {
+		if name := fmt.Sprint(key); strings.EqualFold(name, segment) {
+			matches = append(matches, name)
+		}
+	}
+	if len(matches) == 0 {
+		return ""
+	}
+	sort.Strings(matches)
+	return matches[0]
+}
+
+func setPath(tree map[interface{}]interface{}, path []string, value interface{}) {
+
+	node := tree
+	for _, name := range path[:len(path)-1] {
+		child, ok := node[name].(map[interface{}]interface{})
+		if !ok {
+			child = make(map[interface{}]interface{})
+			node[name] = child
+		}
+		node = child
+	}
+	node[path[len(path)-1]] = value
+}
+
+//ValidateOverrides -- checks that every override of the network spec, at network, organization and node level, names
+//a key of core.yaml or orderer.yaml and has a value of the type of that key
+func ValidateOverrides(nsConfig networkspec.Config) error {
+
+	peerOverrides := map[string]map[string]interface{}{"overrides.peer": nsConfig.Overrides.Peer}
+	for _, orgs := range [][]networkspec.PeerOrganizations{nsConfig.PeerOrganizations, nsConfig.AddPeersToOrganization, nsConfig.AddOrganizations} {
+		for _, org := range orgs {
+			peerOverrides[fmt.Sprintf("%s overrides", org.Name)] = org.Overrides
+			for peerName, overrides := range org.NodeOverrides {
+				peerOverrides[fmt.Sprintf("%s nodeOverrides of %s", org.Name, peerName)] = overrides
+			}
+		}
+	}
+	ordererOverrides := map[string]map[string]interface{}{"overrides.orderer": nsConfig.Overrides.Orderer}
+	for _, orgs := range [][]networkspec.OrdererOrganizations{nsConfig.OrdererOrganizations, nsConfig.AddOrderersToOrganization} {
+		for _, org := range orgs {
+			ordererOverrides[fmt.Sprintf("%s overrides", org.Name)] = org.Overrides
+			for ordererName, overrides := range org.NodeOverrides {
+				ordererOverrides[fmt.Sprintf("%s nodeOverrides of %s", org.Name, ordererName)] = overrides
+			}
+		}
+	}
+	if !hasOverrides(peerOverrides) && !hasOverrides(ordererOverrides) {
+		return nil
+	}
+	coreConfig, err := CoreConfig(nsConfig)
+	if err != nil {
+		return errors.Wrap(err, "failed to read core config")
+	}
+	ordererConfig, err := OrdererConfig(nsConfig)
+	if err != nil {
+		return errors.Wrap(err, "failed to read orderer config")
+	}
+	for _, level := range sortedKeys(peerOverrides) {
+		config := coreConfig
+		if err := applyOverrides(&config, peerOverrides[level]); err != nil {
+			return errors.Wrapf(err, "invalid core.yaml key in %s", level)
+		}
+	}
+	for _, level := range sortedKeys(ordererOverrides) {
+		config := ordererConfig
+		if err := applyOverrides(&config, ordererOverrides[level]); err != nil {
+			return errors.Wrapf(err, "invalid orderer.yaml key in %s", level)
+		}
+	}
+	return nil
+}
+
+func hasOverrides(levels map[string]map[string]interface{}) bool {
+	for _, overrides := range levels {
+		if len(overrides) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(levels map[string]map[string]interface{}) []string {
+	keys := make([]string, 0, len(levels))
+	for key := range levels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,47 @@
+package fabricconfig
+
+import (
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+func sampleConfig(t *testing.T, fileName string, config interface{}) {
+	contents, err := ioutil.ReadFile("../sampleconfig/" + fileName)
+	require.NoError(t, err)
+	require.NoError(t, yaml.Unmarshal(contents, config))
+}
+
+func TestApplyOverrides(t *testing.T) {
+
+	var coreConfig Core
+	sampleConfig(t, "core.yaml", &coreConfig)
+	shared := coreConfig
+	network := map[string]interface{}{"peer.gossip.pvtData.pushAckTimeout": "5s", "chaincode.executeTimeout": "300s"}
+	node := map[string]interface{}{"Peer.Gossip.PvtData.PushAckTimeout": "7s", "ledger.state.couchDBConfig.username": "tester"}
+	require.NoError(t, applyOverrides(&coreConfig, network, node))
+	assert.Equal(t, 7*time.Second, coreConfig.Peer.Gossip.PvtData.PushAckTimeout)
+	assert.Equal(t, 300*time.Second, coreConfig.Chaincode.ExecuteTimeout)
+	assert.Equal(t, "tester", coreConfig.Ledger.State.CouchDBConfig.Username)
+	assert.NotEqual(t, 7*time.Second, shared.Peer.Gossip.PvtData.PushAckTimeout, "overrides should not change the config the node config was copied from")
+
+	err := applyOverrides(&coreConfig, map[string]interface{}{"peer.gossip.pvtData.pushAckTimeOuts": "5s"})
+	assert.EqualError(t, err, "unknown key peer.gossip.pvtData.pushAckTimeOuts")
+	err = applyOverrides(&coreConfig, map[string]interface{}{"peer.id.name": "peer0"})
+	assert.EqualError(t, err, "unknown key peer.id.name")
+	err = applyOverrides(&coreConfig, map[string]interface{}{"peer.validatorPoolSize": "many"})
+	assert.Error(t, err)
+
+	var ordererConfig Orderer
+	sampleConfig(t, "orderer.yaml", &ordererConfig)
+	overrides := map[string]interface{}{"General.Cluster.SendBufferSize": 50, "Debug.BroadcastTraceDir": "/tmp/trace"}
+	require.NoError(t, applyOverrides(&ordererConfig, overrides))
+	assert.Equal(t, 50, ordererConfig.General.Cluster.SendBufferSize)
+	assert.Equal(t, map[interface{}]interface{}{"BroadcastTraceDir": "/tmp/trace", "DeliverTraceDir": nil}, ordererConfig.ExtraProperties["Debug"])
+	err = applyOverrides(&ordererConfig, map[string]interface{}{"Debug.TraceDir": "/tmp/trace"})
+	assert.EqualError(t, err, "unknown key Debug.TraceDir")
+}
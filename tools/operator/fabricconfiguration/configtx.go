@@ -21,9 +21,11 @@ import (
 	"github.com/hyperledger/fabric-protos-go/msp"
 	mb "github.com/hyperledger/fabric-protos-go/msp"
 	"github.com/hyperledger/fabric-protos-go/orderer/etcdraft"
+	"github.com/hyperledger/fabric-test/tools/operator/channelconfig"
 	"github.com/hyperledger/fabric-test/tools/operator/logger"
 	"github.com/hyperledger/fabric-test/tools/operator/networkspec"
 	"github.com/hyperledger/fabric-test/tools/operator/paths"
+	"github.com/hyperledger/fabric-test/tools/operator/smartbft"
 	"github.com/hyperledger/fabric/bccsp"
 	"github.com/hyperledger/fabric/bccsp/factory"
 	mspConfigBuilder "github.com/hyperledger/fabric/msp"
@@ -42,6 +44,7 @@ type Configtxgen struct {
 	ChannelID               string
 	OrgName                 string
 	OutputChannelCreateTx   string
+	OutputChannelBlock      string
 	OutputAnchorPeersUpdate string
 	ArtifactsLocation       string
 }
@@ -51,8 +54,9 @@ type ProviderType int
 func GenerateConfigtxConfiguration(profile string, networkConfig networkspec.Config) *networkspec.ConfigtxProfile {
 
 	var configtxConfiguration ConfigtxConfiguration
-	var ordererPort uint32 = 30000
+	ordererPort := uint32(networkConfig.Port(30000))
 	var consenters []*etcdraft.Consenter
+	var consenterMapping []*smartbft.Consenter
 	var ordererOrganizations []*networkspec.ConfigtxOrganization
 	ordererOrgsPath := paths.OrdererOrgsDir(networkConfig.ArtifactsLocation)
 
@@ -60,16 +64,28 @@ func GenerateConfigtxConfiguration(profile string, networkConfig networkspec.Con
 		var ordererEndpoints []string
 		for i := 0; i < org.NumOrderers; i++ {
 			ordererName := fmt.Sprintf("orderer%d-%s", i, org.Name)
-			ordererEndpoints = append(ordererEndpoints, fmt.Sprintf("%s:%d", ordererName, ordererPort))
+			host, port := networkConfig.Endpoint(ordererName, int(ordererPort))
+			ordererEndpoints = append(ordererEndpoints, fmt.Sprintf("%s:%d", host, port))
 			serverCertPath := paths.JoinPath(ordererOrgsPath, fmt.Sprintf("%s/orderers/%s.%s/tls/server.crt", org.Name, ordererName, org.Name))
 			serverCertContent, _ := readPemFile(serverCertPath)
 			consenter := &etcdraft.Consenter{
-				Host:          ordererName,
-				Port:          ordererPort,
+				Host:          host,
+				Port:          uint32(port),
 				ClientTlsCert: serverCertContent,
 				ServerTlsCert: serverCertContent,
 			}
 			consenters = append(consenters, consenter)
+			identityPath := paths.JoinPath(ordererOrgsPath, fmt.Sprintf("%[1]s/orderers/%[2]s.%[1]s/msp/signcerts/%[2]s.%[1]s-cert.pem", org.Name, ordererName))
+			identityContent, _ := readPemFile(identityPath)
+			consenterMapping = append(consenterMapping, &smartbft.Consenter{
+				Id:            uint32(len(consenterMapping) + 1),
+				Host:          host,
+				Port:          uint32(port),
+				MspId:         org.MSPID,
+				Identity:      identityContent,
+				ClientTlsCert: serverCertContent,
+				ServerTlsCert: serverCertContent,
+			})
 			ordererPort++
 		}
 		ordererOrganization := &networkspec.ConfigtxOrganization{
@@ -116,6 +132,8 @@ func GenerateConfigtxConfiguration(profile string, networkConfig networkspec.Con
 				SnapshotIntervalSize: convertToUint(networkConfig.Orderer.EtcdraftOptions.SnapshotIntervalSize),
 			},
 		},
+		SmartBFT:         smartBFTOptions(networkConfig),
+		ConsenterMapping: consenterMapping,
 		Capabilities: map[string]bool{
 			networkConfig.OrdererCapabilities: true,
 		},
@@ -142,39 +160,10 @@ func GenerateConfigtxConfiguration(profile string, networkConfig networkspec.Con
 
 	var peerOrganizations []*networkspec.ConfigtxOrganization
 	peerOrgsPath := paths.PeerOrgsDir(networkConfig.ArtifactsLocation)
-	var peerPort uint32 = 31000
+	peerPort := uint32(networkConfig.Port(31000))
 	for _, org := range networkConfig.PeerOrganizations {
-		anchorPeers := []*networkspec.ConfigtxAnchorPeer{}
-		anchorPeer := &networkspec.ConfigtxAnchorPeer{
-			Host: fmt.Sprintf("peer0-%s", org.Name),
-			Port: int(peerPort),
-		}
-		anchorPeers = append(anchorPeers, anchorPeer)
-		peerOrganization := &networkspec.ConfigtxOrganization{
-			Name:   org.Name,
-			ID:     org.MSPID,
-			MSPDir: paths.JoinPath(peerOrgsPath, fmt.Sprintf("%s/msp/", org.Name)),
-			Policies: map[string]*networkspec.ConfigtxPolicy{
-				configtx.ReadersPolicyKey: {
-					Type: configtx.SignaturePolicyType,
-					Rule: fmt.Sprintf("OR('%[1]s.admin', '%[1]s.peer', '%[1]s.client')", org.MSPID),
-				},
-				configtx.WritersPolicyKey: {
-					Type: configtx.SignaturePolicyType,
-					Rule: fmt.Sprintf("OR('%[1]s.admin', '%[1]s.client')", org.MSPID),
-				},
-				configtx.AdminsPolicyKey: {
-					Type: configtx.SignaturePolicyType,
-					Rule: fmt.Sprintf("OR('%s.admin')", org.MSPID),
-				},
-				configtx.EndorsementPolicyKey: {
-					Type: configtx.SignaturePolicyType,
-					Rule: fmt.Sprintf("OR('%s.peer')", org.MSPID),
-				},
-			},
-			AnchorPeers: anchorPeers,
-		}
-		peerOrganizations = append(peerOrganizations, peerOrganization)
+		host, port := networkConfig.Endpoint(fmt.Sprintf("peer0-%s", org.Name), int(peerPort))
+		peerOrganizations = append(peerOrganizations, peerOrganization(org, peerOrgsPath, host, port))
 		peerPort = peerPort + uint32(org.NumPeers)
 	}
 
@@ -261,6 +250,52 @@ func GenerateConfigtxConfiguration(profile string, networkConfig networkspec.Con
 	return configtxConfiguration.Profiles[profile]
 }
 
+//peerOrganization -- the configtx organization of a peer organization with its peer0, reached at host and port, as
+//anchor peer
+func peerOrganization(org networkspec.PeerOrganizations, peerOrgsPath, host string, port int) *networkspec.ConfigtxOrganization {
+
+	return &networkspec.ConfigtxOrganization{
+		Name:   org.Name,
+		ID:     org.MSPID,
+		MSPDir: paths.JoinPath(peerOrgsPath, fmt.Sprintf("%s/msp/", org.Name)),
+		Policies: map[string]*networkspec.ConfigtxPolicy{
+			configtx.ReadersPolicyKey: {
+				Type: configtx.SignaturePolicyType,
+				Rule: fmt.Sprintf("OR('%[1]s.admin', '%[1]s.peer', '%[1]s.client')", org.MSPID),
+			},
+			configtx.WritersPolicyKey: {
+				Type: configtx.SignaturePolicyType,
+				Rule: fmt.Sprintf("OR('%[1]s.admin', '%[1]s.client')", org.MSPID),
+			},
+			configtx.AdminsPolicyKey: {
+				Type: configtx.SignaturePolicyType,
+				Rule: fmt.Sprintf("OR('%s.admin')", org.MSPID),
+			},
+			configtx.EndorsementPolicyKey: {
+				Type: configtx.SignaturePolicyType,
+				Rule: fmt.Sprintf("OR('%s.peer')", org.MSPID),
+			},
+		},
+		AnchorPeers: []*networkspec.ConfigtxAnchorPeer{
+			{
+				Host: host,
+				Port: port,
+			},
+		},
+	}
+}
+
+//PeerOrganization -- the channel config of a peer organization whose first peer listens on peerPort, as used in the genesis block
+func PeerOrganization(networkConfig networkspec.Config, org networkspec.PeerOrganizations, peerPort uint32) (configtx.Organization, error) {
+
+	host, port := networkConfig.Endpoint(fmt.Sprintf("peer0-%s", org.Name), int(peerPort))
+	orgs, err := newOrganization([]*networkspec.ConfigtxOrganization{peerOrganization(org, paths.PeerOrgsDir(networkConfig.ArtifactsLocation), host, port)})
+	if err != nil {
+		return configtx.Organization{}, err
+	}
+	return orgs[0], nil
+}
+
 // The ProviderType of a member relative to the member API
 const (
 	FABRIC ProviderType = iota
@@ -284,8 +319,118 @@ func convertToUint(size string) uint32 {
 	return uint32(sizeToInt * 1024 * 1024)
 }
 
+//smartBFTOptions -- SmartBFT options of the network spec with the defaults of configtxgen for the unset ones
+func smartBFTOptions(networkConfig networkspec.Config) *smartbft.Options {
+
+	spec := networkConfig.Orderer.SmartBFTOptions
+	options := &smartbft.Options{
+		RequestBatchMaxCount:      spec.RequestBatchMaxCount,
+		RequestBatchMaxBytes:      10 * 1024 * 1024,
+		RequestBatchMaxInterval:   spec.RequestBatchMaxInterval,
+		IncomingMessageBufferSize: spec.IncomingMessageBufferSize,
+		RequestPoolSize:           spec.RequestPoolSize,
+		RequestForwardTimeout:     spec.RequestForwardTimeout,
+		RequestComplainTimeout:    spec.RequestComplainTimeout,
+		RequestAutoRemoveTimeout:  spec.RequestAutoRemoveTimeout,
+		ViewChangeResendInterval:  spec.ViewChangeResendInterval,
+		ViewChangeTimeout:         spec.ViewChangeTimeout,
+		LeaderHeartbeatTimeout:    spec.LeaderHeartbeatTimeout,
+		LeaderHeartbeatCount:      spec.LeaderHeartbeatCount,
+		CollectTimeout:            spec.CollectTimeout,
+		SyncOnStart:               spec.SyncOnStart,
+		SpeedUpViewChange:         spec.SpeedUpViewChange,
+		DecisionsPerLeader:        spec.DecisionsPerLeader,
+		RequestPoolSubmitTimeout:  spec.RequestPoolSubmitTimeout,
+	}
+	if size := convertToUint(spec.RequestBatchMaxBytes); size > 0 {
+		options.RequestBatchMaxBytes = uint64(size)
+	}
+	options.RequestMaxBytes = uint64(convertToUint(spec.RequestMaxBytes))
+	switch spec.LeaderRotation {
+	case "on":
+		options.LeaderRotation = smartbft.RotationOn
+	case "off":
+		options.LeaderRotation = smartbft.RotationOff
+	}
+	if options.RequestBatchMaxInterval == "" {
+		options.RequestBatchMaxInterval = "50ms"
+	}
+	if options.RequestForwardTimeout == "" {
+		options.RequestForwardTimeout = "2s"
+	}
+	if options.RequestComplainTimeout == "" {
+		options.RequestComplainTimeout = "20s"
+	}
+	if options.RequestAutoRemoveTimeout == "" {
+		options.RequestAutoRemoveTimeout = "3m0s"
+	}
+	if options.ViewChangeResendInterval == "" {
+		options.ViewChangeResendInterval = "5s"
+	}
+	if options.ViewChangeTimeout == "" {
+		options.ViewChangeTimeout = "20s"
+	}
+	if options.LeaderHeartbeatTimeout == "" {
+		options.LeaderHeartbeatTimeout = "1m0s"
+	}
+	if options.CollectTimeout == "" {
+		options.CollectTimeout = "1s"
+	}
+	if options.RequestPoolSubmitTimeout == "" {
+		options.RequestPoolSubmitTimeout = "5s"
+	}
+	if options.RequestBatchMaxCount == 0 {
+		options.RequestBatchMaxCount = 100
+	}
+	if options.IncomingMessageBufferSize == 0 {
+		options.IncomingMessageBufferSize = 200
+	}
+	if options.RequestPoolSize == 0 {
+		options.RequestPoolSize = 100000
+	}
+	if options.LeaderHeartbeatCount == 0 {
+		options.LeaderHeartbeatCount = 10
+	}
+	if options.DecisionsPerLeader == 0 {
+		options.DecisionsPerLeader = 3
+	}
+	return options
+}
+
+//bftGenesisBlock -- replaces the solo consensus of a channel genesis block built by fabric-config, which predates BFT,
+//with the BFT consensus type, SmartBFT options and consenter mapping of the orderer profile
+func bftGenesisBlock(block *cb.Block, channelID string, ordererProfile *networkspec.ConfigtxOrderer) (*cb.Block, error) {
+
+	envelope, err := protoutil.ExtractEnvelope(block, 0)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := protoutil.UnmarshalPayload(envelope.Payload)
+	if err != nil {
+		return nil, err
+	}
+	configEnvelope := &cb.ConfigEnvelope{}
+	err = proto.Unmarshal(payload.Data, configEnvelope)
+	if err != nil {
+		return nil, fmt.Errorf("Error unmarshaling config envelope: %s", err)
+	}
+	c, err := channelconfig.Apply(channelID, configEnvelope.Config, channelconfig.SetBFTConsensus(ordererProfile.ConsenterMapping, ordererProfile.SmartBFT))
+	if err != nil {
+		return nil, err
+	}
+	configEnvelope.Config = c.UpdatedConfig()
+	payload.Data = protoutil.MarshalOrPanic(configEnvelope)
+	envelope.Payload = protoutil.MarshalOrPanic(payload)
+	block.Data.Data[0] = protoutil.MarshalOrPanic(envelope)
+	block.Header.DataHash = protoutil.BlockDataHash(block.Data)
+	return block, nil
+}
+
 func doOutputBlock(config *networkspec.ConfigtxProfile, channelID string, outputBlock string) error {
 
+	if config.Orderer.OrdererType == networkspec.BFT {
+		return fmt.Errorf("BFT networks have no system channel, set bootstrapMethod of the orderer to none")
+	}
 	channel, err := newChannel(config)
 	if err != nil {
 		return fmt.Errorf("Error constructing channel for genesis block: %s", err)
@@ -324,6 +469,30 @@ func doOutputChannelCreateTx(config *networkspec.ConfigtxProfile, channelID stri
 	return nil
 }
 
+func doOutputChannelBlock(config *networkspec.ConfigtxProfile, channelID string, outputChannelBlock string) error {
+
+	channel, err := newChannel(config)
+	if err != nil {
+		return fmt.Errorf("Error constructing channel: %s", err)
+	}
+	genesisBlock, err := configtx.NewApplicationChannelGenesisBlock(channel, channelID)
+	if err != nil {
+		return fmt.Errorf("Error creating channel genesis block: %s", err)
+	}
+	if config.Orderer.OrdererType == networkspec.BFT {
+		genesisBlock, err = bftGenesisBlock(genesisBlock, channelID, config.Orderer)
+		if err != nil {
+			return fmt.Errorf("Error setting BFT consensus of channel genesis block: %s", err)
+		}
+	}
+	logger.INFO("Writing channel genesis block")
+	err = writeFile(outputChannelBlock, protoutil.MarshalOrPanic(genesisBlock), 0640)
+	if err != nil {
+		return fmt.Errorf("Error writing channel genesis block: %s", err)
+	}
+	return nil
+}
+
 func doOutputAnchorPeersUpdate(config *networkspec.ConfigtxProfile, channelID, outputAnchorPeersUpdateTx, orgName, cryptoConfigPath string) error {
 
 	for i, org := range config.Application.Organizations {
@@ -599,8 +768,13 @@ func newChannel(baseProfile *networkspec.ConfigtxProfile) (configtx.Channel, err
 			return channel, err
 		}
 
+		// fabric-config has no BFT support, BFT channels are built as solo and converted by bftGenesisBlock
+		ordererType := baseProfile.Orderer.OrdererType
+		if ordererType == networkspec.BFT {
+			ordererType = orderer.ConsensusTypeSolo
+		}
 		o = configtx.Orderer{
-			OrdererType:  baseProfile.Orderer.OrdererType,
+			OrdererType:  ordererType,
 			BatchTimeout: baseProfile.Orderer.BatchTimeout,
 			BatchSize: orderer.BatchSize{
 				MaxMessageCount:   baseProfile.Orderer.BatchSize.MaxMessageCount,
@@ -1086,9 +1260,9 @@ func CreateConfigtx(config *Configtxgen, networkConfig networkspec.Config) error
 		return fmt.Errorf("Error on initFactories: %v", err)
 	}
 	var profileConfig *networkspec.ConfigtxProfile
-	if config.OutputPath != "" || config.OutputChannelCreateTx != "" || config.OutputAnchorPeersUpdate != "" {
+	if config.OutputPath != "" || config.OutputChannelCreateTx != "" || config.OutputChannelBlock != "" || config.OutputAnchorPeersUpdate != "" {
 		if config.Profile == "" {
-			return fmt.Errorf("The '-profile' is required when '-outputBlock', '-outputChannelCreateTx', or '-outputAnchorPeersUpdate' is specified")
+			return fmt.Errorf("The '-profile' is required when '-outputBlock', '-outputChannelCreateTx', '-outputChannelBlock' or '-outputAnchorPeersUpdate' is specified")
 		}
 		profileConfig = GenerateConfigtxConfiguration(config.Profile, networkConfig)
 	}
@@ -1102,6 +1276,11 @@ func CreateConfigtx(config *Configtxgen, networkConfig networkspec.Config) error
 			return fmt.Errorf("Error on outputChannelCreateTx: %v", err)
 		}
 	}
+	if config.OutputChannelBlock != "" {
+		if err := doOutputChannelBlock(profileConfig, config.ChannelID, config.OutputChannelBlock); err != nil {
+			return fmt.Errorf("Error on outputChannelBlock: %v", err)
+		}
+	}
 	if config.OutputAnchorPeersUpdate != "" {
 		if err := doOutputAnchorPeersUpdate(profileConfig, config.ChannelID, config.OutputAnchorPeersUpdate, config.OrgName, config.ArtifactsLocation); err != nil {
 			return fmt.Errorf("Error on outputChannelCreateTx: %v", err)
@@ -0,0 +1,18 @@
+package dockercompose
+
+import (
+	"github.com/hyperledger/fabric-test/tools/operator/networkspec"
+	"github.com/hyperledger/fabric-test/tools/operator/templates"
+)
+
+//StartChaincodeServer -- starts the container of a chaincode server on the docker network of the network, replacing
+//the container of a previous server with the same name, and waits for it to run. down removes it with the nodes
+func StartChaincodeServer(config networkspec.Config, server networkspec.ChaincodeServer) error {
+
+	docker, err := newEngine(config)
+	if err != nil {
+		return err
+	}
+	defer docker.close()
+	return docker.up([]templates.Service{templates.ChaincodeServerService(config, server)})
+}
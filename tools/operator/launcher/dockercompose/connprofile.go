@@ -5,62 +5,45 @@
 package dockercompose
 
 import (
-	"encoding/json"
 	"fmt"
 	"io/ioutil"
-	"sort"
 
 	yaml "gopkg.in/yaml.v2"
 
 	"github.com/hyperledger/fabric-test/tools/operator/connectionprofile"
 	"github.com/hyperledger/fabric-test/tools/operator/logger"
-	"github.com/hyperledger/fabric-test/tools/operator/networkclient"
 	"github.com/hyperledger/fabric-test/tools/operator/networkspec"
 	"github.com/hyperledger/fabric-test/tools/operator/paths"
+	"github.com/hyperledger/fabric-test/tools/operator/templates"
 )
 
-type ContainerPorts struct {
-	Ports map[string][]struct {
-		HostPort string `json:"HostPort,omitempty"`
-	} `json:"Ports,omitempty"`
-}
-
 //GetDockerExternalIP -- To get the externalIP of a fabric component
 func (d DockerCompose) GetDockerExternalIP() string {
 	return "127.0.0.1"
 }
 
-//GetDockerServicePort -- To get the port number of a docker container
+//GetDockerServicePort -- the host port of the listen address of a container of the network, or of its operations
+//service for health
 func (d DockerCompose) GetDockerServicePort(serviceName string, forHealth bool) (string, error) {
 
-	var port string
-	var containerPorts ContainerPorts
-	var ports []string
-	args := []string{"inspect", "-f", `"{{json .NetworkSettings}}"`, serviceName}
-	output, err := networkclient.ExecuteCommand("docker", args, false)
-	output = output[1 : len(output)-1]
+	if forHealth {
+		return d.dockerHostPort(serviceName, templates.HealthPortLabel)
+	}
+	return d.dockerHostPort(serviceName, templates.ListenPortLabel)
+}
+
+//dockerHostPort -- the host port publishing the container port named by label of a container of the network
+func (d DockerCompose) dockerHostPort(serviceName, label string) (string, error) {
+
+	docker, err := newEngine(d.Config)
 	if err != nil {
-		logger.ERROR("Failed to get the port number for service ", serviceName)
 		return "", err
 	}
-	err = json.Unmarshal([]byte(output), &containerPorts)
+	defer docker.close()
+	port, err := docker.hostPort(serviceName, label)
 	if err != nil {
-		logger.ERROR("Failed to unmarshall containerPorts object")
-	}
-	for port = range containerPorts.Ports {
-		ports = append(ports, port)
-	}
-	sort.Strings(ports)
-	/* We can't do anything with the input file, as it doesn't have anything regarding the ports.
-	   Suppose if we take the first orderer and see the ports that the orderer container is using
-	   will be [8443/tcp 30000/tcp 7050/tcp]. They will not appear in the same orderer every time.
-	   So I am sorting them in line number 50. After sorting, they will be in alphabetical order
-	   [30000/tcp 7050/tcp 8443/tcp]. 8443 is the port that we use to verify the health.
-	   It will be always at index 2.
-	*/
-	port = containerPorts.Ports[ports[0]][0].HostPort
-	if forHealth {
-		port = containerPorts.Ports[ports[2]][0].HostPort
+		logger.ERROR("Failed to get the port number for service ", serviceName)
+		return "", err
 	}
 	return port, nil
 }
@@ -69,64 +52,82 @@ func (d DockerCompose) GetDockerServicePort(serviceName string, forHealth bool)
 func (d DockerCompose) ordererOrgs(config networkspec.Config) (map[string]networkspec.Orderer, error) {
 
 	orderers := make(map[string]networkspec.Orderer)
-	artifactsLocation := config.ArtifactsLocation
-	ordererOrgsPath := paths.OrdererOrgsDir(artifactsLocation)
-	nodeIP := d.GetDockerExternalIP()
-	protocol := "grpc"
-	if config.TLS == "true" || config.TLS == "mutual" {
-		protocol = "grpcs"
-	}
 	for org := 0; org < len(config.OrdererOrganizations); org++ {
 		ordererOrg := config.OrdererOrganizations[org]
-		orgName := ordererOrg.Name
-		connProfile := connectionprofile.ConnProfile{}
 		for i := 0; i < ordererOrg.NumOrderers; i++ {
-			ordererName := fmt.Sprintf("orderer%d-%s", i, orgName)
-			portNumber, err := d.GetDockerServicePort(ordererName, false)
-			if err != nil {
-				return orderers, err
-			}
-			metricsPortNumber, err := d.GetDockerServicePort(ordererName, true)
-			if err != nil {
-				return orderers, err
-			}
-			orderer := networkspec.Orderer{
-				MSPID:      ordererOrg.MSPID,
-				URL:        fmt.Sprintf("%s://%s:%s", protocol, nodeIP, portNumber),
-				MetricsURL: fmt.Sprintf("http://%s:%s", nodeIP, metricsPortNumber),
-			}
-			orderer.GrpcOptions.SslTarget = ordererName
-			tlscaCertPath := paths.JoinPath(ordererOrgsPath, fmt.Sprintf("%s/orderers/%s.%s/msp/tlscacerts/tlsca.%s-cert.pem", orgName, ordererName, orgName, orgName))
-			cert, err := connProfile.GetCertificateFromFile(tlscaCertPath)
-			if err != nil {
-				return orderers, err
-			}
-			orderer.TLSCACerts.Pem = cert
-			adminCertPath := paths.JoinPath(ordererOrgsPath, fmt.Sprintf("%s/users/Admin@%s/msp/signcerts/Admin@%s-cert.pem", orgName, orgName, orgName))
-			cert, err = connProfile.GetCertificateFromFile(adminCertPath)
+			ordererName := fmt.Sprintf("orderer%d-%s", i, ordererOrg.Name)
+			orderer, err := d.orderer(ordererName, ordererOrg, config)
 			if err != nil {
 				return orderers, err
 			}
-			orderer.AdminCert = cert
-			keystorePath := paths.JoinPath(ordererOrgsPath, fmt.Sprintf("%s/users/Admin@%s/msp/keystore", orgName, orgName))
-
-			privKeyFile, err := ioutil.ReadDir(keystorePath)
-			if err != nil {
-				return orderers, err
-			}
-			privKeyPath := paths.JoinPath(keystorePath, fmt.Sprintf("%s", privKeyFile[0].Name()))
-
-			cert, err = connProfile.GetCertificateFromFile(privKeyPath)
-			if err != nil {
-				return orderers, err
-			}
-			orderer.PrivateKey = cert
 			orderers[ordererName] = orderer
 		}
 	}
 	return orderers, nil
 }
 
+//orderer -- the connection profile entry of an orderer
+func (d DockerCompose) orderer(ordererName string, ordererOrg networkspec.OrdererOrganizations, config networkspec.Config) (networkspec.Orderer, error) {
+
+	ordererOrgsPath := paths.OrdererOrgsDir(config.ArtifactsLocation)
+	nodeIP := d.GetDockerExternalIP()
+	protocol := "grpc"
+	if config.TLS == "true" || config.TLS == "mutual" {
+		protocol = "grpcs"
+	}
+	orgName := ordererOrg.Name
+	connProfile := connectionprofile.ConnProfile{}
+	portNumber, err := d.GetDockerServicePort(ordererName, false)
+	if err != nil {
+		return networkspec.Orderer{}, err
+	}
+	metricsPortNumber, err := d.GetDockerServicePort(ordererName, true)
+	if err != nil {
+		return networkspec.Orderer{}, err
+	}
+	adminPortNumber, err := d.dockerHostPort(ordererName, templates.AdminPortLabel)
+	if err != nil {
+		return networkspec.Orderer{}, err
+	}
+	adminProtocol := "http"
+	if config.TLS == "true" || config.TLS == "mutual" {
+		adminProtocol = "https"
+	}
+	orderer := networkspec.Orderer{
+		MSPID:      ordererOrg.MSPID,
+		URL:        fmt.Sprintf("%s://%s:%s", protocol, nodeIP, portNumber),
+		MetricsURL: fmt.Sprintf("http://%s:%s", nodeIP, metricsPortNumber),
+		AdminURL:   fmt.Sprintf("%s://%s:%s", adminProtocol, nodeIP, adminPortNumber),
+	}
+	orderer.GrpcOptions.SslTarget = ordererName
+	tlscaCertPath := paths.JoinPath(ordererOrgsPath, fmt.Sprintf("%s/orderers/%s.%s/msp/tlscacerts/tlsca.%s-cert.pem", orgName, ordererName, orgName, orgName))
+	cert, err := connProfile.GetCertificateFromFile(tlscaCertPath)
+	if err != nil {
+		return orderer, err
+	}
+	orderer.TLSCACerts.Pem = cert
+	adminCertPath := paths.JoinPath(ordererOrgsPath, fmt.Sprintf("%s/users/Admin@%s/msp/signcerts/Admin@%s-cert.pem", orgName, orgName, orgName))
+	cert, err = connProfile.GetCertificateFromFile(adminCertPath)
+	if err != nil {
+		return orderer, err
+	}
+	orderer.AdminCert = cert
+	keystorePath := paths.JoinPath(ordererOrgsPath, fmt.Sprintf("%s/users/Admin@%s/msp/keystore", orgName, orgName))
+
+	privKeyFile, err := ioutil.ReadDir(keystorePath)
+	if err != nil {
+		return orderer, err
+	}
+	privKeyPath := paths.JoinPath(keystorePath, fmt.Sprintf("%s", privKeyFile[0].Name()))
+
+	cert, err = connProfile.GetCertificateFromFile(privKeyPath)
+	if err != nil {
+		return orderer, err
+	}
+	orderer.PrivateKey = cert
+	return orderer, nil
+}
+
 //CertificateAuthorities --
 func (d DockerCompose) certificateAuthorities(peerOrg networkspec.PeerOrganizations, config networkspec.Config) (map[string]networkspec.CertificateAuthority, error) {
 
@@ -0,0 +1,504 @@
+package dockercompose
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/network"
+	volumetypes "github.com/docker/docker/api/types/volume"
+	"github.com/docker/docker/client"
+	"github.com/docker/go-connections/nat"
+	"github.com/pkg/errors"
+
+	"github.com/hyperledger/fabric-test/tools/operator/logger"
+	"github.com/hyperledger/fabric-test/tools/operator/networkspec"
+	"github.com/hyperledger/fabric-test/tools/operator/networkstate"
+	"github.com/hyperledger/fabric-test/tools/operator/templates"
+)
+
+const (
+	startTimeout = time.Minute
+	settleTime   = 5 * time.Second
+	stopTimeout  = 10 * time.Second
+)
+
+//engine -- the docker daemon of the environment, reached through the Docker Engine API. It only lists, inspects and
+//removes the containers, volumes and network labelled with the docker network of the network spec
+type engine struct {
+	client    *client.Client
+	network   string
+	chaincode string
+}
+
+func newEngine(config networkspec.Config) (*engine, error) {
+
+	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to connect to the docker daemon")
+	}
+	return &engine{client: cli, network: config.DockerNetwork(), chaincode: config.ChaincodeNetworkID() + "-"}, nil
+}
+
+func (e *engine) close() {
+	_ = e.client.Close()
+}
+
+//networkFilter -- the filter of the objects created by the operator, with the given extra filters
+func (e *engine) networkFilter(extra ...filters.KeyValuePair) filters.Args {
+	args := filters.NewArgs(filters.Arg("label", fmt.Sprintf("%s=%s", templates.NetworkLabel, e.network)))
+	for _, arg := range extra {
+		args.Add(arg.Key, arg.Value)
+	}
+	return args
+}
+
+func (e *engine) networkLabels() map[string]string {
+	return map[string]string{templates.NetworkLabel: e.network}
+}
+
+//ensureNetwork -- creates the network of the containers unless it exists
+func (e *engine) ensureNetwork(ctx context.Context) error {
+
+	networks, err := e.client.NetworkList(ctx, types.NetworkListOptions{Filters: filters.NewArgs(filters.Arg("name", e.network))})
+	if err != nil {
+		return errors.Wrap(err, "failed to list the docker networks")
+	}
+	for _, existing := range networks {
+		if existing.Name == e.network {
+			return nil
+		}
+	}
+	_, err = e.client.NetworkCreate(ctx, e.network, types.NetworkCreate{CheckDuplicate: true, Driver: "bridge", Labels: e.networkLabels()})
+	if err != nil {
+		return errors.Wrapf(err, "failed to create the docker network %s", e.network)
+	}
+	logger.INFO("Created docker network ", e.network)
+	return nil
+}
+
+//pullImage -- pulls image unless the daemon has it
+func (e *engine) pullImage(ctx context.Context, image string) error {
+
+	_, _, err := e.client.ImageInspectWithRaw(ctx, image)
+	if err == nil {
+		return nil
+	}
+	if !client.IsErrNotFound(err) {
+		return errors.Wrapf(err, "failed to inspect image %s", image)
+	}
+	logger.INFO("Pulling image ", image)
+	progress, err := e.client.ImagePull(ctx, image, types.ImagePullOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to pull image %s", image)
+	}
+	defer progress.Close()
+	_, err = io.Copy(ioutil.Discard, progress)
+	if err != nil {
+		return errors.Wrapf(err, "failed to pull image %s", image)
+	}
+	return nil
+}
+
+//splitCommand -- the arguments of a command line, with single and double quotes grouping words as in a shell
+func splitCommand(command string) ([]string, error) {
+
+	var args []string
+	var current strings.Builder
+	var quote rune
+	inArg := false
+	for _, char := range command {
+		switch {
+		case quote != 0 && char == quote:
+			quote = 0
+		case quote != 0:
+			current.WriteRune(char)
+		case char == '\'' || char == '"':
+			quote, inArg = char, true
+		case char == ' ' || char == '\t':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(char)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, errors.Errorf("unterminated quote in command %q", command)
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
+
+//mounts -- the binds of the volumes of a service: absolute host paths are bind mounted and other names are volumes
+//of the network, which are created with its label
+func (e *engine) mounts(ctx context.Context, volumes []string) ([]string, error) {
+
+	var binds []string
+	for _, volume := range volumes {
+		source := strings.SplitN(volume, ":", 2)[0]
+		if !filepath.IsAbs(source) {
+			_, err := e.client.VolumeCreate(ctx, volumetypes.VolumeCreateBody{Name: source, Labels: e.networkLabels()})
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to create volume %s", source)
+			}
+		}
+		binds = append(binds, volume)
+	}
+	return binds, nil
+}
+
+//containerConfig -- the container, host and network settings running a service on the network of the operator
+func containerConfig(service templates.Service, binds []string, dockerNetwork string) (*container.Config, *container.HostConfig, *network.NetworkingConfig, error) {
+
+	exposed, bindings, err := nat.ParsePortSpecs(service.Ports)
+	if err != nil {
+		return nil, nil, nil, errors.Wrapf(err, "invalid ports of %s", service.Name)
+	}
+	for _, port := range service.Expose {
+		exposed[nat.Port(fmt.Sprintf("%d/tcp", port))] = struct{}{}
+	}
+	cmd, err := splitCommand(service.Command)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	config := &container.Config{
+		Image:        service.Image,
+		Cmd:          cmd,
+		Env:          service.Environment,
+		WorkingDir:   service.WorkingDir,
+		ExposedPorts: exposed,
+		Labels:       service.Labels,
+	}
+	hostConfig := &container.HostConfig{
+		Binds:        binds,
+		PortBindings: bindings,
+		NetworkMode:  container.NetworkMode(dockerNetwork),
+	}
+	networkConfig := &network.NetworkingConfig{
+		EndpointsConfig: map[string]*network.EndpointSettings{dockerNetwork: {Aliases: []string{service.Name}}},
+	}
+	return config, hostConfig, networkConfig, nil
+}
+
+//find -- the container of the network with the given name, or nil
+func (e *engine) find(ctx context.Context, name string) (*types.Container, error) {
+
+	containers, err := e.client.ContainerList(ctx, types.ContainerListOptions{All: true, Filters: e.networkFilter(filters.Arg("label", fmt.Sprintf("%s=%s", templates.NodeLabel, name)))})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list the container of %s", name)
+	}
+	if len(containers) == 0 {
+		return nil, nil
+	}
+	return &containers[0], nil
+}
+
+//up -- creates and starts the containers of services in order, replacing the containers of the network with the same
+//names, and waits for them to run
+func (e *engine) up(services []templates.Service) error {
+
+	ctx := context.Background()
+	err := e.ensureNetwork(ctx)
+	if err != nil {
+		return err
+	}
+	started := make(map[string]string)
+	var names []string
+	for _, service := range services {
+		err = e.remove(service.Name)
+		if err != nil {
+			return err
+		}
+		err = e.pullImage(ctx, service.Image)
+		if err != nil {
+			return err
+		}
+		binds, err := e.mounts(ctx, service.Volumes)
+		if err != nil {
+			return err
+		}
+		config, hostConfig, networkConfig, err := containerConfig(service, binds, e.network)
+		if err != nil {
+			return err
+		}
+		created, err := e.client.ContainerCreate(ctx, config, hostConfig, networkConfig, service.ContainerName)
+		if err != nil {
+			return errors.Wrapf(err, "failed to create the container of %s", service.Name)
+		}
+		err = e.client.ContainerStart(ctx, created.ID, types.ContainerStartOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "failed to start the container of %s", service.Name)
+		}
+		logger.INFO("Started container ", service.Name)
+		started[service.Name] = created.ID
+		names = append(names, service.Name)
+	}
+	return e.waitRunning(ctx, names, started)
+}
+
+//waitRunning -- waits for the containers of names, by the ids they were started with, to run and to keep running for
+//settleTime, failing with the exit code of a container that exits
+func (e *engine) waitRunning(ctx context.Context, names []string, ids map[string]string) error {
+
+	if len(names) == 0 {
+		return nil
+	}
+	logger.INFO("Check status of all the containers to verify they are running")
+	deadline := time.Now().Add(startTimeout)
+	pending := names
+	for len(pending) > 0 {
+		var waiting []string
+		for _, name := range pending {
+			inspected, err := e.client.ContainerInspect(ctx, ids[name])
+			if err != nil {
+				return errors.Wrapf(err, "failed to inspect the container of %s", name)
+			}
+			switch {
+			case inspected.State.Running:
+			case inspected.State.Status == "exited" || inspected.State.Status == "dead":
+				return errors.Errorf("container %s exited with code %d", name, inspected.State.ExitCode)
+			default:
+				waiting = append(waiting, name)
+			}
+		}
+		pending = waiting
+		if len(pending) == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			return errors.Errorf("waiting time to bring up containers exceeded %s: %s", startTimeout, strings.Join(pending, ","))
+		}
+		time.Sleep(time.Second)
+	}
+	time.Sleep(settleTime)
+	for _, name := range names {
+		inspected, err := e.client.ContainerInspect(ctx, ids[name])
+		if err != nil {
+			return errors.Wrapf(err, "failed to inspect the container of %s", name)
+		}
+		if !inspected.State.Running {
+			return errors.Errorf("container %s exited with code %d", name, inspected.State.ExitCode)
+		}
+	}
+	logger.INFO("All the containers are up and running")
+	return nil
+}
+
+//hostPort -- the host port publishing the container port of a container of the network named by its label
+func (e *engine) hostPort(name, label string) (string, error) {
+
+	found, err := e.find(context.Background(), name)
+	if err != nil {
+		return "", err
+	}
+	if found == nil {
+		return "", errors.Errorf("no container of the network runs %s", name)
+	}
+	containerPort := nat.Port(found.Labels[label])
+	if containerPort == "" {
+		return "", errors.Errorf("container %s has no %s label", name, label)
+	}
+	for _, port := range found.Ports {
+		if port.PublicPort != 0 && port.PrivatePort == uint16(containerPort.Int()) && port.Type == containerPort.Proto() {
+			return fmt.Sprint(port.PublicPort), nil
+		}
+	}
+	return "", errors.Errorf("port %s of %s is not published", containerPort, name)
+}
+
+//inventory -- sets the container ID, image and published ports of the components of state from the containers of the
+//network
+func (e *engine) inventory(state *networkstate.State) error {
+
+	containers, err := e.client.ContainerList(context.Background(), types.ContainerListOptions{All: true, Filters: e.networkFilter()})
+	if err != nil {
+		return errors.Wrap(err, "failed to list the containers of the network")
+	}
+	portLabels := map[string]string{"listen": templates.ListenPortLabel, "operations": templates.HealthPortLabel, "admin": templates.AdminPortLabel}
+	for _, found := range containers {
+		component := state.Component(found.Labels[templates.NodeLabel])
+		if component == nil {
+			continue
+		}
+		component.ContainerID = found.ID
+		component.Image = found.Image
+		for key, label := range portLabels {
+			containerPort := nat.Port(found.Labels[label])
+			if containerPort == "" {
+				continue
+			}
+			for _, port := range found.Ports {
+				if port.PublicPort != 0 && port.PrivatePort == uint16(containerPort.Int()) && port.Type == containerPort.Proto() {
+					component.SetPort(key, int(port.PublicPort))
+				}
+			}
+		}
+	}
+	return nil
+}
+
+//states -- the states of the containers of the network, by the name of their component
+func (e *engine) states() (map[string]string, error) {
+
+	containers, err := e.client.ContainerList(context.Background(), types.ContainerListOptions{All: true, Filters: e.networkFilter()})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list the containers of the network")
+	}
+	states := make(map[string]string)
+	for _, found := range containers {
+		if name := found.Labels[templates.NodeLabel]; name != "" {
+			states[name] = found.Status
+		}
+	}
+	return states, nil
+}
+
+//remove -- removes the container of the network with the given name and its anonymous volumes, if it exists
+func (e *engine) remove(name string) error {
+
+	ctx := context.Background()
+	found, err := e.find(ctx, name)
+	if err != nil || found == nil {
+		return err
+	}
+	err = e.client.ContainerRemove(ctx, found.ID, types.ContainerRemoveOptions{RemoveVolumes: true, Force: true})
+	if err != nil {
+		return errors.Wrapf(err, "failed to remove the container of %s", name)
+	}
+	logger.INFO("Removed container ", name)
+	return nil
+}
+
+//restart -- restarts the container of the network with the given name
+func (e *engine) restart(name string) error {
+
+	ctx := context.Background()
+	found, err := e.find(ctx, name)
+	if err != nil {
+		return err
+	}
+	if found == nil {
+		return errors.Errorf("no container of the network runs %s", name)
+	}
+	timeout := stopTimeout
+	err = e.client.ContainerRestart(ctx, found.ID, &timeout)
+	if err != nil {
+		return errors.Wrapf(err, "failed to restart the container of %s", name)
+	}
+	return e.waitRunning(ctx, []string{name}, map[string]string{name: found.ID})
+}
+
+//removeNodes -- removes the containers of the nodes of the network
+func (e *engine) removeNodes() error {
+
+	ctx := context.Background()
+	containers, err := e.client.ContainerList(ctx, types.ContainerListOptions{All: true, Filters: e.networkFilter()})
+	if err != nil {
+		return errors.Wrap(err, "failed to list the containers of the network")
+	}
+	for _, found := range containers {
+		err = e.client.ContainerRemove(ctx, found.ID, types.ContainerRemoveOptions{RemoveVolumes: true, Force: true})
+		if err != nil {
+			return errors.Wrapf(err, "failed to remove the container of %s", found.Labels[templates.NodeLabel])
+		}
+		logger.INFO("Removed container ", found.Labels[templates.NodeLabel])
+	}
+	return nil
+}
+
+//removeChaincodes -- removes the chaincode containers the peers started on the network of the operator and their images
+func (e *engine) removeChaincodes() error {
+
+	ctx := context.Background()
+	containers, err := e.client.ContainerList(ctx, types.ContainerListOptions{All: true, Filters: filters.NewArgs(filters.Arg("network", e.network), filters.Arg("name", e.chaincode))})
+	if err != nil {
+		return errors.Wrap(err, "failed to list the chaincode containers")
+	}
+	images := make(map[string]bool)
+	for _, found := range containers {
+		err = e.client.ContainerRemove(ctx, found.ID, types.ContainerRemoveOptions{RemoveVolumes: true, Force: true})
+		if err != nil {
+			return errors.Wrapf(err, "failed to remove chaincode container %s", strings.Join(found.Names, ","))
+		}
+		images[found.ImageID] = true
+	}
+	for image := range images {
+		_, err = e.client.ImageRemove(ctx, image, types.ImageRemoveOptions{Force: true, PruneChildren: true})
+		if err != nil && !client.IsErrNotFound(err) {
+			return errors.Wrapf(err, "failed to remove chaincode image %s", image)
+		}
+	}
+	return nil
+}
+
+//removeBackup -- removes the backup directory of the ledgers from a container, as the nodes write it as root
+func (e *engine) removeBackup(artifactsLocation string) error {
+
+	ctx := context.Background()
+	err := e.pullImage(ctx, "busybox")
+	if err != nil {
+		return err
+	}
+	config := &container.Config{Image: "busybox", Cmd: []string{"sh", "-c", "(rm -rf /opt/backup)"}, Labels: e.networkLabels()}
+	hostConfig := &container.HostConfig{Binds: []string{fmt.Sprintf("%s:/opt", artifactsLocation)}, NetworkMode: "none"}
+	created, err := e.client.ContainerCreate(ctx, config, hostConfig, nil, "")
+	if err != nil {
+		return errors.Wrap(err, "failed to create the container removing the backup directory")
+	}
+	defer e.client.ContainerRemove(ctx, created.ID, types.ContainerRemoveOptions{Force: true})
+	waited, waitErr := e.client.ContainerWait(ctx, created.ID, container.WaitConditionNextExit)
+	err = e.client.ContainerStart(ctx, created.ID, types.ContainerStartOptions{})
+	if err != nil {
+		return errors.Wrap(err, "failed to start the container removing the backup directory")
+	}
+	select {
+	case result := <-waited:
+		if result.StatusCode != 0 {
+			return errors.Errorf("failed to remove the backup directory, exit code %d", result.StatusCode)
+		}
+	case err = <-waitErr:
+		return errors.Wrap(err, "failed to wait for the container removing the backup directory")
+	}
+	return nil
+}
+
+//removeNetwork -- removes the volumes and the network created by the operator
+func (e *engine) removeNetwork() error {
+
+	ctx := context.Background()
+	volumes, err := e.client.VolumeList(ctx, e.networkFilter())
+	if err != nil {
+		return errors.Wrap(err, "failed to list the volumes of the network")
+	}
+	for _, volume := range volumes.Volumes {
+		err = e.client.VolumeRemove(ctx, volume.Name, true)
+		if err != nil {
+			return errors.Wrapf(err, "failed to remove volume %s", volume.Name)
+		}
+	}
+	networks, err := e.client.NetworkList(ctx, types.NetworkListOptions{Filters: e.networkFilter()})
+	if err != nil {
+		return errors.Wrap(err, "failed to list the docker networks")
+	}
+	for _, existing := range networks {
+		err = e.client.NetworkRemove(ctx, existing.ID)
+		if err != nil {
+			return errors.Wrapf(err, "failed to remove docker network %s", existing.Name)
+		}
+		logger.INFO("Removed docker network ", existing.Name)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package dockercompose
+
+import (
+	"testing"
+
+	"github.com/docker/go-connections/nat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/fabric-test/tools/operator/networkspec"
+	"github.com/hyperledger/fabric-test/tools/operator/templates"
+)
+
+func TestSplitCommand(t *testing.T) {
+
+	args, err := splitCommand("sh -c 'fabric-ca-server start -b admin:adminpw -d'")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sh", "-c", "fabric-ca-server start -b admin:adminpw -d"}, args)
+	args, err = splitCommand("peer  node start")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"peer", "node", "start"}, args)
+	args, err = splitCommand(`echo "" done`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"echo", "", "done"}, args)
+	args, err = splitCommand("")
+	require.NoError(t, err)
+	assert.Empty(t, args)
+	_, err = splitCommand("sh -c 'unterminated")
+	assert.Error(t, err)
+}
+
+func TestContainerConfig(t *testing.T) {
+
+	config := networkspec.Config{
+		ArtifactsLocation:    "/tmp/artifacts",
+		PeerOrganizations:    []networkspec.PeerOrganizations{{Name: "org1", MSPID: "Org1ExampleCom", NumPeers: 1}},
+		OrdererOrganizations: []networkspec.OrdererOrganizations{{Name: "ordererorg1", MSPID: "OrdererOrg1ExampleCom", NumOrderers: 1}},
+	}
+	services, err := templates.Services("docker", config)
+	require.NoError(t, err)
+	require.Len(t, services, 2)
+	peer := services[0]
+	require.Equal(t, "peer0-org1", peer.Name)
+
+	containerCfg, hostConfig, networkConfig, err := containerConfig(peer, peer.Volumes, config.DockerNetwork())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"peer", "node", "start"}, []string(containerCfg.Cmd))
+	assert.Equal(t, config.DockerNetwork(), containerCfg.Labels[templates.NetworkLabel])
+	assert.Equal(t, "peer0-org1", containerCfg.Labels[templates.NodeLabel])
+	assert.Contains(t, containerCfg.ExposedPorts, nat.Port("7051/tcp"))
+	assert.Equal(t, "31000", hostConfig.PortBindings[nat.Port("31000/tcp")][0].HostPort)
+	assert.Equal(t, "31100", hostConfig.PortBindings[nat.Port("9443/tcp")][0].HostPort)
+	assert.Equal(t, "", hostConfig.PortBindings[nat.Port("7051/tcp")][0].HostPort)
+	assert.Equal(t, []string{"peer0-org1"}, networkConfig.EndpointsConfig[config.DockerNetwork()].Aliases)
+
+	orderer := services[1]
+	_, hostConfig, _, err = containerConfig(orderer, orderer.Volumes, config.DockerNetwork())
+	require.NoError(t, err)
+	assert.Equal(t, "30200", hostConfig.PortBindings[nat.Port(orderer.Labels[templates.AdminPortLabel])][0].HostPort)
+	assert.Equal(t, "30100", hostConfig.PortBindings[nat.Port(orderer.Labels[templates.HealthPortLabel])][0].HostPort)
+	assert.Equal(t, "30000", hostConfig.PortBindings[nat.Port(orderer.Labels[templates.ListenPortLabel])][0].HostPort)
+
+	config.NetworkName = "smoke"
+	config.Ports.Base = 34000
+	services, err = templates.Services("docker", config)
+	require.NoError(t, err)
+	peer = services[0]
+	assert.Equal(t, "peer0-org1", peer.Name)
+	assert.Equal(t, "smoke-peer0-org1", peer.ContainerName)
+	assert.Contains(t, peer.Environment, "CORE_VM_DOCKER_HOSTCONFIG_NETWORKMODE=smoke_default")
+	assert.Contains(t, peer.Environment, "CORE_PEER_NETWORKID=smoke")
+	_, hostConfig, networkConfig, err = containerConfig(peer, peer.Volumes, config.DockerNetwork())
+	require.NoError(t, err)
+	assert.Equal(t, "35000", hostConfig.PortBindings[nat.Port("35000/tcp")][0].HostPort)
+	assert.Equal(t, "35100", hostConfig.PortBindings[nat.Port("9443/tcp")][0].HostPort)
+	assert.Equal(t, "smoke_default", string(hostConfig.NetworkMode))
+	assert.Equal(t, []string{"peer0-org1"}, networkConfig.EndpointsConfig["smoke_default"].Aliases)
+	assert.Equal(t, "smoke_default", peer.Labels[templates.NetworkLabel])
+
+	server := templates.ChaincodeServerService(config, networkspec.ChaincodeServer{
+		Name:   "samplecc-v1",
+		Image:  "samplecc:v1",
+		Port:   9999,
+		Env:    []string{"CHAINCODE_SERVER_ADDRESS=0.0.0.0:9999"},
+		TLSDir: "/tmp/artifacts/chaincodes/samplecc-v1",
+	})
+	containerCfg, hostConfig, networkConfig, err = containerConfig(server, server.Volumes, config.DockerNetwork())
+	require.NoError(t, err)
+	assert.Equal(t, "smoke-samplecc-v1", server.ContainerName)
+	assert.Empty(t, containerCfg.Cmd)
+	assert.Contains(t, containerCfg.ExposedPorts, nat.Port("9999/tcp"))
+	assert.Empty(t, hostConfig.PortBindings)
+	assert.Equal(t, []string{"/tmp/artifacts/chaincodes/samplecc-v1:/etc/hyperledger/chaincode/tls:ro"}, hostConfig.Binds)
+	assert.Equal(t, []string{"samplecc-v1"}, networkConfig.EndpointsConfig["smoke_default"].Aliases)
+	assert.Equal(t, "smoke_default", containerCfg.Labels[templates.NetworkLabel])
+}
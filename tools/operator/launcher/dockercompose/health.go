@@ -1,67 +1,17 @@
 package dockercompose
 
 import (
-	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/hyperledger/fabric-test/tools/operator/logger"
-	"github.com/hyperledger/fabric-test/tools/operator/networkclient"
 	"github.com/hyperledger/fabric-test/tools/operator/networkspec"
+	"github.com/hyperledger/fabric-test/tools/operator/networkstate"
 )
 
-func (d DockerCompose) VerifyContainersAreRunning() error {
-
-	logger.INFO("Check status of all the containers to verify they are running")
-	count := 0
-	args := []string{"ps", "-a"}
-	output, err := networkclient.ExecuteCommand("docker", args, false)
-	if err != nil {
-		logger.ERROR("Error occured while listing all the containers")
-		return err
-	}
-	numContainers := len(strings.Split(string(output), "\n"))
-	ticker := time.NewTicker(15 * time.Second)
-	defer ticker.Stop()
-	return func() error {
-		for {
-			select {
-			case <-ticker.C:
-				args = []string{"ps", "-af", "status=running"}
-				output, err = networkclient.ExecuteCommand("docker", args, false)
-				if err != nil {
-					logger.ERROR("Error occured while listing the running containers")
-					return err
-				}
-				runningContainers := len(strings.Split(string(output), "\n"))
-				if numContainers == runningContainers {
-					logger.INFO("All the containers are up and running")
-					return nil
-				}
-				args = []string{"ps", "-af", "status=exited", "-af", "status=created", "--format", "{{.Names}}"}
-				output, err = networkclient.ExecuteCommand("docker", args, false)
-				if err != nil {
-					logger.ERROR("Error occured while listing the exited containers")
-					return err
-				}
-				exitedContainers := strings.Split(strings.TrimSpace(string(output)), "\n")
-				if len(exitedContainers) > 0 {
-					logger.ERROR("Exited Containers: ", strings.Join(exitedContainers, ","))
-					return errors.New("Containers exited")
-				}
-				count++
-				if count >= 4 {
-					return errors.New("Waiting time to bring up containers exceeded 1 minute")
-				}
-			}
-		}
-	}()
-}
-
 func (d DockerCompose) checkHealth(componentName string, config networkspec.Config) error {
 	logger.INFO("Checking health for ", componentName)
 	portNumber, err := d.GetDockerServicePort(componentName, true)
@@ -74,7 +24,9 @@ func (d DockerCompose) checkHealth(componentName string, config networkspec.Conf
 		logger.INFO("Querying /healthz URL: " + url)
 		resp, err := http.Get(url)
 		if err != nil {
-			return fmt.Errorf("Error while hitting the endpoint %s", url)
+			logger.INFO("Error while hitting the endpoint ", url, ": ", err.Error())
+			time.Sleep(time.Second * 5)
+			continue
 		}
 		bodyBytes, err := ioutil.ReadAll(resp.Body)
 		_ = resp.Body.Close()
@@ -93,27 +45,18 @@ func (d DockerCompose) checkHealth(componentName string, config networkspec.Conf
 	return fmt.Errorf("Health check failed for %s", componentName)
 }
 
+//CheckDockerContainersHealth -- checks the health of the orderers and peers launched from config
 func (d DockerCompose) CheckDockerContainersHealth(config networkspec.Config) error {
+	return d.checkNodesHealth(networkstate.New(config, "docker").Nodes(), config)
+}
 
-	var err error
-	for i := 0; i < len(config.OrdererOrganizations); i++ {
-		org := config.OrdererOrganizations[i]
-		for j := 0; j < org.NumOrderers; j++ {
-			ordererName := fmt.Sprintf("orderer%d-%s", j, org.Name)
-			err = d.checkHealth(ordererName, config)
-			if err != nil {
-				return err
-			}
-		}
-	}
-	for i := 0; i < len(config.PeerOrganizations); i++ {
-		org := config.PeerOrganizations[i]
-		for j := 0; j < org.NumPeers; j++ {
-			peerName := fmt.Sprintf("peer%d-%s", j, org.Name)
-			err = d.checkHealth(peerName, config)
-			if err != nil {
-				return err
-			}
+//checkNodesHealth -- checks the health of the orderers and peers with the given names
+func (d DockerCompose) checkNodesHealth(nodeNames []string, config networkspec.Config) error {
+
+	for _, nodeName := range nodeNames {
+		err := d.checkHealth(nodeName, config)
+		if err != nil {
+			return err
 		}
 	}
 	return nil
@@ -1,64 +1,94 @@
 package dockercompose
 
 import (
-	"fmt"
-	"os"
-	"strings"
-
+	"github.com/hyperledger/fabric-test/tools/operator/connectionprofile"
 	"github.com/hyperledger/fabric-test/tools/operator/launcher/nl"
 	"github.com/hyperledger/fabric-test/tools/operator/logger"
 	"github.com/hyperledger/fabric-test/tools/operator/networkclient"
 	"github.com/hyperledger/fabric-test/tools/operator/networkspec"
-	"github.com/hyperledger/fabric-test/tools/operator/paths"
+	"github.com/hyperledger/fabric-test/tools/operator/networkstate"
+	"github.com/hyperledger/fabric-test/tools/operator/plan"
+	"github.com/hyperledger/fabric-test/tools/operator/templates"
 
 	"github.com/pkg/errors"
 )
 
-//DockerCompose --
+//DockerCompose -- launches the containers of the docker compose files of a network through the Docker Engine API
 type DockerCompose struct {
-	ConfigPath string
-	Action     []string
-	Config     networkspec.Config
-}
-
-//Args -- arguments
-func (d DockerCompose) Args() []string {
-
-	args := []string{"-f", d.ConfigPath}
-	return append(args, d.Action...)
+	Config networkspec.Config
 }
 
 //GenerateConfigurationFiles - to generate all the configuration files
 func (d DockerCompose) GenerateConfigurationFiles(upgrade bool) error {
 
-	network := nl.Network{TemplatesDir: paths.TemplateFilePath("docker")}
-	err := network.GenerateConfigurationFiles(upgrade)
+	var network nl.Network
+	err := network.GenerateConfigurationFiles(d.Config, "docker", upgrade)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-//LaunchLocalNetwork -- To launch the network in the local environment
-func (d DockerCompose) LaunchLocalNetwork(config networkspec.Config) error {
+//up -- creates and starts the containers of the services of a docker compose file, or of the named ones only, and
+//waits for them to run
+func up(configFile string, config networkspec.Config, names ...string) error {
+
+	services, err := templates.Services(configFile, config)
+	if err != nil {
+		return err
+	}
+	if len(names) > 0 {
+		var selected []templates.Service
+		for _, service := range services {
+			if contains(names, service.Name) {
+				selected = append(selected, service)
+			}
+		}
+		services = selected
+	}
+	docker, err := newEngine(config)
+	if err != nil {
+		return err
+	}
+	defer docker.close()
+	return docker.up(services)
+}
+
+//removeContainers -- removes the containers of the network with the given names
+func removeContainers(config networkspec.Config, names []string) error {
 
-	d.Config = config
-	configPath := paths.ConfigFilePath("docker")
-	d = DockerCompose{ConfigPath: configPath, Action: []string{"up", "-d"}}
-	_, err := networkclient.ExecuteCommand("docker-compose", d.Args(), true)
+	docker, err := newEngine(config)
 	if err != nil {
 		return err
 	}
+	defer docker.close()
+	for _, name := range names {
+		err = docker.remove(name)
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+//LaunchLocalNetwork -- To launch the network in the local environment
+func (d DockerCompose) LaunchLocalNetwork(config networkspec.Config) error {
+
+	return up("docker", config)
+}
+
 //UpgradeLocalNetwork -- To upgrade the network in the local environment
 func (d DockerCompose) UpgradeLocalNetwork(config networkspec.Config) error {
 
-	d.Config = config
-	configPath := paths.ConfigFilePath("docker")
-	d = DockerCompose{ConfigPath: configPath, Action: []string{"down"}}
-	_, err := networkclient.ExecuteCommand("docker-compose", d.Args(), true)
+	services, err := templates.Services("docker", config)
+	if err != nil {
+		return err
+	}
+	var names []string
+	for _, service := range services {
+		names = append(names, service.Name)
+	}
+	err = removeContainers(config, names)
 	if err != nil {
 		logger.WARNING("Unable to delete all active endpoints")
 	}
@@ -66,21 +96,47 @@ func (d DockerCompose) UpgradeLocalNetwork(config networkspec.Config) error {
 	if err != nil {
 		return err
 	}
-	d = DockerCompose{ConfigPath: configPath, Action: []string{"up", "-d"}}
-	_, err = networkclient.ExecuteCommand("docker-compose", d.Args(), true)
-	if err != nil {
-		return err
+	return up("docker", config)
+}
+
+//RollingUpgradeLocalNetwork -- replaces the orderers and peers of the network in the local environment one at a time,
+//recreating the container of each node from its compose file and waiting for its /healthz
+func (d DockerCompose) RollingUpgradeLocalNetwork(config networkspec.Config) error {
+
+	return networkclient.RollingUpgrade(config, func(node networkclient.UpgradeNode) error {
+		configFile, err := composeFile(node.Name, config)
+		if err != nil {
+			return err
+		}
+		err = up(configFile, config, node.Name)
+		if err != nil {
+			return err
+		}
+		return d.checkHealth(node.Name, config)
+	})
+}
+
+//composeFile -- the compose file running the container of a node
+func composeFile(nodeName string, config networkspec.Config) (string, error) {
+
+	for _, configFile := range []string{"docker", "peer-extend", "org-extend", "orderer-extend"} {
+		services, err := templates.Services(configFile, config)
+		if err != nil {
+			return "", err
+		}
+		for _, service := range services {
+			if service.Name == nodeName {
+				return configFile, nil
+			}
+		}
 	}
-	return nil
+	return "", errors.Errorf("no compose file runs the container of %s", nodeName)
 }
 
 //ExtendLocalNetwork -- To upgrade the network in the local environment
 func (d DockerCompose) ExtendLocalNetwork(config networkspec.Config) error {
 
-	d.Config = config
-	configPath := paths.ConfigFilePath("peer-extend")
-	d = DockerCompose{ConfigPath: configPath, Action: []string{"up", "-d"}}
-	_, err := networkclient.ExecuteCommand("docker-compose", d.Args(), true)
+	err := up("peer-extend", config)
 	if err != nil {
 		return err
 	}
@@ -91,6 +147,111 @@ func (d DockerCompose) ExtendLocalNetwork(config networkspec.Config) error {
 	return nil
 }
 
+//AddOrganizations -- launches the organizations of addOrg, generates their connection profiles and adds them to the network
+func (d DockerCompose) AddOrganizations(config networkspec.Config) error {
+
+	err := up("org-extend", config)
+	if err != nil {
+		return err
+	}
+	newOrgs := config
+	newOrgs.PeerOrganizations = config.AddOrganizations
+	err = d.CheckDockerContainersHealth(newOrgs)
+	if err != nil {
+		return err
+	}
+	err = d.GenerateConnectionProfiles(newOrgs)
+	if err != nil {
+		return err
+	}
+	return networkclient.AddOrganizations(config)
+}
+
+//RemoveOrganizations -- removes the organizations of removeOrg from the network and deletes their containers
+func (d DockerCompose) RemoveOrganizations(config networkspec.Config) error {
+
+	state, err := networkstate.Load(config, "docker")
+	if err != nil {
+		return err
+	}
+	err = networkclient.RemoveOrganizations(config)
+	if err != nil {
+		return err
+	}
+	connProfile := connectionprofile.ConnProfile{Config: config}
+	for _, orgName := range config.RemoveOrganizations {
+		var containers []string
+		for _, component := range state.OfOrg(orgName) {
+			containers = append(containers, component.Name)
+		}
+		err = removeContainers(config, containers)
+		if err != nil {
+			return err
+		}
+		err = connProfile.RemoveConnProfilePerOrg(orgName)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//AddOrderers -- launches the orderers of addOrderer one at a time and adds them to the consenters of all channels
+func (d DockerCompose) AddOrderers(config networkspec.Config) error {
+
+	connProfile := connectionprofile.ConnProfile{Config: config}
+	return networkclient.AddOrderers(config, func(added networkclient.AddedOrderer) error {
+		err := up("orderer-extend", config, added.Name)
+		if err != nil {
+			return err
+		}
+		err = d.checkHealth(added.Name, config)
+		if err != nil {
+			return err
+		}
+		orderer, err := d.orderer(added.Name, networkspec.OrdererOrganizations{Name: added.Org, MSPID: added.MSPID}, config)
+		if err != nil {
+			return err
+		}
+		return connProfile.UpdateOrderers(map[string]networkspec.Orderer{added.Name: orderer}, nil)
+	})
+}
+
+//RemoveOrderers -- removes the orderers of removeOrderer from the consenters of all channels and deletes their containers
+func (d DockerCompose) RemoveOrderers(config networkspec.Config) error {
+
+	connProfile := connectionprofile.ConnProfile{Config: config}
+	return networkclient.RemoveOrderers(config, func(ordererName string) error {
+		err := removeContainers(config, []string{ordererName})
+		if err != nil {
+			return err
+		}
+		return connProfile.UpdateOrderers(nil, []string{ordererName})
+	})
+}
+
+//RotateOrdererCerts -- renews the tls certificates of the orderers of rotateOrdererCert and restarts them one at a time
+func (d DockerCompose) RotateOrdererCerts(config networkspec.Config) error {
+
+	var network nl.Network
+	err := network.RenewOrdererCerts(config)
+	if err != nil {
+		return err
+	}
+	docker, err := newEngine(config)
+	if err != nil {
+		return err
+	}
+	defer docker.close()
+	return networkclient.RotateOrdererCerts(config, func(ordererName string) error {
+		err := docker.restart(ordererName)
+		if err != nil {
+			return err
+		}
+		return d.checkHealth(ordererName, config)
+	})
+}
+
 //UpgradeDB -- upgrade database
 func (d DockerCompose) UpgradeDB(config networkspec.Config) error {
 	err := networkclient.UpgradeDB(config, "")
@@ -118,68 +279,77 @@ func (d DockerCompose) UpdatePolicy(config networkspec.Config) error {
 	return nil
 }
 
-//DownLocalNetwork -- To tear down the local network
+//DownLocalNetwork -- To tear down the local network: removes the containers, chaincode containers and images, volumes
+//and network the operator created, leaving the other containers of the docker daemon alone
 func (d DockerCompose) DownLocalNetwork(config networkspec.Config) error {
 
 	var network nl.Network
-	d.Config = config
-
-	extendConfigPath := paths.ConfigFilePath("peer-extend")
-	_, err := os.Stat(extendConfigPath)
-	if err == nil {
-		d = DockerCompose{ConfigPath: extendConfigPath, Action: []string{"down", "--volumes"}}
-		_, err = networkclient.ExecuteCommand("docker-compose", d.Args(), true)
-		if err != nil {
-			return err
-		}
+	docker, err := newEngine(config)
+	if err != nil {
+		return err
 	}
-
-	configPath := paths.ConfigFilePath("docker")
-	d = DockerCompose{ConfigPath: configPath, Action: []string{"down", "--volumes", "--remove-orphans"}}
-	_, err = networkclient.ExecuteCommand("docker-compose", d.Args(), true)
+	defer docker.close()
+	err = docker.removeNodes()
 	if err != nil {
 		return err
 	}
-
-	configDirPath := config.ArtifactsLocation
-	cleanArgs := []string{"run", "--rm", "-v", fmt.Sprintf("%s:/opt", configDirPath), "busybox", "sh", "-c", "(rm -rf /opt/backup)"}
-	_, err = networkclient.ExecuteCommand("docker", cleanArgs, true)
+	err = docker.removeChaincodes()
 	if err != nil {
 		return err
 	}
-
-	err = network.NetworkCleanUp(config)
+	err = docker.removeBackup(config.ArtifactsLocation)
+	if err != nil {
+		return err
+	}
+	err = docker.removeNetwork()
 	if err != nil {
 		return err
 	}
+	return network.NetworkCleanUp(config)
+}
+
+//Inventory -- sets the container IDs, images and host ports of the components of the state of the network
+func (d DockerCompose) Inventory(state *networkstate.State) error {
 
-	err = d.removeChainCodeContainersAndImages()
+	docker, err := newEngine(d.Config)
 	if err != nil {
 		return err
 	}
-	return nil
+	defer docker.close()
+	return docker.inventory(state)
 }
 
-func (d DockerCompose) removeChainCodeContainersAndImages() error {
+//ComponentStates -- the states of the containers of the components of the network, such as Up 5 minutes
+func (d DockerCompose) ComponentStates(state networkstate.State) (map[string]string, error) {
 
-	var err error
-	dockerList := []string{"ps", "-aq", "-f", "status=exited"}
-	containerList, _ := networkclient.ExecuteCommand("docker", dockerList, false)
-	if containerList != "" {
-		list := strings.Split(containerList, "\n")
-		containerArgs := []string{"rm", "-f"}
-		containerArgs = append(containerArgs, list...)
-		_, err = networkclient.ExecuteCommand("docker", containerArgs, true)
-	}
-	ccimagesList := []string{"images", "-q", "--filter=reference=dev*"}
-	images, _ := networkclient.ExecuteCommand("docker", ccimagesList, false)
-	if images != "" {
-		list := strings.Split(images, "\n")
-		imageArgs := []string{"rmi", "-f"}
-		imageArgs = append(imageArgs, list...)
-		_, err = networkclient.ExecuteCommand("docker", imageArgs, true)
-	}
-	return err
+	docker, err := newEngine(d.Config)
+	if err != nil {
+		return nil, err
+	}
+	defer docker.close()
+	return docker.states()
+}
+
+//Plan -- records in p the configuration files, artifacts and containers of the up action, without launching them
+func (d DockerCompose) Plan(action string, p *plan.Plan) error {
+
+	if action != "up" {
+		return errors.Errorf("dry run of action %s is not supported on docker, only of up", action)
+	}
+	var network nl.Network
+	err := network.PlanConfigurationFiles(d.Config, "docker", false, p)
+	if err != nil {
+		return err
+	}
+	network.PlanNetworkArtifacts(d.Config, p)
+	services, err := templates.Services("docker", d.Config)
+	if err != nil {
+		return err
+	}
+	for _, service := range services {
+		p.Containers = append(p.Containers, plan.Container{Name: service.ContainerName, Image: service.Image, Ports: service.Ports})
+	}
+	return nil
 }
 
 //DockerNetwork --
@@ -203,11 +373,6 @@ func (d DockerCompose) DockerNetwork(action string) error {
 			logger.ERROR("Failed to launch fabric network")
 			return err
 		}
-		err = d.VerifyContainersAreRunning()
-		if err != nil {
-			logger.ERROR("Failed to verify docker container state")
-			return err
-		}
 		err = d.CheckDockerContainersHealth(d.Config)
 		if err != nil {
 			logger.ERROR("Failed to check docker containers health")
@@ -218,7 +383,27 @@ func (d DockerCompose) DockerNetwork(action string) error {
 			logger.ERROR("Failed to generate connection profile")
 			return err
 		}
+		if d.Config.Orderer.BootstrapMethod == "none" {
+			err = networkclient.JoinOrdererChannels(d.Config)
+			if err != nil {
+				logger.ERROR("Failed to join the orderers to the channels")
+				return err
+			}
+		}
 	case "upgradeNetwork":
+		if d.Config.Upgrade.Strategy == networkspec.Rolling {
+			err = network.UpgradeConfigurationFiles(d.Config, "docker")
+			if err != nil {
+				logger.ERROR("Failed to generate docker compose files")
+				return err
+			}
+			err = d.RollingUpgradeLocalNetwork(d.Config)
+			if err != nil {
+				logger.ERROR("Failed to upgrade local fabric network")
+				return err
+			}
+			break
+		}
 		err = d.GenerateConfigurationFiles(true)
 		if err != nil {
 			logger.ERROR("Failed to generate docker compose file")
@@ -231,7 +416,7 @@ func (d DockerCompose) DockerNetwork(action string) error {
 		}
 	case "addPeer":
 		var network nl.Network
-		err = network.ExtendConfigurationFiles("docker")
+		err = network.ExtendConfigurationFiles(d.Config, "docker")
 		if err != nil {
 			logger.ERROR("Failed to generate docker compose file")
 			return err
@@ -246,6 +431,56 @@ func (d DockerCompose) DockerNetwork(action string) error {
 			logger.ERROR("Failed to extend local fabric network")
 			return err
 		}
+	case "addOrg":
+		err = network.AddOrgConfigurationFiles(d.Config, "docker")
+		if err != nil {
+			logger.ERROR("Failed to generate docker compose file")
+			return err
+		}
+		err = network.GenerateOrgCryptoCerts(d.Config)
+		if err != nil {
+			logger.ERROR("Failed to generate certificates")
+			return err
+		}
+		err = d.AddOrganizations(d.Config)
+		if err != nil {
+			logger.ERROR("Failed to add organizations to local fabric network")
+			return err
+		}
+	case "removeOrg":
+		err = d.RemoveOrganizations(d.Config)
+		if err != nil {
+			logger.ERROR("Failed to remove organizations from local fabric network")
+			return err
+		}
+	case "addOrderer":
+		err = network.AddOrdererConfigurationFiles(d.Config, "docker")
+		if err != nil {
+			logger.ERROR("Failed to generate docker compose file")
+			return err
+		}
+		err = network.GenerateOrdererCryptoCerts(d.Config)
+		if err != nil {
+			logger.ERROR("Failed to generate certificates")
+			return err
+		}
+		err = d.AddOrderers(d.Config)
+		if err != nil {
+			logger.ERROR("Failed to add orderers to local fabric network")
+			return err
+		}
+	case "removeOrderer":
+		err = d.RemoveOrderers(d.Config)
+		if err != nil {
+			logger.ERROR("Failed to remove orderers from local fabric network")
+			return err
+		}
+	case "rotateOrdererCert":
+		err = d.RotateOrdererCerts(d.Config)
+		if err != nil {
+			logger.ERROR("Failed to rotate orderer certificates of local fabric network")
+			return err
+		}
 	case "upgradeDB":
 		err = d.UpgradeDB(d.Config)
 		if err != nil {
@@ -271,13 +506,31 @@ func (d DockerCompose) DockerNetwork(action string) error {
 			return err
 		}
 	case "health":
-		err = d.CheckDockerContainersHealth(d.Config)
+		state, err := networkstate.Load(d.Config, "docker")
+		if err != nil {
+			return err
+		}
+		err = d.checkNodesHealth(state.Nodes(), d.Config)
 		if err != nil {
 			logger.ERROR("Failed to check the health of local fabric network")
 			return err
 		}
+		err = networkclient.CheckConsensus(d.Config)
+		if err != nil {
+			logger.ERROR("Failed to check the consensus clusters of local fabric network")
+			return err
+		}
 	default:
 		return errors.Errorf("Incorrect action %s Use up or down for action", action)
 	}
 	return nil
 }
+
+func contains(list []string, item string) bool {
+	for _, element := range list {
+		if element == item {
+			return true
+		}
+	}
+	return false
+}
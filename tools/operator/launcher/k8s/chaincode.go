@@ -0,0 +1,162 @@
+package k8s
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	apiv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/hyperledger/fabric-test/tools/operator/logger"
+	"github.com/hyperledger/fabric-test/tools/operator/networkspec"
+)
+
+const chaincodeServerTimeout = 3 * time.Minute
+
+//StartChaincodeServer -- runs a chaincode server as a deployment of the namespace of the network, with a ClusterIP
+//service of its name for the peers and its TLSDir as a secret, replacing those of a previous server with the same
+//name, and waits for it to be available. down removes them with the namespace
+func (k8s K8s) StartChaincodeServer(server networkspec.ChaincodeServer) error {
+
+	clientset, err := k8s.buildClientset(&k8s.KubeConfigPath)
+	if err != nil {
+		return err
+	}
+	ns := k8s.Config.K8s.Namespace
+	err = k8s.removeChaincodeServer(server.Name, ns, clientset)
+	if err != nil {
+		return err
+	}
+	labels := map[string]string{"k8s-app": server.Name, "type": "chaincode"}
+	container := corev1.Container{
+		Name:            "chaincode",
+		Image:           server.Image,
+		ImagePullPolicy: corev1.PullPolicy("IfNotPresent"),
+		Ports:           []corev1.ContainerPort{{ContainerPort: int32(server.Port)}},
+	}
+	for _, variable := range server.Env {
+		nameValue := strings.SplitN(variable, "=", 2)
+		container.Env = append(container.Env, corev1.EnvVar{Name: nameValue[0], Value: nameValue[len(nameValue)-1]})
+	}
+	var volumes []corev1.Volume
+	if server.TLSDir != "" {
+		err = k8s.createChaincodeServerSecret(server, ns, clientset)
+		if err != nil {
+			return err
+		}
+		container.VolumeMounts = []corev1.VolumeMount{{Name: "tls", MountPath: networkspec.ChaincodeServerTLSDir, ReadOnly: true}}
+		volumes = []corev1.Volume{{Name: "tls", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: fmt.Sprintf("%s-tls", server.Name)}}}}
+	}
+	var replicas int32 = 1
+	deployment := &apiv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: server.Name, Labels: labels},
+		Spec: apiv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec:       corev1.PodSpec{Containers: []corev1.Container{container}, Volumes: volumes},
+			},
+		},
+	}
+	_, err = clientset.AppsV1().Deployments(ns).Create(deployment)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create deployment of chaincode server %s", server.Name)
+	}
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: server.Name, Labels: labels},
+		Spec: corev1.ServiceSpec{
+			Selector: labels,
+			Type:     corev1.ServiceType("ClusterIP"),
+			Ports:    []corev1.ServicePort{{Name: "chaincode", Port: int32(server.Port)}},
+		},
+	}
+	_, err = clientset.CoreV1().Services(ns).Create(service)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create service of chaincode server %s", server.Name)
+	}
+	logger.INFO("Created deployment and service of chaincode server ", server.Name)
+	return k8s.waitChaincodeServer(server.Name, ns, clientset)
+}
+
+//createChaincodeServerSecret -- the secret <server>-tls with the files of the TLSDir of the server
+func (k8s K8s) createChaincodeServerSecret(server networkspec.ChaincodeServer, ns string, clientset *kubernetes.Clientset) error {
+
+	files, err := ioutil.ReadDir(server.TLSDir)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read the TLS of chaincode server %s", server.Name)
+	}
+	data := make(map[string][]byte)
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+		data[file.Name()], err = ioutil.ReadFile(filepath.Join(server.TLSDir, file.Name()))
+		if err != nil {
+			return errors.Wrapf(err, "failed to read the TLS of chaincode server %s", server.Name)
+		}
+	}
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("%s-tls", server.Name)}, Data: data}
+	_, err = clientset.CoreV1().Secrets(ns).Create(secret)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create secret of chaincode server %s", server.Name)
+	}
+	return nil
+}
+
+//removeChaincodeServer -- deletes the deployment, service and secret of a chaincode server, if they exist
+func (k8s K8s) removeChaincodeServer(name, ns string, clientset *kubernetes.Clientset) error {
+
+	propagation := metav1.DeletePropagationForeground
+	options := &metav1.DeleteOptions{PropagationPolicy: &propagation}
+	deletes := []func() error{
+		func() error { return clientset.AppsV1().Deployments(ns).Delete(name, options) },
+		func() error { return clientset.CoreV1().Services(ns).Delete(name, options) },
+		func() error { return clientset.CoreV1().Secrets(ns).Delete(fmt.Sprintf("%s-tls", name), options) },
+	}
+	for _, remove := range deletes {
+		err := remove()
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to remove chaincode server %s", name)
+		}
+	}
+	deadline := time.Now().Add(chaincodeServerTimeout)
+	for {
+		_, err := clientset.AppsV1().Deployments(ns).Get(name, metav1.GetOptions{})
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return errors.Errorf("deployment of chaincode server %s is still being deleted after %s", name, chaincodeServerTimeout)
+		}
+		time.Sleep(2 * time.Second)
+	}
+}
+
+//waitChaincodeServer -- waits for the deployment of a chaincode server to have an available pod
+func (k8s K8s) waitChaincodeServer(name, ns string, clientset *kubernetes.Clientset) error {
+
+	deadline := time.Now().Add(chaincodeServerTimeout)
+	for {
+		deployment, err := clientset.AppsV1().Deployments(ns).Get(name, metav1.GetOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "failed to get deployment of chaincode server %s", name)
+		}
+		if deployment.Status.AvailableReplicas > 0 {
+			logger.INFO("Chaincode server ", name, " is available")
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return errors.Errorf("chaincode server %s is not available after %s", name, chaincodeServerTimeout)
+		}
+		time.Sleep(2 * time.Second)
+	}
+}
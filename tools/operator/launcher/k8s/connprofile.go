@@ -6,6 +6,7 @@ import (
 	"net"
 	"strconv"
 
+	"github.com/pkg/errors"
 	yaml "gopkg.in/yaml.v2"
 
 	"github.com/hyperledger/fabric-test/tools/operator/connectionprofile"
@@ -61,6 +62,24 @@ func (k8s K8s) ExternalIP(config networkspec.Config, serviceName string, clients
 	return nodeIP, nil
 }
 
+//adminServicePort -- the port of the admin endpoint of an orderer, the third port of its service
+func (k8s K8s) adminServicePort(serviceName, serviceType, namespace string, clientset *kubernetes.Clientset) (string, error) {
+
+	output, err := k8s.ServiceStatus(namespace, serviceName, clientset)
+	if err != nil {
+		logger.ERROR("Failed to get the admin port number for service ", serviceName)
+		return "", err
+	}
+	if len(output.Spec.Ports) < 3 {
+		return "", errors.Errorf("service %s has no admin port", serviceName)
+	}
+	portNumber := output.Spec.Ports[2].NodePort
+	if serviceType == "LoadBalancer" {
+		portNumber = output.Spec.Ports[2].Port
+	}
+	return strconv.Itoa(int(portNumber)), nil
+}
+
 //ServicePort -- To get the port number of a fabric k8s component
 func (k8s K8s) ServicePort(serviceName, serviceType, namespace string, forHealth bool, clientset *kubernetes.Clientset) (string, error) {
 
@@ -83,66 +102,84 @@ func (k8s K8s) ServicePort(serviceName, serviceType, namespace string, forHealth
 func (k8s K8s) ordererOrganizations(config networkspec.Config, clientset *kubernetes.Clientset) (map[string]networkspec.Orderer, error) {
 
 	orderers := make(map[string]networkspec.Orderer)
-	artifactsLocation := config.ArtifactsLocation
-	ordererOrgsPath := paths.OrdererOrgsDir(artifactsLocation)
-	protocol := "grpc"
-	if config.TLS == "true" || config.TLS == "mutual" {
-		protocol = "grpcs"
-	}
 	for org := 0; org < len(config.OrdererOrganizations); org++ {
 		ordererOrg := config.OrdererOrganizations[org]
-		orgName := ordererOrg.Name
 		for i := 0; i < ordererOrg.NumOrderers; i++ {
-			connProfile := connectionprofile.ConnProfile{}
-			ordererName := fmt.Sprintf("orderer%d-%s", i, orgName)
-			portNumber, err := k8s.ServicePort(ordererName, config.K8s.ServiceType, config.K8s.Namespace, false, clientset)
+			ordererName := fmt.Sprintf("orderer%d-%s", i, ordererOrg.Name)
+			orderer, err := k8s.orderer(ordererName, ordererOrg, config, clientset)
 			if err != nil {
 				return orderers, err
 			}
-			nodeIP, err := k8s.ExternalIP(config, ordererName, clientset)
-			if err != nil {
-				return orderers, err
-			}
-			metricsPortNumber, err := k8s.ServicePort(ordererName, config.K8s.ServiceType, config.K8s.Namespace, true, clientset)
-			if err != nil {
-				return orderers, err
-			}
-			orderer := networkspec.Orderer{
-				MSPID:      ordererOrg.MSPID,
-				URL:        fmt.Sprintf("%s://%s:%s", protocol, nodeIP, portNumber),
-				MetricsURL: fmt.Sprintf("http://%s:%s", nodeIP, metricsPortNumber),
-			}
-			orderer.GrpcOptions.SslTarget = ordererName
-			tlscaCertPath := paths.JoinPath(ordererOrgsPath, fmt.Sprintf("%s/orderers/%s.%s/msp/tlscacerts/tlsca.%s-cert.pem", orgName, ordererName, orgName, orgName))
-			cert, err := connProfile.GetCertificateFromFile(tlscaCertPath)
-			if err != nil {
-				return orderers, err
-			}
-			orderer.TLSCACerts.Pem = cert
-			adminCertPath := paths.JoinPath(ordererOrgsPath, fmt.Sprintf("%s/users/Admin@%s/msp/signcerts/Admin@%s-cert.pem", orgName, orgName, orgName))
-			cert, err = connProfile.GetCertificateFromFile(adminCertPath)
-			if err != nil {
-				return orderers, err
-			}
-			orderer.AdminCert = cert
-			keystorePath := paths.JoinPath(ordererOrgsPath, fmt.Sprintf("%s/users/Admin@%s/msp/keystore", orgName, orgName))
-
-			privKeyFile, err := ioutil.ReadDir(keystorePath)
-			if err != nil {
-				return orderers, err
-			}
-			privKeyPath := paths.JoinPath(keystorePath, fmt.Sprintf("%s", privKeyFile[0].Name()))
-			cert, err = connProfile.GetCertificateFromFile(privKeyPath)
-			if err != nil {
-				return orderers, err
-			}
-			orderer.PrivateKey = cert
 			orderers[ordererName] = orderer
 		}
 	}
 	return orderers, nil
 }
 
+//orderer -- the connection profile entry of an orderer
+func (k8s K8s) orderer(ordererName string, ordererOrg networkspec.OrdererOrganizations, config networkspec.Config, clientset *kubernetes.Clientset) (networkspec.Orderer, error) {
+
+	ordererOrgsPath := paths.OrdererOrgsDir(config.ArtifactsLocation)
+	protocol := "grpc"
+	if config.TLS == "true" || config.TLS == "mutual" {
+		protocol = "grpcs"
+	}
+	orgName := ordererOrg.Name
+	connProfile := connectionprofile.ConnProfile{}
+	portNumber, err := k8s.ServicePort(ordererName, config.K8s.ServiceType, config.K8s.Namespace, false, clientset)
+	if err != nil {
+		return networkspec.Orderer{}, err
+	}
+	nodeIP, err := k8s.ExternalIP(config, ordererName, clientset)
+	if err != nil {
+		return networkspec.Orderer{}, err
+	}
+	metricsPortNumber, err := k8s.ServicePort(ordererName, config.K8s.ServiceType, config.K8s.Namespace, true, clientset)
+	if err != nil {
+		return networkspec.Orderer{}, err
+	}
+	adminPortNumber, err := k8s.adminServicePort(ordererName, config.K8s.ServiceType, config.K8s.Namespace, clientset)
+	if err != nil {
+		return networkspec.Orderer{}, err
+	}
+	adminProtocol := "http"
+	if config.TLS == "true" || config.TLS == "mutual" {
+		adminProtocol = "https"
+	}
+	orderer := networkspec.Orderer{
+		MSPID:      ordererOrg.MSPID,
+		URL:        fmt.Sprintf("%s://%s:%s", protocol, nodeIP, portNumber),
+		MetricsURL: fmt.Sprintf("http://%s:%s", nodeIP, metricsPortNumber),
+		AdminURL:   fmt.Sprintf("%s://%s:%s", adminProtocol, nodeIP, adminPortNumber),
+	}
+	orderer.GrpcOptions.SslTarget = ordererName
+	tlscaCertPath := paths.JoinPath(ordererOrgsPath, fmt.Sprintf("%s/orderers/%s.%s/msp/tlscacerts/tlsca.%s-cert.pem", orgName, ordererName, orgName, orgName))
+	cert, err := connProfile.GetCertificateFromFile(tlscaCertPath)
+	if err != nil {
+		return orderer, err
+	}
+	orderer.TLSCACerts.Pem = cert
+	adminCertPath := paths.JoinPath(ordererOrgsPath, fmt.Sprintf("%s/users/Admin@%s/msp/signcerts/Admin@%s-cert.pem", orgName, orgName, orgName))
+	cert, err = connProfile.GetCertificateFromFile(adminCertPath)
+	if err != nil {
+		return orderer, err
+	}
+	orderer.AdminCert = cert
+	keystorePath := paths.JoinPath(ordererOrgsPath, fmt.Sprintf("%s/users/Admin@%s/msp/keystore", orgName, orgName))
+
+	privKeyFile, err := ioutil.ReadDir(keystorePath)
+	if err != nil {
+		return orderer, err
+	}
+	privKeyPath := paths.JoinPath(keystorePath, fmt.Sprintf("%s", privKeyFile[0].Name()))
+	cert, err = connProfile.GetCertificateFromFile(privKeyPath)
+	if err != nil {
+		return orderer, err
+	}
+	orderer.PrivateKey = cert
+	return orderer, nil
+}
+
 func (k8s K8s) certificateAuthorities(peerOrg networkspec.PeerOrganizations, config networkspec.Config, clientset *kubernetes.Clientset) (map[string]networkspec.CertificateAuthority, error) {
 
 	CAs := make(map[string]networkspec.CertificateAuthority)
@@ -9,6 +9,7 @@ import (
 
 	"github.com/hyperledger/fabric-test/tools/operator/logger"
 	"github.com/hyperledger/fabric-test/tools/operator/networkspec"
+	"github.com/hyperledger/fabric-test/tools/operator/networkstate"
 	"k8s.io/client-go/kubernetes"
 )
 
@@ -28,7 +29,9 @@ func (k8s K8s) checkHealth(componentName string, config networkspec.Config, clie
 		logger.INFO("Querying /healthz URL: " + url)
 		resp, err := http.Get(url)
 		if err != nil {
-			return fmt.Errorf("Error while hitting the endpoint")
+			logger.INFO("Error while hitting the endpoint ", url, ": ", err.Error())
+			time.Sleep(time.Second * 5)
+			continue
 		}
 		bytes, err := ioutil.ReadAll(resp.Body)
 		_ = resp.Body.Close()
@@ -49,24 +52,16 @@ func (k8s K8s) checkHealth(componentName string, config networkspec.Config, clie
 
 //CheckComponentsHealth --
 func (k8s K8s) CheckComponentsHealth(config networkspec.Config, clientset *kubernetes.Clientset) error {
-	for i := 0; i < len(config.OrdererOrganizations); i++ {
-		org := config.OrdererOrganizations[i]
-		for j := 0; j < org.NumOrderers; j++ {
-			ordererName := fmt.Sprintf("orderer%d-%s", j, org.Name)
-			err := k8s.checkHealth(ordererName, config, clientset)
-			if err != nil {
-				return err
-			}
-		}
-	}
-	for i := 0; i < len(config.PeerOrganizations); i++ {
-		org := config.PeerOrganizations[i]
-		for j := 0; j < org.NumPeers; j++ {
-			peerName := fmt.Sprintf("peer%d-%s", j, org.Name)
-			err := k8s.checkHealth(peerName, config, clientset)
-			if err != nil {
-				return err
-			}
+	return k8s.checkNodesHealth(networkstate.New(config, "k8s").Nodes(), config, clientset)
+}
+
+//checkNodesHealth -- checks the health of the orderers and peers with the given names
+func (k8s K8s) checkNodesHealth(nodeNames []string, config networkspec.Config, clientset *kubernetes.Clientset) error {
+
+	for _, nodeName := range nodeNames {
+		err := k8s.checkHealth(nodeName, config, clientset)
+		if err != nil {
+			return err
 		}
 	}
 	return nil
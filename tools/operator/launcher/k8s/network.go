@@ -2,18 +2,22 @@ package k8s
 
 import (
 	"fmt"
+	"io/ioutil"
 
 	"github.com/pkg/errors"
 
 	corev1 "k8s.io/api/core/v1"
 	apiResource "k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/clientcmd"
 
 	"github.com/hyperledger/fabric-test/tools/operator/fabricconfig"
 	"github.com/hyperledger/fabric-test/tools/operator/launcher/nl"
 	"github.com/hyperledger/fabric-test/tools/operator/logger"
+	"github.com/hyperledger/fabric-test/tools/operator/networkclient"
 	"github.com/hyperledger/fabric-test/tools/operator/networkspec"
+	"github.com/hyperledger/fabric-test/tools/operator/networkstate"
 )
 
 // K8s -
@@ -34,7 +38,14 @@ type LaunchConfig struct {
 	Ports      []int32            `json:"ports,omitempty"`
 }
 
-func (k8s K8s) launchObject(nsConfig networkspec.Config) ([]LaunchConfig, error) {
+//writeFile -- writes a configuration file of a node
+func writeFile(path string, contents []byte) error {
+	return ioutil.WriteFile(path, contents, 0644)
+}
+
+//launchObject -- the statefulsets of the network, passing the core.yaml and orderer.yaml of its peers and orderers to
+//write
+func (k8s K8s) launchObject(nsConfig networkspec.Config, write func(path string, contents []byte) error) ([]LaunchConfig, error) {
 
 	var launchConfig []LaunchConfig
 	coreConfig, err := fabricconfig.CoreConfig(nsConfig)
@@ -46,137 +57,55 @@ func (k8s K8s) launchObject(nsConfig networkspec.Config) ([]LaunchConfig, error)
 		return nil, errors.Wrap(err, "failed to read orderer config")
 	}
 
-	caImage := nl.DockerImage("ca", nsConfig.DockerOrg, nsConfig.DockerTag, nsConfig.DockerImages.Ca)
-	peerImage := nl.DockerImage("peer", nsConfig.DockerOrg, nsConfig.DockerTag, nsConfig.DockerImages.Peer)
-	ordererImage := nl.DockerImage("orderer", nsConfig.DockerOrg, nsConfig.DockerTag, nsConfig.DockerImages.Orderer)
-
-	var peerPort int32 = 31000
-	var peerMetricsPort int32 = 32000
-	var caPort int32 = 30500
-	var privileged bool = true
+	peerPort := int32(nsConfig.Port(31000))
+	peerMetricsPort := int32(nsConfig.Port(32000))
+	caPort := int32(nsConfig.Port(30500))
 	for i := 0; i < len(nsConfig.PeerOrganizations); i++ {
 		org := nsConfig.PeerOrganizations[i]
 		for j := 0; j < org.NumPeers; j++ {
-			err := fabricconfig.GenerateCorePeerConfig(fmt.Sprintf("peer%d-%s", j, org.Name), org.Name, org.MSPID, nsConfig.ArtifactsLocation, peerPort, peerMetricsPort, coreConfig)
+			configPath, contents, err := fabricconfig.CorePeerConfigFile(fmt.Sprintf("peer%d-%s", j, org.Name), org.Name, org.MSPID, peerPort, peerMetricsPort, coreConfig, nsConfig)
+			if err == nil {
+				err = write(configPath, contents)
+			}
 			if err != nil {
 				return nil, errors.Wrap(err, "failed to generate core configuration file")
 			}
-			containers := make([]corev1.Container, 0)
-			container := corev1.Container{
-				Name:            "dind",
-				Image:           "docker:dind",
-				ImagePullPolicy: corev1.PullPolicy("Always"),
-				Args:            []string{"dockerd", "-H tcp://0.0.0.0:2375", "-H unix://var/run/docker.sock"},
-				SecurityContext: &corev1.SecurityContext{Privileged: &privileged},
-				Resources:       k8s.resources(nsConfig.K8s.Resources.Dind),
-			}
-			containers = append(containers, container)
-			container = corev1.Container{
-				Name:            "peer",
-				Command:         []string{"peer"},
-				Args:            []string{"node", "start"},
-				Resources:       k8s.resources(nsConfig.K8s.Resources.Peers),
-				Image:           peerImage,
-				ImagePullPolicy: corev1.PullPolicy("Always"),
-				Env: []corev1.EnvVar{
-					{Name: "FABRIC_LOGGING_SPEC", Value: nsConfig.PeerFabricLoggingSpec},
-				},
-				VolumeMounts: k8s.volumeMountLists("peer", nsConfig.K8s.DataPersistence, nsConfig.EnableNodeOUs),
-			}
-			containers = append(containers, container)
-			l := LaunchConfig{
-				Name:       fmt.Sprintf("peer%d-%s", j, org.Name),
-				Type:       "peer",
-				Containers: containers,
-				Volumes:    k8s.volumesList("peer", org.Name, fmt.Sprintf("peer%d-%s", j, org.Name), nsConfig.K8s.DataPersistence, nsConfig.EnableNodeOUs),
-				Ports:      []int32{peerPort, peerMetricsPort},
-			}
-			launchConfig = append(launchConfig, l)
+			peerName := fmt.Sprintf("peer%d-%s", j, org.Name)
+			launchConfig = append(launchConfig, k8s.peerLaunchConfig(peerName, org.Name, []int32{peerPort, peerMetricsPort}, nsConfig))
 			if nsConfig.DBType == "couchdb" {
-				c := LaunchConfig{
-					Name: fmt.Sprintf("couchdb-peer%d-%s", j, org.Name),
-					Type: "couchdb",
-				}
-				container = corev1.Container{
-					Name:            "couchdb",
-					Resources:       k8s.resources(nsConfig.K8s.Resources.Couchdb),
-					Image:           "couchdb:3.3.2",
-					ImagePullPolicy: corev1.PullPolicy("Always"),
-					Env: []corev1.EnvVar{
-						{
-							Name:  "COUCHDB_USER",
-							Value: "admin",
-						},
-						{
-							Name:  "COUCHDB_PASSWORD",
-							Value: "adminpw",
-						},
-					},
-				}
-				if nsConfig.K8s.DataPersistence == "true" || nsConfig.K8s.DataPersistence == "local" {
-					volumeMount := corev1.VolumeMount{MountPath: "/opt/couchdb/data", Name: "couchdb-data-storage"}
-					container.VolumeMounts = append(container.VolumeMounts, volumeMount)
-					volume := corev1.Volume{
-						Name: "couchdb-data-storage",
-						VolumeSource: corev1.VolumeSource{
-							PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
-								ClaimName: fmt.Sprintf("couchdb-peer%d-%s-data", j, org.Name),
-							},
-						},
-					}
-					c.Volumes = []corev1.Volume{volume}
-				}
-				c.Containers = []corev1.Container{container}
-				launchConfig = append(launchConfig, c)
+				launchConfig = append(launchConfig, k8s.couchdbLaunchConfig(peerName, nsConfig))
 			}
 			peerPort++
 			peerMetricsPort++
 		}
 		for m := 0; m < org.NumCA; m++ {
-			l := k8s.caLaunchConfig(m, org.Name, caImage)
+			l := k8s.caLaunchConfig(m, org.Name, nsConfig.Image("ca", org.Name, fmt.Sprintf("ca%d-%s", m, org.Name)))
 			l.Ports = []int32{caPort}
 			launchConfig = append(launchConfig, l)
 			caPort++
 		}
 	}
 
-	var ordererPort int32 = 30000
-	var ordererMetricsPort int32 = 32500
-	var ordererAdminListenPort int32 = 32700
+	ordererPort := int32(nsConfig.Port(30000))
+	ordererMetricsPort := int32(nsConfig.Port(32500))
+	ordererAdminListenPort := int32(nsConfig.Port(32700))
 	for i := 0; i < len(nsConfig.OrdererOrganizations); i++ {
 		org := nsConfig.OrdererOrganizations[i]
 		for j := 0; j < org.NumOrderers; j++ {
-			err := fabricconfig.GenerateOrdererConfig(fmt.Sprintf("orderer%d-%s", j, org.Name), org.Name, org.MSPID, nsConfig.ArtifactsLocation, ordererPort, ordererMetricsPort, ordererAdminListenPort, ordererConfig)
+			configPath, contents, err := fabricconfig.OrdererConfigFile(fmt.Sprintf("orderer%d-%s", j, org.Name), org.Name, org.MSPID, nsConfig.ArtifactsLocation, ordererPort, ordererMetricsPort, ordererAdminListenPort, ordererConfig, nsConfig.OrdererOverrides(org.Name, fmt.Sprintf("orderer%d-%s", j, org.Name)))
+			if err == nil {
+				err = write(configPath, contents)
+			}
 			if err != nil {
 				return nil, errors.Wrap(err, "failed to generate orderer configuration file")
 			}
-			containers := make([]corev1.Container, 0)
-			container := corev1.Container{
-				Name:            "orderer",
-				Command:         []string{"orderer"},
-				Resources:       k8s.resources(nsConfig.K8s.Resources.Orderers),
-				Image:           ordererImage,
-				ImagePullPolicy: corev1.PullPolicy("Always"),
-				Env: []corev1.EnvVar{
-					{Name: "FABRIC_LOGGING_SPEC", Value: nsConfig.OrdererFabricLoggingSpec},
-				},
-				VolumeMounts: k8s.volumeMountLists("orderer", nsConfig.K8s.DataPersistence, nsConfig.EnableNodeOUs),
-			}
-			containers = append(containers, container)
-			l := LaunchConfig{
-				Name:       fmt.Sprintf("orderer%d-%s", j, org.Name),
-				Type:       "orderer",
-				Containers: containers,
-				Volumes:    k8s.volumesList("orderer", org.Name, fmt.Sprintf("orderer%d-%s", j, org.Name), nsConfig.K8s.DataPersistence, nsConfig.EnableNodeOUs),
-				Ports:      []int32{ordererPort, ordererMetricsPort, ordererAdminListenPort},
-			}
-			launchConfig = append(launchConfig, l)
+			launchConfig = append(launchConfig, k8s.ordererLaunchConfig(fmt.Sprintf("orderer%d-%s", j, org.Name), org.Name, []int32{ordererPort, ordererMetricsPort, ordererAdminListenPort}, nsConfig))
 			ordererPort++
 			ordererMetricsPort++
 			ordererAdminListenPort++
 		}
 		for m := 0; m < org.NumCA; m++ {
-			l := k8s.caLaunchConfig(m, org.Name, caImage)
+			l := k8s.caLaunchConfig(m, org.Name, nsConfig.Image("ca", org.Name, fmt.Sprintf("ca%d-%s", m, org.Name)))
 			l.Ports = []int32{caPort}
 			launchConfig = append(launchConfig, l)
 			caPort++
@@ -185,6 +114,47 @@ func (k8s K8s) launchObject(nsConfig networkspec.Config) ([]LaunchConfig, error)
 	return launchConfig, nil
 }
 
+func (k8s K8s) ordererLaunchConfig(ordererName, orgName string, ports []int32, nsConfig networkspec.Config) LaunchConfig {
+
+	containers := make([]corev1.Container, 0)
+	container := corev1.Container{
+		Name:            "orderer",
+		Command:         []string{"orderer"},
+		Resources:       k8s.resources(nsConfig.K8s.Resources.Orderers),
+		Image:           nsConfig.Image("orderer", orgName, ordererName),
+		ImagePullPolicy: corev1.PullPolicy("Always"),
+		Env: []corev1.EnvVar{
+			{Name: "FABRIC_LOGGING_SPEC", Value: nsConfig.OrdererFabricLoggingSpec},
+		},
+		VolumeMounts: k8s.volumeMountLists("orderer", nsConfig.K8s.DataPersistence, nsConfig.EnableNodeOUs),
+	}
+	volumes := k8s.volumesList("orderer", orgName, ordererName, nsConfig.K8s.DataPersistence, nsConfig.EnableNodeOUs)
+	if nsConfig.Orderer.BootstrapMethod == "none" {
+		var volumeMounts []corev1.VolumeMount
+		for _, volumeMount := range container.VolumeMounts {
+			if volumeMount.Name != "genesisblock" {
+				volumeMounts = append(volumeMounts, volumeMount)
+			}
+		}
+		container.VolumeMounts = volumeMounts
+		var withoutGenesisBlock []corev1.Volume
+		for _, volume := range volumes {
+			if volume.Name != "genesisblock" {
+				withoutGenesisBlock = append(withoutGenesisBlock, volume)
+			}
+		}
+		volumes = withoutGenesisBlock
+	}
+	containers = append(containers, container)
+	return LaunchConfig{
+		Name:       ordererName,
+		Type:       "orderer",
+		Containers: containers,
+		Volumes:    volumes,
+		Ports:      ports,
+	}
+}
+
 func (k8s K8s) resources(resource networkspec.Resource) corev1.ResourceRequirements {
 	return corev1.ResourceRequirements{
 		Limits: corev1.ResourceList{
@@ -198,6 +168,82 @@ func (k8s K8s) resources(resource networkspec.Resource) corev1.ResourceRequireme
 	}
 }
 
+func (k8s K8s) peerLaunchConfig(peerName, orgName string, ports []int32, nsConfig networkspec.Config) LaunchConfig {
+
+	var privileged bool = true
+	containers := make([]corev1.Container, 0)
+	if !nsConfig.ChaincodeAsAService() {
+		container := corev1.Container{
+			Name:            "dind",
+			Image:           "docker:dind",
+			ImagePullPolicy: corev1.PullPolicy("Always"),
+			Args:            []string{"dockerd", "-H tcp://0.0.0.0:2375", "-H unix://var/run/docker.sock"},
+			SecurityContext: &corev1.SecurityContext{Privileged: &privileged},
+			Resources:       k8s.resources(nsConfig.K8s.Resources.Dind),
+		}
+		containers = append(containers, container)
+	}
+	container := corev1.Container{
+		Name:            "peer",
+		Command:         []string{"peer"},
+		Args:            []string{"node", "start"},
+		Resources:       k8s.resources(nsConfig.K8s.Resources.Peers),
+		Image:           nsConfig.Image("peer", orgName, peerName),
+		ImagePullPolicy: corev1.PullPolicy("Always"),
+		Env: []corev1.EnvVar{
+			{Name: "FABRIC_LOGGING_SPEC", Value: nsConfig.PeerFabricLoggingSpec},
+		},
+		VolumeMounts: k8s.volumeMountLists("peer", nsConfig.K8s.DataPersistence, nsConfig.EnableNodeOUs),
+	}
+	containers = append(containers, container)
+	return LaunchConfig{
+		Name:       peerName,
+		Type:       "peer",
+		Containers: containers,
+		Volumes:    k8s.volumesList("peer", orgName, peerName, nsConfig.K8s.DataPersistence, nsConfig.EnableNodeOUs),
+		Ports:      ports,
+	}
+}
+
+func (k8s K8s) couchdbLaunchConfig(peerName string, nsConfig networkspec.Config) LaunchConfig {
+
+	launchConfig := LaunchConfig{
+		Name: fmt.Sprintf("couchdb-%s", peerName),
+		Type: "couchdb",
+	}
+	container := corev1.Container{
+		Name:            "couchdb",
+		Resources:       k8s.resources(nsConfig.K8s.Resources.Couchdb),
+		Image:           "couchdb:3.3.2",
+		ImagePullPolicy: corev1.PullPolicy("Always"),
+		Env: []corev1.EnvVar{
+			{
+				Name:  "COUCHDB_USER",
+				Value: "admin",
+			},
+			{
+				Name:  "COUCHDB_PASSWORD",
+				Value: "adminpw",
+			},
+		},
+	}
+	if nsConfig.K8s.DataPersistence == "true" || nsConfig.K8s.DataPersistence == "local" {
+		volumeMount := corev1.VolumeMount{MountPath: "/opt/couchdb/data", Name: "couchdb-data-storage"}
+		container.VolumeMounts = append(container.VolumeMounts, volumeMount)
+		volume := corev1.Volume{
+			Name: "couchdb-data-storage",
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: fmt.Sprintf("couchdb-%s-data", peerName),
+				},
+			},
+		}
+		launchConfig.Volumes = []corev1.Volume{volume}
+	}
+	launchConfig.Containers = []corev1.Container{container}
+	return launchConfig
+}
+
 func (k8s K8s) caLaunchConfig(id int, orgName, caImage string) LaunchConfig {
 
 	container := corev1.Container{
@@ -410,7 +456,7 @@ func (k8s K8s) volumesList(componentType, orgName, name, dataPersistence string,
 func (k8s K8s) GenerateConfigurationFiles(upgrade bool) error {
 
 	network := nl.Network{}
-	err := network.GenerateConfigurationFiles(upgrade)
+	err := network.GenerateConfigurationFiles(k8s.Config, "k8s", upgrade)
 	if err != nil {
 		return err
 	}
@@ -419,7 +465,7 @@ func (k8s K8s) GenerateConfigurationFiles(upgrade bool) error {
 
 func (k8s K8s) launchNetwork(config networkspec.Config, clientset *kubernetes.Clientset) error {
 
-	launchConfig, err := k8s.launchObject(config)
+	launchConfig, err := k8s.launchObject(config, writeFile)
 	if err != nil {
 		logger.ERROR("Failed to launch the fabric k8s components")
 		return err
@@ -449,6 +495,38 @@ func (k8s K8s) buildClientset(kubeconfig *string) (*kubernetes.Clientset, error)
 	return clientset, nil
 }
 
+//Inventory -- sets the pods of the components of the state of the network, the single pods of their statefulsets
+func (k8s K8s) Inventory(state *networkstate.State) error {
+	for i := range state.Components {
+		state.Components[i].PodName = fmt.Sprintf("%s-0", state.Components[i].Name)
+	}
+	return nil
+}
+
+//ComponentStates -- the phases of the pods of the components of the network
+func (k8s K8s) ComponentStates(state networkstate.State) (map[string]string, error) {
+
+	clientset, err := k8s.buildClientset(&k8s.KubeConfigPath)
+	if err != nil {
+		return nil, err
+	}
+	pods, err := clientset.CoreV1().Pods(k8s.Config.K8s.Namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list the pods of the network")
+	}
+	phases := make(map[string]string)
+	for _, pod := range pods.Items {
+		phases[pod.Name] = string(pod.Status.Phase)
+	}
+	states := make(map[string]string)
+	for _, component := range state.Components {
+		if phase, ok := phases[component.PodName]; ok {
+			states[component.Name] = phase
+		}
+	}
+	return states, nil
+}
+
 // Network --
 func (k8s K8s) Network(action string) error {
 
@@ -479,10 +557,12 @@ func (k8s K8s) Network(action string) error {
 			logger.ERROR("Failed to create namespace")
 			return err
 		}
-		err = k8s.CreateSecret("genesisblock", k8s.Config, clientset)
-		if err != nil {
-			logger.ERROR("Failed to create secret for genesis block")
-			return err
+		if k8s.Config.Orderer.BootstrapMethod != "none" {
+			err = k8s.CreateSecret("genesisblock", k8s.Config, clientset)
+			if err != nil {
+				logger.ERROR("Failed to create secret for genesis block")
+				return err
+			}
 		}
 		err = k8s.CreateMSPConfigMaps(k8s.Config, clientset)
 		if err != nil {
@@ -509,8 +589,29 @@ func (k8s K8s) Network(action string) error {
 			logger.ERROR("Failed to generate connection profile")
 			return err
 		}
+		if k8s.Config.Orderer.BootstrapMethod == "none" {
+			err = networkclient.JoinOrdererChannels(k8s.Config)
+			if err != nil {
+				logger.ERROR("Failed to join the orderers to the channels")
+				return err
+			}
+		}
+	case "upgradeNetwork":
+		if k8s.Config.Upgrade.Strategy != networkspec.Rolling {
+			return errors.Errorf("upgradeNetwork on k8s only supports upgrade strategy %s", networkspec.Rolling)
+		}
+		clientset, err := k8s.buildClientset(kubeconfig)
+		if err != nil {
+			logger.ERROR("Failed to generate clientset for kubernetes")
+			return err
+		}
+		err = k8s.RollingUpgrade(k8s.Config, clientset)
+		if err != nil {
+			logger.ERROR("Failed to upgrade k8s fabric network")
+			return err
+		}
 	case "addPeer":
-		err = network.ExtendConfigurationFiles("k8s")
+		err = network.ExtendConfigurationFiles(k8s.Config, "k8s")
 		if err != nil {
 			logger.ERROR("Failed to generate docker compose file")
 			return err
@@ -545,6 +646,86 @@ func (k8s K8s) Network(action string) error {
 			logger.ERROR("Failed to generate connection profile")
 			return err
 		}
+	case "addOrg":
+		err = network.AddOrgConfigurationFiles(k8s.Config, "k8s")
+		if err != nil {
+			logger.ERROR("Failed to generate configuration files")
+			return err
+		}
+		err = network.GenerateOrgCryptoCerts(k8s.Config)
+		if err != nil {
+			logger.ERROR("Failed to generate certificates")
+			return err
+		}
+		clientset, err := k8s.buildClientset(kubeconfig)
+		if err != nil {
+			logger.ERROR("Failed to generate clientset for kubernetes")
+			return err
+		}
+		err = k8s.AddOrganizations(k8s.Config, clientset)
+		if err != nil {
+			logger.ERROR("Failed to add organizations to k8s fabric network")
+			return err
+		}
+	case "removeOrg":
+		clientset, err := k8s.buildClientset(kubeconfig)
+		if err != nil {
+			logger.ERROR("Failed to generate clientset for kubernetes")
+			return err
+		}
+		err = k8s.RemoveOrganizations(k8s.Config, clientset)
+		if err != nil {
+			logger.ERROR("Failed to remove organizations from k8s fabric network")
+			return err
+		}
+	case "addOrderer":
+		err = network.AddOrdererConfigurationFiles(k8s.Config, "k8s")
+		if err != nil {
+			logger.ERROR("Failed to generate configuration files")
+			return err
+		}
+		err = network.GenerateOrdererCryptoCerts(k8s.Config)
+		if err != nil {
+			logger.ERROR("Failed to generate certificates")
+			return err
+		}
+		clientset, err := k8s.buildClientset(kubeconfig)
+		if err != nil {
+			logger.ERROR("Failed to generate clientset for kubernetes")
+			return err
+		}
+		err = k8s.AddOrderers(k8s.Config, clientset)
+		if err != nil {
+			logger.ERROR("Failed to add orderers to k8s fabric network")
+			return err
+		}
+	case "removeOrderer":
+		clientset, err := k8s.buildClientset(kubeconfig)
+		if err != nil {
+			logger.ERROR("Failed to generate clientset for kubernetes")
+			return err
+		}
+		err = k8s.RemoveOrderers(k8s.Config, clientset)
+		if err != nil {
+			logger.ERROR("Failed to remove orderers from k8s fabric network")
+			return err
+		}
+	case "rotateOrdererCert":
+		err = network.RenewOrdererCerts(k8s.Config)
+		if err != nil {
+			logger.ERROR("Failed to renew orderer certificates")
+			return err
+		}
+		clientset, err := k8s.buildClientset(kubeconfig)
+		if err != nil {
+			logger.ERROR("Failed to generate clientset for kubernetes")
+			return err
+		}
+		err = k8s.RotateOrdererCerts(k8s.Config, clientset)
+		if err != nil {
+			logger.ERROR("Failed to rotate orderer certificates of k8s fabric network")
+			return err
+		}
 	case "down":
 		clientset, err := k8s.buildClientset(kubeconfig)
 		if err != nil {
@@ -566,8 +747,17 @@ func (k8s K8s) Network(action string) error {
 			logger.ERROR("Failed to generate clientset for kubernetes")
 			return err
 		}
-		err = k8s.CheckComponentsHealth(k8s.Config, clientset)
+		state, err := networkstate.Load(k8s.Config, "k8s")
+		if err != nil {
+			return err
+		}
+		err = k8s.checkNodesHealth(state.Nodes(), k8s.Config, clientset)
+		if err != nil {
+			return err
+		}
+		err = networkclient.CheckConsensus(k8s.Config)
 		if err != nil {
+			logger.ERROR("Failed to check the consensus clusters of fabric network")
 			return err
 		}
 	default:
@@ -0,0 +1,132 @@
+package k8s
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/hyperledger/fabric-test/tools/operator/connectionprofile"
+	"github.com/hyperledger/fabric-test/tools/operator/fabricconfig"
+	"github.com/hyperledger/fabric-test/tools/operator/logger"
+	"github.com/hyperledger/fabric-test/tools/operator/networkclient"
+	"github.com/hyperledger/fabric-test/tools/operator/networkspec"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+//AddOrderers -- launches the orderers of addOrderer one at a time, each bootstrapped from the latest config block of
+//the system channel or, with bootstrapMethod none, without bootstrap block, and adds them to the consenters of all
+//channels
+func (k8s K8s) AddOrderers(config networkspec.Config, clientset *kubernetes.Clientset) error {
+
+	ordererConfig, err := fabricconfig.OrdererConfig(config)
+	if err != nil {
+		return errors.Wrap(err, "failed to read orderer config")
+	}
+	connProfile := connectionprofile.ConnProfile{Config: config}
+	return networkclient.AddOrderers(config, func(added networkclient.AddedOrderer) error {
+		ordererPort := int32(added.Port)
+		ordererMetricsPort := ordererPort + 2500
+		ordererAdminListenPort := ordererPort + 2700
+		err := fabricconfig.GenerateOrdererConfig(added.Name, added.Org, added.MSPID, config.ArtifactsLocation, ordererPort, ordererMetricsPort, ordererAdminListenPort, ordererConfig, config.OrdererOverrides(added.Org, added.Name))
+		if err != nil {
+			return errors.Wrap(err, "failed to generate orderer configuration file")
+		}
+		launchConfig := k8s.ordererLaunchConfig(added.Name, added.Org, []int32{ordererPort, ordererMetricsPort, ordererAdminListenPort}, config)
+		if config.Orderer.BootstrapMethod != "none" {
+			secretName := fmt.Sprintf("%s-bootstrap", added.Name)
+			err = k8s.createBootstrapSecret(secretName, networkclient.BootstrapBlockPath(config, added.Name), config, clientset)
+			if err != nil {
+				return err
+			}
+			for _, volume := range launchConfig.Volumes {
+				if volume.Name == "genesisblock" {
+					volume.Secret.SecretName = secretName
+				}
+			}
+		}
+		err = k8s.CreateStatefulset(launchConfig, config, clientset)
+		if err != nil {
+			return err
+		}
+		err = k8s.PodStatusCheck(config.K8s.Namespace, clientset)
+		if err != nil {
+			return err
+		}
+		err = k8s.checkHealth(added.Name, config, clientset)
+		if err != nil {
+			return err
+		}
+		orderer, err := k8s.orderer(added.Name, networkspec.OrdererOrganizations{Name: added.Org, MSPID: added.MSPID}, config, clientset)
+		if err != nil {
+			return err
+		}
+		return connProfile.UpdateOrderers(map[string]networkspec.Orderer{added.Name: orderer}, nil)
+	})
+}
+
+//RemoveOrderers -- removes the orderers of removeOrderer from the consenters of all channels and deletes their statefulsets and services
+func (k8s K8s) RemoveOrderers(config networkspec.Config, clientset *kubernetes.Clientset) error {
+
+	connProfile := connectionprofile.ConnProfile{Config: config}
+	return networkclient.RemoveOrderers(config, func(ordererName string) error {
+		err := k8s.deleteComponent(ordererName, config.K8s.Namespace, clientset)
+		if err != nil {
+			return err
+		}
+		return connProfile.UpdateOrderers(nil, []string{ordererName})
+	})
+}
+
+//RotateOrdererCerts -- replaces the msp and tls configmaps of the orderers of rotateOrdererCert with their renewed
+//certificates and restarts their pods one at a time
+func (k8s K8s) RotateOrdererCerts(config networkspec.Config, clientset *kubernetes.Clientset) error {
+
+	ns := config.K8s.Namespace
+	return networkclient.RotateOrdererCerts(config, func(ordererName string) error {
+		for _, certsType := range []string{"msp", "tls"} {
+			name := fmt.Sprintf("%s-%s", ordererName, certsType)
+			err := clientset.CoreV1().ConfigMaps(ns).Delete(name, &metav1.DeleteOptions{})
+			if err != nil {
+				return errors.Wrapf(err, "failed to delete configmap %s", name)
+			}
+			err = k8s.createConfigMap(ordererName, "orderer", certsType, ns, config, clientset)
+			if err != nil {
+				return err
+			}
+		}
+		podName := fmt.Sprintf("%s-0", ordererName)
+		err := clientset.CoreV1().Pods(ns).Delete(podName, &metav1.DeleteOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "failed to delete pod %s", podName)
+		}
+		logger.INFO("Deleted pod ", podName, " to restart it with the renewed certificates")
+		err = k8s.PodStatusCheck(ns, clientset)
+		if err != nil {
+			return err
+		}
+		return k8s.checkHealth(ordererName, config, clientset)
+	})
+}
+
+func (k8s K8s) createBootstrapSecret(name, blockPath string, nsConfig networkspec.Config, clientset *kubernetes.Clientset) error {
+
+	data, err := ioutil.ReadFile(blockPath)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read %s", blockPath)
+	}
+	secretRes := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Data: map[string][]byte{
+			"genesis.block": data,
+		},
+	}
+	_, err = clientset.CoreV1().Secrets(nsConfig.K8s.Namespace).Create(secretRes)
+	if err != nil {
+		return errors.Wrap(err, "failed to create secret")
+	}
+	return nil
+}
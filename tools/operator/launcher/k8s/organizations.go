@@ -0,0 +1,158 @@
+package k8s
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-test/tools/operator/connectionprofile"
+	"github.com/hyperledger/fabric-test/tools/operator/fabricconfig"
+	"github.com/hyperledger/fabric-test/tools/operator/logger"
+	"github.com/hyperledger/fabric-test/tools/operator/networkclient"
+	"github.com/hyperledger/fabric-test/tools/operator/networkspec"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+func (k8s K8s) addOrgLaunchObject(nsConfig networkspec.Config) ([]LaunchConfig, error) {
+
+	var launchConfig []LaunchConfig
+	coreConfig, err := fabricconfig.CoreConfig(nsConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read core config")
+	}
+
+	caPort := int32(nsConfig.Port(30500))
+	for _, org := range nsConfig.PeerOrganizations {
+		caPort = caPort + int32(org.NumCA)
+	}
+	for _, org := range nsConfig.OrdererOrganizations {
+		caPort = caPort + int32(org.NumCA)
+	}
+	peerPorts := networkclient.AddedOrganizationPeerPorts(nsConfig)
+	for i, org := range nsConfig.AddOrganizations {
+		peerPort := int32(peerPorts[i])
+		peerMetricsPort := peerPort + 1000
+		for j := 0; j < org.NumPeers; j++ {
+			peerName := fmt.Sprintf("peer%d-%s", j, org.Name)
+			err := fabricconfig.GenerateCorePeerConfig(peerName, org.Name, org.MSPID, peerPort, peerMetricsPort, coreConfig, nsConfig)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to generate core configuration file")
+			}
+			launchConfig = append(launchConfig, k8s.peerLaunchConfig(peerName, org.Name, []int32{peerPort, peerMetricsPort}, nsConfig))
+			if nsConfig.DBType == "couchdb" {
+				launchConfig = append(launchConfig, k8s.couchdbLaunchConfig(peerName, nsConfig))
+			}
+			peerPort++
+			peerMetricsPort++
+		}
+		for m := 0; m < org.NumCA; m++ {
+			l := k8s.caLaunchConfig(m, org.Name, nsConfig.Image("ca", org.Name, fmt.Sprintf("ca%d-%s", m, org.Name)))
+			l.Ports = []int32{caPort}
+			launchConfig = append(launchConfig, l)
+			caPort++
+		}
+	}
+	return launchConfig, nil
+}
+
+//AddOrganizations -- launches the organizations of addOrg, generates their connection profiles and adds them to the network
+func (k8s K8s) AddOrganizations(config networkspec.Config, clientset *kubernetes.Clientset) error {
+
+	for _, org := range config.AddOrganizations {
+		err := k8s.createCertsConfigmap(org.NumCA, "peer", org.Name, config, clientset)
+		if err != nil {
+			return err
+		}
+	}
+	launchConfig, err := k8s.addOrgLaunchObject(config)
+	if err != nil {
+		logger.ERROR("Failed to launch the fabric k8s components")
+		return err
+	}
+	for i := 0; i < len(launchConfig); i++ {
+		err = k8s.CreateStatefulset(launchConfig[i], config, clientset)
+		if err != nil {
+			logger.ERROR("Failed to launch the fabric k8s network")
+			return err
+		}
+	}
+	err = k8s.PodStatusCheck(config.K8s.Namespace, clientset)
+	if err != nil {
+		logger.ERROR("Failed to verify fabric K8s pods state")
+		return err
+	}
+	newOrgs := config
+	newOrgs.PeerOrganizations = config.AddOrganizations
+	newOrgs.OrdererOrganizations = nil
+	err = k8s.CheckComponentsHealth(newOrgs, clientset)
+	if err != nil {
+		logger.ERROR("Failed to check fabric K8s pods health")
+		return err
+	}
+	newOrgs.OrdererOrganizations = config.OrdererOrganizations
+	err = k8s.GenerateConnectionProfiles(newOrgs, clientset)
+	if err != nil {
+		logger.ERROR("Failed to generate connection profile")
+		return err
+	}
+	return networkclient.AddOrganizations(config)
+}
+
+//RemoveOrganizations -- removes the organizations of removeOrg from the network and deletes their statefulsets and services
+func (k8s K8s) RemoveOrganizations(config networkspec.Config, clientset *kubernetes.Clientset) error {
+
+	err := networkclient.RemoveOrganizations(config)
+	if err != nil {
+		return err
+	}
+	connProfile := connectionprofile.ConnProfile{Config: config}
+	for _, org := range config.PeerOrganizations {
+		if !contains(config.RemoveOrganizations, org.Name) {
+			continue
+		}
+		var names []string
+		for i := 0; i < org.NumPeers; i++ {
+			names = append(names, fmt.Sprintf("peer%d-%s", i, org.Name))
+			if config.DBType == "couchdb" {
+				names = append(names, fmt.Sprintf("couchdb-peer%d-%s", i, org.Name))
+			}
+		}
+		for i := 0; i < org.NumCA; i++ {
+			names = append(names, fmt.Sprintf("ca%d-%s", i, org.Name))
+		}
+		for _, name := range names {
+			err = k8s.deleteComponent(name, config.K8s.Namespace, clientset)
+			if err != nil {
+				return err
+			}
+		}
+		err = connProfile.RemoveConnProfilePerOrg(org.Name)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (k8s K8s) deleteComponent(name, ns string, clientset *kubernetes.Clientset) error {
+
+	err := clientset.AppsV1().StatefulSets(ns).Delete(name, &metav1.DeleteOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete statefulset %s", name)
+	}
+	err = clientset.CoreV1().Services(ns).Delete(name, &metav1.DeleteOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete service %s", name)
+	}
+	logger.INFO("Deleted statefulset and service of ", name)
+	return nil
+}
+
+func contains(list []string, item string) bool {
+	for _, element := range list {
+		if element == item {
+			return true
+		}
+	}
+	return false
+}
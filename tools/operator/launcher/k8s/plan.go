@@ -0,0 +1,69 @@
+package k8s
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/hyperledger/fabric-test/tools/operator/launcher/nl"
+	"github.com/hyperledger/fabric-test/tools/operator/plan"
+)
+
+//Plan -- records in p the configuration files, artifacts and k8s objects of the up action, in the order the up action
+//creates them, without a cluster
+func (k8s K8s) Plan(action string, p *plan.Plan) error {
+
+	if action != "up" {
+		return errors.Errorf("dry run of action %s is not supported on k8s, only of up", action)
+	}
+	var network nl.Network
+	err := network.PlanConfigurationFiles(k8s.Config, "k8s", false, p)
+	if err != nil {
+		return err
+	}
+	network.PlanNetworkArtifacts(k8s.Config, p)
+	ns := k8s.Config.K8s.Namespace
+	object := func(kind, name, image string) {
+		p.K8sObjects = append(p.K8sObjects, plan.K8sObject{Kind: kind, Namespace: ns, Name: name, Image: image})
+	}
+	p.K8sObjects = append(p.K8sObjects, plan.K8sObject{Kind: "Namespace", Name: ns})
+	if k8s.Config.Orderer.BootstrapMethod != "none" {
+		object("Secret", "genesisblock", "")
+	}
+	for _, org := range k8s.Config.OrdererOrganizations {
+		k8s.planCertsConfigmap(org.NumCA, org.Name, object)
+	}
+	for _, org := range k8s.Config.PeerOrganizations {
+		k8s.planCertsConfigmap(org.NumCA, org.Name, object)
+	}
+	launchConfig, err := k8s.launchObject(k8s.Config, p.AddFile)
+	if err != nil {
+		return err
+	}
+	for _, launch := range launchConfig {
+		if launch.Type != "ca" {
+			for _, certsType := range []string{"msp", "tls", "config"} {
+				object("ConfigMap", fmt.Sprintf("%s-%s", launch.Name, certsType), "")
+			}
+			if k8s.Config.K8s.DataPersistence == "true" {
+				object("PersistentVolumeClaim", fmt.Sprintf("%s-data", launch.Name), "")
+			}
+		}
+		object("Service", launch.Name, "")
+		var image string
+		if len(launch.Containers) > 0 {
+			image = launch.Containers[0].Image
+		}
+		object("StatefulSet", launch.Name, image)
+	}
+	return nil
+}
+
+//planCertsConfigmap -- the configmaps createCertsConfigmap creates for an organization
+func (k8s K8s) planCertsConfigmap(numCA int, orgName string, object func(kind, name, image string)) {
+
+	object("ConfigMap", fmt.Sprintf("%s-admincerts", orgName), "")
+	if numCA > 0 || k8s.Config.TLS == "mutual" {
+		object("ConfigMap", fmt.Sprintf("%s-ca", orgName), "")
+	}
+}
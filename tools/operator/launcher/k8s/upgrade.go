@@ -0,0 +1,95 @@
+package k8s
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-test/tools/operator/logger"
+	"github.com/hyperledger/fabric-test/tools/operator/networkclient"
+	"github.com/hyperledger/fabric-test/tools/operator/networkspec"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	rolloutTimeout      = 5 * time.Minute
+	rolloutPollInterval = 5 * time.Second
+)
+
+//RollingUpgrade -- writes the core.yaml and orderer.yaml files of the network with the images of the spec, then
+//replaces the orderers and peers one at a time: the config configmap of each node is recreated, the image of its
+//fabric container is set to the image of the spec, and its statefulset has to roll out the new pod and pass /healthz
+func (k8s K8s) RollingUpgrade(config networkspec.Config, clientset *kubernetes.Clientset) error {
+
+	// the launch objects are only built for the configuration files they write
+	_, err := k8s.launchObject(config, writeFile)
+	if err != nil {
+		return err
+	}
+	_, err = k8s.extendLaunchObject(config)
+	if err != nil {
+		return err
+	}
+	_, err = k8s.addOrgLaunchObject(config)
+	if err != nil {
+		return err
+	}
+	ns := config.K8s.Namespace
+	return networkclient.RollingUpgrade(config, func(node networkclient.UpgradeNode) error {
+		configMap := fmt.Sprintf("%s-config", node.Name)
+		err := clientset.CoreV1().ConfigMaps(ns).Delete(configMap, &metav1.DeleteOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "failed to delete configmap %s", configMap)
+		}
+		err = k8s.createConfigMap(node.Name, node.Component, "config", ns, config, clientset)
+		if err != nil {
+			return err
+		}
+		statefulset, err := clientset.AppsV1().StatefulSets(ns).Get(node.Name, metav1.GetOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "failed to get statefulset %s", node.Name)
+		}
+		template := &statefulset.Spec.Template
+		for i := range template.Spec.Containers {
+			if template.Spec.Containers[i].Name == node.Component {
+				template.Spec.Containers[i].Image = config.Image(node.Component, node.Org, node.Name)
+			}
+		}
+		if template.ObjectMeta.Annotations == nil {
+			template.ObjectMeta.Annotations = make(map[string]string)
+		}
+		// restarts the pod even if its image did not change, so that it reads its new configmap
+		template.ObjectMeta.Annotations["fabric-test/upgradedAt"] = time.Now().UTC().Format(time.RFC3339)
+		_, err = clientset.AppsV1().StatefulSets(ns).Update(statefulset)
+		if err != nil {
+			return errors.Wrapf(err, "failed to update statefulset %s", node.Name)
+		}
+		err = k8s.waitForRollout(node.Name, ns, clientset)
+		if err != nil {
+			return err
+		}
+		return k8s.checkHealth(node.Name, config, clientset)
+	})
+}
+
+//waitForRollout -- waits until the statefulset runs the pods of its latest revision and all of them are ready
+func (k8s K8s) waitForRollout(name, ns string, clientset *kubernetes.Clientset) error {
+
+	deadline := time.Now().Add(rolloutTimeout)
+	for {
+		statefulset, err := clientset.AppsV1().StatefulSets(ns).Get(name, metav1.GetOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "failed to get statefulset %s", name)
+		}
+		status := statefulset.Status
+		if status.ObservedGeneration >= statefulset.Generation && status.CurrentRevision == status.UpdateRevision && status.ReadyReplicas == *statefulset.Spec.Replicas {
+			logger.INFO("Rolled out statefulset ", name, " with revision ", status.UpdateRevision)
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return errors.Errorf("statefulset %s did not roll out within %s", name, rolloutTimeout)
+		}
+		time.Sleep(rolloutPollInterval)
+	}
+}
@@ -5,18 +5,25 @@
 package launcher
 
 import (
+	"encoding/json"
 	"fmt"
-	"io/ioutil"
+	"os"
 	"strings"
 
 	"github.com/davecgh/go-spew/spew"
+	"github.com/hyperledger/fabric-test/tools/operator/fabricconfig"
 	"github.com/hyperledger/fabric-test/tools/operator/launcher/dockercompose"
 	"github.com/hyperledger/fabric-test/tools/operator/launcher/k8s"
+	"github.com/hyperledger/fabric-test/tools/operator/launcher/local"
 	"github.com/hyperledger/fabric-test/tools/operator/launcher/nl"
 	"github.com/hyperledger/fabric-test/tools/operator/logger"
+	"github.com/hyperledger/fabric-test/tools/operator/networkclient"
 	"github.com/hyperledger/fabric-test/tools/operator/networkspec"
+	"github.com/hyperledger/fabric-test/tools/operator/networkstate"
 	"github.com/hyperledger/fabric-test/tools/operator/paths"
-	ytt "github.com/hyperledger/fabric-test/tools/operator/ytt-helper"
+	"github.com/hyperledger/fabric-test/tools/operator/plan"
+	"github.com/hyperledger/fabric-test/tools/operator/smartbft"
+	"github.com/hyperledger/fabric-test/tools/operator/testclient"
 	"github.com/pkg/errors"
 
 	"k8s.io/client-go/kubernetes"
@@ -38,21 +45,111 @@ func doAction(action, env, kubeConfigPath string, config networkspec.Config) err
 	// print action (in bold) and input
 	fmt.Printf("\033[1m\nAction:%s\nInput:\033[0m\n%s\n", action, spew.Sdump(config))
 
+	run := func() error {
+		switch env {
+		case "k8s":
+			k8s := k8s.K8s{KubeConfigPath: kubeConfigPath, Config: config}
+			return k8s.Network(action)
+		case "docker":
+			dc := dockercompose.DockerCompose{Config: config}
+			return dc.DockerNetwork(action)
+		case "local":
+			local := local.Local{Config: config}
+			return local.Network(action)
+		}
+		return nil
+	}
 	var err error
+	if action == "upgradeNetwork" && config.Upgrade.Strategy == networkspec.Rolling && config.Upgrade.LoadInput != "" {
+		err = runWithLoad(config.Upgrade.LoadInput, run)
+	} else {
+		err = run()
+	}
+	if err != nil {
+		return err
+	}
+	return recordState(action, env, kubeConfigPath, config)
+}
+
+//runtime -- the containers, pods or processes running the components of a network: Inventory sets their container
+//IDs, pod names or pids in a state, ComponentStates reports whether they run and Plan records what an action would
+//create without creating it
+type runtime interface {
+	Inventory(state *networkstate.State) error
+	ComponentStates(state networkstate.State) (map[string]string, error)
+	Plan(action string, p *plan.Plan) error
+}
+
+//newRuntime -- the runtime of env, or nil for an unknown env
+func newRuntime(env, kubeConfigPath string, config networkspec.Config) runtime {
+
 	switch env {
 	case "k8s":
-		k8s := k8s.K8s{KubeConfigPath: kubeConfigPath, Config: config}
-		err = k8s.Network(action)
+		return k8s.K8s{KubeConfigPath: kubeConfigPath, Config: config}
 	case "docker":
-		dc := dockercompose.DockerCompose{Config: config}
-		err = dc.DockerNetwork(action)
+		return dockercompose.DockerCompose{Config: config}
+	case "local":
+		return local.Local{Config: config}
+	}
+	return nil
+}
+
+//recordState -- writes the network-state.json of the network after an action changed its components; down removes it
+//with the other artifacts of the network
+func recordState(action, env, kubeConfigPath string, config networkspec.Config) error {
+
+	var state networkstate.State
+	var err error
+	switch action {
+	case "up":
+		state = networkstate.New(config, env)
+	case "upgradeNetwork", "addPeer", "addOrg", "removeOrg", "addOrderer", "removeOrderer", "rotateOrdererCert":
+		state, err = networkstate.Load(config, env)
+		if err != nil {
+			return err
+		}
+		state.Apply(action, config)
+	default:
+		return nil
+	}
+	err = state.Discover()
+	if err != nil {
+		return err
+	}
+	runtime := newRuntime(env, kubeConfigPath, config)
+	if runtime != nil {
+		err = runtime.Inventory(&state)
+		if err != nil {
+			return err
+		}
 	}
+	err = state.Write()
 	if err != nil {
 		return err
 	}
+	logger.INFO("Launcher: Recorded the state of the network in ", networkstate.Path(config.ArtifactsLocation))
 	return nil
 }
 
+//runWithLoad -- runs an action while invoking the transactions of the test input file loadInput, and waits for both
+func runWithLoad(loadInput string, run func() error) error {
+
+	loadErr := make(chan error, 1)
+	go func() {
+		logger.INFO("Launcher: Starting load from ", loadInput)
+		loadErr <- testclient.Testclient("invoke", loadInput)
+	}()
+	err := run()
+	logger.INFO("Launcher: Waiting for the load from ", loadInput, " to finish")
+	if loadErr := <-loadErr; loadErr != nil {
+		logger.ERROR("Launcher: Load from ", loadInput, " failed")
+		if err == nil {
+			return errors.Wrap(loadErr, "load failed during the rolling upgrade")
+		}
+	}
+	return err
+}
+
 func validateBasicConsensusConfig(config networkspec.Config) error {
 
 	ordererType := config.Orderer.OrdererType
@@ -64,73 +161,150 @@ func validateBasicConsensusConfig(config networkspec.Config) error {
 		if len(config.OrdererOrganizations) != 1 {
 			return errors.New("Launcher: Consensus type kafka should have only one orderer organization")
 		}
+	} else if ordererType == networkspec.BFT {
+		numOrderers := 0
+		for _, org := range config.OrdererOrganizations {
+			numOrderers += org.NumOrderers
+		}
+		if !smartbft.ValidClusterSize(numOrderers) {
+			return errors.Errorf("Launcher: Consensus type BFT should have at least 4 orderers to tolerate a faulty orderer, found %d orderers", numOrderers)
+		}
+		if config.Orderer.BootstrapMethod != "none" {
+			return errors.New("Launcher: Consensus type BFT has no system channel, bootstrapMethod of orderer should be none")
+		}
+		if config.ChannelCapabilities != "V3_0" {
+			return errors.Errorf("Launcher: Consensus type BFT should have channel capability V3_0, found %s", config.ChannelCapabilities)
+		}
 	}
 	return nil
 }
 
-func Launcher(action, env, kubeConfigPath, networkSpecPath string) error {
+//readConfig -- reads the network spec or state file, with the artifactsLocation made absolute
+func readConfig(networkSpecPath string) (networkspec.Config, error) {
 
 	var network nl.Network
-	var yttObject ytt.YTT
-	err := yttObject.DownloadYtt()
-	if err != nil {
-		return errors.Errorf("Launcher: Failed to download ytt with error: %s", err)
-	}
-
-	err = validateArguments(networkSpecPath, kubeConfigPath)
-	if err != nil {
-		return errors.Errorf("Launcher: Failed to validate arguments with error: %s", err)
-	}
-
-	contents, _ := ioutil.ReadFile(networkSpecPath)
-	stringContents := strings.Split(string(contents), "artifactsLocation")
-	finalContents := stringContents[0] + "orderer: \n" + strings.Split(stringContents[1], "orderer:")[1]
 	config, err := network.GetConfigData(networkSpecPath)
 	if err != nil {
 		logger.ERROR("Launcher: Failed to read the input file", networkSpecPath)
-		return err
+		return config, err
 	}
 
 	if !(strings.HasPrefix(config.ArtifactsLocation, "/")) {
 		currentDir, err := paths.GetCurrentDir()
 		if err != nil {
 			logger.ERROR("Launcher: GetCurrentDir failed; unable to join with ArtifactsLocation", config.ArtifactsLocation)
-			return err
+			return config, err
 		}
 		config.ArtifactsLocation = paths.JoinPath(currentDir, config.ArtifactsLocation)
 	}
+	return config, nil
+}
+
+//Status -- prints the live status of every component of the network, as a table or as JSON when output is json
+func Status(env, kubeConfigPath, networkSpecPath, output string) error {
+
+	err := validateArguments(networkSpecPath, kubeConfigPath)
+	if err != nil {
+		return errors.Errorf("Launcher: Failed to validate arguments with error: %s", err)
+	}
+	if output != "table" && output != "json" {
+		return errors.Errorf("Launcher: unknown status output %s, use table or json", output)
+	}
+	config, err := readConfig(networkSpecPath)
+	if err != nil {
+		return err
+	}
+	state, err := networkstate.Load(config, env)
+	if err != nil {
+		logger.ERROR("Launcher: Failed to load the state of network ", config.NetworkName)
+		return err
+	}
+	var states map[string]string
+	if runtime := newRuntime(state.Runtime, kubeConfigPath, config); runtime != nil {
+		states, err = runtime.ComponentStates(state)
+		if err != nil {
+			logger.WARNING("Launcher: Failed to get the states of the components of the network: ", err.Error())
+		}
+	}
+	status := networkclient.Status(config, state, states)
+	if output == "json" {
+		contents, err := json.MarshalIndent(status, "", "  ")
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal the status of the network")
+		}
+		fmt.Println(string(contents))
+		return nil
+	}
+	return status.WriteTable(os.Stdout)
+}
+
+//prepareConfig -- reads and validates the network spec of an action. A dry run neither queries the cluster for the
+//external IP of NodePort services nor keeps the ports it allocates
+func prepareConfig(action, env, kubeConfigPath, networkSpecPath string, dryRun bool) (networkspec.Config, error) {
+
+	var network nl.Network
+	err := validateArguments(networkSpecPath, kubeConfigPath)
+	if err != nil {
+		return networkspec.Config{}, errors.Errorf("Launcher: Failed to validate arguments with error: %s", err)
+	}
+
+	config, err := readConfig(networkSpecPath)
+	if err != nil {
+		return config, err
+	}
 
-	finalContents = finalContents + fmt.Sprintf("artifactsLocation: %s\n", config.ArtifactsLocation)
-	contents = []byte(finalContents)
-	contents = append([]byte("#@data/values \n"), contents...)
-	nodeportIP := ""
-	if kubeConfigPath != "" && config.K8s.ServiceType == "NodePort" {
+	if kubeConfigPath != "" && config.K8s.ServiceType == "NodePort" && !dryRun {
 		K8s := k8s.K8s{KubeConfigPath: kubeConfigPath, Config: config}
 		kubeConfig, err := clientcmd.BuildConfigFromFlags("", kubeConfigPath)
 		if err != nil {
 			logger.ERROR("Failed to create config for kubernetes")
-			return err
+			return config, err
 		}
 		clientset, err := kubernetes.NewForConfig(kubeConfig)
 		if err != nil {
 			logger.ERROR("Failed to create clientset for kubernetes")
-			return err
+			return config, err
 		}
-		nodeportIP, _ = K8s.ExternalIP(config, "", clientset)
+		config.NodeportIP, _ = K8s.ExternalIP(config, "", clientset)
 	}
-	contents = append(contents, []byte(fmt.Sprintf("nodeportIP: %s\n", nodeportIP))...)
-	inputPath := paths.JoinPath(paths.TemplatesDir(), "input.yaml")
-	ioutil.WriteFile(inputPath, contents, 0644)
 
-	config, err = network.GetConfigData(inputPath)
-	if err != nil {
-		logger.ERROR("Launcher: Failed to get configuration data from network input file ", networkSpecPath)
-		return err
+	if action == "up" && env == "docker" {
+		allocate := network.AllocatePorts
+		if dryRun {
+			allocate = network.PlanPorts
+		}
+		config, err = allocate(config)
+		if err != nil {
+			logger.ERROR("Launcher: Failed to allocate the ports of network ", config.NetworkName)
+			return config, err
+		}
 	}
 
 	err = validateBasicConsensusConfig(config)
 	if err != nil {
 		logger.ERROR("Launcher: Failed to validate consensus configuration in network input file ", networkSpecPath)
+		return config, err
+	}
+
+	err = fabricconfig.ValidateOverrides(config)
+	if err != nil {
+		logger.ERROR("Launcher: Failed to validate overrides in network input file ", networkSpecPath)
+		return config, err
+	}
+	if config.Upgrade.Strategy != "" && config.Upgrade.Strategy != networkspec.Rolling {
+		logger.ERROR("Launcher: Invalid upgrade strategy in network input file ", networkSpecPath)
+		return config, errors.Errorf("unknown upgrade strategy %s, use %s or leave it empty to recreate the network", config.Upgrade.Strategy, networkspec.Rolling)
+	}
+	for _, warning := range config.CapabilityWarnings() {
+		logger.WARNING("Launcher: ", warning)
+	}
+	return config, nil
+}
+
+func Launcher(action, env, kubeConfigPath, networkSpecPath string) error {
+
+	config, err := prepareConfig(action, env, kubeConfigPath, networkSpecPath, false)
+	if err != nil {
 		return err
 	}
 
@@ -141,3 +315,24 @@ func Launcher(action, env, kubeConfigPath, networkSpecPath string) error {
 	}
 	return nil
 }
+
+//Plan -- runs an action up to the point where it would change the network, and returns what it would do instead
+func Plan(action, env, kubeConfigPath, networkSpecPath string) (plan.Plan, error) {
+
+	p := plan.Plan{Action: action, Runtime: env}
+	config, err := prepareConfig(action, env, kubeConfigPath, networkSpecPath, true)
+	if err != nil {
+		return p, err
+	}
+	p.NetworkName = config.NetworkName
+	runtime := newRuntime(env, kubeConfigPath, config)
+	if runtime == nil {
+		return p, errors.Errorf("Launcher: unknown runtime %s", env)
+	}
+	err = runtime.Plan(action, &p)
+	if err != nil {
+		logger.ERROR("Launcher: Failed to plan ", action, " action using network input file ", networkSpecPath)
+		return p, err
+	}
+	return p, nil
+}
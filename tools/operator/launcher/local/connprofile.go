@@ -0,0 +1,204 @@
+package local
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/hyperledger/fabric-test/tools/operator/connectionprofile"
+	"github.com/hyperledger/fabric-test/tools/operator/logger"
+	"github.com/hyperledger/fabric-test/tools/operator/networkspec"
+	"github.com/hyperledger/fabric-test/tools/operator/paths"
+)
+
+func protocol(config networkspec.Config, secure, insecure string) string {
+	if config.TLS == "true" || config.TLS == "mutual" {
+		return secure
+	}
+	return insecure
+}
+
+//orderer -- the connection profile entry of an orderer process
+func orderer(proc process, config networkspec.Config) (networkspec.Orderer, error) {
+
+	ordererOrgsPath := paths.OrdererOrgsDir(config.ArtifactsLocation)
+	connProfile := connectionprofile.ConnProfile{}
+	orgName := proc.Org
+	orderer := networkspec.Orderer{
+		MSPID:      proc.MSPID,
+		URL:        fmt.Sprintf("%s://%s:%d", protocol(config, "grpcs", "grpc"), localIP, proc.Port),
+		MetricsURL: fmt.Sprintf("http://%s:%d", localIP, proc.OperationsPort),
+		AdminURL:   fmt.Sprintf("%s://%s:%d", protocol(config, "https", "http"), localIP, proc.AdminPort),
+	}
+	orderer.GrpcOptions.SslTarget = proc.Name
+	tlscaCertPath := paths.JoinPath(ordererOrgsPath, fmt.Sprintf("%s/orderers/%s.%s/msp/tlscacerts/tlsca.%s-cert.pem", orgName, proc.Name, orgName, orgName))
+	cert, err := connProfile.GetCertificateFromFile(tlscaCertPath)
+	if err != nil {
+		return orderer, err
+	}
+	orderer.TLSCACerts.Pem = cert
+	adminCertPath := paths.JoinPath(ordererOrgsPath, fmt.Sprintf("%s/users/Admin@%s/msp/signcerts/Admin@%s-cert.pem", orgName, orgName, orgName))
+	cert, err = connProfile.GetCertificateFromFile(adminCertPath)
+	if err != nil {
+		return orderer, err
+	}
+	orderer.AdminCert = cert
+	keystorePath := paths.JoinPath(ordererOrgsPath, fmt.Sprintf("%s/users/Admin@%s/msp/keystore", orgName, orgName))
+	privKeyFile, err := ioutil.ReadDir(keystorePath)
+	if err != nil {
+		return orderer, err
+	}
+	cert, err = connProfile.GetCertificateFromFile(paths.JoinPath(keystorePath, privKeyFile[0].Name()))
+	if err != nil {
+		return orderer, err
+	}
+	orderer.PrivateKey = cert
+	return orderer, nil
+}
+
+//certificateAuthorities -- the connection profile entries of the CA processes of a peer organization
+func certificateAuthorities(peerOrg networkspec.PeerOrganizations, config networkspec.Config, procs processes) (map[string]networkspec.CertificateAuthority, error) {
+
+	CAs := make(map[string]networkspec.CertificateAuthority)
+	for i := 0; i < peerOrg.NumCA; i++ {
+		caName := fmt.Sprintf("ca%d-%s", i, peerOrg.Name)
+		proc := procs.find(caName)
+		if proc == nil {
+			return CAs, fmt.Errorf("no process found for %s", caName)
+		}
+		connProfile := connectionprofile.ConnProfile{}
+		tlscaCertPath := paths.JoinPath(paths.PeerOrgsDir(config.ArtifactsLocation), fmt.Sprintf("%s/ca/ca.%s-cert.pem", peerOrg.Name, peerOrg.Name))
+		cert, err := connProfile.GetCertificateFromFile(tlscaCertPath)
+		if err != nil {
+			return CAs, err
+		}
+		CA := networkspec.CertificateAuthority{
+			URL:    fmt.Sprintf("%s://%s:%d", protocol(config, "https", "http"), localIP, proc.Port),
+			CAName: caName,
+		}
+		CA.TLSCACerts.Pem = cert
+		CA.HTTPOptions.Verify = false
+		CA.Registrar.EnrollID, CA.Registrar.EnrollSecret = "admin", "adminpw"
+		CAs[fmt.Sprintf("ca%d", i)] = CA
+	}
+	return CAs, nil
+}
+
+//peers -- the connection profile entries of the peer processes of an organization
+func peers(orgName string, config networkspec.Config, procs []process) (map[string]networkspec.Peer, error) {
+
+	output := make(map[string]networkspec.Peer)
+	connProfile := connectionprofile.ConnProfile{}
+	tlscaCertPath := paths.JoinPath(paths.PeerOrgsDir(config.ArtifactsLocation), fmt.Sprintf("%s/tlsca/tlsca.%s-cert.pem", orgName, orgName))
+	for _, proc := range procs {
+		if proc.Component != "peer" || proc.Org != orgName {
+			continue
+		}
+		peer := networkspec.Peer{
+			URL:        fmt.Sprintf("%s://%s:%d", protocol(config, "grpcs", "grpc"), localIP, proc.Port),
+			MetricsURL: fmt.Sprintf("http://%s:%d", localIP, proc.OperationsPort),
+		}
+		peer.GrpcOptions.SslTarget = proc.Name
+		cert, err := connProfile.GetCertificateFromFile(tlscaCertPath)
+		if err != nil {
+			return output, err
+		}
+		peer.TLSCACerts.Pem = cert
+		output[proc.Name] = peer
+	}
+	return output, nil
+}
+
+//generateConnectionProfiles -- generates the connection profiles of the peer organizations, pointing to the ports of
+//the local processes
+func generateConnectionProfiles(config networkspec.Config, procs processes) error {
+
+	orderers := make(map[string]networkspec.Orderer)
+	for _, proc := range procs.Processes {
+		if proc.Component != "orderer" {
+			continue
+		}
+		orderer, err := orderer(proc, config)
+		if err != nil {
+			return err
+		}
+		orderers[proc.Name] = orderer
+	}
+	connProfile := connectionprofile.ConnProfile{Orderers: orderers, Config: config}
+	for _, peerOrg := range config.PeerOrganizations {
+		peersMap, err := peers(peerOrg.Name, config, procs.Processes)
+		if err != nil {
+			return err
+		}
+		connProfile.Peers = peersMap
+		ca, err := certificateAuthorities(peerOrg, config, procs)
+		if err != nil {
+			return err
+		}
+		connProfile.CA = ca
+		caList := make([]string, 0, len(ca))
+		for k := range ca {
+			caList = append(caList, k)
+		}
+		org, err := connProfile.Organization(peerOrg, caList)
+		if err != nil {
+			logger.ERROR("Failed to get the organization details")
+			return err
+		}
+		connProfile.Organizations = map[string]networkspec.Organization{peerOrg.Name: org}
+		err = connProfile.GenerateConnProfilePerOrg(peerOrg.Name)
+		if err != nil {
+			logger.ERROR("Failed to generate connection profile")
+			return err
+		}
+		err = connProfile.GenerateCaliperConnProfilePerOrg(peerOrg.Name)
+		if err != nil {
+			logger.ERROR("Failed to generate caliper connection profile")
+			return err
+		}
+	}
+	return nil
+}
+
+//addPeersToConnectionProfiles -- adds the new peer processes to the connection profiles of their organizations
+func addPeersToConnectionProfiles(config networkspec.Config, newPeers []process) error {
+
+	for _, org := range config.AddPeersToOrganization {
+		peersMap, err := peers(org.Name, config, newPeers)
+		if err != nil {
+			return err
+		}
+		var connectionProfileObject networkspec.ConnectionProfile
+		fileName := paths.JoinPath(paths.ConnectionProfilesDir(config.ArtifactsLocation), fmt.Sprintf("connection_profile_%s.yaml", org.Name))
+		yamlFile, err := ioutil.ReadFile(fileName)
+		if err != nil {
+			logger.ERROR("Failed to read connection profile")
+			return err
+		}
+		err = yaml.Unmarshal(yamlFile, &connectionProfileObject)
+		if err != nil {
+			logger.ERROR("Failed to unmarshall yaml file")
+			return err
+		}
+		orgObject := connectionProfileObject.Organizations[org.Name]
+		for peerName, peerConfig := range peersMap {
+			orgObject.Peers = append(orgObject.Peers, peerName)
+			connectionProfileObject.Peers[peerName] = peerConfig
+		}
+		connectionProfileObject.Organizations[org.Name] = orgObject
+		yamlBytes, err := yaml.Marshal(connectionProfileObject)
+		if err != nil {
+			logger.ERROR("Failed to convert the connection profile struct to bytes")
+			return err
+		}
+		yamlBytes = append([]byte("version: 1.0 \nname: My network \ndescription: Connection Profile for Blockchain Network \n"), yamlBytes...)
+		err = ioutil.WriteFile(fileName, yamlBytes, 0644)
+		if err != nil {
+			logger.ERROR("Failed to write content to ", fileName)
+			return err
+		}
+		logger.INFO("Successfully updated ", fileName)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package local
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/hyperledger/fabric-test/tools/operator/logger"
+	"github.com/hyperledger/fabric-test/tools/operator/networkspec"
+)
+
+//checkHealth -- waits for the /healthz of the operations service of the process
+func (p process) checkHealth(config networkspec.Config) error {
+
+	logger.INFO("Checking health for ", p.Name)
+	url := fmt.Sprintf("http://%s:%d/healthz", localIP, p.OperationsPort)
+	for i := 0; i < 6; i++ {
+		logger.INFO("Querying /healthz URL: " + url)
+		resp, err := http.Get(url)
+		if err != nil {
+			logger.INFO("Error while hitting the endpoint ", url, ": ", err.Error())
+			time.Sleep(time.Second * 5)
+			continue
+		}
+		bodyBytes, err := ioutil.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if err != nil {
+			return err
+		}
+		logger.INFO("Response status: ", strconv.Itoa(resp.StatusCode))
+		logger.INFO("Response body: ", string(bodyBytes))
+		if resp.StatusCode == http.StatusOK {
+			logger.INFO("Health check passed for ", p.Name)
+			return nil
+		}
+		time.Sleep(time.Second * 5)
+	}
+	return fmt.Errorf("Health check failed for %s, see %s", p.Name, p.logPath(config))
+}
+
+//checkProcessesHealth -- checks the health of every process of the local network
+func checkProcessesHealth(config networkspec.Config, procs []process) error {
+
+	for _, proc := range procs {
+		err := proc.checkHealth(config)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
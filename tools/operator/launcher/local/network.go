@@ -0,0 +1,250 @@
+package local
+
+import (
+	"os"
+	"syscall"
+
+	"github.com/pkg/errors"
+
+	"github.com/hyperledger/fabric-test/tools/operator/launcher/nl"
+	"github.com/hyperledger/fabric-test/tools/operator/logger"
+	"github.com/hyperledger/fabric-test/tools/operator/networkclient"
+	"github.com/hyperledger/fabric-test/tools/operator/networkspec"
+	"github.com/hyperledger/fabric-test/tools/operator/networkstate"
+	"github.com/hyperledger/fabric-test/tools/operator/plan"
+)
+
+//Local -- a network whose peers, orderers and CAs run as processes of the local machine, started from the peer,
+//orderer and fabric-ca-server binaries in PATH
+type Local struct {
+	Config networkspec.Config
+}
+
+//launch -- starts the new processes, records them in processes.yaml and waits for their health
+func (l Local) launch(procs processes, newProcesses []process) (processes, error) {
+
+	for i := range newProcesses {
+		err := newProcesses[i].launch(l.Config)
+		procs.Processes = append(procs.Processes, newProcesses[i])
+		if err != nil {
+			procs.write(l.Config)
+			return procs, err
+		}
+	}
+	err := procs.write(l.Config)
+	if err != nil {
+		return procs, err
+	}
+	return procs, checkProcessesHealth(l.Config, newProcesses)
+}
+
+//LaunchLocalNetwork -- generates the artifacts of the network for the ports of its processes and starts them
+func (l Local) LaunchLocalNetwork() error {
+
+	var network nl.Network
+	newProcesses := networkProcesses(l.Config)
+	err := validate(l.Config, newProcesses)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(localDir(l.Config), 0755)
+	if err != nil {
+		return err
+	}
+	err = allocatePorts(newProcesses)
+	if err != nil {
+		return err
+	}
+	l.Config.Endpoints = processes{Processes: newProcesses}.endpoints()
+	err = network.GenerateConfigurationFiles(l.Config, "local", false)
+	if err != nil {
+		logger.ERROR("Failed to generate crypto config file")
+		return err
+	}
+	err = network.GenerateNetworkArtifacts(l.Config)
+	if err != nil {
+		return err
+	}
+	procs, err := l.launch(processes{}, newProcesses)
+	if err != nil {
+		logger.ERROR("Failed to launch local processes")
+		return err
+	}
+	err = generateConnectionProfiles(l.Config, procs)
+	if err != nil {
+		logger.ERROR("Failed to generate connection profile")
+		return err
+	}
+	if l.Config.Orderer.BootstrapMethod == "none" {
+		err = networkclient.JoinOrdererChannels(l.Config)
+		if err != nil {
+			logger.ERROR("Failed to join the orderers to the channels")
+			return err
+		}
+	}
+	return nil
+}
+
+//ExtendLocalNetwork -- starts the peers of addPeer and adds them to the connection profiles
+func (l Local) ExtendLocalNetwork() error {
+
+	var network nl.Network
+	procs, err := readProcesses(l.Config)
+	if err != nil {
+		return err
+	}
+	newPeers := addedPeerProcesses(l.Config)
+	err = validate(l.Config, newPeers)
+	if err != nil {
+		return err
+	}
+	err = network.ExtendConfigurationFiles(l.Config, "local")
+	if err != nil {
+		logger.ERROR("Failed to generate crypto config file")
+		return err
+	}
+	err = network.GenerateCryptoCerts(l.Config, "extend")
+	if err != nil {
+		logger.ERROR("Failed to generate certificates")
+		return err
+	}
+	err = allocatePorts(newPeers)
+	if err != nil {
+		return err
+	}
+	_, err = l.launch(procs, newPeers)
+	if err != nil {
+		logger.ERROR("Failed to launch local processes")
+		return err
+	}
+	return addPeersToConnectionProfiles(l.Config, newPeers)
+}
+
+//DownLocalNetwork -- stops the processes of the network and removes its artifacts
+func (l Local) DownLocalNetwork() error {
+
+	var network nl.Network
+	procs, err := readProcesses(l.Config)
+	if err != nil {
+		logger.WARNING(err.Error())
+	}
+	for _, proc := range procs.Processes {
+		err = proc.stop()
+		if err != nil {
+			return err
+		}
+	}
+	err = os.RemoveAll(localDir(l.Config))
+	if err != nil {
+		return err
+	}
+	return network.NetworkCleanUp(l.Config)
+}
+
+//Inventory -- sets the pids and ports of the components of the state of the network from its processes
+func (l Local) Inventory(state *networkstate.State) error {
+
+	procs, err := readProcesses(l.Config)
+	if err != nil {
+		return err
+	}
+	for _, proc := range procs.Processes {
+		component := state.Component(proc.Name)
+		if component == nil {
+			continue
+		}
+		component.Pid = proc.Pid
+		component.SetPort("listen", int(proc.Port))
+		component.SetPort("operations", int(proc.OperationsPort))
+		if proc.AdminPort != 0 {
+			component.SetPort("admin", int(proc.AdminPort))
+		}
+	}
+	return nil
+}
+
+//ComponentStates -- whether the processes of the components of the network are running
+func (l Local) ComponentStates(state networkstate.State) (map[string]string, error) {
+
+	states := make(map[string]string)
+	for _, component := range state.Components {
+		if component.Pid == 0 {
+			continue
+		}
+		states[component.Name] = "stopped"
+		proc, err := os.FindProcess(component.Pid)
+		if err == nil && proc.Signal(syscall.Signal(0)) == nil {
+			states[component.Name] = "running"
+		}
+	}
+	return states, nil
+}
+
+//Plan -- records in p the configuration files, artifacts and processes of the up action, without starting them. The
+//ports of the processes are only chosen when they start
+func (l Local) Plan(action string, p *plan.Plan) error {
+
+	if action != "up" {
+		return errors.Errorf("dry run of action %s is not supported on local, only of up", action)
+	}
+	var network nl.Network
+	newProcesses := networkProcesses(l.Config)
+	err := validate(l.Config, newProcesses)
+	if err != nil {
+		return err
+	}
+	err = network.PlanConfigurationFiles(l.Config, "local", false, p)
+	if err != nil {
+		return err
+	}
+	network.PlanNetworkArtifacts(l.Config, p)
+	for _, proc := range newProcesses {
+		p.Processes = append(p.Processes, plan.Process{Name: proc.Name, Binary: binaries[proc.Component]})
+	}
+	return nil
+}
+
+//Network -- runs an action on the local network
+func (l Local) Network(action string) error {
+
+	var err error
+	switch action {
+	case "up":
+		err = l.LaunchLocalNetwork()
+		if err != nil {
+			logger.ERROR("Failed to launch local fabric network")
+			return err
+		}
+	case "addPeer":
+		err = l.ExtendLocalNetwork()
+		if err != nil {
+			logger.ERROR("Failed to extend local fabric network")
+			return err
+		}
+	case "down":
+		err = l.DownLocalNetwork()
+		if err != nil {
+			logger.ERROR("Failed to down local fabric network")
+			return err
+		}
+	case "health":
+		var procs processes
+		procs, err = readProcesses(l.Config)
+		if err != nil {
+			return err
+		}
+		err = checkProcessesHealth(l.Config, procs.Processes)
+		if err != nil {
+			logger.ERROR("Failed to check the health of local fabric network")
+			return err
+		}
+		err = networkclient.CheckConsensus(l.Config)
+		if err != nil {
+			logger.ERROR("Failed to check the consensus clusters of local fabric network")
+			return err
+		}
+	default:
+		return errors.Errorf("Incorrect action %s, the local runtime supports up, down, health and addPeer", action)
+	}
+	return nil
+}
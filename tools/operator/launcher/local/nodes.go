@@ -0,0 +1,153 @@
+package local
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/pkg/errors"
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/hyperledger/fabric-test/tools/operator/fabricconfig"
+	"github.com/hyperledger/fabric-test/tools/operator/networkspec"
+	"github.com/hyperledger/fabric-test/tools/operator/paths"
+)
+
+//binaries -- the binary run by the processes of each component, looked up in PATH
+var binaries = map[string]string{"peer": "peer", "orderer": "orderer", "ca": "fabric-ca-server"}
+
+//validate -- checks that the network spec can run as local processes and that the binaries it needs are in PATH
+func validate(config networkspec.Config, newProcesses []process) error {
+
+	if config.DBType == "couchdb" {
+		return errors.New("dbType couchdb is not supported by the local runtime, use goleveldb")
+	}
+	if config.Orderer.OrdererType == "kafka" {
+		return errors.New("ordererType kafka is not supported by the local runtime")
+	}
+	for _, proc := range newProcesses {
+		_, err := exec.LookPath(binaries[proc.Component])
+		if err != nil {
+			return errors.Wrapf(err, "%s needs the %s binary in PATH", proc.Name, binaries[proc.Component])
+		}
+	}
+	return nil
+}
+
+//networkProcesses -- the CAs, orderers and peers of the network spec, without ports
+func networkProcesses(config networkspec.Config) []process {
+
+	var output []process
+	for _, org := range config.OrdererOrganizations {
+		for i := 0; i < org.NumCA; i++ {
+			output = append(output, process{Name: fmt.Sprintf("ca%d-%s", i, org.Name), Org: org.Name, Component: "ca", OrgType: "orderer"})
+		}
+		for i := 0; i < org.NumOrderers; i++ {
+			output = append(output, process{Name: fmt.Sprintf("orderer%d-%s", i, org.Name), Org: org.Name, MSPID: org.MSPID, Component: "orderer", OrgType: "orderer"})
+		}
+	}
+	for _, org := range config.PeerOrganizations {
+		for i := 0; i < org.NumCA; i++ {
+			output = append(output, process{Name: fmt.Sprintf("ca%d-%s", i, org.Name), Org: org.Name, Component: "ca", OrgType: "peer"})
+		}
+		output = append(output, peerProcesses(org, 0, org.NumPeers)...)
+	}
+	return output
+}
+
+//addedPeerProcesses -- the peers of addPeer, numbered after the peers their organization already has
+func addedPeerProcesses(config networkspec.Config) []process {
+
+	var output []process
+	for _, added := range config.AddPeersToOrganization {
+		for _, org := range config.PeerOrganizations {
+			if org.Name == added.Name {
+				output = append(output, peerProcesses(added, org.NumPeers, added.NumPeers)...)
+			}
+		}
+	}
+	return output
+}
+
+func peerProcesses(org networkspec.PeerOrganizations, first, count int) []process {
+
+	var output []process
+	for i := first; i < first+count; i++ {
+		output = append(output, process{Name: fmt.Sprintf("peer%d-%s", i, org.Name), Org: org.Name, MSPID: org.MSPID, Component: "peer", OrgType: "peer"})
+	}
+	return output
+}
+
+//launch -- writes the config of the process to its directory and starts it
+func (p *process) launch(config networkspec.Config) error {
+
+	dir := p.dir(config)
+	dataDir := paths.JoinPath(dir, "data")
+	err := os.MkdirAll(dataDir, 0755)
+	if err != nil {
+		return err
+	}
+	var configName string
+	var configFile interface{}
+	var args, env []string
+	switch p.Component {
+	case "peer":
+		coreConfig, err := fabricconfig.CoreConfig(config)
+		if err != nil {
+			return errors.Wrap(err, "failed to read core config")
+		}
+		configName = "core.yaml"
+		configFile, err = fabricconfig.LocalPeerConfig(p.Name, p.Org, p.MSPID, p.Port, p.ChaincodePort, p.OperationsPort, dataDir, coreConfig, config)
+		if err != nil {
+			return err
+		}
+		args = []string{"node", "start"}
+		env = []string{fmt.Sprintf("FABRIC_CFG_PATH=%s", dir), fmt.Sprintf("FABRIC_LOGGING_SPEC=%s", config.PeerFabricLoggingSpec)}
+	case "orderer":
+		ordererConfig, err := fabricconfig.OrdererConfig(config)
+		if err != nil {
+			return errors.Wrap(err, "failed to read orderer config")
+		}
+		configName = "orderer.yaml"
+		configFile, err = fabricconfig.LocalOrdererConfig(p.Name, p.Org, p.MSPID, p.Port, p.OperationsPort, p.AdminPort, dataDir, ordererConfig, config)
+		if err != nil {
+			return err
+		}
+		env = []string{fmt.Sprintf("FABRIC_CFG_PATH=%s", dir), fmt.Sprintf("FABRIC_LOGGING_SPEC=%s", config.OrdererFabricLoggingSpec)}
+	case "ca":
+		args = []string{"start", "-b", "admin:adminpw", "-d"}
+		env = p.caEnv(config, dataDir)
+	}
+	if configFile != nil {
+		contents, err := yaml.Marshal(configFile)
+		if err != nil {
+			return err
+		}
+		err = ioutil.WriteFile(paths.JoinPath(dir, configName), contents, 0644)
+		if err != nil {
+			return errors.Wrapf(err, "failed to write the config of %s", p.Name)
+		}
+	}
+	return p.start(config, binaries[p.Component], args, env)
+}
+
+//caEnv -- the environment of a fabric-ca-server, as given to the CA containers of the docker network
+func (p process) caEnv(config networkspec.Config, dataDir string) []string {
+
+	orgDir := paths.JoinPath(paths.CryptoConfigDir(config.ArtifactsLocation), fmt.Sprintf("%sOrganizations/%s", p.OrgType, p.Org))
+	tls := strings.ToLower(config.TLS)
+	return []string{
+		fmt.Sprintf("FABRIC_CA_HOME=%s", dataDir),
+		fmt.Sprintf("FABRIC_CA_SERVER_ADDRESS=%s", localIP),
+		fmt.Sprintf("FABRIC_CA_SERVER_PORT=%d", p.Port),
+		fmt.Sprintf("FABRIC_CA_SERVER_OPERATIONS_LISTENADDRESS=%s:%d", localIP, p.OperationsPort),
+		fmt.Sprintf("FABRIC_CA_SERVER_CA_NAME=%s", p.Name),
+		fmt.Sprintf("FABRIC_CA_SERVER_CA_CERTFILE=%s/ca/ca.%s-cert.pem", orgDir, p.Org),
+		fmt.Sprintf("FABRIC_CA_SERVER_CA_KEYFILE=%s/ca/ca-priv_sk", orgDir),
+		fmt.Sprintf("FABRIC_CA_SERVER_TLS_ENABLED=%t", tls == "true" || tls == "mutual"),
+		fmt.Sprintf("FABRIC_CA_SERVER_TLS_CERTFILE=%s/tlsca/tlsca.%s-cert.pem", orgDir, p.Org),
+		fmt.Sprintf("FABRIC_CA_SERVER_TLS_KEYFILE=%s/tlsca/tlsca-priv_sk", orgDir),
+	}
+}
@@ -0,0 +1,213 @@
+package local
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/exec"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/hyperledger/fabric-test/tools/operator/logger"
+	"github.com/hyperledger/fabric-test/tools/operator/networkspec"
+	"github.com/hyperledger/fabric-test/tools/operator/paths"
+)
+
+const (
+	localIP     = "127.0.0.1"
+	stopTimeout = 10 * time.Second
+)
+
+//process -- a peer, orderer or CA of the network run as a local process, with the ports it listens on
+type process struct {
+	Name           string `yaml:"name"`
+	Org            string `yaml:"org"`
+	MSPID          string `yaml:"mspID,omitempty"`
+	Component      string `yaml:"component"`
+	OrgType        string `yaml:"orgType"`
+	Pid            int    `yaml:"pid,omitempty"`
+	Port           int32  `yaml:"port"`
+	OperationsPort int32  `yaml:"operationsPort"`
+	ChaincodePort  int32  `yaml:"chaincodePort,omitempty"`
+	AdminPort      int32  `yaml:"adminPort,omitempty"`
+}
+
+//processes -- the processes of a local network, kept in the processes.yaml of the local directory between actions
+type processes struct {
+	Processes []process `yaml:"processes"`
+}
+
+//localDir -- the directory holding the config files, ledgers, logs and processes.yaml of the local processes
+func localDir(config networkspec.Config) string {
+	return paths.JoinPath(config.ArtifactsLocation, "local")
+}
+
+func (p process) dir(config networkspec.Config) string {
+	return paths.JoinPath(localDir(config), p.Name)
+}
+
+func (p process) logPath(config networkspec.Config) string {
+	return paths.JoinPath(localDir(config), fmt.Sprintf("logs/%s.log", p.Name))
+}
+
+//find -- the process with the given name, or nil
+func (p processes) find(name string) *process {
+	for i := range p.Processes {
+		if p.Processes[i].Name == name {
+			return &p.Processes[i]
+		}
+	}
+	return nil
+}
+
+//endpoints -- the addresses of the peers and orderers, replacing <name>:<port> in the channel configs
+func (p processes) endpoints() map[string]networkspec.Endpoint {
+
+	output := make(map[string]networkspec.Endpoint)
+	for _, proc := range p.Processes {
+		if proc.Component == "peer" || proc.Component == "orderer" {
+			output[proc.Name] = networkspec.Endpoint{Host: localIP, Port: int(proc.Port)}
+		}
+	}
+	return output
+}
+
+//readProcesses -- reads processes.yaml of the local network
+func readProcesses(config networkspec.Config) (processes, error) {
+
+	var output processes
+	contents, err := ioutil.ReadFile(paths.JoinPath(localDir(config), "processes.yaml"))
+	if err != nil {
+		return output, errors.Wrap(err, "failed to read the processes of the local network, is it up?")
+	}
+	err = yaml.Unmarshal(contents, &output)
+	if err != nil {
+		return output, errors.Wrap(err, "failed to unmarshal the processes of the local network")
+	}
+	return output, nil
+}
+
+//write -- writes processes.yaml of the local network
+func (p processes) write(config networkspec.Config) error {
+
+	contents, err := yaml.Marshal(p)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(paths.JoinPath(localDir(config), "processes.yaml"), contents, 0644)
+}
+
+//freePorts -- count free ports of 127.0.0.1. The listeners stay open until all ports are found so that none repeats
+func freePorts(count int) ([]int32, error) {
+
+	var ports []int32
+	var listeners []net.Listener
+	defer func() {
+		for _, listener := range listeners {
+			listener.Close()
+		}
+	}()
+	for i := 0; i < count; i++ {
+		listener, err := net.Listen("tcp", fmt.Sprintf("%s:0", localIP))
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to find a free port")
+		}
+		listeners = append(listeners, listener)
+		ports = append(ports, int32(listener.Addr().(*net.TCPAddr).Port))
+	}
+	return ports, nil
+}
+
+//allocatePorts -- sets the ports of new processes: every process gets a listen and an operations port, peers a
+//chaincode port and orderers an admin port
+func allocatePorts(newProcesses []process) error {
+
+	count := 0
+	for _, proc := range newProcesses {
+		count += 2
+		if proc.Component != "ca" {
+			count++
+		}
+	}
+	ports, err := freePorts(count)
+	if err != nil {
+		return err
+	}
+	next := func() int32 {
+		port := ports[0]
+		ports = ports[1:]
+		return port
+	}
+	for i := range newProcesses {
+		newProcesses[i].Port = next()
+		newProcesses[i].OperationsPort = next()
+		switch newProcesses[i].Component {
+		case "peer":
+			newProcesses[i].ChaincodePort = next()
+		case "orderer":
+			newProcesses[i].AdminPort = next()
+		}
+	}
+	return nil
+}
+
+//start -- starts binary as a child process left running after the operator exits, with its output in the log file of
+//the process
+func (p *process) start(config networkspec.Config, binary string, args, env []string) error {
+
+	err := os.MkdirAll(paths.JoinPath(localDir(config), "logs"), 0755)
+	if err != nil {
+		return err
+	}
+	logFile, err := os.OpenFile(p.logPath(config), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open the log file of %s", p.Name)
+	}
+	defer logFile.Close()
+	cmd := exec.Command(binary, args...)
+	cmd.Dir = p.dir(config)
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	err = cmd.Start()
+	if err != nil {
+		return errors.Wrapf(err, "failed to start %s", p.Name)
+	}
+	p.Pid = cmd.Process.Pid
+	logger.INFO(fmt.Sprintf("Started %s with pid %d, logging to %s", p.Name, p.Pid, p.logPath(config)))
+	return cmd.Process.Release()
+}
+
+//stop -- stops the process, killing it if it does not exit within stopTimeout
+func (p process) stop() error {
+
+	if p.Pid == 0 {
+		return nil
+	}
+	proc, err := os.FindProcess(p.Pid)
+	if err != nil {
+		return nil
+	}
+	if proc.Signal(syscall.SIGTERM) != nil {
+		logger.INFO(fmt.Sprintf("%s with pid %d is not running", p.Name, p.Pid))
+		return nil
+	}
+	deadline := time.Now().Add(stopTimeout)
+	for time.Now().Before(deadline) {
+		if proc.Signal(syscall.Signal(0)) != nil {
+			logger.INFO("Stopped ", p.Name)
+			return nil
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	err = proc.Kill()
+	if err != nil {
+		return errors.Wrapf(err, "failed to kill %s with pid %d", p.Name, p.Pid)
+	}
+	logger.INFO("Killed ", p.Name)
+	return nil
+}
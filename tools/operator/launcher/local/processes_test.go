@@ -0,0 +1,51 @@
+package local
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/fabric-test/tools/operator/networkspec"
+)
+
+func TestAllocatePorts(t *testing.T) {
+
+	config := networkspec.Config{
+		OrdererOrganizations:   []networkspec.OrdererOrganizations{{Name: "ordererorg1", NumOrderers: 2}},
+		PeerOrganizations:      []networkspec.PeerOrganizations{{Name: "org1", NumPeers: 2, NumCA: 1}},
+		AddPeersToOrganization: []networkspec.PeerOrganizations{{Name: "org1", NumPeers: 1}},
+	}
+	procs := networkProcesses(config)
+	added := addedPeerProcesses(config)
+	require.Len(t, added, 1)
+	assert.Equal(t, "peer2-org1", added[0].Name)
+	procs = append(procs, added...)
+	require.NoError(t, allocatePorts(procs))
+
+	seen := make(map[int32]string)
+	for _, proc := range procs {
+		ports := []int32{proc.Port, proc.OperationsPort}
+		switch proc.Component {
+		case "peer":
+			ports = append(ports, proc.ChaincodePort)
+		case "orderer":
+			ports = append(ports, proc.AdminPort)
+		}
+		for _, port := range ports {
+			assert.NotZero(t, port, proc.Name)
+			assert.NotContains(t, seen, port, "%s reuses the port of %s", proc.Name, seen[port])
+			seen[port] = proc.Name
+		}
+	}
+
+	endpoints := processes{Processes: procs}.endpoints()
+	assert.Len(t, endpoints, 5)
+	config.Endpoints = endpoints
+	host, port := config.Endpoint("orderer1-ordererorg1", 30001)
+	assert.Equal(t, "127.0.0.1", host)
+	assert.Equal(t, int(procs[1].Port), port)
+	host, port = networkspec.Config{}.Endpoint("orderer1-ordererorg1", 30001)
+	assert.Equal(t, "orderer1-ordererorg1", host)
+	assert.Equal(t, 30001, port)
+}
@@ -7,33 +7,38 @@ package nl
 import (
 	"fmt"
 	"io/ioutil"
+	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/hyperledger/fabric-test/tools/operator/fabricconfiguration"
 	"github.com/hyperledger/fabric-test/tools/operator/logger"
 	"github.com/hyperledger/fabric-test/tools/operator/networkclient"
 	"github.com/hyperledger/fabric-test/tools/operator/networkspec"
+	"github.com/hyperledger/fabric-test/tools/operator/networkstate"
 	"github.com/hyperledger/fabric-test/tools/operator/paths"
-	ytt "github.com/hyperledger/fabric-test/tools/operator/ytt-helper"
+	"github.com/hyperledger/fabric-test/tools/operator/plan"
+	"github.com/hyperledger/fabric-test/tools/operator/templates"
+	"github.com/pkg/errors"
 	yaml "gopkg.in/yaml.v2"
 )
 
-type Network struct {
-	TemplatesDir string
-}
-
-//DockerImage --
-func DockerImage(component, dockerOrg, dockerTag, componentImage string) string {
-	if componentImage != "" {
-		return componentImage
-	}
-	return fmt.Sprintf("%s/fabric-%s:%s", dockerOrg, component, dockerTag)
-}
+type Network struct{}
 
-//GetConfigData - to read the yaml file and parse the data
+//GetConfigData - to read the yaml file and parse the data, scoped to its networkName. The network-state.json of a
+//launched network can be given instead; its network spec is already scoped
 func (n Network) GetConfigData(networkSpecPath string) (networkspec.Config, error) {
 
 	var config networkspec.Config
+	if networkstate.IsStateFile(networkSpecPath) {
+		state, err := networkstate.Read(networkSpecPath)
+		if err != nil {
+			logger.ERROR("Failed to read the state of the network")
+			return config, err
+		}
+		return state.Spec.Config, nil
+	}
 	yamlFile, err := ioutil.ReadFile(networkSpecPath)
 	if err != nil {
 		logger.ERROR("Failed to read input file")
@@ -44,51 +49,195 @@ func (n Network) GetConfigData(networkSpecPath string) (networkspec.Config, erro
 		logger.ERROR("Failed to create config object")
 		return config, err
 	}
-	return config, nil
+	err = config.ValidateNetwork()
+	if err != nil {
+		return config, err
+	}
+	return scopeNetwork(config)
 }
 
 //GenerateConfigurationFiles - to generate all the configuration files
-func (n Network) GenerateConfigurationFiles(upgrade bool) error {
-
-	var inputArgs []string
-	cryptoConfigPath := paths.TemplateFilePath("crypto-config")
-	inputFilePath := paths.TemplateFilePath("input")
-	configFilesPath := fmt.Sprintf("--output=%s", paths.ConfigFilesDir(false))
-	yttPath := fmt.Sprintf("%s/ytt", paths.YTTPath())
-	inputArgs = []string{cryptoConfigPath}
-	if n.TemplatesDir != "" {
-		inputArgs = append(inputArgs, n.TemplatesDir)
-		if upgrade {
-			inputArgs = []string{n.TemplatesDir}
-		}
-	}
-	yttObject := ytt.YTT{InputPath: inputFilePath, OutputPath: configFilesPath}
-	_, err := networkclient.ExecuteCommand(yttPath, yttObject.Args(inputArgs), true)
+func (n Network) GenerateConfigurationFiles(config networkspec.Config, env string, upgrade bool) error {
+	return n.renderConfigurationFiles(config, configurationFiles(env, upgrade), writeConfigurationFile)
+}
+
+//PlanConfigurationFiles -- records in p the configuration files GenerateConfigurationFiles would write
+func (n Network) PlanConfigurationFiles(config networkspec.Config, env string, upgrade bool, p *plan.Plan) error {
+	return n.renderConfigurationFiles(config, configurationFiles(env, upgrade), p.AddFile)
+}
+
+//configurationFiles -- the configuration files of a network launched, or upgraded, in env
+func configurationFiles(env string, upgrade bool) []string {
+
+	var configFiles []string
+	if !upgrade {
+		configFiles = append(configFiles, "crypto-config")
+	}
+	if env == "docker" {
+		configFiles = append(configFiles, "docker")
+	}
+	return configFiles
+}
+
+//ExtendConfigurationFiles - to extend all the configuration files
+func (n Network) ExtendConfigurationFiles(config networkspec.Config, env string) error {
+
+	configFiles := []string{"crypto-config-extend"}
+	if env == "docker" {
+		configFiles = append(configFiles, "peer-extend")
+	}
+	return n.renderConfigurationFiles(config, configFiles, writeConfigurationFile)
+}
+
+//AddOrgConfigurationFiles - to generate the configuration files of the organizations of addOrg
+func (n Network) AddOrgConfigurationFiles(config networkspec.Config, env string) error {
+
+	configFiles := []string{"crypto-config-addorg"}
+	if env == "docker" {
+		configFiles = append(configFiles, "org-extend")
+	}
+	return n.renderConfigurationFiles(config, configFiles, writeConfigurationFile)
+}
+
+//UpgradeConfigurationFiles - to generate the docker compose files of the network and of its addPeer, addOrg and
+//addOrderer nodes again, with the images of the spec
+func (n Network) UpgradeConfigurationFiles(config networkspec.Config, env string) error {
+
+	var configFiles []string
+	if env == "docker" {
+		configFiles = append(configFiles, "docker")
+		if len(config.AddPeersToOrganization) > 0 {
+			configFiles = append(configFiles, "peer-extend")
+		}
+		if len(config.AddOrganizations) > 0 {
+			configFiles = append(configFiles, "org-extend")
+		}
+		if len(config.AddOrderersToOrganization) > 0 {
+			configFiles = append(configFiles, "orderer-extend")
+		}
+	}
+	return n.renderConfigurationFiles(config, configFiles, writeConfigurationFile)
+}
+
+func (n Network) renderConfigurationFiles(config networkspec.Config, configFiles []string, write func(path string, contents []byte) error) error {
+
+	for _, configFile := range configFiles {
+		contents, err := templates.Render(configFile, config)
+		if err != nil {
+			logger.ERROR("Failed to render ", configFile)
+			return err
+		}
+		err = write(paths.ConfigFilePath(config.NetworkName, configFile), contents)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeConfigurationFile(configPath string, contents []byte) error {
+
+	err := os.MkdirAll(filepath.Dir(configPath), 0755)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create the directory of %s", configPath)
+	}
+	return errors.Wrapf(ioutil.WriteFile(configPath, contents, 0644), "failed to write %s", configPath)
+}
+
+//GenerateOrgCryptoCerts - to extend the crypto certs with the organizations of addOrg
+func (n Network) GenerateOrgCryptoCerts(config networkspec.Config) error {
+
+	artifactsLocation := config.ArtifactsLocation
+	generate := networkclient.Cryptogen{ConfigPath: paths.ConfigFilePath(config.NetworkName, "crypto-config-addorg"), Output: paths.CryptoConfigDir(artifactsLocation)}
+	_, err := networkclient.ExecuteCommand("cryptogen", generate.Args("extend"), true)
 	if err != nil {
 		return err
 	}
+	for _, org := range config.AddOrganizations {
+		err = n.changeKeyNames(artifactsLocation, "peer", org.Name, org.NumPeers)
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-//ExtendConfigurationFiles - to extend all the configuration files
-func (n Network) ExtendConfigurationFiles(env string) error {
+//AddOrdererConfigurationFiles - to generate the configuration files of the orderers of addOrderer
+func (n Network) AddOrdererConfigurationFiles(config networkspec.Config, env string) error {
 
-	var inputArgs []string
-	var peerExtendPath string
-	cryptoConfigPath := paths.TemplateFilePath("crypto-config-extend")
-	inputArgs = []string{cryptoConfigPath}
+	configFiles := []string{"crypto-config-addorderer"}
 	if env == "docker" {
-		peerExtendPath = paths.TemplateFilePath("peer-extend")
-		inputArgs = append(inputArgs, peerExtendPath)
-	}
-	inputFilePath := paths.TemplateFilePath("input")
-	configFilesPath := fmt.Sprintf("--output=%s", paths.ConfigFilesDir(true))
-	yttPath := fmt.Sprintf("%s/ytt", paths.YTTPath())
-	yttObject := ytt.YTT{InputPath: inputFilePath, OutputPath: configFilesPath}
-	_, err := networkclient.ExecuteCommand(yttPath, yttObject.Args(inputArgs), true)
+		configFiles = append(configFiles, "orderer-extend")
+	}
+	return n.renderConfigurationFiles(config, configFiles, writeConfigurationFile)
+}
+
+//GenerateOrdererCryptoCerts - to extend the crypto certs with the orderers of addOrderer
+func (n Network) GenerateOrdererCryptoCerts(config networkspec.Config) error {
+
+	artifactsLocation := config.ArtifactsLocation
+	generate := networkclient.Cryptogen{ConfigPath: paths.ConfigFilePath(config.NetworkName, "crypto-config-addorderer"), Output: paths.CryptoConfigDir(artifactsLocation)}
+	_, err := networkclient.ExecuteCommand("cryptogen", generate.Args("extend"), true)
 	if err != nil {
 		return err
 	}
+	for _, org := range config.OrdererOrganizations {
+		numOrderers := org.NumOrderers
+		for _, added := range config.AddOrderersToOrganization {
+			if added.Name == org.Name {
+				numOrderers += added.NumOrderers
+			}
+		}
+		err = n.changeKeyNames(artifactsLocation, "orderer", org.Name, numOrderers)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//RenewOrdererCerts - to issue new certificates to the orderers of rotateOrdererCert from the certificate authorities of
+//their organizations, the previous certificates are kept in the backup directory of the artifacts location
+func (n Network) RenewOrdererCerts(config networkspec.Config) error {
+
+	artifactsLocation := config.ArtifactsLocation
+	ordererOrgsDir := paths.OrdererOrgsDir(artifactsLocation)
+	for _, ordererName := range config.RotateOrdererCerts {
+		orgName := ordererName[strings.LastIndex(ordererName, "-")+1:]
+		ordererDir := paths.JoinPath(ordererOrgsDir, fmt.Sprintf("%s/orderers/%s.%s", orgName, ordererName, orgName))
+		backupDir := paths.JoinPath(artifactsLocation, fmt.Sprintf("backup/certs/%s-%d", ordererName, time.Now().Unix()))
+		_, err := networkclient.ExecuteCommand("mkdir", []string{"-p", paths.JoinPath(artifactsLocation, "backup/certs")}, true)
+		if err != nil {
+			return err
+		}
+		_, err = networkclient.ExecuteCommand("mv", []string{ordererDir, backupDir}, true)
+		if err != nil {
+			logger.ERROR("Failed to back up the certificates of ", ordererName)
+			return err
+		}
+	}
+	for _, configFile := range []string{"crypto-config", "crypto-config-addorderer"} {
+		configPath := paths.ConfigFilePath(config.NetworkName, configFile)
+		if _, err := os.Stat(configPath); err != nil {
+			continue
+		}
+		generate := networkclient.Cryptogen{ConfigPath: configPath, Output: paths.CryptoConfigDir(artifactsLocation)}
+		_, err := networkclient.ExecuteCommand("cryptogen", generate.Args("extend"), true)
+		if err != nil {
+			return err
+		}
+	}
+	for _, ordererName := range config.RotateOrdererCerts {
+		orgName := ordererName[strings.LastIndex(ordererName, "-")+1:]
+		ordererDir := paths.JoinPath(ordererOrgsDir, fmt.Sprintf("%s/orderers/%s.%s", orgName, ordererName, orgName))
+		if _, err := os.Stat(ordererDir); err != nil {
+			return errors.Errorf("no certificates were issued to %s, it is not listed in the crypto config", ordererName)
+		}
+		err := n.moveKey(paths.JoinPath(ordererDir, "msp/keystore"), "priv_sk")
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -97,9 +246,9 @@ func (n Network) GenerateCryptoCerts(config networkspec.Config, cryptoAction str
 
 	artifactsLocation := config.ArtifactsLocation
 	outputPath := paths.CryptoConfigDir(artifactsLocation)
-	cryptoConfigPath := paths.ConfigFilePath("crypto-config")
+	cryptoConfigPath := paths.ConfigFilePath(config.NetworkName, "crypto-config")
 	if cryptoAction == "extend" {
-		cryptoConfigPath = paths.ConfigFilePath("crypto-config-extend")
+		cryptoConfigPath = paths.ConfigFilePath(config.NetworkName, "crypto-config-extend")
 	}
 	generate := networkclient.Cryptogen{ConfigPath: cryptoConfigPath, Output: outputPath}
 	_, err := networkclient.ExecuteCommand("cryptogen", generate.Args(cryptoAction), true)
@@ -126,11 +275,12 @@ func (n Network) GenerateCryptoCerts(config networkspec.Config, cryptoAction str
 //GenerateGenesisBlock - to generate a genesis block and to create channel transactions
 func (n Network) GenerateGenesisBlock(config networkspec.Config) error {
 
-	artifactsLocation := config.ArtifactsLocation
-	path := paths.ChannelArtifactsDir(artifactsLocation)
-	outputPath := paths.JoinPath(path, "genesis.block")
-	configFilesPath := paths.ConfigFilesDir(false)
-	configtxgen := fabricconfiguration.Configtxgen{ConfigPath: configFilesPath, OutputPath: outputPath, Profile: "testOrgsOrdererGenesis", ChannelID: "orderersystemchannel"}
+	if config.Orderer.BootstrapMethod == "none" {
+		logger.INFO("Orderers bootstrap without system channel, skipping genesis block")
+		return nil
+	}
+	configFilesPath := paths.ConfigFilesDir(config.NetworkName, false)
+	configtxgen := fabricconfiguration.Configtxgen{ConfigPath: configFilesPath, OutputPath: genesisBlockPath(config), Profile: "testOrgsOrdererGenesis", ChannelID: "orderersystemchannel"}
 	err := fabricconfiguration.CreateConfigtx(&configtxgen, config)
 	if err != nil {
 		return err
@@ -138,6 +288,11 @@ func (n Network) GenerateGenesisBlock(config networkspec.Config) error {
 	return nil
 }
 
+//genesisBlockPath -- the genesis block of the system channel the orderers bootstrap from
+func genesisBlockPath(config networkspec.Config) string {
+	return paths.JoinPath(paths.ChannelArtifactsDir(config.ArtifactsLocation), "genesis.block")
+}
+
 func (n Network) changeKeyNames(artifactsLocation, orgType, orgName string, numComponents int) error {
 
 	var path string
@@ -187,7 +342,7 @@ func (n Network) moveKey(path, fileName string) error {
 
 func (n Network) GenerateNetworkArtifacts(config networkspec.Config) error {
 
-	configFilesPath := paths.ConfigFilesDir(false)
+	configFilesPath := paths.ConfigFilesDir(config.NetworkName, false)
 	var err error
 
 	err = n.GenerateCryptoCerts(config, "generate")
@@ -209,3 +364,15 @@ func (n Network) GenerateNetworkArtifacts(config networkspec.Config) error {
 	}
 	return err
 }
+
+//PlanNetworkArtifacts -- records in p the cryptogen run, the genesis block and the channel artifacts
+//GenerateNetworkArtifacts would generate
+func (n Network) PlanNetworkArtifacts(config networkspec.Config, p *plan.Plan) {
+
+	generate := networkclient.Cryptogen{ConfigPath: paths.ConfigFilePath(config.NetworkName, "crypto-config"), Output: paths.CryptoConfigDir(config.ArtifactsLocation)}
+	p.AddCommand("cryptogen", generate.Args("generate")...)
+	if config.Orderer.BootstrapMethod != "none" {
+		p.ChannelTransactions = append(p.ChannelTransactions, plan.ChannelTransaction{Channel: "orderersystemchannel", Kind: "genesis block", Path: genesisBlockPath(config)})
+	}
+	networkclient.PlanChannelTransaction(config, p)
+}
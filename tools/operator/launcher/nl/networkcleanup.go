@@ -10,21 +10,26 @@ import (
 	"strings"
 
 	"github.com/hyperledger/fabric-test/tools/operator/networkspec"
+	"github.com/hyperledger/fabric-test/tools/operator/networkstate"
 	"github.com/hyperledger/fabric-test/tools/operator/paths"
 )
 
 //NetworkCleanUp - to clean up the network
 func (n Network) NetworkCleanUp(config networkspec.Config) error {
 
+	err := n.ReleasePorts(config)
+	if err != nil {
+		return err
+	}
 	artifactsLocation := config.ArtifactsLocation
 	paths := []string{
-		paths.ConfigFilesDir(false),
-		paths.JoinPath(paths.TemplatesDir(), "input.yaml"),
+		paths.ConfigFilesDir(config.NetworkName, false),
 		paths.ChannelArtifactsDir(artifactsLocation),
 		paths.CryptoConfigDir(artifactsLocation),
 		paths.ConnectionProfilesDir(artifactsLocation),
-		paths.CaliperConnectionProfilesDir(artifactsLocation)}
-	err := n.removeDirectories(paths)
+		paths.CaliperConnectionProfilesDir(artifactsLocation),
+		networkstate.Path(artifactsLocation)}
+	err = n.removeDirectories(paths)
 	if err != nil {
 		return err
 	}
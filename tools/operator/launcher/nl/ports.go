@@ -0,0 +1,249 @@
+package nl
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/hyperledger/fabric-test/tools/operator/logger"
+	"github.com/hyperledger/fabric-test/tools/operator/networkspec"
+	"github.com/hyperledger/fabric-test/tools/operator/paths"
+)
+
+//portClaimsDir -- the host-wide directory of the claims on port ranges, shared by the operators of every checkout
+var portClaimsDir = filepath.Join(os.TempDir(), "fabric-test-ports")
+
+//staleClaimAge -- the age after which a claim whose network has no port-base file is left over by an operator that
+//was interrupted before it kept the port base, or by a network removed without being taken down
+const staleClaimAge = 5 * time.Minute
+
+//portBaseFile -- the file keeping the port base allocated to a named network until the network is taken down
+func portBaseFile(networkName string) string {
+	return paths.JoinPath(paths.ConfigFilesDir(networkName, false), "port-base")
+}
+
+//scopeNetwork -- scopes a named network: its artifacts are kept in <artifactsLocation>/<networkName>, its k8s
+//namespace defaults to networkName and its ports to the range allocated when it was launched
+func scopeNetwork(config networkspec.Config) (networkspec.Config, error) {
+
+	if config.NetworkName == "" {
+		return config, nil
+	}
+	config.ArtifactsLocation = paths.JoinPath(config.ArtifactsLocation, config.NetworkName)
+	if config.K8s.Namespace == "" {
+		config.K8s.Namespace = config.NetworkName
+	}
+	if config.Ports.Base != 0 {
+		return config, nil
+	}
+	contents, err := ioutil.ReadFile(portBaseFile(config.NetworkName))
+	if err != nil {
+		return config, nil
+	}
+	base, err := strconv.Atoi(strings.TrimSpace(string(contents)))
+	if err != nil {
+		return config, errors.Wrapf(err, "invalid port base of network %s", config.NetworkName)
+	}
+	config.Ports.Base = base
+	return config, nil
+}
+
+//takenPortBases -- the port bases allocated to the named networks launched from this operator directory, and the
+//default one
+func takenPortBases(networkName string) map[int]bool {
+
+	taken := map[int]bool{networkspec.DefaultPortBase: true}
+	configFilesDir := filepath.Dir(paths.ConfigFilesDir(networkName, false))
+	files, _ := filepath.Glob(paths.JoinPath(configFilesDir, "configFiles-*/port-base"))
+	for _, file := range files {
+		contents, err := ioutil.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		base, err := strconv.Atoi(strings.TrimSpace(string(contents)))
+		if err == nil {
+			taken[base] = true
+		}
+	}
+	return taken
+}
+
+//portsFree -- whether no port of the range starting at base is in use on the host
+func portsFree(base int) bool {
+
+	for port := base; port < base+networkspec.PortRangeSize; port++ {
+		listener, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+		if err != nil {
+			return false
+		}
+		listener.Close()
+	}
+	return true
+}
+
+//portClaimFile -- the claim on the range of ports starting at base, which holds the port-base file of its network
+func portClaimFile(base int) string {
+	return filepath.Join(portClaimsDir, fmt.Sprintf("%d.claim", base))
+}
+
+//claimPorts -- claims the range of ports starting at base for the network keeping its port base in owner. The claim
+//file is created exclusively, so of the operators of the host allocating ports at the same time only one gets the
+//range. A stale claim is removed and the range claimed again
+func claimPorts(base int, owner string) (bool, error) {
+
+	err := os.MkdirAll(portClaimsDir, 0755)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to create the directory of port claims")
+	}
+	for attempt := 0; attempt < 2; attempt++ {
+		file, err := os.OpenFile(portClaimFile(base), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if os.IsExist(err) {
+			if !removeStaleClaim(base) {
+				return false, nil
+			}
+			continue
+		}
+		if err != nil {
+			return false, errors.Wrapf(err, "failed to claim ports %d to %d", base, base+networkspec.PortRangeSize-1)
+		}
+		_, err = file.WriteString(owner)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(portClaimFile(base))
+			return false, errors.Wrapf(err, "failed to claim ports %d to %d", base, base+networkspec.PortRangeSize-1)
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+//removeStaleClaim -- removes the claim on the range starting at base if it is older than staleClaimAge and the
+//port-base file of its network is gone
+func removeStaleClaim(base int) bool {
+
+	claimFile := portClaimFile(base)
+	info, err := os.Stat(claimFile)
+	if err != nil || time.Since(info.ModTime()) < staleClaimAge {
+		return false
+	}
+	owner, err := ioutil.ReadFile(claimFile)
+	if err != nil {
+		return false
+	}
+	if _, err := os.Stat(string(owner)); err == nil {
+		return false
+	}
+	current, err := os.Stat(claimFile)
+	if err != nil || !os.SameFile(info, current) {
+		return false
+	}
+	logger.INFO(fmt.Sprintf("Removing the stale claim of %s on ports %d to %d", owner, base, base+networkspec.PortRangeSize-1))
+	return os.Remove(claimFile) == nil
+}
+
+//releasePorts -- removes the claim on the range starting at base if it is the claim of the network keeping its port
+//base in owner
+func releasePorts(base int, owner string) error {
+
+	claimFile := portClaimFile(base)
+	contents, err := ioutil.ReadFile(claimFile)
+	if os.IsNotExist(err) || (err == nil && string(contents) != owner) {
+		return nil
+	}
+	if err == nil {
+		err = os.Remove(claimFile)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to release ports %d to %d", base, base+networkspec.PortRangeSize-1)
+	}
+	return nil
+}
+
+//AllocatePorts -- gives a named network without ports.base the first range of ports above the default one that no
+//other named network of the host claimed and that has no port in use on the host. The range is claimed in
+//portClaimsDir and kept until the network is taken down
+func (n Network) AllocatePorts(config networkspec.Config) (networkspec.Config, error) {
+
+	if config.NetworkName == "" || config.Ports.Base != 0 {
+		return config, nil
+	}
+	base, err := freePortBase(config.NetworkName, true)
+	if err != nil {
+		return config, err
+	}
+	err = ioutil.WriteFile(portBaseFile(config.NetworkName), []byte(strconv.Itoa(base)), 0644)
+	if err != nil {
+		releasePorts(base, portBaseFile(config.NetworkName))
+		return config, errors.Wrapf(err, "failed to keep the port base of network %s", config.NetworkName)
+	}
+	logger.INFO(fmt.Sprintf("Allocated ports %d to %d to network %s", base, base+networkspec.PortRangeSize-1, config.NetworkName))
+	config.Ports.Base = base
+	return config, nil
+}
+
+//PlanPorts -- gives a named network without ports.base the range of ports AllocatePorts would allocate, without
+//keeping it
+func (n Network) PlanPorts(config networkspec.Config) (networkspec.Config, error) {
+
+	if config.NetworkName == "" || config.Ports.Base != 0 {
+		return config, nil
+	}
+	base, err := freePortBase(config.NetworkName, false)
+	config.Ports.Base = base
+	return config, err
+}
+
+//ReleasePorts -- removes the claim of a named network on the range of ports AllocatePorts gave it
+func (n Network) ReleasePorts(config networkspec.Config) error {
+
+	if config.NetworkName == "" || config.Ports.Base == 0 {
+		return nil
+	}
+	return releasePorts(config.Ports.Base, portBaseFile(config.NetworkName))
+}
+
+//freePortBase -- the first range of ports above the default one that is neither allocated nor in use, claimed for
+//the network if claim is set
+func freePortBase(networkName string, claim bool) (int, error) {
+
+	taken := takenPortBases(networkName)
+	owner := portBaseFile(networkName)
+	for base := networkspec.DefaultPortBase; base+networkspec.PortRangeSize-1 <= 65535; base += networkspec.PortRangeSize {
+		if taken[base] {
+			continue
+		}
+		if !claim {
+			if _, err := os.Stat(portClaimFile(base)); err == nil {
+				continue
+			}
+			if portsFree(base) {
+				return base, nil
+			}
+			continue
+		}
+		claimed, err := claimPorts(base, owner)
+		if err != nil {
+			return 0, err
+		}
+		if !claimed {
+			continue
+		}
+		if portsFree(base) {
+			return base, nil
+		}
+		err = releasePorts(base, owner)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return 0, errors.Errorf("no free range of %d ports left for network %s", networkspec.PortRangeSize, networkName)
+}
@@ -0,0 +1,87 @@
+package nl
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/fabric-test/tools/operator/networkspec"
+)
+
+func TestFreePortBaseClaimsDistinctRanges(t *testing.T) {
+
+	portClaimsDir = t.TempDir()
+	var wg sync.WaitGroup
+	bases := make([]int, 4)
+	errs := make([]error, len(bases))
+	for index := range bases {
+		wg.Add(1)
+		go func(index int) {
+			defer wg.Done()
+			bases[index], errs[index] = freePortBase(fmt.Sprintf("ports%d", index), true)
+		}(index)
+	}
+	wg.Wait()
+	claimed := make(map[int]bool)
+	for index, base := range bases {
+		require.NoError(t, errs[index])
+		assert.NotEqual(t, networkspec.DefaultPortBase, base)
+		assert.False(t, claimed[base], "port base %d allocated twice", base)
+		claimed[base] = true
+		owner, err := ioutil.ReadFile(portClaimFile(base))
+		require.NoError(t, err)
+		assert.Equal(t, portBaseFile(fmt.Sprintf("ports%d", index)), string(owner))
+	}
+
+	planned, err := freePortBase("ports4", false)
+	require.NoError(t, err)
+	assert.False(t, claimed[planned])
+	assert.NoFileExists(t, portClaimFile(planned))
+
+	require.NoError(t, releasePorts(bases[0], portBaseFile("ports1")))
+	assert.FileExists(t, portClaimFile(bases[0]))
+	require.NoError(t, Network{}.ReleasePorts(networkspec.Config{NetworkName: "ports0", Ports: networkspec.Ports{Base: bases[0]}}))
+	assert.NoFileExists(t, portClaimFile(bases[0]))
+	require.NoError(t, releasePorts(bases[0], portBaseFile("ports0")))
+}
+
+func TestClaimPortsStaleClaims(t *testing.T) {
+
+	portClaimsDir = t.TempDir()
+	base := networkspec.DefaultPortBase + networkspec.PortRangeSize
+	old := time.Now().Add(-2 * staleClaimAge)
+	ownerDir := t.TempDir()
+	liveOwner := filepath.Join(ownerDir, "port-base")
+	require.NoError(t, ioutil.WriteFile(liveOwner, []byte(fmt.Sprint(base)), 0644))
+	goneOwner := filepath.Join(ownerDir, "gone", "port-base")
+
+	for _, test := range []struct {
+		owner   string
+		modTime time.Time
+		claimed bool
+	}{
+		{owner: goneOwner, modTime: time.Now(), claimed: false},
+		{owner: liveOwner, modTime: old, claimed: false},
+		{owner: goneOwner, modTime: old, claimed: true},
+	} {
+		require.NoError(t, ioutil.WriteFile(portClaimFile(base), []byte(test.owner), 0644))
+		require.NoError(t, os.Chtimes(portClaimFile(base), test.modTime, test.modTime))
+		claimed, err := claimPorts(base, "new-owner")
+		require.NoError(t, err)
+		assert.Equal(t, test.claimed, claimed, "%+v", test)
+		owner, err := ioutil.ReadFile(portClaimFile(base))
+		require.NoError(t, err)
+		if test.claimed {
+			assert.Equal(t, "new-owner", string(owner))
+		} else {
+			assert.Equal(t, test.owner, string(owner))
+		}
+	}
+}
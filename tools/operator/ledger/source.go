@@ -0,0 +1,172 @@
+package ledger
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-protos-go/orderer"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-test/tools/operator/fabricclient"
+	"github.com/hyperledger/fabric/protoutil"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+)
+
+//BlockSource -- a node that serves the blocks of a channel
+type BlockSource interface {
+	//Name -- name of the node used in reports
+	Name() string
+	//ValidatesTransactions -- whether the blocks carry the validation codes of the node, true for peers
+	ValidatesTransactions() bool
+	//Height -- number of blocks of the channel on the node
+	Height(channel string) (uint64, error)
+	//Blocks -- calls handler for every block from start to end, both inclusive
+	Blocks(channel string, start, end uint64, handler func(*common.Block) error) error
+	//Close -- releases the connection to the node
+	Close() error
+}
+
+//ChannelNotServedError -- the node does not serve the channel, e.g. because it has not joined it. A node that serves
+//the channel but denies access to it returns an error of its own
+type ChannelNotServedError struct {
+	Node    string
+	Channel string
+	Status  common.Status
+}
+
+func (e *ChannelNotServedError) Error() string {
+	return fmt.Sprintf("%s does not serve channel %s: %s", e.Node, e.Channel, e.Status)
+}
+
+//DeliverSource -- a peer or orderer read through its deliver service
+type DeliverSource struct {
+	name        string
+	conn        *grpc.ClientConn
+	identity    *fabricclient.Identity
+	tlsCertHash []byte
+	peer        bool
+}
+
+//NewPeerSource -- block source reading from the deliver service of a peer
+func NewPeerSource(name string, conn *grpc.ClientConn, identity *fabricclient.Identity, tlsCertHash []byte) *DeliverSource {
+	return &DeliverSource{name: name, conn: conn, identity: identity, tlsCertHash: tlsCertHash, peer: true}
+}
+
+//NewOrdererSource -- block source reading from the deliver service of an orderer
+func NewOrdererSource(name string, conn *grpc.ClientConn, identity *fabricclient.Identity, tlsCertHash []byte) *DeliverSource {
+	return &DeliverSource{name: name, conn: conn, identity: identity, tlsCertHash: tlsCertHash}
+}
+
+//Name -- name of the node
+func (d *DeliverSource) Name() string {
+	return d.name
+}
+
+//ValidatesTransactions -- true for peers
+func (d *DeliverSource) ValidatesTransactions() bool {
+	return d.peer
+}
+
+//Close -- closes the connection to the node
+func (d *DeliverSource) Close() error {
+	return d.conn.Close()
+}
+
+//Height -- fetches the newest block of the channel and returns its number plus one
+func (d *DeliverSource) Height(channel string) (uint64, error) {
+
+	var height uint64
+	newest := &orderer.SeekPosition{Type: &orderer.SeekPosition_Newest{Newest: &orderer.SeekNewest{}}}
+	err := d.deliver(channel, newest, newest, func(block *common.Block) error {
+		height = block.Header.Number + 1
+		return nil
+	})
+	return height, err
+}
+
+//Blocks -- streams the blocks from start to end to handler
+func (d *DeliverSource) Blocks(channel string, start, end uint64, handler func(*common.Block) error) error {
+	return d.deliver(channel, specified(start), specified(end), handler)
+}
+
+func specified(number uint64) *orderer.SeekPosition {
+	return &orderer.SeekPosition{Type: &orderer.SeekPosition_Specified{Specified: &orderer.SeekSpecified{Number: number}}}
+}
+
+//deliver -- sends a seek request and hands every received block to handler until the node reports a status
+func (d *DeliverSource) deliver(channel string, start, stop *orderer.SeekPosition, handler func(*common.Block) error) error {
+
+	seekInfo := &orderer.SeekInfo{Start: start, Stop: stop, Behavior: orderer.SeekInfo_FAIL_IF_NOT_READY}
+	envelope, err := protoutil.CreateSignedEnvelopeWithTLSBinding(common.HeaderType_DELIVER_SEEK_INFO, channel, d.identity, seekInfo, 0, 0, d.tlsCertHash)
+	if err != nil {
+		return errors.Wrap(err, "failed to create deliver envelope")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var recv func() (*common.Block, *common.Status, error)
+	if d.peer {
+		stream, err := peer.NewDeliverClient(d.conn).Deliver(ctx)
+		if err != nil {
+			return errors.Wrapf(err, "failed to connect to deliver service of %s", d.name)
+		}
+		if err := stream.Send(envelope); err != nil {
+			return errors.Wrapf(err, "failed to send deliver request to %s", d.name)
+		}
+		recv = func() (*common.Block, *common.Status, error) {
+			response, err := stream.Recv()
+			if err != nil {
+				return nil, nil, err
+			}
+			switch t := response.Type.(type) {
+			case *peer.DeliverResponse_Block:
+				return t.Block, nil, nil
+			case *peer.DeliverResponse_Status:
+				return nil, &t.Status, nil
+			}
+			return nil, nil, errors.Errorf("unexpected deliver response %T", response.Type)
+		}
+	} else {
+		stream, err := orderer.NewAtomicBroadcastClient(d.conn).Deliver(ctx)
+		if err != nil {
+			return errors.Wrapf(err, "failed to connect to deliver service of %s", d.name)
+		}
+		if err := stream.Send(envelope); err != nil {
+			return errors.Wrapf(err, "failed to send deliver request to %s", d.name)
+		}
+		recv = func() (*common.Block, *common.Status, error) {
+			response, err := stream.Recv()
+			if err != nil {
+				return nil, nil, err
+			}
+			switch t := response.Type.(type) {
+			case *orderer.DeliverResponse_Block:
+				return t.Block, nil, nil
+			case *orderer.DeliverResponse_Status:
+				return nil, &t.Status, nil
+			}
+			return nil, nil, errors.Errorf("unexpected deliver response %T", response.Type)
+		}
+	}
+	for {
+		block, status, err := recv()
+		if err != nil {
+			return errors.Wrapf(err, "failed to receive blocks of channel %s from %s", channel, d.name)
+		}
+		if status != nil {
+			switch *status {
+			case common.Status_SUCCESS:
+				return nil
+			case common.Status_NOT_FOUND:
+				return &ChannelNotServedError{Node: d.name, Channel: channel, Status: *status}
+			case common.Status_FORBIDDEN:
+				return errors.Errorf("%s denied access to the blocks of channel %s, check the identity used and the ACLs of the channel", d.name, channel)
+			default:
+				return errors.Errorf("%s returned %s while delivering blocks of channel %s", d.name, *status, channel)
+			}
+		}
+		if err := handler(block); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,265 @@
+package ledger
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-test/tools/operator/logger"
+	"github.com/hyperledger/fabric/protoutil"
+	"github.com/pkg/errors"
+)
+
+const (
+	blockBatchSize = 100
+	heightRetries  = 3
+)
+
+var heightRetryPeriod = 3 * time.Second
+
+//Divergence -- first block of a channel at which a node differs from the reference node
+type Divergence struct {
+	Channel       string
+	BlockNumber   uint64
+	Node          string
+	ReferenceNode string
+	Field         string
+	Expected      string
+	Actual        string
+}
+
+func (d *Divergence) Error() string {
+	return fmt.Sprintf("channel %s diverges at block %d: %s of %s is %s, %s has %s", d.Channel, d.BlockNumber, d.Field, d.Node, d.Actual, d.ReferenceNode, d.Expected)
+}
+
+//HeightMismatchError -- the nodes of a channel did not reach the same height
+type HeightMismatchError struct {
+	Channel string
+	Heights map[string]uint64
+}
+
+func (e *HeightMismatchError) Error() string {
+	var heights []string
+	for node, height := range e.Heights {
+		heights = append(heights, fmt.Sprintf("%s=%d", node, height))
+	}
+	sort.Strings(heights)
+	return fmt.Sprintf("nodes of channel %s are at different heights: %s", e.Channel, strings.Join(heights, ", "))
+}
+
+//UnservedChannelError -- nodes expected on a channel do not serve it while other nodes do
+type UnservedChannelError struct {
+	Channel string
+	Nodes   []string
+}
+
+func (e *UnservedChannelError) Error() string {
+	return fmt.Sprintf("channel %s is not served by %s", e.Channel, strings.Join(e.Nodes, ", "))
+}
+
+//ChannelResult -- outcome of verifying a channel
+type ChannelResult struct {
+	Channel string
+	Height  uint64
+	Nodes   []string
+	Skipped []string
+}
+
+//VerifyChannel -- compares every block of the channel across the sources and returns the first divergence. Sources
+//that do not serve the channel are skipped unless they are expected to serve it. A channel no source serves is not
+//verified
+func VerifyChannel(channel string, sources []BlockSource, expected []string) (ChannelResult, error) {
+
+	result := ChannelResult{Channel: channel}
+	heights, serving, err := channelHeights(channel, sources)
+	if err != nil {
+		return result, err
+	}
+	var unserved []string
+	for _, source := range sources {
+		if _, ok := heights[source.Name()]; !ok {
+			result.Skipped = append(result.Skipped, source.Name())
+			if contains(expected, source.Name()) {
+				unserved = append(unserved, source.Name())
+			}
+		}
+	}
+	if len(serving) == 0 {
+		return result, nil
+	}
+	if len(unserved) > 0 {
+		return result, &UnservedChannelError{Channel: channel, Nodes: unserved}
+	}
+	for _, source := range serving {
+		result.Nodes = append(result.Nodes, source.Name())
+	}
+	result.Height = heights[serving[0].Name()]
+	for start := uint64(0); start < result.Height; start += blockBatchSize {
+		end := start + blockBatchSize - 1
+		if end >= result.Height {
+			end = result.Height - 1
+		}
+		blocks, err := fetchBlocks(channel, serving, start, end)
+		if err != nil {
+			return result, err
+		}
+		for offset := uint64(0); offset <= end-start; offset++ {
+			if err := compareBlocks(channel, start+offset, serving, blocks, offset); err != nil {
+				return result, err
+			}
+		}
+	}
+	return result, nil
+}
+
+//channelHeights -- waits for the serving nodes to reach the same height
+func channelHeights(channel string, sources []BlockSource) (map[string]uint64, []BlockSource, error) {
+
+	var heights map[string]uint64
+	var serving []BlockSource
+	for attempt := 0; ; attempt++ {
+		heights = make(map[string]uint64)
+		serving = nil
+		for _, source := range sources {
+			height, err := source.Height(channel)
+			if _, ok := err.(*ChannelNotServedError); ok {
+				logger.WARNING(err.Error())
+				continue
+			}
+			if err != nil {
+				return nil, nil, err
+			}
+			heights[source.Name()] = height
+			serving = append(serving, source)
+		}
+		if inSync(heights) {
+			return heights, serving, nil
+		}
+		if attempt == heightRetries {
+			return nil, nil, &HeightMismatchError{Channel: channel, Heights: heights}
+		}
+		logger.INFO(fmt.Sprintf("Nodes of channel %s are at different heights, waiting for %s", channel, heightRetryPeriod))
+		time.Sleep(heightRetryPeriod)
+	}
+}
+
+func contains(list []string, item string) bool {
+	for _, element := range list {
+		if element == item {
+			return true
+		}
+	}
+	return false
+}
+
+func inSync(heights map[string]uint64) bool {
+	var reference uint64
+	first := true
+	for _, height := range heights {
+		if first {
+			reference = height
+			first = false
+		} else if height != reference {
+			return false
+		}
+	}
+	return true
+}
+
+//fetchBlocks -- fetches the blocks from start to end from every source in parallel
+func fetchBlocks(channel string, sources []BlockSource, start, end uint64) ([][]*common.Block, error) {
+
+	var wg sync.WaitGroup
+	blocks := make([][]*common.Block, len(sources))
+	errs := make([]error, len(sources))
+	for index := range sources {
+		wg.Add(1)
+		go func(index int) {
+			defer wg.Done()
+			errs[index] = sources[index].Blocks(channel, start, end, func(block *common.Block) error {
+				blocks[index] = append(blocks[index], block)
+				return nil
+			})
+		}(index)
+	}
+	wg.Wait()
+	for index, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+		if uint64(len(blocks[index])) != end-start+1 {
+			return nil, errors.Errorf("%s returned %d blocks of channel %s for range %d-%d", sources[index].Name(), len(blocks[index]), channel, start, end)
+		}
+	}
+	return blocks, nil
+}
+
+//compareBlocks -- compares the block at offset of every source with the first source
+func compareBlocks(channel string, number uint64, sources []BlockSource, blocks [][]*common.Block, offset uint64) error {
+
+	reference := blocks[0][offset]
+	var validatingReference int = -1
+	for index, source := range sources {
+		if source.ValidatesTransactions() {
+			validatingReference = index
+			break
+		}
+	}
+	for index := range sources {
+		block := blocks[index][offset]
+		divergence := &Divergence{Channel: channel, BlockNumber: number, Node: sources[index].Name(), ReferenceNode: sources[0].Name()}
+		if block.Header.Number != number {
+			divergence.Field, divergence.Expected, divergence.Actual = "block number", fmt.Sprint(number), fmt.Sprint(block.Header.Number)
+			return divergence
+		}
+		if expected, actual := protoutil.BlockDataHash(reference.Data), protoutil.BlockDataHash(block.Data); !bytes.Equal(expected, actual) {
+			divergence.Field, divergence.Expected, divergence.Actual = "data hash", hex.EncodeToString(expected), hex.EncodeToString(actual)
+			return divergence
+		}
+		if expected, actual := protoutil.BlockHeaderHash(reference.Header), protoutil.BlockHeaderHash(block.Header); !bytes.Equal(expected, actual) {
+			divergence.Field, divergence.Expected, divergence.Actual = "header hash", hex.EncodeToString(expected), hex.EncodeToString(actual)
+			return divergence
+		}
+		if !sources[index].ValidatesTransactions() || index == validatingReference {
+			continue
+		}
+		expected := transactionsFilter(blocks[validatingReference][offset])
+		actual := transactionsFilter(block)
+		if !bytes.Equal(expected, actual) {
+			divergence.ReferenceNode = sources[validatingReference].Name()
+			divergence.Field, divergence.Expected, divergence.Actual = "validation codes", hex.EncodeToString(expected), hex.EncodeToString(actual)
+			return divergence
+		}
+	}
+	return nil
+}
+
+func transactionsFilter(block *common.Block) []byte {
+	if block.Metadata == nil || len(block.Metadata.Metadata) <= int(common.BlockMetadataIndex_TRANSACTIONS_FILTER) {
+		return nil
+	}
+	return block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER]
+}
+
+//LatestBlockHash -- hex encoded header hash of the newest block of the channel on the node
+func LatestBlockHash(source BlockSource, channel string) (string, error) {
+
+	var hash string
+	height, err := source.Height(channel)
+	if err != nil {
+		return "", err
+	}
+	if height == 0 {
+		return "", nil
+	}
+	err = source.Blocks(channel, height-1, height-1, func(block *common.Block) error {
+		hash = hex.EncodeToString(protoutil.BlockHeaderHash(block.Header))
+		return nil
+	})
+	return hash, err
+}
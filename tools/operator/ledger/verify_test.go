@@ -0,0 +1,218 @@
+package ledger
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric/protoutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//fakeSource -- a node serving blocks, or failing every read with err
+type fakeSource struct {
+	name   string
+	peer   bool
+	blocks []*common.Block
+	err    error
+}
+
+func (f *fakeSource) Name() string                { return f.name }
+func (f *fakeSource) ValidatesTransactions() bool { return f.peer }
+func (f *fakeSource) Close() error                { return nil }
+
+func (f *fakeSource) Height(channel string) (uint64, error) {
+	if f.err != nil {
+		return 0, f.err
+	}
+	return uint64(len(f.blocks)), nil
+}
+
+func (f *fakeSource) Blocks(channel string, start, end uint64, handler func(*common.Block) error) error {
+	if f.err != nil {
+		return f.err
+	}
+	for number := start; number <= end && number < uint64(len(f.blocks)); number++ {
+		if err := handler(f.blocks[number]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//chain -- blocks linked by their previous hashes, the transactions filter of every block set to filter
+func chain(height int, filter byte) []*common.Block {
+
+	var blocks []*common.Block
+	var previousHash []byte
+	for number := 0; number < height; number++ {
+		block := protoutil.NewBlock(uint64(number), previousHash)
+		block.Data.Data = [][]byte{{byte(number)}}
+		block.Header.DataHash = protoutil.BlockDataHash(block.Data)
+		block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER] = []byte{filter}
+		previousHash = protoutil.BlockHeaderHash(block.Header)
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
+//copyChain -- a deep copy of the blocks, so that a source can diverge from the others
+func copyChain(blocks []*common.Block) []*common.Block {
+	var copied []*common.Block
+	for _, block := range blocks {
+		copied = append(copied, proto.Clone(block).(*common.Block))
+	}
+	return copied
+}
+
+func TestVerifyChannel(t *testing.T) {
+
+	heightRetryPeriod = time.Millisecond
+	blocks := chain(150, 0)
+	notServed := &ChannelNotServedError{Node: "peer1-org2", Channel: "testorgschannel0", Status: common.Status_NOT_FOUND}
+	dataDiverged := copyChain(blocks)
+	dataDiverged[120].Data.Data = [][]byte{[]byte("forged")}
+	headerDiverged := copyChain(blocks)
+	headerDiverged[7].Header.PreviousHash = []byte("forged")
+	filterDiverged := copyChain(blocks)
+	filterDiverged[3].Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER] = []byte{11}
+
+	tests := []struct {
+		name     string
+		sources  []BlockSource
+		expected []string
+		height   uint64
+		nodes    []string
+		skipped  []string
+		diverges *Divergence
+		err      string
+	}{
+		{
+			name: "in sync",
+			sources: []BlockSource{
+				&fakeSource{name: "orderer0-ordererorg1", blocks: blocks},
+				&fakeSource{name: "peer0-org1", peer: true, blocks: blocks},
+				&fakeSource{name: "peer0-org2", peer: true, blocks: blocks},
+			},
+			expected: []string{"orderer0-ordererorg1", "peer0-org1", "peer0-org2"},
+			height:   150,
+			nodes:    []string{"orderer0-ordererorg1", "peer0-org1", "peer0-org2"},
+		},
+		{
+			name: "height mismatch",
+			sources: []BlockSource{
+				&fakeSource{name: "orderer0-ordererorg1", blocks: blocks},
+				&fakeSource{name: "peer0-org1", peer: true, blocks: blocks[:149]},
+			},
+			err: "nodes of channel testorgschannel0 are at different heights: orderer0-ordererorg1=150, peer0-org1=149",
+		},
+		{
+			name: "data hash divergence",
+			sources: []BlockSource{
+				&fakeSource{name: "orderer0-ordererorg1", blocks: blocks},
+				&fakeSource{name: "peer0-org1", peer: true, blocks: dataDiverged},
+			},
+			diverges: &Divergence{BlockNumber: 120, Node: "peer0-org1", ReferenceNode: "orderer0-ordererorg1", Field: "data hash"},
+		},
+		{
+			name: "header hash divergence",
+			sources: []BlockSource{
+				&fakeSource{name: "orderer0-ordererorg1", blocks: blocks},
+				&fakeSource{name: "peer0-org1", peer: true, blocks: headerDiverged},
+			},
+			diverges: &Divergence{BlockNumber: 7, Node: "peer0-org1", ReferenceNode: "orderer0-ordererorg1", Field: "header hash"},
+		},
+		{
+			name: "validation codes divergence",
+			sources: []BlockSource{
+				&fakeSource{name: "orderer0-ordererorg1", blocks: filterDiverged},
+				&fakeSource{name: "peer0-org1", peer: true, blocks: blocks},
+				&fakeSource{name: "peer0-org2", peer: true, blocks: filterDiverged},
+			},
+			diverges: &Divergence{BlockNumber: 3, Node: "peer0-org2", ReferenceNode: "peer0-org1", Field: "validation codes", Expected: "00", Actual: "0b"},
+		},
+		{
+			name: "not served by an unexpected node",
+			sources: []BlockSource{
+				&fakeSource{name: "peer0-org1", peer: true, blocks: blocks},
+				&fakeSource{name: "peer1-org2", peer: true, err: notServed},
+			},
+			expected: []string{"peer0-org1"},
+			height:   150,
+			nodes:    []string{"peer0-org1"},
+			skipped:  []string{"peer1-org2"},
+		},
+		{
+			name: "not served by an expected node",
+			sources: []BlockSource{
+				&fakeSource{name: "peer0-org1", peer: true, blocks: blocks},
+				&fakeSource{name: "peer1-org2", peer: true, err: notServed},
+			},
+			expected: []string{"peer0-org1", "peer1-org2"},
+			skipped:  []string{"peer1-org2"},
+			err:      "channel testorgschannel0 is not served by peer1-org2",
+		},
+		{
+			name: "not served by any node",
+			sources: []BlockSource{
+				&fakeSource{name: "orderer0-ordererorg1", err: notServed},
+				&fakeSource{name: "peer1-org2", peer: true, err: notServed},
+			},
+			expected: []string{"orderer0-ordererorg1", "peer1-org2"},
+			skipped:  []string{"orderer0-ordererorg1", "peer1-org2"},
+		},
+		{
+			name: "access denied",
+			sources: []BlockSource{
+				&fakeSource{name: "peer0-org1", peer: true, blocks: blocks},
+				&fakeSource{name: "peer1-org2", peer: true, err: errors.New("peer1-org2 denied access to the blocks of channel testorgschannel0")},
+			},
+			err: "peer1-org2 denied access to the blocks of channel testorgschannel0",
+		},
+	}
+	for _, test := range tests {
+		result, err := VerifyChannel("testorgschannel0", test.sources, test.expected)
+		assert.Equal(t, test.skipped, result.Skipped, test.name)
+		if test.diverges != nil {
+			var divergence *Divergence
+			require.True(t, errors.As(err, &divergence), test.name)
+			assert.Equal(t, "testorgschannel0", divergence.Channel, test.name)
+			assert.Equal(t, test.diverges.BlockNumber, divergence.BlockNumber, test.name)
+			assert.Equal(t, test.diverges.Node, divergence.Node, test.name)
+			assert.Equal(t, test.diverges.ReferenceNode, divergence.ReferenceNode, test.name)
+			assert.Equal(t, test.diverges.Field, divergence.Field, test.name)
+			if test.diverges.Expected != "" {
+				assert.Equal(t, test.diverges.Expected, divergence.Expected, test.name)
+				assert.Equal(t, test.diverges.Actual, divergence.Actual, test.name)
+			}
+			continue
+		}
+		if test.err != "" {
+			assert.EqualError(t, err, test.err, test.name)
+			continue
+		}
+		require.NoError(t, err, test.name)
+		assert.Equal(t, test.height, result.Height, test.name)
+		assert.Equal(t, test.nodes, result.Nodes, test.name)
+	}
+}
+
+func TestLatestBlockHash(t *testing.T) {
+
+	blocks := chain(3, 0)
+	hash, err := LatestBlockHash(&fakeSource{name: "peer0-org1", blocks: blocks}, "testorgschannel0")
+	require.NoError(t, err)
+	assert.Equal(t, hex.EncodeToString(protoutil.BlockHeaderHash(blocks[2].Header)), hash)
+
+	hash, err = LatestBlockHash(&fakeSource{name: "peer0-org1"}, "testorgschannel0")
+	require.NoError(t, err)
+	assert.Empty(t, hash)
+
+	notServed := &ChannelNotServedError{Node: "peer0-org1", Channel: "testorgschannel0", Status: common.Status_NOT_FOUND}
+	_, err = LatestBlockHash(&fakeSource{name: "peer0-org1", err: notServed}, "testorgschannel0")
+	assert.EqualError(t, err, "peer0-org1 does not serve channel testorgschannel0: NOT_FOUND")
+}
@@ -0,0 +1,166 @@
+package lifecycle
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+//Connection -- connection.json of a chaincode package of type ccaas: the address the peers reach the chaincode server
+//at and, with TLS, the CA of the server and the client certificate of the peers as PEM
+type Connection struct {
+	Address            string `json:"address"`
+	DialTimeout        string `json:"dial_timeout"`
+	TLSRequired        bool   `json:"tls_required"`
+	ClientAuthRequired bool   `json:"client_auth_required"`
+	ClientKey          string `json:"client_key,omitempty"`
+	ClientCert         string `json:"client_cert,omitempty"`
+	RootCert           string `json:"root_cert,omitempty"`
+}
+
+//NewServicePackage -- the package of a chaincode run as a service, for the ccaas_builder of the peers: metadata.json
+//of type ccaas and connection.json in code.tar.gz
+func NewServicePackage(label string, connection Connection) (Package, error) {
+
+	connectionBytes, err := json.Marshal(connection)
+	if err != nil {
+		return Package{}, err
+	}
+	code, err := tarGz([]tarEntry{{name: "connection.json", contents: connectionBytes}})
+	if err != nil {
+		return Package{}, err
+	}
+	metadataBytes, err := json.Marshal(packageMetadata{Type: "ccaas", Label: label})
+	if err != nil {
+		return Package{}, err
+	}
+	contents, err := tarGz([]tarEntry{{name: "metadata.json", contents: metadataBytes}, {name: "code.tar.gz", contents: code}})
+	if err != nil {
+		return Package{}, err
+	}
+	return Package{Label: label, Bytes: contents}, nil
+}
+
+//ServiceTLS -- the TLS of a chaincode server and of the peers connecting to it, issued by a CA of their own. The
+//files are ca.crt, server.crt and server.key for the server and client.crt and client.key for the peers
+type ServiceTLS struct {
+	Dir        string
+	CACert     []byte
+	ClientCert []byte
+	ClientKey  []byte
+}
+
+//serviceTLSFiles -- the files of a ServiceTLS directory
+var serviceTLSFiles = []string{"ca.crt", "server.crt", "server.key", "client.crt", "client.key"}
+
+//LoadServiceTLS -- reads the TLS of the chaincode server host from dir, generating it on first use. Installing the
+//same chaincode again then gives the same package, as connection.json keeps the same certificates
+func LoadServiceTLS(dir, host string) (ServiceTLS, error) {
+
+	_, err := os.Stat(filepath.Join(dir, "ca.crt"))
+	if os.IsNotExist(err) {
+		err = generateServiceTLS(dir, host)
+	}
+	if err != nil {
+		return ServiceTLS{}, err
+	}
+	files := make(map[string][]byte)
+	for _, name := range serviceTLSFiles {
+		files[name], err = ioutil.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return ServiceTLS{}, errors.Wrapf(err, "failed to read the TLS of chaincode server %s", host)
+		}
+	}
+	return ServiceTLS{Dir: dir, CACert: files["ca.crt"], ClientCert: files["client.crt"], ClientKey: files["client.key"]}, nil
+}
+
+//generateServiceTLS -- writes a CA, a server certificate for host and a client certificate issued by the CA to dir
+func generateServiceTLS(dir, host string) error {
+
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return err
+	}
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return err
+	}
+	caTemplate := certificateTemplate(host + "-ca")
+	caTemplate.IsCA = true
+	caTemplate.BasicConstraintsValid = true
+	caTemplate.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create the CA of chaincode server %s", host)
+	}
+	caCert, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		return err
+	}
+	files := map[string][]byte{"ca.crt": pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER})}
+	for _, name := range []string{"server", "client"} {
+		template := certificateTemplate(host)
+		template.KeyUsage = x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+		if name == "server" {
+			template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+			template.DNSNames = []string{host, "localhost"}
+		}
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			return err
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, caCert, &key.PublicKey, caKey)
+		if err != nil {
+			return errors.Wrapf(err, "failed to create the %s certificate of chaincode server %s", name, host)
+		}
+		keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+		if err != nil {
+			return err
+		}
+		files[name+".crt"] = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+		files[name+".key"] = pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+	}
+	for _, name := range serviceTLSFiles {
+		err = ioutil.WriteFile(filepath.Join(dir, name), files[name], 0644)
+		if err != nil {
+			return errors.Wrapf(err, "failed to write the TLS of chaincode server %s", host)
+		}
+	}
+	return nil
+}
+
+func certificateTemplate(commonName string) *x509.Certificate {
+
+	serial, _ := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	return &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: commonName, Organization: []string{"fabric-test"}},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().AddDate(10, 0, 0),
+	}
+}
+
+//Connection -- connection.json of the chaincode server at address with this TLS
+func (t ServiceTLS) Connection(address string) Connection {
+	return Connection{
+		Address:            address,
+		DialTimeout:        "10s",
+		TLSRequired:        true,
+		ClientAuthRequired: true,
+		ClientKey:          string(t.ClientKey),
+		ClientCert:         string(t.ClientCert),
+		RootCert:           string(t.CACert),
+	}
+}
@@ -0,0 +1,316 @@
+package lifecycle
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-protos-go/orderer"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-test/tools/operator/fabricclient"
+	"github.com/hyperledger/fabric-test/tools/operator/logger"
+	"github.com/hyperledger/fabric-test/tools/operator/networkspec"
+	"github.com/hyperledger/fabric-test/tools/operator/paths"
+	"github.com/hyperledger/fabric/protoutil"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+)
+
+const (
+	lifecycleName         = "_lifecycle"
+	defaultRequestTimeout = 60 * time.Second
+	defaultInstallTimeout = 10 * time.Minute
+	defaultCommitTimeout  = 2 * time.Minute
+)
+
+//Peer -- a peer the admin of an organization sends lifecycle proposals to
+type Peer struct {
+	Name     string
+	MSPID    string
+	conn     *grpc.ClientConn
+	endorser peer.EndorserClient
+}
+
+//Org -- the admin of an organization running the chaincode lifecycle on its peers. Every Org has its own identity and
+//connections, so that the lifecycle of several organizations can run in parallel
+type Org struct {
+	Name               string
+	MSPID              string
+	Peers              []*Peer
+	RequestTimeout     time.Duration
+	InstallTimeout     time.Duration
+	CommitTimeout      time.Duration
+	identity           *fabricclient.Identity
+	connProfile        networkspec.ConnectionProfile
+	clientCertificates []tls.Certificate
+	tlsCertHash        []byte
+}
+
+//Connect -- connects the admin of an organization of a connection profile to its peers, all of them when no peer
+//names are given. With mutual tls the admin uses the client certificate of the crypto-config of the operator
+func Connect(connProfilePath, orgName string, peerNames []string, mutualTLS bool) (*Org, error) {
+
+	connProfile, err := fabricclient.ConnectionProfile(connProfilePath, orgName)
+	if err != nil {
+		return nil, err
+	}
+	organization, ok := connProfile.Organizations[orgName]
+	if !ok {
+		return nil, errors.Errorf("organization %s not found in connection profile %s", orgName, connProfilePath)
+	}
+	identity, err := fabricclient.OrganizationIdentity(organization)
+	if err != nil {
+		return nil, err
+	}
+	o := &Org{
+		Name:           orgName,
+		MSPID:          organization.MSPID,
+		RequestTimeout: defaultRequestTimeout,
+		InstallTimeout: defaultInstallTimeout,
+		CommitTimeout:  defaultCommitTimeout,
+		identity:       identity,
+		connProfile:    connProfile,
+	}
+	if mutualTLS {
+		currentDir, err := paths.GetCurrentDir()
+		if err != nil {
+			return nil, err
+		}
+		o.clientCertificates, err = fabricclient.ClientTLSCertificate(fmt.Sprintf("%s/crypto-config/peerOrganizations/%s/users/Admin@%s/tls", currentDir, orgName, orgName))
+		if err != nil {
+			return nil, err
+		}
+		o.tlsCertHash = fabricclient.TLSCertHash(o.clientCertificates)
+	}
+	if len(peerNames) == 0 {
+		peerNames = organization.Peers
+	}
+	for _, peerName := range peerNames {
+		peerInfo, ok := connProfile.Peers[peerName]
+		if !ok {
+			o.Close()
+			return nil, errors.Errorf("peer %s not found in connection profile of %s", peerName, orgName)
+		}
+		conn, err := fabricclient.Dial(peerInfo.URL, peerInfo.GrpcOptions.SslTarget, peerInfo.TLSCACerts.Pem, o.clientCertificates)
+		if err != nil {
+			o.Close()
+			return nil, err
+		}
+		o.Peers = append(o.Peers, &Peer{Name: peerName, MSPID: o.MSPID, conn: conn, endorser: peer.NewEndorserClient(conn)})
+	}
+	if len(o.Peers) == 0 {
+		return nil, errors.Errorf("no peers of organization %s in its connection profile", orgName)
+	}
+	return o, nil
+}
+
+//Close -- closes the connections to the peers
+func (o *Org) Close() {
+	for _, p := range o.Peers {
+		p.conn.Close()
+	}
+}
+
+//proposal -- a signed proposal of the admin calling a function of _lifecycle on a channel, or on the peer when the
+//channel is empty
+func (o *Org) proposal(channel, function string, args proto.Message) (*peer.Proposal, *peer.SignedProposal, string, error) {
+
+	argBytes, err := proto.Marshal(args)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	creator, err := o.identity.Serialize()
+	if err != nil {
+		return nil, nil, "", err
+	}
+	spec := &peer.ChaincodeInvocationSpec{
+		ChaincodeSpec: &peer.ChaincodeSpec{
+			Type:        peer.ChaincodeSpec_GOLANG,
+			ChaincodeId: &peer.ChaincodeID{Name: lifecycleName},
+			Input:       &peer.ChaincodeInput{Args: [][]byte{[]byte(function), argBytes}},
+		},
+	}
+	proposal, txID, err := protoutil.CreateChaincodeProposal(common.HeaderType_ENDORSER_TRANSACTION, channel, spec, creator)
+	if err != nil {
+		return nil, nil, "", errors.Wrapf(err, "failed to create %s proposal", function)
+	}
+	signedProposal, err := protoutil.GetSignedProposal(proposal, o.identity)
+	if err != nil {
+		return nil, nil, "", errors.Wrapf(err, "failed to sign %s proposal", function)
+	}
+	return proposal, signedProposal, txID, nil
+}
+
+//endorse -- sends a signed proposal to peers in parallel and returns their responses
+func endorse(signedProposal *peer.SignedProposal, function string, peers []*Peer, timeout time.Duration) ([]*peer.ProposalResponse, error) {
+
+	responses := make([]*peer.ProposalResponse, len(peers))
+	errs := make([]string, len(peers))
+	var wg sync.WaitGroup
+	for index := range peers {
+		wg.Add(1)
+		go func(index int) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+			response, err := peers[index].endorser.ProcessProposal(ctx, signedProposal)
+			switch {
+			case err != nil:
+				errs[index] = fmt.Sprintf("%s: %s", peers[index].Name, err)
+			case response.Response == nil || response.Response.Status < 200 || response.Response.Status >= 400:
+				errs[index] = fmt.Sprintf("%s: %d %s", peers[index].Name, response.GetResponse().GetStatus(), response.GetResponse().GetMessage())
+			default:
+				responses[index] = response
+			}
+		}(index)
+	}
+	wg.Wait()
+	var failures []string
+	for _, err := range errs {
+		if err != "" {
+			failures = append(failures, err)
+		}
+	}
+	if len(failures) > 0 {
+		return nil, errors.Errorf("%s failed on %s", function, strings.Join(failures, "; "))
+	}
+	return responses, nil
+}
+
+//query -- calls a function of _lifecycle on a peer and unmarshals its result
+func (o *Org) query(p *Peer, channel, function string, args, result proto.Message, timeout time.Duration) error {
+
+	_, signedProposal, _, err := o.proposal(channel, function, args)
+	if err != nil {
+		return err
+	}
+	responses, err := endorse(signedProposal, function, []*Peer{p}, timeout)
+	if err != nil {
+		return err
+	}
+	err = proto.Unmarshal(responses[0].Response.Payload, result)
+	if err != nil {
+		return errors.Wrapf(err, "failed to unmarshal the result of %s from %s", function, p.Name)
+	}
+	return nil
+}
+
+//submit -- endorses a _lifecycle transaction on peers, orders it and waits until it is committed on the first peer
+func (o *Org) submit(channel, function string, args proto.Message, peers []*Peer) (string, error) {
+
+	proposal, signedProposal, txID, err := o.proposal(channel, function, args)
+	if err != nil {
+		return "", err
+	}
+	responses, err := endorse(signedProposal, function, peers, o.RequestTimeout)
+	if err != nil {
+		return txID, err
+	}
+	envelope, err := protoutil.CreateSignedTx(proposal, o.identity, responses...)
+	if err != nil {
+		return txID, errors.Wrapf(err, "failed to create %s transaction", function)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), o.CommitTimeout)
+	defer cancel()
+	committed, err := o.commitEvent(ctx, peers[0], channel, txID)
+	if err != nil {
+		return txID, err
+	}
+	err = o.broadcast(channel, envelope)
+	if err != nil {
+		return txID, err
+	}
+	select {
+	case err = <-committed:
+		return txID, err
+	case <-ctx.Done():
+		return txID, errors.Errorf("timed out waiting for %s transaction %s to be committed on %s", function, txID, peers[0].Name)
+	}
+}
+
+//commitEvent -- listens to the filtered blocks of a channel on a peer for the validation code of a transaction
+func (o *Org) commitEvent(ctx context.Context, p *Peer, channel, txID string) (<-chan error, error) {
+
+	seekInfo := &orderer.SeekInfo{
+		Start:    &orderer.SeekPosition{Type: &orderer.SeekPosition_Newest{Newest: &orderer.SeekNewest{}}},
+		Stop:     &orderer.SeekPosition{Type: &orderer.SeekPosition_Specified{Specified: &orderer.SeekSpecified{Number: ^uint64(0)}}},
+		Behavior: orderer.SeekInfo_BLOCK_UNTIL_READY,
+	}
+	envelope, err := protoutil.CreateSignedEnvelopeWithTLSBinding(common.HeaderType_DELIVER_SEEK_INFO, channel, o.identity, seekInfo, 0, 0, o.tlsCertHash)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create deliver envelope")
+	}
+	stream, err := peer.NewDeliverClient(p.conn).DeliverFiltered(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to connect to the event service of %s", p.Name)
+	}
+	err = stream.Send(envelope)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to register for events on %s", p.Name)
+	}
+	committed := make(chan error, 1)
+	go func() {
+		for {
+			response, err := stream.Recv()
+			if err != nil {
+				committed <- errors.Wrapf(err, "event service of %s failed", p.Name)
+				return
+			}
+			switch r := response.Type.(type) {
+			case *peer.DeliverResponse_Status:
+				committed <- errors.Errorf("event service of %s returned %s", p.Name, r.Status)
+				return
+			case *peer.DeliverResponse_FilteredBlock:
+				for _, tx := range r.FilteredBlock.FilteredTransactions {
+					if tx.Txid != txID {
+						continue
+					}
+					if tx.TxValidationCode != peer.TxValidationCode_VALID {
+						committed <- errors.Errorf("transaction %s was committed on %s with status %s", txID, p.Name, tx.TxValidationCode)
+						return
+					}
+					committed <- nil
+					return
+				}
+			}
+		}
+	}()
+	return committed, nil
+}
+
+//broadcast -- sends a transaction to the orderers of the channel in the connection profile, one after the other until
+//one accepts it
+func (o *Org) broadcast(channel string, envelope *common.Envelope) error {
+
+	ordererNames := o.connProfile.Channels[channel].Orderers
+	if len(ordererNames) == 0 {
+		for ordererName := range o.connProfile.Orderers {
+			ordererNames = append(ordererNames, ordererName)
+		}
+		sort.Strings(ordererNames)
+	}
+	if len(ordererNames) == 0 {
+		return errors.Errorf("no orderers of channel %s in the connection profile of %s", channel, o.Name)
+	}
+	var err error
+	for _, ordererName := range ordererNames {
+		ordererInfo := o.connProfile.Orderers[ordererName]
+		var conn *grpc.ClientConn
+		conn, err = fabricclient.Dial(ordererInfo.URL, ordererInfo.GrpcOptions.SslTarget, ordererInfo.TLSCACerts.Pem, o.clientCertificates)
+		if err == nil {
+			err = fabricclient.Broadcast(conn, envelope)
+			conn.Close()
+		}
+		if err == nil {
+			return nil
+		}
+		logger.WARNING(fmt.Sprintf("Failed to send the transaction to orderer %s: %s", ordererName, err.Error()))
+	}
+	return errors.Wrapf(err, "no orderer of channel %s accepted the transaction", channel)
+}
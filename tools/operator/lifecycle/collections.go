@@ -0,0 +1,108 @@
+package lifecycle
+
+import (
+	"encoding/json"
+	"io/ioutil"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-test/tools/operator/policy"
+	"github.com/pkg/errors"
+)
+
+//collectionConfig -- a private data collection in the collections config files of the peer cli
+type collectionConfig struct {
+	Name              string `json:"name"`
+	Policy            string `json:"policy"`
+	RequiredPeerCount int32  `json:"requiredPeerCount"`
+	MaxPeerCount      int32  `json:"maxPeerCount"`
+	BlockToLive       uint64 `json:"blockToLive"`
+	MemberOnlyRead    bool   `json:"memberOnlyRead"`
+	MemberOnlyWrite   bool   `json:"memberOnlyWrite"`
+	EndorsementPolicy *struct {
+		SignaturePolicy     string `json:"signaturePolicy"`
+		ChannelConfigPolicy string `json:"channelConfigPolicy"`
+	} `json:"endorsementPolicy"`
+}
+
+//Collections -- reads the private data collections of a chaincode definition from a collections config file of the
+//peer cli. Their policies are Fabric policy DSL expressions of MSP IDs
+func Collections(path string) (*peer.CollectionConfigPackage, error) {
+
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read collections config %s", path)
+	}
+	var configs []collectionConfig
+	err = json.Unmarshal(contents, &configs)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse collections config %s", path)
+	}
+	collections := &peer.CollectionConfigPackage{}
+	for _, config := range configs {
+		memberOrgsPolicy, err := signaturePolicy(config.Policy)
+		if err != nil {
+			return nil, errors.Wrapf(err, "policy of collection %s", config.Name)
+		}
+		staticConfig := &peer.StaticCollectionConfig{
+			Name:              config.Name,
+			MemberOrgsPolicy:  &peer.CollectionPolicyConfig{Payload: &peer.CollectionPolicyConfig_SignaturePolicy{SignaturePolicy: memberOrgsPolicy}},
+			RequiredPeerCount: config.RequiredPeerCount,
+			MaximumPeerCount:  config.MaxPeerCount,
+			BlockToLive:       config.BlockToLive,
+			MemberOnlyRead:    config.MemberOnlyRead,
+			MemberOnlyWrite:   config.MemberOnlyWrite,
+		}
+		if config.EndorsementPolicy != nil {
+			staticConfig.EndorsementPolicy, err = applicationPolicy(config.EndorsementPolicy.SignaturePolicy, config.EndorsementPolicy.ChannelConfigPolicy)
+			if err != nil {
+				return nil, errors.Wrapf(err, "endorsement policy of collection %s", config.Name)
+			}
+		}
+		collections.Config = append(collections.Config, &peer.CollectionConfig{
+			Payload: &peer.CollectionConfig_StaticCollectionConfig{StaticCollectionConfig: staticConfig},
+		})
+	}
+	return collections, nil
+}
+
+//signaturePolicy -- the envelope of a Fabric policy DSL expression of MSP IDs
+func signaturePolicy(expression string) (*common.SignaturePolicyEnvelope, error) {
+
+	parsed, err := policy.Parse(expression)
+	if err != nil {
+		return nil, err
+	}
+	return parsed.Envelope()
+}
+
+//applicationPolicy -- the endorsement policy of a chaincode or collection: a signature policy, a reference to a
+//policy of the channel config, or nil for the default endorsement policy of the channel
+func applicationPolicy(signature, channelConfigPolicy string) (*peer.ApplicationPolicy, error) {
+
+	switch {
+	case signature != "" && channelConfigPolicy != "":
+		return nil, errors.New("either a signature policy or a channel config policy can be given, not both")
+	case signature != "":
+		envelope, err := signaturePolicy(signature)
+		if err != nil {
+			return nil, err
+		}
+		return &peer.ApplicationPolicy{Type: &peer.ApplicationPolicy_SignaturePolicy{SignaturePolicy: envelope}}, nil
+	case channelConfigPolicy != "":
+		return &peer.ApplicationPolicy{Type: &peer.ApplicationPolicy_ChannelConfigPolicyReference{ChannelConfigPolicyReference: channelConfigPolicy}}, nil
+	}
+	return nil, nil
+}
+
+//validationParameter -- the marshaled endorsement policy of a chaincode definition, nil for the default endorsement
+//policy of the channel
+func validationParameter(signature, channelConfigPolicy string) ([]byte, error) {
+
+	applicationPolicy, err := applicationPolicy(signature, channelConfigPolicy)
+	if err != nil || applicationPolicy == nil {
+		return nil, err
+	}
+	return proto.Marshal(applicationPolicy)
+}
@@ -0,0 +1,224 @@
+package lifecycle
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/hyperledger/fabric-protos-go/peer"
+	lb "github.com/hyperledger/fabric-protos-go/peer/lifecycle"
+	"github.com/pkg/errors"
+)
+
+//Definition -- a chaincode definition as approved by organizations and committed to a channel. Policy is a Fabric
+//policy DSL expression of MSP IDs; without Policy and ChannelConfigPolicy the chaincode gets the default endorsement
+//policy of the channel
+type Definition struct {
+	Name                string
+	Version             string
+	Sequence            int64
+	Policy              string
+	ChannelConfigPolicy string
+	Collections         *peer.CollectionConfigPackage
+	InitRequired        bool
+	EndorsementPlugin   string
+	ValidationPlugin    string
+}
+
+//Installed -- a chaincode package installed on a peer
+type Installed struct {
+	Peer      string
+	PackageID string
+	Label     string
+}
+
+//Approval -- the approval of a chaincode definition by an organization
+type Approval struct {
+	Org       string
+	MSPID     string
+	PackageID string
+	TxID      string
+}
+
+//Committed -- a chaincode definition committed to a channel
+type Committed struct {
+	Channel string
+	TxID    string
+}
+
+//Install -- installs a chaincode package on all peers of the organization in parallel
+func (o *Org) Install(pkg Package) ([]Installed, error) {
+
+	installed := make([]Installed, len(o.Peers))
+	errs := make([]error, len(o.Peers))
+	var wg sync.WaitGroup
+	for index := range o.Peers {
+		wg.Add(1)
+		go func(index int) {
+			defer wg.Done()
+			result := &lb.InstallChaincodeResult{}
+			errs[index] = o.query(o.Peers[index], "", "InstallChaincode", &lb.InstallChaincodeArgs{ChaincodeInstallPackage: pkg.Bytes}, result, o.InstallTimeout)
+			installed[index] = Installed{Peer: o.Peers[index].Name, PackageID: result.PackageId, Label: result.Label}
+		}(index)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to install chaincode %s", pkg.Label)
+		}
+	}
+	return installed, nil
+}
+
+//QueryInstalled -- the chaincode packages installed on a peer of the organization
+func (o *Org) QueryInstalled(p *Peer) ([]Installed, error) {
+
+	result := &lb.QueryInstalledChaincodesResult{}
+	err := o.query(p, "", "QueryInstalledChaincodes", &lb.QueryInstalledChaincodesArgs{}, result, o.RequestTimeout)
+	if err != nil {
+		return nil, err
+	}
+	var installed []Installed
+	for _, chaincode := range result.InstalledChaincodes {
+		installed = append(installed, Installed{Peer: p.Name, PackageID: chaincode.PackageId, Label: chaincode.Label})
+	}
+	return installed, nil
+}
+
+//PackageID -- the ID of the package with a label installed on the first peer of the organization
+func (o *Org) PackageID(label string) (string, error) {
+
+	installed, err := o.QueryInstalled(o.Peers[0])
+	if err != nil {
+		return "", err
+	}
+	for _, chaincode := range installed {
+		if chaincode.Label == label {
+			return chaincode.PackageID, nil
+		}
+	}
+	return "", errors.Errorf("no chaincode with label %s installed on %s", label, o.Peers[0].Name)
+}
+
+//Approve -- approves a chaincode definition on a channel for the organization, for the package with packageID or
+//for no package when packageID is empty, and waits for the approval to be committed
+func (o *Org) Approve(channel string, definition Definition, packageID string) (Approval, error) {
+
+	parameter, err := validationParameter(definition.Policy, definition.ChannelConfigPolicy)
+	if err != nil {
+		return Approval{}, errors.Wrapf(err, "endorsement policy of chaincode %s", definition.Name)
+	}
+	source := &lb.ChaincodeSource{Type: &lb.ChaincodeSource_Unavailable_{Unavailable: &lb.ChaincodeSource_Unavailable{}}}
+	if packageID != "" {
+		source = &lb.ChaincodeSource{Type: &lb.ChaincodeSource_LocalPackage{LocalPackage: &lb.ChaincodeSource_Local{PackageId: packageID}}}
+	}
+	args := &lb.ApproveChaincodeDefinitionForMyOrgArgs{
+		Name:                definition.Name,
+		Version:             definition.Version,
+		Sequence:            definition.Sequence,
+		EndorsementPlugin:   definition.endorsementPlugin(),
+		ValidationPlugin:    definition.validationPlugin(),
+		ValidationParameter: parameter,
+		Collections:         definition.Collections,
+		InitRequired:        definition.InitRequired,
+		Source:              source,
+	}
+	txID, err := o.submit(channel, "ApproveChaincodeDefinitionForMyOrg", args, o.Peers)
+	if err != nil {
+		return Approval{}, errors.Wrapf(err, "failed to approve chaincode %s on channel %s for %s", definition.Name, channel, o.Name)
+	}
+	return Approval{Org: o.Name, MSPID: o.MSPID, PackageID: packageID, TxID: txID}, nil
+}
+
+//CheckCommitReadiness -- the organizations by MSP ID and whether they approved the chaincode definition on a channel
+func (o *Org) CheckCommitReadiness(channel string, definition Definition) (map[string]bool, error) {
+
+	parameter, err := validationParameter(definition.Policy, definition.ChannelConfigPolicy)
+	if err != nil {
+		return nil, errors.Wrapf(err, "endorsement policy of chaincode %s", definition.Name)
+	}
+	args := &lb.CheckCommitReadinessArgs{
+		Name:                definition.Name,
+		Version:             definition.Version,
+		Sequence:            definition.Sequence,
+		EndorsementPlugin:   definition.endorsementPlugin(),
+		ValidationPlugin:    definition.validationPlugin(),
+		ValidationParameter: parameter,
+		Collections:         definition.Collections,
+		InitRequired:        definition.InitRequired,
+	}
+	result := &lb.CheckCommitReadinessResult{}
+	err = o.query(o.Peers[0], channel, "CheckCommitReadiness", args, result, o.RequestTimeout)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to check commit readiness of chaincode %s on channel %s", definition.Name, channel)
+	}
+	return result.Approvals, nil
+}
+
+//Commit -- commits a chaincode definition to a channel with the endorsements of peers, which may belong to other
+//organizations, and waits for the definition to be committed
+func (o *Org) Commit(channel string, definition Definition, peers []*Peer) (Committed, error) {
+
+	parameter, err := validationParameter(definition.Policy, definition.ChannelConfigPolicy)
+	if err != nil {
+		return Committed{}, errors.Wrapf(err, "endorsement policy of chaincode %s", definition.Name)
+	}
+	if len(peers) == 0 {
+		peers = o.Peers
+	}
+	args := &lb.CommitChaincodeDefinitionArgs{
+		Name:                definition.Name,
+		Version:             definition.Version,
+		Sequence:            definition.Sequence,
+		EndorsementPlugin:   definition.endorsementPlugin(),
+		ValidationPlugin:    definition.validationPlugin(),
+		ValidationParameter: parameter,
+		Collections:         definition.Collections,
+		InitRequired:        definition.InitRequired,
+	}
+	txID, err := o.submit(channel, "CommitChaincodeDefinition", args, peers)
+	if err != nil {
+		return Committed{}, errors.Wrapf(err, "failed to commit chaincode %s on channel %s", definition.Name, channel)
+	}
+	return Committed{Channel: channel, TxID: txID}, nil
+}
+
+//QueryApproved -- the definition of a chaincode the organization approved on a channel for a sequence
+func (o *Org) QueryApproved(channel, name string, sequence int64) (*lb.QueryApprovedChaincodeDefinitionResult, error) {
+
+	result := &lb.QueryApprovedChaincodeDefinitionResult{}
+	err := o.query(o.Peers[0], channel, "QueryApprovedChaincodeDefinition", &lb.QueryApprovedChaincodeDefinitionArgs{Name: name, Sequence: sequence}, result, o.RequestTimeout)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to query the approval of chaincode %s on channel %s for %s", name, channel, o.Name)
+	}
+	return result, nil
+}
+
+//QueryCommitted -- the committed definition of a chaincode on a channel
+func (o *Org) QueryCommitted(channel, name string) (*lb.QueryChaincodeDefinitionResult, error) {
+
+	result := &lb.QueryChaincodeDefinitionResult{}
+	err := o.query(o.Peers[0], channel, "QueryChaincodeDefinition", &lb.QueryChaincodeDefinitionArgs{Name: name}, result, o.RequestTimeout)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to query chaincode %s on channel %s", name, channel)
+	}
+	return result, nil
+}
+
+func (d Definition) endorsementPlugin() string {
+	if d.EndorsementPlugin == "" {
+		return "escc"
+	}
+	return d.EndorsementPlugin
+}
+
+func (d Definition) validationPlugin() string {
+	if d.ValidationPlugin == "" {
+		return "vscc"
+	}
+	return d.ValidationPlugin
+}
+
+//String -- the name, version and sequence of the definition
+func (d Definition) String() string {
+	return fmt.Sprintf("%s %s (sequence %d)", d.Name, d.Version, d.Sequence)
+}
@@ -0,0 +1,297 @@
+package lifecycle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	for name, contents := range files {
+		path := filepath.Join(root, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, ioutil.WriteFile(path, []byte(contents), 0644))
+	}
+}
+
+//untar -- the files of a .tar.gz by name, with directories as empty entries ending with /
+func untar(t *testing.T, contents []byte) map[string]string {
+
+	gr, err := gzip.NewReader(bytes.NewReader(contents))
+	require.NoError(t, err)
+	tr := tar.NewReader(gr)
+	files := make(map[string]string)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return files
+		}
+		require.NoError(t, err)
+		data, err := ioutil.ReadAll(tr)
+		require.NoError(t, err)
+		files[header.Name] = string(data)
+	}
+}
+
+func TestNewPackageGo(t *testing.T) {
+
+	root, err := ioutil.TempDir("", "chaincode")
+	require.NoError(t, err)
+	defer os.RemoveAll(root)
+	writeFiles(t, root, map[string]string{
+		"go.mod":       "module example.com/chaincodes\n\ngo 1.14\n",
+		"util/util.go": "package util\n",
+		"cc/main.go":   "package main\n\nfunc main() {}\n",
+		"cc/.env":      "KEY=value\n",
+		"cc/.git/HEAD": "ref: refs/heads/main\n",
+		"cc/META-INF/statedb/couchdb/indexes/index.json": `{"index":{"fields":["owner"]},"name":"owner","type":"json"}`,
+		"cc/META-INF/.hidden":                            "hidden\n",
+	})
+	chaincodePath := filepath.Join(root, "cc")
+
+	pkg, err := NewPackage("mycc_v1", "GOLANG", chaincodePath)
+	require.NoError(t, err)
+	assert.Regexp(t, regexp.MustCompile(`^mycc_v1:[0-9a-f]{64}$`), pkg.ID())
+
+	again, err := NewPackage("mycc_v1", "golang", chaincodePath)
+	require.NoError(t, err)
+	assert.Equal(t, pkg.ID(), again.ID())
+
+	outer := untar(t, pkg.Bytes)
+	require.Len(t, outer, 2)
+	var metadata packageMetadata
+	require.NoError(t, json.Unmarshal([]byte(outer["metadata.json"]), &metadata))
+	assert.Equal(t, packageMetadata{Path: "example.com/chaincodes/cc", Type: "golang", Label: "mycc_v1"}, metadata)
+
+	// the layout of the golang platform of fabric: the module under src/ with hidden files but without hidden
+	// directories, and META-INF without hidden files
+	code := untar(t, []byte(outer["code.tar.gz"]))
+	assert.Equal(t, map[string]string{
+		"src/":                              "",
+		"src/cc/":                           "",
+		"src/util/":                         "",
+		"META-INF/":                         "",
+		"META-INF/statedb/":                 "",
+		"META-INF/statedb/couchdb/":         "",
+		"META-INF/statedb/couchdb/indexes/": "",
+		"src/go.mod":                        "module example.com/chaincodes\n\ngo 1.14\n",
+		"src/util/util.go":                  "package util\n",
+		"src/cc/main.go":                    "package main\n\nfunc main() {}\n",
+		"src/cc/.env":                       "KEY=value\n",
+		"META-INF/statedb/couchdb/indexes/index.json": `{"index":{"fields":["owner"]},"name":"owner","type":"json"}`,
+	}, code)
+}
+
+func TestNewPackageNode(t *testing.T) {
+
+	root, err := ioutil.TempDir("", "chaincode")
+	require.NoError(t, err)
+	defer os.RemoveAll(root)
+	writeFiles(t, root, map[string]string{
+		"package.json":              `{"name":"mycc"}`,
+		"lib/chaincode.js":          "module.exports = {}\n",
+		"node_modules/dep/index.js": "module.exports = {}\n",
+	})
+
+	pkg, err := NewPackage("mycc_v1", "node", root)
+	require.NoError(t, err)
+	code := untar(t, []byte(untar(t, pkg.Bytes)["code.tar.gz"]))
+	assert.Equal(t, map[string]string{
+		"src/":                 "",
+		"src/lib/":             "",
+		"src/package.json":     `{"name":"mycc"}`,
+		"src/lib/chaincode.js": "module.exports = {}\n",
+	}, code)
+
+	_, err = NewPackage("mycc_v1", "cobol", root)
+	assert.EqualError(t, err, "unsupported chaincode language cobol")
+	_, err = NewPackage("mycc_v1", "golang", root)
+	assert.Error(t, err)
+}
+
+func TestCollections(t *testing.T) {
+
+	root, err := ioutil.TempDir("", "collections")
+	require.NoError(t, err)
+	defer os.RemoveAll(root)
+	path := filepath.Join(root, "collections.json")
+	writeFiles(t, root, map[string]string{"collections.json": `[
+  {
+    "name": "collectionMarbles",
+    "policy": "OR('Org1MSP.member', 'Org2MSP.member')",
+    "requiredPeerCount": 1,
+    "maxPeerCount": 3,
+    "blockToLive": 1000000,
+    "memberOnlyRead": true,
+    "endorsementPolicy": {"signaturePolicy": "AND('Org1MSP.peer', 'Org2MSP.peer')"}
+  },
+  {
+    "name": "collectionMarblePrivateDetails",
+    "policy": "OR('Org1MSP.member')",
+    "blockToLive": 3,
+    "endorsementPolicy": {"channelConfigPolicy": "/Channel/Application/Writers"}
+  }
+]`})
+
+	collections, err := Collections(path)
+	require.NoError(t, err)
+	require.Len(t, collections.Config, 2)
+	marbles := collections.Config[0].GetStaticCollectionConfig()
+	assert.Equal(t, "collectionMarbles", marbles.Name)
+	assert.Equal(t, int32(1), marbles.RequiredPeerCount)
+	assert.Equal(t, int32(3), marbles.MaximumPeerCount)
+	assert.Equal(t, uint64(1000000), marbles.BlockToLive)
+	assert.True(t, marbles.MemberOnlyRead)
+	assert.False(t, marbles.MemberOnlyWrite)
+	assert.Len(t, marbles.MemberOrgsPolicy.GetSignaturePolicy().Identities, 2)
+	assert.Equal(t, int32(2), marbles.EndorsementPolicy.GetSignaturePolicy().Rule.GetNOutOf().N)
+	details := collections.Config[1].GetStaticCollectionConfig()
+	assert.Equal(t, "/Channel/Application/Writers", details.EndorsementPolicy.GetChannelConfigPolicyReference())
+
+	writeFiles(t, root, map[string]string{"invalid.json": `[{"name": "invalid", "policy": "OR('Org1MSP.member'"}]`})
+	_, err = Collections(filepath.Join(root, "invalid.json"))
+	assert.Contains(t, err.Error(), "policy of collection invalid")
+}
+
+func TestValidationParameter(t *testing.T) {
+
+	parameter, err := validationParameter("", "")
+	assert.NoError(t, err)
+	assert.Nil(t, parameter)
+
+	parameter, err = validationParameter("OR('Org1MSP.member', 'Org2MSP.member')", "")
+	require.NoError(t, err)
+	applicationPolicy := &peer.ApplicationPolicy{}
+	require.NoError(t, proto.Unmarshal(parameter, applicationPolicy))
+	assert.Equal(t, int32(1), applicationPolicy.GetSignaturePolicy().Rule.GetNOutOf().N)
+
+	_, err = validationParameter("OR('Org1MSP.member')", "/Channel/Application/Endorsement")
+	assert.Error(t, err)
+}
+
+func TestReadiness(t *testing.T) {
+
+	definition, err := approvedDefinition(Definition{Name: "samplecc", Version: "v1", Sequence: 2, Policy: "OR('Org1MSP.member', 'Org2MSP.member')"})
+	require.NoError(t, err)
+	same, err := approvedDefinition(Definition{Name: "samplecc", Version: "v1", Sequence: 2, Policy: "OR('Org1MSP.member', 'Org2MSP.member')"})
+	require.NoError(t, err)
+	assert.Equal(t, definition, same)
+	assert.Len(t, definition.Policy, 8)
+	assert.Equal(t, "", definition.Collections)
+	divergent, err := approvedDefinition(Definition{Name: "samplecc", Version: "v1", Sequence: 2, Policy: "OR('Org2MSP.member')"})
+	require.NoError(t, err)
+	assert.NotEqual(t, definition.Policy, divergent.Policy)
+	channelDefault, err := approvedDefinition(Definition{Name: "samplecc", Version: "v1", Sequence: 2})
+	require.NoError(t, err)
+	assert.Equal(t, "default", channelDefault.Policy)
+	// peers approve a definition without endorsement policy with a reference to the default one of the channel
+	approvedDefault, err := proto.Marshal(&peer.ApplicationPolicy{
+		Type: &peer.ApplicationPolicy_ChannelConfigPolicyReference{ChannelConfigPolicyReference: "/Channel/Application/Endorsement"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, channelDefault.Policy, policyHash(approvedDefault))
+	channelEndorsement, err := approvedDefinition(Definition{Name: "samplecc", Version: "v1", Sequence: 2, ChannelConfigPolicy: "/Channel/Application/Endorsement"})
+	require.NoError(t, err)
+	assert.Equal(t, "default", channelEndorsement.Policy)
+	channelWriters, err := approvedDefinition(Definition{Name: "samplecc", Version: "v1", Sequence: 2, ChannelConfigPolicy: "/Channel/Application/Writers"})
+	require.NoError(t, err)
+	assert.NotEqual(t, "default", channelWriters.Policy)
+
+	divergent.PackageID = "samplecc_v1:0123"
+	readiness := Readiness{
+		Channel:    "testorgschannel0",
+		Name:       "samplecc",
+		Definition: definition,
+		Orgs: []OrgReadiness{
+			{Org: "org1", MSPID: "Org1MSP", Ready: true, Approved: &ApprovedDefinition{Sequence: 2, Version: "v1", Policy: definition.Policy, PackageID: "samplecc_v1:0123"}, Committed: "v1 (sequence 1)"},
+			{Org: "org2", MSPID: "Org2MSP", Approved: &divergent, Committed: "v1 (sequence 1)"},
+			{Org: "org3", MSPID: "Org3MSP", Errors: []string{"sequence 2 not approved"}},
+			{MSPID: "Org4MSP"},
+		},
+	}
+	approvals, orgs := readiness.Approvals()
+	assert.Equal(t, 1, approvals)
+	assert.Equal(t, 4, orgs)
+	matrix := readiness.String()
+	assert.Contains(t, matrix, "Commit readiness of chaincode samplecc v1 (sequence 2) on channel testorgschannel0\n")
+	assert.Regexp(t, `org2 +Org2MSP +false +2 +v1 +`+divergent.Policy+`\* +- +false +samplecc_v1:0123 +v1 \(sequence 1\)`, matrix)
+	assert.Regexp(t, `org1 +Org1MSP +true +2 +v1 +`+definition.Policy+` +- `, matrix)
+	assert.Regexp(t, `- +Org4MSP +false +- +- +- +- +- +- +-\n`, matrix)
+	assert.Contains(t, matrix, "1 of 4 organizations approved the definition\nERRORS\n  org3: sequence 2 not approved\n")
+
+	readiness.Orgs[1].Ready = true
+	readiness.Error = "requested sequence is 2, but new definition must be sequence 3"
+	matrix = readiness.String()
+	assert.Contains(t, matrix, "2 of 4 organizations approved the definition\nERRORS\n  requested sequence is 2, but new definition must be sequence 3\n  org3: sequence 2 not approved\n")
+
+	defaultReadiness := Readiness{
+		Channel:    "testorgschannel0",
+		Name:       "samplecc",
+		Definition: channelDefault,
+		Orgs:       []OrgReadiness{{Org: "org1", MSPID: "Org1MSP", Ready: true, Approved: &ApprovedDefinition{Sequence: 2, Version: "v1", Policy: policyHash(approvedDefault)}}},
+	}
+	assert.Regexp(t, `org1 +Org1MSP +true +2 +v1 +default +- +false +- +-\n`, defaultReadiness.String())
+}
+
+func TestNewServicePackage(t *testing.T) {
+
+	root, err := ioutil.TempDir("", "ccaas")
+	require.NoError(t, err)
+	defer os.RemoveAll(root)
+	dir := filepath.Join(root, "samplecc-v1")
+
+	serviceTLS, err := LoadServiceTLS(dir, "samplecc-v1")
+	require.NoError(t, err)
+	again, err := LoadServiceTLS(dir, "samplecc-v1")
+	require.NoError(t, err)
+	assert.Equal(t, serviceTLS, again)
+
+	caPool := x509.NewCertPool()
+	require.True(t, caPool.AppendCertsFromPEM(serviceTLS.CACert))
+	serverPEM, err := ioutil.ReadFile(filepath.Join(dir, "server.crt"))
+	require.NoError(t, err)
+	block, _ := pem.Decode(serverPEM)
+	serverCert, err := x509.ParseCertificate(block.Bytes)
+	require.NoError(t, err)
+	_, err = serverCert.Verify(x509.VerifyOptions{DNSName: "samplecc-v1", Roots: caPool})
+	assert.NoError(t, err)
+	_, err = tls.X509KeyPair(serviceTLS.ClientCert, serviceTLS.ClientKey)
+	assert.NoError(t, err)
+
+	pkg, err := NewServicePackage("samplecc_v1", serviceTLS.Connection("samplecc-v1:9999"))
+	require.NoError(t, err)
+	same, err := NewServicePackage("samplecc_v1", serviceTLS.Connection("samplecc-v1:9999"))
+	require.NoError(t, err)
+	assert.Equal(t, pkg.ID(), same.ID())
+
+	outer := untar(t, pkg.Bytes)
+	assert.JSONEq(t, `{"path":"","type":"ccaas","label":"samplecc_v1"}`, outer["metadata.json"])
+	code := untar(t, []byte(outer["code.tar.gz"]))
+	require.Len(t, code, 1)
+	var connection Connection
+	require.NoError(t, json.Unmarshal([]byte(code["connection.json"]), &connection))
+	assert.Equal(t, "samplecc-v1:9999", connection.Address)
+	assert.True(t, connection.TLSRequired)
+	assert.True(t, connection.ClientAuthRequired)
+	assert.Equal(t, string(serviceTLS.CACert), connection.RootCert)
+
+	plain, err := NewServicePackage("samplecc_v1", Connection{Address: "samplecc-v1:9999", DialTimeout: "10s"})
+	require.NoError(t, err)
+	code = untar(t, []byte(untar(t, plain.Bytes)["code.tar.gz"]))
+	assert.JSONEq(t, `{"address":"samplecc-v1:9999","dial_timeout":"10s","tls_required":false,"client_auth_required":false}`, code["connection.json"])
+}
@@ -0,0 +1,249 @@
+package lifecycle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+//Package -- a chaincode package as installed on peers: a .tar.gz with metadata.json and code.tar.gz
+type Package struct {
+	Label string
+	Bytes []byte
+}
+
+//ID -- the package ID peers give the package when it is installed, <label>:<sha256 of the package>
+func (p Package) ID() string {
+	return fmt.Sprintf("%s:%x", p.Label, sha256.Sum256(p.Bytes))
+}
+
+//packageMetadata -- metadata.json of a chaincode package
+type packageMetadata struct {
+	Path  string `json:"path"`
+	Type  string `json:"type"`
+	Label string `json:"label"`
+}
+
+//packageFile -- a file of the code of a chaincode and its name in code.tar.gz
+type packageFile struct {
+	name string
+	path string
+}
+
+//NewPackage -- packages the chaincode at chaincodePath the way the peer cli does, without running it. Go chaincode
+//must be in a module: its code is the module with the import path of the chaincode in metadata.json. The code of
+//node and java chaincode is their directory without node_modules and build outputs. Files get no timestamps so that
+//packaging the same code gives the same package ID
+func NewPackage(label, language, chaincodePath string) (Package, error) {
+
+	chaincodePath, err := filepath.Abs(chaincodePath)
+	if err != nil {
+		return Package{}, err
+	}
+	var files []packageFile
+	metadata := packageMetadata{Path: chaincodePath, Type: strings.ToLower(language), Label: label}
+	switch metadata.Type {
+	case "golang", "go":
+		metadata.Type = "golang"
+		metadata.Path, files, err = goFiles(chaincodePath)
+	case "node", "java":
+		files, err = sourceFiles(chaincodePath, chaincodePath, excludedDirs[metadata.Type])
+	default:
+		return Package{}, errors.Errorf("unsupported chaincode language %s", language)
+	}
+	if err != nil {
+		return Package{}, err
+	}
+	metaInf, err := metaInfFiles(chaincodePath)
+	if err != nil {
+		return Package{}, err
+	}
+	code, err := codePackage(append(files, metaInf...))
+	if err != nil {
+		return Package{}, errors.Wrapf(err, "failed to package the code of chaincode %s", chaincodePath)
+	}
+	metadataBytes, err := json.Marshal(metadata)
+	if err != nil {
+		return Package{}, err
+	}
+	contents, err := tarGz([]tarEntry{{name: "metadata.json", contents: metadataBytes}, {name: "code.tar.gz", contents: code}})
+	if err != nil {
+		return Package{}, err
+	}
+	return Package{Label: label, Bytes: contents}, nil
+}
+
+//excludedDirs -- the directories left out of the code of node and java chaincode
+var excludedDirs = map[string][]string{
+	"node": {"node_modules"},
+	"java": {"build", "target", "out"},
+}
+
+//goFiles -- the files of the module of Go chaincode and the import path of the chaincode
+func goFiles(chaincodePath string) (string, []packageFile, error) {
+
+	moduleDir := chaincodePath
+	for {
+		if _, err := os.Stat(filepath.Join(moduleDir, "go.mod")); err == nil {
+			break
+		}
+		parent := filepath.Dir(moduleDir)
+		if parent == moduleDir {
+			return "", nil, errors.Errorf("chaincode %s is not in a Go module", chaincodePath)
+		}
+		moduleDir = parent
+	}
+	goMod, err := ioutil.ReadFile(filepath.Join(moduleDir, "go.mod"))
+	if err != nil {
+		return "", nil, err
+	}
+	var modulePath string
+	for _, line := range strings.Split(string(goMod), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[0] == "module" {
+			modulePath = strings.Trim(fields[1], `"`)
+			break
+		}
+	}
+	if modulePath == "" {
+		return "", nil, errors.Errorf("no module path in %s", filepath.Join(moduleDir, "go.mod"))
+	}
+	relativePath, err := filepath.Rel(moduleDir, chaincodePath)
+	if err != nil {
+		return "", nil, err
+	}
+	files, err := sourceFiles(moduleDir, chaincodePath, nil)
+	return path.Join(modulePath, filepath.ToSlash(relativePath)), files, err
+}
+
+//sourceFiles -- the files under root as src/<path relative to root>, without hidden directories, excluded directories
+//and the META-INF directory of the chaincode at chaincodePath
+func sourceFiles(root, chaincodePath string, excluded []string) ([]packageFile, error) {
+
+	var files []packageFile
+	metaInf := filepath.Join(chaincodePath, "META-INF")
+	err := filepath.Walk(root, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if filePath == root {
+			return nil
+		}
+		if info.IsDir() && (strings.HasPrefix(info.Name(), ".") || filePath == metaInf || contains(excluded, info.Name())) {
+			return filepath.SkipDir
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		name, err := filepath.Rel(root, filePath)
+		if err != nil {
+			return err
+		}
+		files = append(files, packageFile{name: path.Join("src", filepath.ToSlash(name)), path: filePath})
+		return nil
+	})
+	return files, err
+}
+
+//metaInfFiles -- the files of the META-INF directory of the chaincode, such as its statedb indexes, without hidden
+//files
+func metaInfFiles(chaincodePath string) ([]packageFile, error) {
+
+	metaInf := filepath.Join(chaincodePath, "META-INF")
+	if _, err := os.Stat(metaInf); os.IsNotExist(err) {
+		return nil, nil
+	}
+	files, err := sourceFiles(metaInf, "", nil)
+	var metaInfFiles []packageFile
+	for _, file := range files {
+		if !strings.HasPrefix(path.Base(file.name), ".") {
+			metaInfFiles = append(metaInfFiles, packageFile{name: "META-INF/" + strings.TrimPrefix(file.name, "src/"), path: file.path})
+		}
+	}
+	return metaInfFiles, err
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+//tarEntry -- a file of a tar, or a directory when contents is nil and the name ends with /
+type tarEntry struct {
+	name     string
+	contents []byte
+}
+
+//codePackage -- code.tar.gz of the files, with the directories that hold them
+func codePackage(files []packageFile) ([]byte, error) {
+
+	sort.Slice(files, func(i, j int) bool { return files[i].name < files[j].name })
+	var entries []tarEntry
+	dirs := make(map[string]bool)
+	for _, file := range files {
+		for dir := path.Dir(file.name); dir != "." && !dirs[dir]; dir = path.Dir(dir) {
+			dirs[dir] = true
+		}
+	}
+	var dirNames []string
+	for dir := range dirs {
+		dirNames = append(dirNames, dir)
+	}
+	sort.Strings(dirNames)
+	for _, dir := range dirNames {
+		entries = append(entries, tarEntry{name: dir + "/"})
+	}
+	for _, file := range files {
+		contents, err := ioutil.ReadFile(file.path)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, tarEntry{name: file.name, contents: contents})
+	}
+	return tarGz(entries)
+}
+
+//tarGz -- a .tar.gz of the entries, owned by uid 500 and without timestamps as in the packages of the peer cli
+func tarGz(entries []tarEntry) ([]byte, error) {
+
+	var buffer bytes.Buffer
+	gw := gzip.NewWriter(&buffer)
+	tw := tar.NewWriter(gw)
+	for _, entry := range entries {
+		header := &tar.Header{Name: entry.name, Mode: 0100644, Size: int64(len(entry.contents)), Uid: 500, Gid: 500}
+		if strings.HasSuffix(entry.name, "/") {
+			header = &tar.Header{Typeflag: tar.TypeDir, Name: entry.name, Mode: 040755, Uid: 500, Gid: 500}
+		}
+		err := tw.WriteHeader(header)
+		if err != nil {
+			return nil, err
+		}
+		_, err = tw.Write(entry.contents)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err := tw.Close()
+	if err == nil {
+		err = gw.Close()
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to write tar")
+	}
+	return buffer.Bytes(), nil
+}
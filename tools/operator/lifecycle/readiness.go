@@ -0,0 +1,247 @@
+package lifecycle
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric/protoutil"
+)
+
+//ApprovedDefinition -- a chaincode definition as approved by an organization, with its endorsement policy and
+//collections as short hashes so that the approvals of organizations can be compared
+type ApprovedDefinition struct {
+	Sequence     int64
+	Version      string
+	Policy       string
+	Collections  string
+	InitRequired bool
+	PackageID    string
+}
+
+//OrgReadiness -- the approval of an organization of the channel: whether it matches the definition to commit, what it
+//approved for the sequence and what its peer has committed. Organizations of the channel that are not in the test
+//input only have their MSP ID and Ready
+type OrgReadiness struct {
+	Org       string
+	MSPID     string
+	Ready     bool
+	Approved  *ApprovedDefinition
+	Committed string
+	Errors    []string
+}
+
+//Readiness -- the commit readiness of a chaincode definition on a channel and the approvals of its organizations
+type Readiness struct {
+	Channel    string
+	Name       string
+	Definition ApprovedDefinition
+	Orgs       []OrgReadiness
+	Error      string
+}
+
+//CheckReadiness -- checks the commit readiness of a chaincode definition on a channel with the first organization and
+//queries the approved and committed definitions of every organization in parallel
+func CheckReadiness(orgs []*Org, channel string, definition Definition) (Readiness, error) {
+
+	readiness := Readiness{Channel: channel, Name: definition.Name}
+	var err error
+	readiness.Definition, err = approvedDefinition(definition)
+	if err != nil {
+		return readiness, err
+	}
+	approvals, err := orgs[0].CheckCommitReadiness(channel, definition)
+	if err != nil {
+		readiness.Error = err.Error()
+	}
+	readiness.Orgs = make([]OrgReadiness, len(orgs))
+	var wg sync.WaitGroup
+	for index, org := range orgs {
+		wg.Add(1)
+		go func(index int, org *Org) {
+			defer wg.Done()
+			readiness.Orgs[index] = org.readiness(channel, definition.Name, definition.Sequence, approvals[org.MSPID])
+		}(index, org)
+	}
+	wg.Wait()
+	var others []string
+	for mspID := range approvals {
+		known := false
+		for _, org := range orgs {
+			known = known || org.MSPID == mspID
+		}
+		if !known {
+			others = append(others, mspID)
+		}
+	}
+	sort.Strings(others)
+	for _, mspID := range others {
+		readiness.Orgs = append(readiness.Orgs, OrgReadiness{MSPID: mspID, Ready: approvals[mspID]})
+	}
+	return readiness, nil
+}
+
+//readiness -- the approval of the organization for a sequence and the definition committed on its peer
+func (o *Org) readiness(channel, name string, sequence int64, ready bool) OrgReadiness {
+
+	orgReadiness := OrgReadiness{Org: o.Name, MSPID: o.MSPID, Ready: ready}
+	approved, err := o.QueryApproved(channel, name, sequence)
+	if err == nil {
+		orgReadiness.Approved = &ApprovedDefinition{
+			Sequence:     approved.Sequence,
+			Version:      approved.Version,
+			Policy:       policyHash(approved.ValidationParameter),
+			InitRequired: approved.InitRequired,
+		}
+		orgReadiness.Approved.Collections, err = collectionsHash(approved.Collections)
+		if local := approved.GetSource().GetLocalPackage(); local != nil {
+			orgReadiness.Approved.PackageID = local.PackageId
+		}
+	}
+	if err != nil {
+		orgReadiness.Errors = append(orgReadiness.Errors, err.Error())
+	}
+	committed, err := o.QueryCommitted(channel, name)
+	switch {
+	case err == nil:
+		orgReadiness.Committed = fmt.Sprintf("%s (sequence %d)", committed.Version, committed.Sequence)
+	case !strings.Contains(err.Error(), "is not defined"):
+		orgReadiness.Errors = append(orgReadiness.Errors, err.Error())
+	}
+	return orgReadiness
+}
+
+//approvedDefinition -- the definition to commit as it would be approved
+func approvedDefinition(definition Definition) (ApprovedDefinition, error) {
+
+	parameter, err := validationParameter(definition.Policy, definition.ChannelConfigPolicy)
+	if err != nil {
+		return ApprovedDefinition{}, err
+	}
+	collections, err := collectionsHash(definition.Collections)
+	if err != nil {
+		return ApprovedDefinition{}, err
+	}
+	return ApprovedDefinition{
+		Sequence:     definition.Sequence,
+		Version:      definition.Version,
+		Policy:       policyHash(parameter),
+		Collections:  collections,
+		InitRequired: definition.InitRequired,
+	}, nil
+}
+
+//defaultEndorsementPolicy -- the validation parameter peers store for a definition approved without an endorsement
+//policy, a reference to the default endorsement policy of the channel
+var defaultEndorsementPolicy = protoutil.MarshalOrPanic(&peer.ApplicationPolicy{
+	Type: &peer.ApplicationPolicy_ChannelConfigPolicyReference{ChannelConfigPolicyReference: "/Channel/Application/Endorsement"},
+})
+
+//policyHash -- the short hash of the validation parameter of a definition, default for the default endorsement policy
+//of the channel whether it is left empty, as in the definition to commit, or referenced, as peers approve it
+func policyHash(parameter []byte) string {
+
+	if len(parameter) == 0 || bytes.Equal(parameter, defaultEndorsementPolicy) {
+		return "default"
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(parameter))[:8]
+}
+
+//collectionsHash -- the short hash of the collections of a definition, empty without collections
+func collectionsHash(collections *peer.CollectionConfigPackage) (string, error) {
+
+	if len(collections.GetConfig()) == 0 {
+		return "", nil
+	}
+	contents, err := proto.Marshal(collections)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(contents))[:8], nil
+}
+
+//Approvals -- the number of organizations of the channel whose approval matches the definition, and their number
+func (r Readiness) Approvals() (int, int) {
+
+	var approvals int
+	for _, org := range r.Orgs {
+		if org.Ready {
+			approvals++
+		}
+	}
+	return approvals, len(r.Orgs)
+}
+
+//Write -- writes the readiness as a matrix of the definition to commit and what every organization approved for its
+//sequence, with * on what differs from the definition, followed by the queries that failed
+func (r Readiness) Write(w io.Writer) error {
+
+	fmt.Fprintf(w, "Commit readiness of chaincode %s %s (sequence %d) on channel %s\n", r.Name, r.Definition.Version, r.Definition.Sequence, r.Channel)
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "ORG\tMSPID\tREADY\tSEQUENCE\tVERSION\tPOLICY\tCOLLECTIONS\tINIT\tPACKAGE\tCOMMITTED")
+	d := r.Definition
+	fmt.Fprintf(table, "(commit)\t-\t-\t%d\t%s\t%s\t%s\t%t\t-\t-\n", d.Sequence, d.Version, d.Policy, dash(d.Collections), d.InitRequired)
+	for _, org := range r.Orgs {
+		approved := "-\t-\t-\t-\t-\t-"
+		if a := org.Approved; a != nil {
+			approved = strings.Join([]string{
+				differs(strconv.FormatInt(a.Sequence, 10), a.Sequence != d.Sequence),
+				differs(a.Version, a.Version != d.Version),
+				differs(a.Policy, a.Policy != d.Policy),
+				differs(dash(a.Collections), a.Collections != d.Collections),
+				differs(strconv.FormatBool(a.InitRequired), a.InitRequired != d.InitRequired),
+				dash(a.PackageID),
+			}, "\t")
+		}
+		fmt.Fprintf(table, "%s\t%s\t%t\t%s\t%s\n", dash(org.Org), org.MSPID, org.Ready, approved, dash(org.Committed))
+	}
+	err := table.Flush()
+	if err != nil {
+		return err
+	}
+	approvals, orgs := r.Approvals()
+	fmt.Fprintf(w, "%d of %d organizations approved the definition\n", approvals, orgs)
+	var errs []string
+	if r.Error != "" {
+		errs = append(errs, "  "+r.Error)
+	}
+	for _, org := range r.Orgs {
+		for _, message := range org.Errors {
+			errs = append(errs, fmt.Sprintf("  %s: %s", org.Org, message))
+		}
+	}
+	if len(errs) > 0 {
+		fmt.Fprintf(w, "ERRORS\n%s\n", strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
+//String -- the matrix of Write
+func (r Readiness) String() string {
+
+	var buffer bytes.Buffer
+	r.Write(&buffer)
+	return buffer.String()
+}
+
+func dash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+func differs(value string, different bool) string {
+	if different {
+		return value + "*"
+	}
+	return value
+}
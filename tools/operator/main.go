@@ -1,6 +1,7 @@
 package main
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
 	"io"
@@ -13,14 +14,20 @@ import (
 	"github.com/hyperledger/fabric-test/tools/operator/logger"
 	"github.com/hyperledger/fabric-test/tools/operator/networkclient"
 	"github.com/hyperledger/fabric-test/tools/operator/networkspec"
+	"github.com/hyperledger/fabric-test/tools/operator/networkstate"
 	"github.com/hyperledger/fabric-test/tools/operator/paths"
+	"github.com/hyperledger/fabric-test/tools/operator/plan"
 	"github.com/hyperledger/fabric-test/tools/operator/testclient"
 	"github.com/pkg/errors"
+	yaml "gopkg.in/yaml.v2"
 )
 
 var inputFilePath = flag.String("i", "", "Input file path (required)")
 var kubeConfigPath = flag.String("k", "", "Kube config file path (optional)")
-var action = flag.String("a", "up", "Set action (Available options up, down, create, join, install, instantiate, upgrade, invoke, query, createChannelTxn, migrate, health)")
+var runtime = flag.String("r", "", "Runtime of the network: docker, k8s or local processes (optional, k8s if a kube config file is given, docker otherwise)")
+var action = flag.String("a", "up", "Set action (Available options up, down, create, join, install, instantiate, upgrade, invoke, query, metricsSnapshot, createChannelTxn, migrate, health, verifyLedger, configUpdate, addOrg, removeOrg, addOrderer, removeOrderer, rotateOrdererCert, listChannels, joinChannel, removeChannel, status)")
+var output = flag.String("o", "table", "Output of the status action and of --dry-run: table or json (optional)")
+var dryRun = flag.Bool("dry-run", false, "Print the plan of the up action or of a create, anchorpeer, join, install, instantiate or upgrade action of a test input file without running it (optional)")
 
 func validateArguments(networkSpecPath *string, kubeConfigPath *string) error {
 
@@ -46,13 +53,9 @@ func doAction(action, env, kubeConfigPath, inputFilePath string) error {
 	var err error
 	var inputPath string
 	var config networkspec.Config
-	actions := []string{"up", "down", "createChannelTxn", "migrate", "health", "upgradeNetwork", "networkInSync", "updateCapability", "updatePolicy", "upgradeDB", "addPeer"}
+	actions := []string{"up", "down", "createChannelTxn", "migrate", "health", "upgradeNetwork", "networkInSync", "verifyLedger", "configUpdate", "updateCapability", "updatePolicy", "upgradeDB", "addPeer", "addOrg", "removeOrg", "addOrderer", "removeOrderer", "rotateOrdererCert", "listChannels", "joinChannel", "removeChannel"}
 	if contains(actions, action) {
-		contents, _ := ioutil.ReadFile(inputFilePath)
-		contents = append([]byte("#@data/values \n"), contents...)
-		inputPath = paths.JoinPath(paths.TemplatesDir(), "input.yaml")
-		ioutil.WriteFile(inputPath, contents, 0644)
-
+		inputPath = inputFilePath
 		var network nl.Network
 		config, err = network.GetConfigData(inputPath)
 		if err != nil {
@@ -85,6 +88,36 @@ func doAction(action, env, kubeConfigPath, inputFilePath string) error {
 			logger.ERROR("Failed to add peers to network")
 			return err
 		}
+	case "addOrg":
+		err = launcher.Launcher("addOrg", env, kubeConfigPath, inputPath)
+		if err != nil {
+			logger.ERROR("Failed to add organizations to network")
+			return err
+		}
+	case "removeOrg":
+		err = launcher.Launcher("removeOrg", env, kubeConfigPath, inputPath)
+		if err != nil {
+			logger.ERROR("Failed to remove organizations from network")
+			return err
+		}
+	case "addOrderer":
+		err = launcher.Launcher("addOrderer", env, kubeConfigPath, inputPath)
+		if err != nil {
+			logger.ERROR("Failed to add orderers to network")
+			return err
+		}
+	case "removeOrderer":
+		err = launcher.Launcher("removeOrderer", env, kubeConfigPath, inputPath)
+		if err != nil {
+			logger.ERROR("Failed to remove orderers from network")
+			return err
+		}
+	case "rotateOrdererCert":
+		err = launcher.Launcher("rotateOrdererCert", env, kubeConfigPath, inputPath)
+		if err != nil {
+			logger.ERROR("Failed to rotate orderer certificates of network")
+			return err
+		}
 	case "upgradeDB":
 		err = launcher.Launcher("upgradeDB", env, kubeConfigPath, inputPath)
 		if err != nil {
@@ -161,8 +194,14 @@ func doAction(action, env, kubeConfigPath, inputFilePath string) error {
 			logger.ERROR("Failed to send queries")
 			return err
 		}
+	case "metricsSnapshot":
+		err = testclient.Testclient("metricsSnapshot", inputFilePath)
+		if err != nil {
+			logger.ERROR("Failed to take metrics snapshot")
+			return err
+		}
 	case "createChannelTxn":
-		configTxnPath := paths.ConfigFilesDir(false)
+		configTxnPath := paths.ConfigFilesDir(config.NetworkName, false)
 		err = networkclient.GenerateChannelTransaction(config, configTxnPath)
 		if err != nil {
 			logger.ERROR("Failed to create channel transaction")
@@ -179,6 +218,42 @@ func doAction(action, env, kubeConfigPath, inputFilePath string) error {
 		if err != nil {
 			return err
 		}
+	case "verifyLedger":
+		err = networkclient.VerifyLedger(config)
+		if err != nil {
+			logger.ERROR("Failed to verify that the ledgers of peers and orderers match")
+			return err
+		}
+	case "configUpdate":
+		err = networkclient.ConfigUpdate(config)
+		if err != nil {
+			logger.ERROR("Failed to apply the config updates")
+			return err
+		}
+	case "status":
+		err = launcher.Status(env, kubeConfigPath, inputFilePath, *output)
+		if err != nil {
+			logger.ERROR("Failed to get the status of the network")
+			return err
+		}
+	case "listChannels":
+		err = networkclient.ListOrdererChannels(config)
+		if err != nil {
+			logger.ERROR("Failed to list the channels of the orderers")
+			return err
+		}
+	case "joinChannel":
+		err = networkclient.JoinOrdererChannels(config)
+		if err != nil {
+			logger.ERROR("Failed to join the orderers to the channels")
+			return err
+		}
+	case "removeChannel":
+		err = networkclient.RemoveOrdererChannels(config)
+		if err != nil {
+			logger.ERROR("Failed to remove the orderers from the channels")
+			return err
+		}
 	case "command":
 		err = testclient.Testclient("command", inputFilePath)
 		if err != nil {
@@ -192,12 +267,40 @@ func doAction(action, env, kubeConfigPath, inputFilePath string) error {
 			return err
 		}
 	default:
-		logger.ERROR("Incorrect action ", action, " provided. Use up or down or create or join or anchorpeer or install or instantiate or upgrade or invoke or query or createChannelTxn or migrate or health or upgradeNetwork for action ")
+		logger.ERROR("Incorrect action ", action, " provided. Use up or down or create or join or anchorpeer or install or instantiate or upgrade or invoke or query or metricsSnapshot or createChannelTxn or migrate or health or verifyLedger or configUpdate or upgradeNetwork or status for action ")
 		return err
 	}
 	return nil
 }
 
+//planAction -- prints the plan of an action instead of running it
+func planAction(action, env, kubeConfigPath, inputFilePath, output string) error {
+
+	var p plan.Plan
+	var err error
+	if output != "table" && output != "json" {
+		return errors.Errorf("unknown plan output %s, use table or json", output)
+	}
+	switch action {
+	case "create", "anchorpeer", "join", "install", "instantiate", "upgrade":
+		p, err = testclient.Plan(action, inputFilePath)
+	default:
+		p, err = launcher.Plan(action, env, kubeConfigPath, inputFilePath)
+	}
+	if err != nil {
+		return err
+	}
+	if output == "json" {
+		contents, err := json.MarshalIndent(p, "", "  ")
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal the plan")
+		}
+		fmt.Println(string(contents))
+		return nil
+	}
+	return p.Write(os.Stdout)
+}
+
 func writeLogToAFile() {
 	f, err := os.OpenFile("text.log",
 		os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
@@ -207,6 +310,24 @@ func writeLogToAFile() {
 	defer f.Close()
 }
 
+//logFilePath -- the log file of the operator, /tmp/orders-<networkName>.log for a named network so that operators
+//of networks running side by side do not share it
+func logFilePath(inputFilePath string) string {
+
+	var config networkspec.Config
+	if networkstate.IsStateFile(inputFilePath) {
+		state, err := networkstate.Read(inputFilePath)
+		if err == nil && state.NetworkName != "" {
+			return fmt.Sprintf("/tmp/orders-%s.log", state.NetworkName)
+		}
+	}
+	contents, err := ioutil.ReadFile(inputFilePath)
+	if err == nil && yaml.Unmarshal(contents, &config) == nil && config.NetworkName != "" && config.ValidateNetwork() == nil {
+		return fmt.Sprintf("/tmp/orders-%s.log", config.NetworkName)
+	}
+	return "/tmp/orders.log"
+}
+
 func main() {
 
 	flag.Parse()
@@ -216,7 +337,20 @@ func main() {
 	if *kubeConfigPath != "" {
 		env = "k8s"
 	}
-	f, err := os.OpenFile("/tmp/orders.log", os.O_RDWR|os.O_CREATE|os.O_APPEND, 0666)
+	if networkstate.IsStateFile(*inputFilePath) {
+		state, err := networkstate.Read(*inputFilePath)
+		if err == nil && state.Runtime != "" {
+			env = state.Runtime
+		}
+	}
+	if *runtime != "" {
+		env = *runtime
+	}
+	if !contains([]string{"docker", "k8s", "local"}, env) || (env == "k8s" && *kubeConfigPath == "") {
+		logger.ERROR("Incorrect runtime ", env, " provided. Use docker, local or k8s with a kube config file")
+		os.Exit(1)
+	}
+	f, err := os.OpenFile(logFilePath(*inputFilePath), os.O_RDWR|os.O_CREATE|os.O_APPEND, 0666)
 	if err != nil {
 		log.Fatalf("error opening file: %v", err)
 	}
@@ -224,7 +358,11 @@ func main() {
 	wrt := io.MultiWriter(f)
 	log.SetOutput(wrt)
 
-	err = doAction(*action, env, *kubeConfigPath, *inputFilePath)
+	if *dryRun {
+		err = planAction(*action, env, *kubeConfigPath, *inputFilePath, *output)
+	} else {
+		err = doAction(*action, env, *kubeConfigPath, *inputFilePath)
+	}
 	if err != nil {
 		logger.ERROR(fmt.Sprintln("Operator failed with error ", err))
 		os.Exit(1)
@@ -0,0 +1,270 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const scrapeTimeout = 30 * time.Second
+
+//Sample -- a single sample of a metric
+type Sample struct {
+	Name      string            `json:"name"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	Value     float64           `json:"value"`
+	Timestamp int64             `json:"timestamp,omitempty"`
+}
+
+//Family -- all the samples of a metric together with its type and help text
+type Family struct {
+	Name    string   `json:"name"`
+	Type    string   `json:"type,omitempty"`
+	Help    string   `json:"help,omitempty"`
+	Samples []Sample `json:"samples"`
+}
+
+//Metrics -- metric families of a component keyed by metric name
+type Metrics map[string]*Family
+
+//Find -- returns the samples of a metric whose labels contain all of the given labels
+func (m Metrics) Find(name string, labels map[string]string) []Sample {
+
+	var samples []Sample
+	family, ok := m[name]
+	if !ok {
+		family, ok = m[familyName(name)]
+	}
+	if !ok {
+		return nil
+	}
+	for _, sample := range family.Samples {
+		if sample.Name == name && sample.Matches(labels) {
+			samples = append(samples, sample)
+		}
+	}
+	return samples
+}
+
+//Value -- returns the value of the single sample of a metric that matches labels
+func (m Metrics) Value(name string, labels map[string]string) (float64, error) {
+
+	samples := m.Find(name, labels)
+	if len(samples) == 0 {
+		return 0, errors.Errorf("metric %s%s not found", name, formatLabels(labels))
+	}
+	if len(samples) > 1 {
+		return 0, errors.Errorf("metric %s%s matches %d samples", name, formatLabels(labels), len(samples))
+	}
+	return samples[0].Value, nil
+}
+
+//Sum -- returns the sum of the samples of a metric that match labels
+func (m Metrics) Sum(name string, labels map[string]string) float64 {
+
+	var sum float64
+	for _, sample := range m.Find(name, labels) {
+		sum += sample.Value
+	}
+	return sum
+}
+
+//Matches -- checks whether the sample has all of the given labels
+func (s Sample) Matches(labels map[string]string) bool {
+	for key, value := range labels {
+		if s.Labels[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+//Scrape -- fetches and parses the /metrics endpoint of a peer or orderer
+func Scrape(metricsURL string) (Metrics, error) {
+
+	endpoint := strings.TrimSuffix(metricsURL, "/")
+	if !strings.HasSuffix(endpoint, "/metrics") {
+		endpoint = endpoint + "/metrics"
+	}
+	client := http.Client{Timeout: scrapeTimeout}
+	resp, err := client.Get(endpoint)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to scrape %s", endpoint)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("failed to scrape %s: %s", endpoint, resp.Status)
+	}
+	return Parse(resp.Body)
+}
+
+//Parse -- parses metrics in the prometheus text exposition format
+func Parse(reader io.Reader) (Metrics, error) {
+
+	metrics := make(Metrics)
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			fields := strings.SplitN(line, " ", 4)
+			if len(fields) < 3 || (fields[1] != "HELP" && fields[1] != "TYPE") {
+				continue
+			}
+			family := metrics.family(fields[2])
+			text := ""
+			if len(fields) == 4 {
+				text = fields[3]
+			}
+			if fields[1] == "HELP" {
+				family.Help = unescape(text, false)
+			} else {
+				family.Type = text
+			}
+			continue
+		}
+		sample, err := parseSample(line)
+		if err != nil {
+			return nil, errors.Wrapf(err, "line %d", lineNumber)
+		}
+		family := metrics.family(familyNameForType(sample.Name, metrics))
+		family.Samples = append(family.Samples, sample)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return metrics, nil
+}
+
+func (m Metrics) family(name string) *Family {
+	family, ok := m[name]
+	if !ok {
+		family = &Family{Name: name}
+		m[name] = family
+	}
+	return family
+}
+
+//familyNameForType -- maps a sample name to its family, summaries and histograms expose suffixed samples
+func familyNameForType(sampleName string, metrics Metrics) string {
+	if _, ok := metrics[sampleName]; ok {
+		return sampleName
+	}
+	name := familyName(sampleName)
+	if family, ok := metrics[name]; ok && (family.Type == "histogram" || family.Type == "summary") {
+		return name
+	}
+	return sampleName
+}
+
+func familyName(sampleName string) string {
+	for _, suffix := range []string{"_bucket", "_sum", "_count"} {
+		if strings.HasSuffix(sampleName, suffix) {
+			return strings.TrimSuffix(sampleName, suffix)
+		}
+	}
+	return sampleName
+}
+
+//parseSample -- parses a line of the form name{label="value",...} value [timestamp]
+func parseSample(line string) (Sample, error) {
+
+	var err error
+	sample := Sample{}
+	index := strings.IndexAny(line, "{ \t")
+	if index <= 0 {
+		return sample, errors.Errorf("invalid sample %q", line)
+	}
+	sample.Name = line[:index]
+	rest := line[index:]
+	if rest[0] == '{' {
+		sample.Labels, rest, err = parseLabels(rest[1:])
+		if err != nil {
+			return sample, errors.Wrapf(err, "invalid labels in %q", line)
+		}
+	}
+	fields := strings.Fields(rest)
+	if len(fields) == 0 || len(fields) > 2 {
+		return sample, errors.Errorf("invalid value in %q", line)
+	}
+	sample.Value, err = strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return sample, errors.Errorf("invalid value %q in %q", fields[0], line)
+	}
+	if len(fields) == 2 {
+		sample.Timestamp, err = strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return sample, errors.Errorf("invalid timestamp %q in %q", fields[1], line)
+		}
+	}
+	return sample, nil
+}
+
+//parseLabels -- parses the labels up to the closing brace and returns the remainder of the line
+func parseLabels(input string) (map[string]string, string, error) {
+
+	labels := make(map[string]string)
+	for {
+		input = strings.TrimLeft(input, " \t,")
+		if input == "" {
+			return nil, "", errors.New("missing closing brace")
+		}
+		if input[0] == '}' {
+			return labels, input[1:], nil
+		}
+		equals := strings.Index(input, "=")
+		if equals <= 0 || len(input) < equals+2 || input[equals+1] != '"' {
+			return nil, "", errors.Errorf("invalid label at %q", input)
+		}
+		name := strings.TrimSpace(input[:equals])
+		input = input[equals+2:]
+		end := -1
+		for i := 0; i < len(input); i++ {
+			if input[i] == '\\' {
+				i++
+				continue
+			}
+			if input[i] == '"' {
+				end = i
+				break
+			}
+		}
+		if end < 0 {
+			return nil, "", errors.Errorf("unterminated value of label %s", name)
+		}
+		labels[name] = unescape(input[:end], true)
+		input = input[end+1:]
+	}
+}
+
+func unescape(value string, quotes bool) string {
+	replacements := []string{`\\`, `\`, `\n`, "\n"}
+	if quotes {
+		replacements = append(replacements, `\"`, `"`)
+	}
+	return strings.NewReplacer(replacements...).Replace(value)
+}
+
+func formatLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	var pairs []string
+	for key, value := range labels {
+		pairs = append(pairs, fmt.Sprintf("%s=%q", key, value))
+	}
+	sort.Strings(pairs)
+	return "{" + strings.Join(pairs, ",") + "}"
+}
@@ -0,0 +1,66 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const peerMetrics = `# HELP ledger_blockchain_height Height of the chain in blocks.
+# TYPE ledger_blockchain_height gauge
+ledger_blockchain_height{channel="testorgschannel0"} 12
+ledger_blockchain_height{channel="testorgschannel1"} 7
+# HELP ledger_transaction_count Number of transactions processed.
+# TYPE ledger_transaction_count counter
+ledger_transaction_count{chaincode="samplecc:v1",channel="testorgschannel0",transaction_type="ENDORSER_TRANSACTION",validation_code="VALID"} 100
+ledger_transaction_count{chaincode="samplecc:v1",channel="testorgschannel0",transaction_type="ENDORSER_TRANSACTION",validation_code="MVCC_READ_CONFLICT"} 3
+# HELP endorser_proposal_duration The time to complete a proposal.
+# TYPE endorser_proposal_duration histogram
+endorser_proposal_duration_bucket{channel="testorgschannel0",le="0.005"} 4
+endorser_proposal_duration_bucket{channel="testorgschannel0",le="+Inf"} 10
+endorser_proposal_duration_sum{channel="testorgschannel0"} 0.25
+endorser_proposal_duration_count{channel="testorgschannel0"} 10
+logger_message{text="a \"quoted\" \\ value"} 1 1600000000000
+`
+
+func TestParse(t *testing.T) {
+	metrics, err := Parse(strings.NewReader(peerMetrics))
+	assert.NoError(t, err)
+
+	height, err := metrics.Value("ledger_blockchain_height", map[string]string{"channel": "testorgschannel1"})
+	assert.NoError(t, err)
+	assert.Equal(t, float64(7), height)
+	assert.Equal(t, "gauge", metrics["ledger_blockchain_height"].Type)
+	assert.Equal(t, "Height of the chain in blocks.", metrics["ledger_blockchain_height"].Help)
+
+	valid, err := metrics.Value("ledger_transaction_count", map[string]string{"channel": "testorgschannel0", "validation_code": "VALID"})
+	assert.NoError(t, err)
+	assert.Equal(t, float64(100), valid)
+	assert.Equal(t, float64(103), metrics.Sum("ledger_transaction_count", map[string]string{"chaincode": "samplecc:v1"}))
+
+	histogram := metrics["endorser_proposal_duration"]
+	assert.Equal(t, "histogram", histogram.Type)
+	assert.Len(t, histogram.Samples, 4)
+	count, err := metrics.Value("endorser_proposal_duration_count", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(10), count)
+	assert.Len(t, metrics.Find("endorser_proposal_duration_bucket", map[string]string{"le": "+Inf"}), 1)
+
+	message := metrics["logger_message"].Samples[0]
+	assert.Equal(t, `a "quoted" \ value`, message.Labels["text"])
+	assert.Equal(t, int64(1600000000000), message.Timestamp)
+
+	_, err = metrics.Value("ledger_blockchain_height", map[string]string{"channel": "missing"})
+	assert.EqualError(t, err, `metric ledger_blockchain_height{channel="missing"} not found`)
+	_, err = metrics.Value("ledger_blockchain_height", nil)
+	assert.EqualError(t, err, "metric ledger_blockchain_height matches 2 samples")
+}
+
+func TestParseInvalid(t *testing.T) {
+	_, err := Parse(strings.NewReader(`ledger_blockchain_height{channel="c" 1`))
+	assert.Error(t, err)
+
+	_, err = Parse(strings.NewReader(`ledger_blockchain_height{channel="c"} one`))
+	assert.EqualError(t, err, `line 1: invalid value "one" in "ledger_blockchain_height{channel=\"c\"} one"`)
+}
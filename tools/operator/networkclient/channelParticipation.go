@@ -0,0 +1,318 @@
+package networkclient
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-test/tools/operator/channelconfig"
+	"github.com/hyperledger/fabric-test/tools/operator/fabricclient"
+	"github.com/hyperledger/fabric-test/tools/operator/logger"
+	"github.com/hyperledger/fabric-test/tools/operator/networkspec"
+	"github.com/hyperledger/fabric-test/tools/operator/paths"
+	"github.com/pkg/errors"
+)
+
+const participationPath = "/participation/v1/channels"
+
+//participationChannel -- a channel as reported by the channel participation api of an orderer
+type participationChannel struct {
+	Name              string `json:"name"`
+	URL               string `json:"url"`
+	ConsensusRelation string `json:"consensusRelation,omitempty"`
+	Status            string `json:"status,omitempty"`
+	Height            uint64 `json:"height,omitempty"`
+}
+
+//participationChannelList -- the channels an orderer is a member of
+type participationChannelList struct {
+	SystemChannel *participationChannel  `json:"systemChannel"`
+	Channels      []participationChannel `json:"channels"`
+}
+
+//participationClient -- a client of the channel participation api of the admin endpoint of an orderer
+type participationClient struct {
+	node   string
+	url    string
+	client *http.Client
+}
+
+//JoinOrdererChannels -- joins every orderer to the application channels of the network spec it is not a member of
+//yet, using the channel genesis blocks generated for networks without system channel
+func JoinOrdererChannels(config networkspec.Config) error {
+
+	nodes, err := readNetworkNodes(config)
+	if err != nil {
+		return err
+	}
+	for _, channel := range channelNames(config, false) {
+		blockPath := ChannelBlockPath(config, channel)
+		block, err := ioutil.ReadFile(blockPath)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read genesis block of channel %s, it is generated for networks with orderer bootstrapMethod none", channel)
+		}
+		for _, orderer := range nodes.orderers {
+			client, err := newParticipationClient(config, orderer)
+			if err != nil {
+				return err
+			}
+			_, found, err := client.channel(channel)
+			if err != nil {
+				return err
+			}
+			if found {
+				logger.INFO(fmt.Sprintf("%s already joined channel %s", orderer.name, channel))
+				continue
+			}
+			info, err := client.join(channel, block)
+			if err != nil {
+				return err
+			}
+			logger.INFO(fmt.Sprintf("Joined %s to channel %s as %s, status %s", orderer.name, channel, info.ConsensusRelation, info.Status))
+		}
+	}
+	return nil
+}
+
+//joinOrdererChannel -- joins an orderer to a channel with the latest config block of the channel on the first orderer,
+//unless it is already a member of the channel
+func joinOrdererChannel(config networkspec.Config, nodes networkNodes, orderer networkNode, channel string) error {
+
+	client, err := newParticipationClient(config, orderer)
+	if err != nil {
+		return err
+	}
+	_, found, err := client.channel(channel)
+	if err != nil {
+		return err
+	}
+	if found {
+		logger.INFO(fmt.Sprintf("%s already joined channel %s", orderer.name, channel))
+		return nil
+	}
+	source, err := nodes.orderers[0].blockSource(false)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+	block, err := channelconfig.FetchBlock(source, channel)
+	if err != nil {
+		return err
+	}
+	blockBytes, err := proto.Marshal(block)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal config block of channel %s", channel)
+	}
+	info, err := client.join(channel, blockBytes)
+	if err != nil {
+		return err
+	}
+	logger.INFO(fmt.Sprintf("Joined %s to channel %s with config block %d as %s, status %s", orderer.name, channel, block.Header.Number, info.ConsensusRelation, info.Status))
+	return nil
+}
+
+//ListOrdererChannels -- lists the channels every orderer is a member of with its consensus relation, status and height
+func ListOrdererChannels(config networkspec.Config) error {
+
+	nodes, err := readNetworkNodes(config)
+	if err != nil {
+		return err
+	}
+	for _, orderer := range nodes.orderers {
+		client, err := newParticipationClient(config, orderer)
+		if err != nil {
+			return err
+		}
+		list, err := client.list()
+		if err != nil {
+			return err
+		}
+		channels := list.Channels
+		if list.SystemChannel != nil {
+			channels = append([]participationChannel{*list.SystemChannel}, channels...)
+		}
+		logger.INFO(fmt.Sprintf("%s is a member of %d channels", orderer.name, len(channels)))
+		for _, channel := range channels {
+			info, _, err := client.channel(channel.Name)
+			if err != nil {
+				return err
+			}
+			logger.INFO(fmt.Sprintf("  %s: consensusRelation %s, status %s, height %d", info.Name, info.ConsensusRelation, info.Status, info.Height))
+		}
+	}
+	return nil
+}
+
+//RemoveOrdererChannels -- removes every orderer from the channels of removeChannel
+func RemoveOrdererChannels(config networkspec.Config) error {
+
+	if len(config.RemoveChannels) == 0 {
+		return errors.New("no channels found in removeChannel")
+	}
+	nodes, err := readNetworkNodes(config)
+	if err != nil {
+		return err
+	}
+	for _, channel := range config.RemoveChannels {
+		for _, orderer := range nodes.orderers {
+			client, err := newParticipationClient(config, orderer)
+			if err != nil {
+				return err
+			}
+			_, found, err := client.channel(channel)
+			if err != nil {
+				return err
+			}
+			if !found {
+				logger.INFO(fmt.Sprintf("%s is not a member of channel %s, skipping", orderer.name, channel))
+				continue
+			}
+			err = client.remove(channel)
+			if err != nil {
+				return err
+			}
+			logger.INFO(fmt.Sprintf("Removed %s from channel %s", orderer.name, channel))
+		}
+	}
+	return nil
+}
+
+//newParticipationClient -- a client of the admin endpoint of the orderer authenticating with the tls client
+//certificate of the admin of its organization when the network uses tls
+func newParticipationClient(config networkspec.Config, orderer networkNode) (participationClient, error) {
+
+	if orderer.adminURL == "" {
+		return participationClient{}, errors.Errorf("no admin url found for %s in the connection profiles", orderer.name)
+	}
+	client := &http.Client{Timeout: 30 * time.Second}
+	if config.TLS == "true" || config.TLS == "mutual" {
+		rootCAs := x509.NewCertPool()
+		if !rootCAs.AppendCertsFromPEM([]byte(orderer.tlsCACert)) {
+			return participationClient{}, errors.Errorf("failed to read the tls ca certificate of %s", orderer.name)
+		}
+		userTLSDir := paths.JoinPath(paths.CryptoConfigDir(config.ArtifactsLocation), fmt.Sprintf("ordererOrganizations/%s/users/Admin@%s/tls", orderer.org, orderer.org))
+		certificates, err := fabricclient.ClientTLSCertificate(userTLSDir)
+		if err != nil {
+			return participationClient{}, err
+		}
+		if len(certificates) == 0 {
+			return participationClient{}, errors.Errorf("no tls client certificate found in %s", userTLSDir)
+		}
+		client.Transport = &http.Transport{
+			TLSClientConfig: &tls.Config{RootCAs: rootCAs, Certificates: certificates, ServerName: orderer.sslTarget},
+		}
+	}
+	return participationClient{node: orderer.name, url: orderer.adminURL, client: client}, nil
+}
+
+//list -- the channels the orderer is a member of
+func (p participationClient) list() (participationChannelList, error) {
+
+	var list participationChannelList
+	req, err := http.NewRequest(http.MethodGet, p.url+participationPath, nil)
+	if err != nil {
+		return list, errors.Wrap(err, "failed to create request")
+	}
+	_, err = p.do(req, &list, http.StatusOK)
+	if err != nil {
+		return list, errors.Wrapf(err, "failed to list the channels of %s", p.node)
+	}
+	return list, nil
+}
+
+//channel -- the details of a channel of the orderer and whether it is a member of the channel
+func (p participationClient) channel(name string) (participationChannel, bool, error) {
+
+	var info participationChannel
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s%s/%s", p.url, participationPath, name), nil)
+	if err != nil {
+		return info, false, errors.Wrap(err, "failed to create request")
+	}
+	status, err := p.do(req, &info, http.StatusOK, http.StatusNotFound)
+	if err != nil {
+		return info, false, errors.Wrapf(err, "failed to get channel %s of %s", name, p.node)
+	}
+	return info, status == http.StatusOK, nil
+}
+
+//join -- joins the orderer to the channel of the config block
+func (p participationClient) join(name string, configBlock []byte) (participationChannel, error) {
+
+	var info participationChannel
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("config-block", fmt.Sprintf("%s.block", name))
+	if err == nil {
+		_, err = part.Write(configBlock)
+	}
+	if err == nil {
+		err = writer.Close()
+	}
+	if err != nil {
+		return info, errors.Wrap(err, "failed to create join request")
+	}
+	req, err := http.NewRequest(http.MethodPost, p.url+participationPath, body)
+	if err != nil {
+		return info, errors.Wrap(err, "failed to create request")
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	_, err = p.do(req, &info, http.StatusCreated)
+	if err != nil {
+		return info, errors.Wrapf(err, "failed to join %s to channel %s", p.node, name)
+	}
+	return info, nil
+}
+
+//remove -- removes the orderer from the channel
+func (p participationClient) remove(name string) error {
+
+	req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("%s%s/%s", p.url, participationPath, name), nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to create request")
+	}
+	_, err = p.do(req, nil, http.StatusNoContent)
+	if err != nil {
+		return errors.Wrapf(err, "failed to remove %s from channel %s", p.node, name)
+	}
+	return nil
+}
+
+//do -- sends the request and decodes the response into out when its status is the first of the expected statuses
+func (p participationClient) do(req *http.Request, out interface{}, expected ...int) (int, error) {
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, errors.Wrap(err, "failed to read response")
+	}
+	for i, status := range expected {
+		if resp.StatusCode != status {
+			continue
+		}
+		if i == 0 && out != nil {
+			err = json.Unmarshal(body, out)
+			if err != nil {
+				return resp.StatusCode, errors.Wrap(err, "failed to decode response")
+			}
+		}
+		return resp.StatusCode, nil
+	}
+	var participationErr struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(body, &participationErr) == nil && participationErr.Error != "" {
+		return resp.StatusCode, errors.Errorf("%s: %s", resp.Status, participationErr.Error)
+	}
+	return resp.StatusCode, errors.Errorf("unexpected response %s", resp.Status)
+}
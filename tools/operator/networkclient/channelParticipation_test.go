@@ -0,0 +1,80 @@
+package networkclient
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParticipationClient(t *testing.T) {
+
+	channels := map[string][]byte{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, participationPath), "/")
+		switch {
+		case r.Method == http.MethodGet && name == "":
+			list := participationChannelList{}
+			for channel := range channels {
+				list.Channels = append(list.Channels, participationChannel{Name: channel, URL: participationPath + "/" + channel})
+			}
+			json.NewEncoder(w).Encode(list)
+		case r.Method == http.MethodGet:
+			if _, ok := channels[name]; !ok {
+				w.WriteHeader(http.StatusNotFound)
+				json.NewEncoder(w).Encode(map[string]string{"error": "channel does not exist"})
+				return
+			}
+			json.NewEncoder(w).Encode(participationChannel{Name: name, ConsensusRelation: "consenter", Status: "active", Height: 1})
+		case r.Method == http.MethodPost:
+			file, header, err := r.FormFile("config-block")
+			require.NoError(t, err)
+			block, err := ioutil.ReadAll(file)
+			require.NoError(t, err)
+			channel := strings.TrimSuffix(header.Filename, ".block")
+			if _, ok := channels[channel]; ok {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				json.NewEncoder(w).Encode(map[string]string{"error": "cannot join: channel already exists"})
+				return
+			}
+			channels[channel] = block
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(participationChannel{Name: channel, ConsensusRelation: "consenter", Status: "onboarding"})
+		case r.Method == http.MethodDelete:
+			delete(channels, name)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+	client := participationClient{node: "orderer0-ordererorg1", url: server.URL, client: server.Client()}
+
+	_, found, err := client.channel("testorgschannel0")
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	info, err := client.join("testorgschannel0", []byte("genesis block"))
+	require.NoError(t, err)
+	assert.Equal(t, participationChannel{Name: "testorgschannel0", ConsensusRelation: "consenter", Status: "onboarding"}, info)
+	assert.Equal(t, []byte("genesis block"), channels["testorgschannel0"])
+
+	_, err = client.join("testorgschannel0", []byte("genesis block"))
+	assert.EqualError(t, err, "failed to join orderer0-ordererorg1 to channel testorgschannel0: 405 Method Not Allowed: cannot join: channel already exists")
+
+	list, err := client.list()
+	require.NoError(t, err)
+	assert.Nil(t, list.SystemChannel)
+	assert.Equal(t, []participationChannel{{Name: "testorgschannel0", URL: participationPath + "/testorgschannel0"}}, list.Channels)
+
+	info, found, err = client.channel("testorgschannel0")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, uint64(1), info.Height)
+
+	require.NoError(t, client.remove("testorgschannel0"))
+	assert.Empty(t, channels)
+}
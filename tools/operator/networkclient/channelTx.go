@@ -6,38 +6,89 @@ import (
 	"github.com/hyperledger/fabric-test/tools/operator/fabricconfiguration"
 	"github.com/hyperledger/fabric-test/tools/operator/networkspec"
 	"github.com/hyperledger/fabric-test/tools/operator/paths"
+	"github.com/hyperledger/fabric-test/tools/operator/plan"
 )
 
 //GenerateChannelTransaction - to generate channel transactions
 func GenerateChannelTransaction(config networkspec.Config, configtxPath string) error {
 
-	artifactsLocation := paths.ChannelArtifactsDir(config.ArtifactsLocation)
-	var outputPath string
-	for i := 0; i < config.NumChannels; i++ {
-		channelName := fmt.Sprintf("testorgschannel%d", i)
-		outputPath = paths.JoinPath(artifactsLocation, fmt.Sprintf("%s.tx", channelName))
-		configFilesPath := paths.ConfigFilesDir(false)
-		configtxgen := fabricconfiguration.Configtxgen{ConfigPath: configFilesPath, OutputChannelCreateTx: outputPath, Profile: "testorgschannel", ChannelID: channelName}
+	for _, configtxgen := range channelTransactions(config) {
 		err := fabricconfiguration.CreateConfigtx(&configtxgen, config)
 		if err != nil {
 			return err
 		}
+	}
+	if config.Orderer.BootstrapMethod == "none" {
+		return GenerateChannelBlocks(config)
+	}
+	return nil
+}
+
+//PlanChannelTransaction -- records in p the channel artifacts GenerateChannelTransaction would generate, and the
+//orderers of a network without system channel joining its channels
+func PlanChannelTransaction(config networkspec.Config, p *plan.Plan) {
+
+	for _, configtxgen := range channelTransactions(config) {
+		tx := plan.ChannelTransaction{Channel: configtxgen.ChannelID, Kind: "channel creation tx", Path: configtxgen.OutputChannelCreateTx}
+		if configtxgen.OutputAnchorPeersUpdate != "" {
+			tx = plan.ChannelTransaction{Channel: configtxgen.ChannelID, Kind: "anchor peers update", Org: configtxgen.OrgName, Path: configtxgen.OutputAnchorPeersUpdate}
+		}
+		p.ChannelTransactions = append(p.ChannelTransactions, tx)
+	}
+	if config.Orderer.BootstrapMethod != "none" {
+		return
+	}
+	for _, channelName := range channelNames(config, false) {
+		p.ChannelTransactions = append(p.ChannelTransactions, plan.ChannelTransaction{Channel: channelName, Kind: "channel genesis block", Path: ChannelBlockPath(config, channelName)})
+		for _, org := range config.OrdererOrganizations {
+			p.ChannelTransactions = append(p.ChannelTransactions, plan.ChannelTransaction{Channel: channelName, Kind: "orderer join", Org: org.Name, Path: ChannelBlockPath(config, channelName)})
+		}
+	}
+}
 
+//channelTransactions -- the configtxgen runs generating the channel creation transactions, unless the network has no
+//system channel, and the anchor peers updates of the channels of the network spec
+func channelTransactions(config networkspec.Config) []fabricconfiguration.Configtxgen {
+
+	var output []fabricconfiguration.Configtxgen
+	artifactsLocation := paths.ChannelArtifactsDir(config.ArtifactsLocation)
+	for i := 0; i < config.NumChannels; i++ {
+		channelName := fmt.Sprintf("testorgschannel%d", i)
+		if config.Orderer.BootstrapMethod != "none" {
+			outputPath := paths.JoinPath(artifactsLocation, fmt.Sprintf("%s.tx", channelName))
+			configFilesPath := paths.ConfigFilesDir(config.NetworkName, false)
+			output = append(output, fabricconfiguration.Configtxgen{ConfigPath: configFilesPath, OutputChannelCreateTx: outputPath, Profile: "testorgschannel", ChannelID: channelName})
+		}
 		for j := 0; j < len(config.PeerOrganizations); j++ {
-			outputPath = paths.JoinPath(artifactsLocation, fmt.Sprintf("%s%sanchor.tx", channelName, config.PeerOrganizations[j].Name))
+			outputPath := paths.JoinPath(artifactsLocation, fmt.Sprintf("%s%sanchor.tx", channelName, config.PeerOrganizations[j].Name))
 			cryptoConfigPath := paths.CryptoConfigDir(config.ArtifactsLocation)
-			configtxgen := fabricconfiguration.Configtxgen{
+			output = append(output, fabricconfiguration.Configtxgen{
 				OutputAnchorPeersUpdate: outputPath,
 				Profile:                 "testorgschannel",
 				ChannelID:               channelName,
 				OrgName:                 config.PeerOrganizations[j].Name,
 				ArtifactsLocation:       cryptoConfigPath,
-			}
-			err := fabricconfiguration.CreateConfigtx(&configtxgen, config)
-			if err != nil {
-				return err
-			}
+			})
+		}
+	}
+	return output
+}
+
+//GenerateChannelBlocks - to generate the genesis blocks the orderers of a network without system channel join the
+//application channels with
+func GenerateChannelBlocks(config networkspec.Config) error {
+
+	for _, channelName := range channelNames(config, false) {
+		configtxgen := fabricconfiguration.Configtxgen{OutputChannelBlock: ChannelBlockPath(config, channelName), Profile: "testorgschannel", ChannelID: channelName}
+		err := fabricconfiguration.CreateConfigtx(&configtxgen, config)
+		if err != nil {
+			return err
 		}
 	}
 	return nil
 }
+
+//ChannelBlockPath -- the path of the genesis block of an application channel of a network without system channel
+func ChannelBlockPath(config networkspec.Config, channelName string) string {
+	return paths.JoinPath(paths.ChannelArtifactsDir(config.ArtifactsLocation), fmt.Sprintf("%s.block", channelName))
+}
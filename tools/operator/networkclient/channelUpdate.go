@@ -0,0 +1,101 @@
+package networkclient
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-test/tools/operator/channelconfig"
+	"github.com/hyperledger/fabric-test/tools/operator/fabricclient"
+	"github.com/hyperledger/fabric-test/tools/operator/ledger"
+	"github.com/hyperledger/fabric-test/tools/operator/logger"
+	"github.com/hyperledger/fabric-test/tools/operator/networkspec"
+	"github.com/pkg/errors"
+)
+
+const (
+	consortiumName      = "FabricConsortium"
+	configCommitTimeout = 2 * time.Minute
+	configPollInterval  = 2 * time.Second
+)
+
+//updateChannel -- applies the changes to the latest config of a channel and submits the update to the first orderer,
+//signed by the admins of signerOrgs and the orderer organization, then waits until the orderer committed it
+func updateChannel(nodes networkNodes, channel string, signerOrgs []string, changes ...channelconfig.Change) error {
+
+	groups := channelconfig.Groups(changes)
+	orderer := nodes.orderers[0]
+	source, err := orderer.blockSource(false)
+	if err != nil {
+		return &channelconfig.UpdateError{Channel: channel, Group: groups, Err: err}
+	}
+	defer source.Close()
+	config, err := channelconfig.Fetch(source, channel)
+	if err != nil {
+		return &channelconfig.UpdateError{Channel: channel, Group: groups, Err: err}
+	}
+	c, err := channelconfig.Apply(channel, config, changes...)
+	if err != nil {
+		return err
+	}
+	if !channelconfig.Changed(c) {
+		logger.INFO(fmt.Sprintf("%s of channel %s is already up to date", groups, channel))
+		return nil
+	}
+	var signers []*fabricclient.Identity
+	for _, orgName := range signerOrgs {
+		identity, err := nodes.admin(orgName)
+		if err != nil {
+			return &channelconfig.UpdateError{Channel: channel, Group: groups, Err: err}
+		}
+		signers = append(signers, identity)
+	}
+	envelope, err := channelconfig.Envelope(channel, c, orderer.identity, signers...)
+	if err != nil {
+		return &channelconfig.UpdateError{Channel: channel, Group: groups, Err: err}
+	}
+	conn, err := fabricclient.Dial(orderer.url, orderer.sslTarget, orderer.tlsCACert, orderer.clientCertificates)
+	if err != nil {
+		return &channelconfig.UpdateError{Channel: channel, Group: groups, Err: err}
+	}
+	defer conn.Close()
+	logger.INFO(fmt.Sprintf("Submitting config update of %s for channel %s", groups, channel))
+	err = fabricclient.Broadcast(conn, envelope)
+	if err != nil {
+		return &channelconfig.UpdateError{Channel: channel, Group: groups, Err: err}
+	}
+	err = waitForConfig(source, channel, config.Sequence)
+	if err != nil {
+		return &channelconfig.UpdateError{Channel: channel, Group: groups, Err: err}
+	}
+	logger.INFO(fmt.Sprintf("Successfully updated %s of channel %s", groups, channel))
+	return nil
+}
+
+//waitForConfig -- waits until the node serves a config of the channel newer than sequence
+func waitForConfig(source ledger.BlockSource, channel string, sequence uint64) error {
+
+	var config *common.Config
+	var err error
+	deadline := time.Now().Add(configCommitTimeout)
+	for time.Now().Before(deadline) {
+		config, err = channelconfig.Fetch(source, channel)
+		if err == nil && config.Sequence > sequence {
+			return nil
+		}
+		time.Sleep(configPollInterval)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "config update was not committed within %s", configCommitTimeout)
+	}
+	return errors.Errorf("config update was not committed within %s", configCommitTimeout)
+}
+
+//peerOrgNames -- names of the peer organizations of the network
+func peerOrgNames(config networkspec.Config) []string {
+	var orgNames []string
+	for _, peerOrg := range config.PeerOrganizations {
+		orgNames = append(orgNames, peerOrg.Name)
+	}
+	return orgNames
+}
@@ -0,0 +1,66 @@
+package networkclient
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-test/tools/operator/channelconfig"
+	"github.com/hyperledger/fabric-test/tools/operator/logger"
+	"github.com/hyperledger/fabric-test/tools/operator/networkspec"
+	"github.com/pkg/errors"
+)
+
+//ConfigUpdate -- applies the configUpdates of the network spec to their channels, one config update per channel and entry
+func ConfigUpdate(config networkspec.Config) error {
+
+	if len(config.ConfigUpdates) == 0 {
+		return errors.New("no configUpdates found in the network spec")
+	}
+	nodes, err := readNetworkNodes(config)
+	if err != nil {
+		return err
+	}
+	for i, update := range config.ConfigUpdates {
+		changes, err := configUpdateChanges(update)
+		if err != nil {
+			return errors.Wrapf(err, "invalid configUpdates entry %d", i)
+		}
+		channels := update.Channels
+		if len(channels) == 0 {
+			channels = channelNames(config, false)
+		}
+		signerOrgs := update.Signers
+		if len(signerOrgs) == 0 {
+			signerOrgs = peerOrgNames(config)
+		}
+		for _, channel := range channels {
+			err = updateChannel(nodes, channel, signerOrgs, changes...)
+			if err != nil {
+				logger.ERROR(fmt.Sprintf("Failed to apply configUpdates entry %d to channel %s", i, channel))
+				return err
+			}
+		}
+	}
+	logger.INFO("Successfully applied all config updates")
+	return nil
+}
+
+//configUpdateChanges -- the changes of the edits of a configUpdates entry, in the order they are listed
+func configUpdateChanges(update networkspec.ConfigUpdate) ([]channelconfig.Change, error) {
+
+	if len(update.Edits) == 0 {
+		return nil, errors.New("no edits given")
+	}
+	var changes []channelconfig.Change
+	for _, edit := range update.Edits {
+		path, ok := edit.Key.(string)
+		if !ok {
+			return nil, errors.Errorf("config path %v is not a string", edit.Key)
+		}
+		change, err := channelconfig.Edit(path, edit.Value)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
@@ -1,43 +1,238 @@
 package networkclient
 
 import (
-	"errors"
 	"fmt"
+	"io/ioutil"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/hyperledger/fabric-protos-go/orderer"
+	"github.com/hyperledger/fabric-protos-go/orderer/etcdraft"
+	"github.com/hyperledger/fabric-test/tools/operator/channelconfig"
 	"github.com/hyperledger/fabric-test/tools/operator/logger"
 	"github.com/hyperledger/fabric-test/tools/operator/networkspec"
 	"github.com/hyperledger/fabric-test/tools/operator/paths"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
 )
 
+const (
+	ordererRestartTimeout = 3 * time.Minute
+	ordererBasePort       = 30000
+)
+
+//ConsensusMismatchError -- an orderer that does not report the expected consensus type or state for a channel
+type ConsensusMismatchError struct {
+	Channel  string
+	Orderer  string
+	Expected string
+	Actual   string
+}
+
+func (e *ConsensusMismatchError) Error() string {
+	return fmt.Sprintf("%s reports consensus %s for channel %s, expected %s", e.Orderer, e.Actual, e.Channel, e.Expected)
+}
+
 //MigrateToRaft -  to migrate from solo or kafka to raft
 func MigrateToRaft(config networkspec.Config, kubeConfigPath string) error {
 
-	if config.K8s.DataPersistence != "true" {
+	if kubeConfigPath != "" && config.K8s.DataPersistence != "true" {
 		return errors.New("MigrateToRaft: Data persistance is disabled. Make sure it is enabled")
 	}
-	artifactsLocation := config.ArtifactsLocation
-	ordererOrgs := []string{}
-	numOrderersPerOrg := []string{}
-	for j := 0; j < len(config.OrdererOrganizations); j++ {
-		ordererOrgs = append(ordererOrgs, config.OrdererOrganizations[j].Name)
-		numOrderersPerOrg = append(numOrderersPerOrg, fmt.Sprintf("%d", config.OrdererOrganizations[j].NumOrderers))
+	nodes, err := readNetworkNodes(config)
+	if err != nil {
+		return err
+	}
+	consenters, err := raftConsenters(config)
+	if err != nil {
+		return err
 	}
-	if !(strings.HasPrefix(artifactsLocation, "/")) {
-		currentDir, err := paths.GetCurrentDir()
+	channels := channelNames(config, true)
+	for _, channel := range channels {
+		logger.INFO("Changing the consensus state to maintenance for channel ", channel)
+		err = updateChannel(nodes, channel, nil, channelconfig.SetConsensusState(orderer.ConsensusType_STATE_MAINTENANCE))
 		if err != nil {
-			logger.ERROR("MigrateToRaft: GetCurrentDir failed; unable to join with ArtifactsLocation", artifactsLocation)
 			return err
 		}
-		artifactsLocation = paths.JoinPath(currentDir, artifactsLocation)
+		logger.INFO("Changing the consensus type to etcdraft for channel ", channel)
+		err = updateChannel(nodes, channel, nil, channelconfig.MigrateToEtcdRaft(consenters, raftOptions(config)))
+		if err != nil {
+			return err
+		}
+	}
+	err = VerifyLedger(config)
+	if err != nil {
+		return err
 	}
-	ordererOrg := strings.Join(ordererOrgs[:], ",")
-	numOrderers := strings.Join(numOrderersPerOrg[:], ",")
-	args := []string{kubeConfigPath, config.OrdererOrganizations[0].MSPID, artifactsLocation, ordererOrg, numOrderers, fmt.Sprintf("%d", config.NumChannels)}
-	_, err := ExecuteCommand(paths.JoinPath(paths.ScriptsDir(), "migrateToRaft.sh"), args, true)
+	err = checkConsensus(nodes, channels, orderer.ConsensusType_STATE_MAINTENANCE)
 	if err != nil {
 		return err
 	}
-	logger.INFO("Successfully migrated from kafka to etcdraft")
+	err = restartOrderers(config, kubeConfigPath, nodes)
+	if err != nil {
+		return err
+	}
+	err = waitForOrderers(nodes, channels, orderer.ConsensusType_STATE_MAINTENANCE)
+	if err != nil {
+		return err
+	}
+	for _, channel := range channels {
+		logger.INFO("Changing the consensus state to normal for channel ", channel)
+		err = updateChannel(nodes, channel, nil, channelconfig.SetConsensusState(orderer.ConsensusType_STATE_NORMAL))
+		if err != nil {
+			return err
+		}
+	}
+	err = VerifyLedger(config)
+	if err != nil {
+		return err
+	}
+	err = checkConsensus(nodes, channels, orderer.ConsensusType_STATE_NORMAL)
+	if err != nil {
+		return err
+	}
+	logger.INFO("Successfully migrated from ", config.Orderer.OrdererType, " to etcdraft")
 	return nil
 }
+
+//raftConsenters -- one consenter for every orderer, using its tls server certificate as client and server certificate
+func raftConsenters(config networkspec.Config) ([]*etcdraft.Consenter, error) {
+
+	var consenters []*etcdraft.Consenter
+	port := config.Port(ordererBasePort)
+	for _, ordererOrg := range config.OrdererOrganizations {
+		for i := 0; i < ordererOrg.NumOrderers; i++ {
+			ordererName := fmt.Sprintf("orderer%d-%s", i, ordererOrg.Name)
+			cert, err := ordererTLSCert(config, ordererOrg.Name, ordererName)
+			if err != nil {
+				return nil, err
+			}
+			consenters = append(consenters, &etcdraft.Consenter{Host: ordererName, Port: uint32(port), ClientTlsCert: cert, ServerTlsCert: cert})
+			port++
+		}
+	}
+	return consenters, nil
+}
+
+//ordererTLSCert -- reads the tls server certificate of an orderer, which it also uses as cluster client certificate
+func ordererTLSCert(config networkspec.Config, orgName, ordererName string) ([]byte, error) {
+
+	certPath := paths.JoinPath(paths.OrdererOrgsDir(config.ArtifactsLocation), fmt.Sprintf("%s/orderers/%s.%s/tls/server.crt", orgName, ordererName, orgName))
+	cert, err := ioutil.ReadFile(certPath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read tls certificate of %s", ordererName)
+	}
+	return cert, nil
+}
+
+//raftOptions -- etcdraft options of the network spec with the defaults of configtxgen for the unset ones
+func raftOptions(config networkspec.Config) *etcdraft.Options {
+
+	options := config.Orderer.EtcdraftOptions
+	raftOptions := &etcdraft.Options{
+		TickInterval:         options.TickInterval,
+		ElectionTick:         options.ElectionTick,
+		HeartbeatTick:        options.HeartbeatTick,
+		MaxInflightBlocks:    options.MaxInflightBlocks,
+		SnapshotIntervalSize: 100 * 1024 * 1024,
+	}
+	if raftOptions.TickInterval == "" {
+		raftOptions.TickInterval = "500ms"
+	}
+	if raftOptions.ElectionTick == 0 {
+		raftOptions.ElectionTick = 10
+	}
+	if raftOptions.HeartbeatTick == 0 {
+		raftOptions.HeartbeatTick = 1
+	}
+	if raftOptions.MaxInflightBlocks == 0 {
+		raftOptions.MaxInflightBlocks = 5
+	}
+	if size, err := strconv.Atoi(strings.Trim(options.SnapshotIntervalSize, " MB")); err == nil && size > 0 {
+		raftOptions.SnapshotIntervalSize = uint32(size * 1024 * 1024)
+	}
+	return raftOptions
+}
+
+//checkConsensus -- verifies that every orderer reports etcdraft in the given state for every channel
+func checkConsensus(nodes networkNodes, channels []string, state orderer.ConsensusType_State) error {
+
+	expected := fmt.Sprintf("etcdraft/%s", state)
+	for _, node := range nodes.orderers {
+		source, err := node.blockSource(false)
+		if err != nil {
+			return err
+		}
+		for _, channel := range channels {
+			config, err := channelconfig.Fetch(source, channel)
+			if err != nil {
+				source.Close()
+				return err
+			}
+			consensusType, err := channelconfig.ConsensusType(config)
+			if err != nil {
+				source.Close()
+				return err
+			}
+			actual := fmt.Sprintf("%s/%s", consensusType.Type, consensusType.State)
+			if actual != expected {
+				source.Close()
+				return &ConsensusMismatchError{Channel: channel, Orderer: node.name, Expected: expected, Actual: actual}
+			}
+		}
+		source.Close()
+	}
+	logger.INFO("All orderers report consensus ", expected, " for all channels")
+	return nil
+}
+
+//restartOrderers -- restarts the orderers so that they start the etcdraft chains
+func restartOrderers(config networkspec.Config, kubeConfigPath string, nodes networkNodes) error {
+
+	if kubeConfigPath == "" {
+		for _, node := range nodes.orderers {
+			_, err := ExecuteCommand("docker", []string{"restart", config.ContainerName(node.name)}, true)
+			if err != nil {
+				return errors.Wrapf(err, "failed to restart %s", node.name)
+			}
+		}
+		return nil
+	}
+	kubeConfig, err := clientcmd.BuildConfigFromFlags("", kubeConfigPath)
+	if err != nil {
+		logger.ERROR("Failed to create config for kubernetes")
+		return err
+	}
+	clientset, err := kubernetes.NewForConfig(kubeConfig)
+	if err != nil {
+		logger.ERROR("Failed to create clientset for kubernetes")
+		return err
+	}
+	for _, node := range nodes.orderers {
+		podName := fmt.Sprintf("%s-0", node.name)
+		err = clientset.CoreV1().Pods(config.K8s.Namespace).Delete(podName, &metav1.DeleteOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "failed to restart %s", podName)
+		}
+		logger.INFO("Deleted pod ", podName, " to restart ", node.name)
+	}
+	return nil
+}
+
+//waitForOrderers -- waits until all orderers serve the channels again after a restart
+func waitForOrderers(nodes networkNodes, channels []string, state orderer.ConsensusType_State) error {
+
+	var err error
+	deadline := time.Now().Add(ordererRestartTimeout)
+	for time.Now().Before(deadline) {
+		time.Sleep(10 * time.Second)
+		if err = checkConsensus(nodes, channels, state); err == nil {
+			return nil
+		}
+		logger.INFO("Waiting for orderers to restart: ", err.Error())
+	}
+	return errors.Wrapf(err, "orderers did not restart within %s", ordererRestartTimeout)
+}
@@ -0,0 +1,172 @@
+package networkclient
+
+import (
+	"crypto/tls"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hyperledger/fabric-test/tools/operator/fabricclient"
+	"github.com/hyperledger/fabric-test/tools/operator/ledger"
+	"github.com/hyperledger/fabric-test/tools/operator/networkspec"
+	"github.com/hyperledger/fabric-test/tools/operator/paths"
+	"github.com/pkg/errors"
+)
+
+//networkNode -- a peer or orderer of the network as listed in the connection profiles
+type networkNode struct {
+	name               string
+	org                string
+	url                string
+	sslTarget          string
+	tlsCACert          string
+	metricsURL         string
+	adminURL           string
+	identity           *fabricclient.Identity
+	clientCertificates []tls.Certificate
+}
+
+//networkNodes -- the peers and orderers of the network, the admin identities of their organizations and the nodes
+//the connection profiles list for every channel
+type networkNodes struct {
+	peers    []networkNode
+	orderers []networkNode
+	admins   map[string]*fabricclient.Identity
+	channels map[string][]string
+}
+
+//blockSource -- connects to the deliver service of the node
+func (n networkNode) blockSource(peer bool) (*ledger.DeliverSource, error) {
+
+	conn, err := fabricclient.Dial(n.url, n.sslTarget, n.tlsCACert, n.clientCertificates)
+	if err != nil {
+		return nil, err
+	}
+	if peer {
+		return ledger.NewPeerSource(n.name, conn, n.identity, fabricclient.TLSCertHash(n.clientCertificates)), nil
+	}
+	return ledger.NewOrdererSource(n.name, conn, n.identity, fabricclient.TLSCertHash(n.clientCertificates)), nil
+}
+
+//readNetworkNodes -- reads the peers, orderers and admin identities from the connection profiles of the network
+func readNetworkNodes(config networkspec.Config) (networkNodes, error) {
+
+	nodes := networkNodes{admins: make(map[string]*fabricclient.Identity), channels: make(map[string][]string)}
+	connProfilesDir := paths.ConnectionProfilesDir(config.ArtifactsLocation)
+	ordererOrgs := make(map[string]string)
+	for _, ordererOrg := range config.OrdererOrganizations {
+		ordererOrgs[ordererOrg.MSPID] = ordererOrg.Name
+	}
+	for _, peerOrg := range config.PeerOrganizations {
+		connProfile, err := fabricclient.ConnectionProfile(paths.JoinPath(connProfilesDir, fmt.Sprintf("connection_profile_%s.yaml", peerOrg.Name)), peerOrg.Name)
+		if err != nil {
+			return nodes, err
+		}
+		organization, ok := connProfile.Organizations[peerOrg.Name]
+		if !ok {
+			return nodes, errors.Errorf("organization %s not found in its connection profile", peerOrg.Name)
+		}
+		identity, err := fabricclient.OrganizationIdentity(organization)
+		if err != nil {
+			return nodes, err
+		}
+		nodes.admins[peerOrg.Name] = identity
+		for channelName, channel := range connProfile.Channels {
+			for _, nodeName := range append(append([]string{}, channel.Peers...), channel.Orderers...) {
+				if !contains(nodes.channels[channelName], nodeName) {
+					nodes.channels[channelName] = append(nodes.channels[channelName], nodeName)
+				}
+			}
+		}
+		certificates, err := adminClientCertificates(config, "peerOrganizations", peerOrg.Name)
+		if err != nil {
+			return nodes, err
+		}
+		peerNames := append([]string{}, organization.Peers...)
+		sort.Strings(peerNames)
+		for _, peerName := range peerNames {
+			peer := connProfile.Peers[peerName]
+			nodes.peers = append(nodes.peers, networkNode{name: peerName, org: peerOrg.Name, url: peer.URL, sslTarget: peer.GrpcOptions.SslTarget, tlsCACert: peer.TLSCACerts.Pem, metricsURL: peer.MetricsURL, identity: identity, clientCertificates: certificates})
+		}
+		var ordererNames []string
+		for ordererName := range connProfile.Orderers {
+			ordererNames = append(ordererNames, ordererName)
+		}
+		sort.Strings(ordererNames)
+		for _, ordererName := range ordererNames {
+			if nodes.orderer(ordererName) != nil {
+				continue
+			}
+			orderer := connProfile.Orderers[ordererName]
+			ordererOrg := ordererOrgs[orderer.MSPID]
+			identity, err := fabricclient.OrdererIdentity(orderer)
+			if err != nil {
+				return nodes, err
+			}
+			nodes.admins[ordererOrg] = identity
+			certificates, err := adminClientCertificates(config, "ordererOrganizations", ordererOrg)
+			if err != nil {
+				return nodes, err
+			}
+			nodes.orderers = append(nodes.orderers, networkNode{name: ordererName, org: ordererOrg, url: orderer.URL, sslTarget: orderer.GrpcOptions.SslTarget, tlsCACert: orderer.TLSCACerts.Pem, metricsURL: orderer.MetricsURL, adminURL: orderer.AdminURL, identity: identity, clientCertificates: certificates})
+		}
+	}
+	if len(nodes.orderers) == 0 {
+		return nodes, errors.New("no orderers found in the connection profiles")
+	}
+	return nodes, nil
+}
+
+//orderer -- returns the orderer with the given name
+func (n networkNodes) orderer(name string) *networkNode {
+	for i := range n.orderers {
+		if n.orderers[i].name == name {
+			return &n.orderers[i]
+		}
+	}
+	return nil
+}
+
+//consenterName -- the name of the orderer a consenter of a channel config points to: the orderer named host, or the
+//orderer listening on host and port when consenters are addressed by IP, as on local process networks
+func (n networkNodes) consenterName(host string, port uint32) string {
+	if n.orderer(host) != nil {
+		return host
+	}
+	for _, orderer := range n.orderers {
+		if strings.HasSuffix(orderer.url, fmt.Sprintf("://%s:%d", host, port)) {
+			return orderer.name
+		}
+	}
+	return host
+}
+
+//withoutOrderer -- returns the nodes without the orderer with the given name
+func (n networkNodes) withoutOrderer(name string) networkNodes {
+	var orderers []networkNode
+	for _, orderer := range n.orderers {
+		if orderer.name != name {
+			orderers = append(orderers, orderer)
+		}
+	}
+	n.orderers = orderers
+	return n
+}
+
+//admin -- returns the admin identity of an organization
+func (n networkNodes) admin(orgName string) (*fabricclient.Identity, error) {
+	identity, ok := n.admins[orgName]
+	if !ok {
+		return nil, errors.Errorf("no admin identity found for organization %s", orgName)
+	}
+	return identity, nil
+}
+
+//adminClientCertificates -- loads the client tls certificate of the admin of an organization when the network uses mutual tls
+func adminClientCertificates(config networkspec.Config, orgsDir, orgName string) ([]tls.Certificate, error) {
+	if config.TLS != "mutual" {
+		return nil, nil
+	}
+	userTLSDir := paths.JoinPath(paths.CryptoConfigDir(config.ArtifactsLocation), fmt.Sprintf("%s/%s/users/Admin@%s/tls", orgsDir, orgName, orgName))
+	return fabricclient.ClientTLSCertificate(userTLSDir)
+}
@@ -0,0 +1,433 @@
+package networkclient
+
+import (
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-test/tools/operator/channelconfig"
+	"github.com/hyperledger/fabric-test/tools/operator/logger"
+	"github.com/hyperledger/fabric-test/tools/operator/metrics"
+	"github.com/hyperledger/fabric-test/tools/operator/networkspec"
+	"github.com/hyperledger/fabric-test/tools/operator/paths"
+	"github.com/pkg/errors"
+)
+
+const (
+	consensusStabilizeTimeout = 6 * time.Minute
+	consensusPollInterval     = 10 * time.Second
+)
+
+//AddedOrderer -- an orderer of addOrderer and the port it listens on
+type AddedOrderer struct {
+	Name  string
+	Org   string
+	MSPID string
+	Port  int
+}
+
+//AddedOrderers -- the orderers of addOrderer, numbered after the orderers of their organization and listening on the
+//ports following the orderers of ordererOrganizations
+func AddedOrderers(config networkspec.Config) ([]AddedOrderer, error) {
+
+	port := config.Port(ordererBasePort)
+	for _, org := range config.OrdererOrganizations {
+		port += org.NumOrderers
+	}
+	var orderers []AddedOrderer
+	for _, added := range config.AddOrderersToOrganization {
+		var ordererOrg *networkspec.OrdererOrganizations
+		for i := range config.OrdererOrganizations {
+			if config.OrdererOrganizations[i].Name == added.Name {
+				ordererOrg = &config.OrdererOrganizations[i]
+			}
+		}
+		if ordererOrg == nil {
+			return nil, errors.Errorf("organization %s of addOrderer is not an orderer organization of the network", added.Name)
+		}
+		for i := 0; i < added.NumOrderers; i++ {
+			orderers = append(orderers, AddedOrderer{
+				Name:  fmt.Sprintf("orderer%d-%s", ordererOrg.NumOrderers+i, ordererOrg.Name),
+				Org:   ordererOrg.Name,
+				MSPID: ordererOrg.MSPID,
+				Port:  port,
+			})
+			port++
+		}
+	}
+	return orderers, nil
+}
+
+//BootstrapBlockPath -- the path of the system channel config block an orderer of addOrderer is started with
+func BootstrapBlockPath(config networkspec.Config, ordererName string) string {
+	return paths.JoinPath(paths.ChannelArtifactsDir(config.ArtifactsLocation), fmt.Sprintf("%s.block", ordererName))
+}
+
+//AddOrderers -- adds the orderers of addOrderer to the consenters of the system channel one at a time, writes the
+//resulting config block as their bootstrap block and starts them with start, which must also add them to the
+//connection profiles. Once an orderer joined the raft cluster of the system channel it is added to every application
+//channel, waiting for the etcdraft or BFT cluster of each channel to stabilize before moving on. Without system
+//channel, with bootstrapMethod none, the orderers are started without bootstrap block and joined to every application
+//channel through the channel participation api once they are consenters of it
+func AddOrderers(config networkspec.Config, start func(orderer AddedOrderer) error) error {
+
+	if len(config.AddOrderersToOrganization) == 0 {
+		return errors.New("no orderers found in addOrderer")
+	}
+	orderers, err := AddedOrderers(config)
+	if err != nil {
+		return err
+	}
+	nodes, err := readNetworkNodes(config)
+	if err != nil {
+		return err
+	}
+	withSystemChannel := config.Orderer.BootstrapMethod != "none"
+	for _, orderer := range orderers {
+		cert, err := ordererTLSCert(config, orderer.Org, orderer.Name)
+		if err != nil {
+			return err
+		}
+		identity, err := ordererIdentityCert(config, orderer.Org, orderer.Name)
+		if err != nil {
+			return err
+		}
+		consenter := channelconfig.Consenter{Host: orderer.Name, Port: uint32(orderer.Port), MSPID: orderer.MSPID, Identity: identity, TLSCert: cert}
+		if withSystemChannel {
+			err = updateChannel(nodes, systemChannel, nil, channelconfig.AddConsenter(orderer.Org, consenter))
+			if err != nil {
+				return err
+			}
+			err = writeBootstrapBlock(nodes, BootstrapBlockPath(config, orderer.Name))
+			if err != nil {
+				return err
+			}
+		}
+		err = start(orderer)
+		if err != nil {
+			logger.ERROR("Failed to start ", orderer.Name)
+			return err
+		}
+		nodes, err = readNetworkNodes(config)
+		if err != nil {
+			return err
+		}
+		added := nodes.orderer(orderer.Name)
+		if added == nil {
+			return errors.Errorf("orderer %s not found in the connection profiles once started", orderer.Name)
+		}
+		// the existing orderers come first, they serve the channels the added orderer is not a member of yet
+		nodes = nodes.withoutOrderer(orderer.Name)
+		nodes.orderers = append(nodes.orderers, *added)
+		if withSystemChannel {
+			err = waitForConsensus(nodes, systemChannel)
+			if err != nil {
+				return err
+			}
+		}
+		for _, channel := range channelNames(config, false) {
+			err = updateChannel(nodes, channel, nil, channelconfig.AddConsenter(orderer.Org, consenter))
+			if err == nil && !withSystemChannel {
+				err = joinOrdererChannel(config, nodes, *added, channel)
+			}
+			if err == nil {
+				err = waitForConsensus(nodes, channel)
+			}
+			err = skipUnservedChannel(err)
+			if err != nil {
+				return err
+			}
+		}
+		logger.INFO("Added ", orderer.Name, " to the consenters of all channels")
+	}
+	return nil
+}
+
+//RemoveOrderers -- removes the orderers of removeOrderer from the consenters of every application channel and then of
+//the system channel, one channel at a time, waiting for the etcdraft or BFT cluster of each channel to stabilize, and
+//stops each orderer with stop once it is no longer a consenter
+func RemoveOrderers(config networkspec.Config, stop func(ordererName string) error) error {
+
+	if len(config.RemoveOrderers) == 0 {
+		return errors.New("no orderers found in removeOrderer")
+	}
+	nodes, err := readNetworkNodes(config)
+	if err != nil {
+		return err
+	}
+	for _, ordererName := range config.RemoveOrderers {
+		if nodes.orderer(ordererName) == nil {
+			return errors.Errorf("orderer %s of removeOrderer not found in the connection profiles", ordererName)
+		}
+	}
+	if len(nodes.orderers) == len(config.RemoveOrderers) {
+		return errors.New("cannot remove all orderers of the network")
+	}
+	for _, ordererName := range config.RemoveOrderers {
+		orgName := nodes.orderer(ordererName).org
+		nodes = nodes.withoutOrderer(ordererName)
+		for _, channel := range append(channelNames(config, false), systemChannel) {
+			err = updateChannel(nodes, channel, nil, channelconfig.RemoveConsenter(orgName, ordererName))
+			if err == nil {
+				err = waitForConsensus(nodes, channel)
+			}
+			err = skipUnservedChannel(err)
+			if err != nil {
+				return err
+			}
+		}
+		err = stop(ordererName)
+		if err != nil {
+			logger.ERROR("Failed to stop ", ordererName)
+			return err
+		}
+		logger.INFO("Removed ", ordererName, " from the consenters of all channels")
+	}
+	return nil
+}
+
+//RotateOrdererCerts -- replaces the tls certificates of the consenters of rotateOrdererCert with their renewed
+//certificates in the system channel and every application channel, one channel at a time, then restarts each orderer
+//with restart and waits for the etcdraft or BFT cluster of every channel to stabilize before rotating the next one
+func RotateOrdererCerts(config networkspec.Config, restart func(ordererName string) error) error {
+
+	if len(config.RotateOrdererCerts) == 0 {
+		return errors.New("no orderers found in rotateOrdererCert")
+	}
+	nodes, err := readNetworkNodes(config)
+	if err != nil {
+		return err
+	}
+	channels := channelNames(config, true)
+	for _, ordererName := range config.RotateOrdererCerts {
+		node := nodes.orderer(ordererName)
+		if node == nil {
+			return errors.Errorf("orderer %s of rotateOrdererCert not found in the connection profiles", ordererName)
+		}
+		others := nodes.withoutOrderer(ordererName)
+		if len(others.orderers) == 0 {
+			return errors.Errorf("cannot rotate the certificate of %s, the only orderer of the network", ordererName)
+		}
+		cert, err := ordererTLSCert(config, node.org, ordererName)
+		if err != nil {
+			return err
+		}
+		for _, channel := range channels {
+			err = skipUnservedChannel(updateChannel(others, channel, nil, channelconfig.SetConsenterCert(ordererName, cert)))
+			if err != nil {
+				return err
+			}
+		}
+		err = restart(ordererName)
+		if err != nil {
+			logger.ERROR("Failed to restart ", ordererName)
+			return err
+		}
+		restarted := others
+		restarted.orderers = append(restarted.orderers, *node)
+		for _, channel := range channels {
+			err = skipUnservedChannel(waitForConsensus(restarted, channel))
+			if err != nil {
+				return err
+			}
+		}
+		logger.INFO("Rotated the certificate of ", ordererName, " in all channels")
+	}
+	return nil
+}
+
+//ordererIdentityCert -- the enrollment certificate of an orderer, its identity in the consenter mapping of BFT channels
+func ordererIdentityCert(config networkspec.Config, orgName, ordererName string) ([]byte, error) {
+
+	certPath := paths.JoinPath(paths.OrdererOrgsDir(config.ArtifactsLocation), fmt.Sprintf("%[1]s/orderers/%[2]s.%[1]s/msp/signcerts/%[2]s.%[1]s-cert.pem", orgName, ordererName))
+	cert, err := ioutil.ReadFile(certPath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read enrollment certificate of %s", ordererName)
+	}
+	return cert, nil
+}
+
+//writeBootstrapBlock -- writes the latest config block of the system channel to path
+func writeBootstrapBlock(nodes networkNodes, path string) error {
+
+	source, err := nodes.orderers[0].blockSource(false)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+	block, err := channelconfig.FetchBlock(source, systemChannel)
+	if err != nil {
+		return err
+	}
+	blockBytes, err := proto.Marshal(block)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal config block of the system channel")
+	}
+	err = ioutil.WriteFile(path, blockBytes, 0644)
+	if err != nil {
+		return errors.Wrapf(err, "failed to write %s", path)
+	}
+	logger.INFO(fmt.Sprintf("Wrote config block %d of the system channel to %s", block.Header.Number, path))
+	return nil
+}
+
+//waitForConsensus -- waits until the consensus cluster of the channel is stable according to the metrics of its
+//consenters, for etcdraft and BFT channels
+func waitForConsensus(nodes networkNodes, channel string) error {
+
+	source, err := nodes.orderers[0].blockSource(false)
+	if err != nil {
+		return err
+	}
+	config, err := channelconfig.Fetch(source, channel)
+	source.Close()
+	if err != nil {
+		return err
+	}
+	consensusType, err := channelconfig.ConsensusType(config)
+	if err != nil {
+		return err
+	}
+	var names []string
+	var status func(channel string, consenters []string, snapshots map[string]metrics.Metrics) error
+	switch consensusType.Type {
+	case networkspec.EtcdRaft:
+		consenters, err := channelconfig.Consenters(config)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read the consenters of channel %s", channel)
+		}
+		for _, consenter := range consenters {
+			names = append(names, nodes.consenterName(consenter.Host, consenter.Port))
+		}
+		status = raftStatus
+	case networkspec.BFT:
+		consenters, err := channelconfig.BFTConsenters(config)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read the consenters of channel %s", channel)
+		}
+		for _, consenter := range consenters {
+			names = append(names, nodes.consenterName(consenter.Host, consenter.Port))
+		}
+		status = bftStatus
+	default:
+		logger.INFO(fmt.Sprintf("Channel %s uses consensus type %s, no consenters to check", channel, consensusType.Type))
+		return nil
+	}
+	deadline := time.Now().Add(consensusStabilizeTimeout)
+	for {
+		snapshots := make(map[string]metrics.Metrics)
+		for _, name := range names {
+			node := nodes.orderer(name)
+			if node == nil || node.metricsURL == "" {
+				return errors.Errorf("no metrics url found for consenter %s of channel %s in the connection profiles", name, channel)
+			}
+			if snapshot, scrapeErr := metrics.Scrape(node.metricsURL); scrapeErr == nil {
+				snapshots[name] = snapshot
+			}
+		}
+		err = status(channel, names, snapshots)
+		if err == nil {
+			logger.INFO(fmt.Sprintf("%s cluster of channel %s is stable with %d consenters", consensusType.Type, channel, len(names)))
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return errors.Wrapf(err, "%s cluster of channel %s did not stabilize within %s", consensusType.Type, channel, consensusStabilizeTimeout)
+		}
+		logger.INFO("Waiting for consensus cluster to stabilize: ", err.Error())
+		time.Sleep(consensusPollInterval)
+	}
+}
+
+//raftStatus -- checks that every consenter reports the full cluster size for the channel and that exactly one of them
+//is the leader and sees all consenters as active
+func raftStatus(channel string, consenters []string, snapshots map[string]metrics.Metrics) error {
+
+	labels := map[string]string{"channel": channel}
+	expected := float64(len(consenters))
+	var leaders []string
+	for _, name := range consenters {
+		snapshot, ok := snapshots[name]
+		if !ok {
+			return errors.Errorf("no metrics from %s", name)
+		}
+		size, err := snapshot.Value("consensus_etcdraft_cluster_size", labels)
+		if err != nil {
+			return errors.Errorf("%s is not part of the raft cluster of channel %s", name, channel)
+		}
+		if size != expected {
+			return errors.Errorf("%s reports %v consenters for channel %s, expected %v", name, size, channel, expected)
+		}
+		if snapshot.Sum("consensus_etcdraft_is_leader", labels) == 1 {
+			leaders = append(leaders, name)
+		}
+	}
+	if len(leaders) != 1 {
+		return errors.Errorf("channel %s has %d leaders %v, expected 1", channel, len(leaders), leaders)
+	}
+	active, err := snapshots[leaders[0]].Value("consensus_etcdraft_active_nodes", labels)
+	if err != nil {
+		return err
+	}
+	if active != expected {
+		return errors.Errorf("leader %s of channel %s sees %v active consenters, expected %v", leaders[0], channel, active, expected)
+	}
+	return nil
+}
+
+//bftStatus -- checks that every consenter reports the full cluster size for the channel, that all of them follow the
+//same leader and that exactly one of them is the leader
+func bftStatus(channel string, consenters []string, snapshots map[string]metrics.Metrics) error {
+
+	labels := map[string]string{"channel": channel}
+	expected := float64(len(consenters))
+	var leaders []string
+	leaderIDs := make(map[float64][]string)
+	for _, name := range consenters {
+		snapshot, ok := snapshots[name]
+		if !ok {
+			return errors.Errorf("no metrics from %s", name)
+		}
+		size, err := snapshot.Value("consensus_smartbft_cluster_size", labels)
+		if err != nil {
+			return errors.Errorf("%s is not part of the BFT cluster of channel %s", name, channel)
+		}
+		if size != expected {
+			return errors.Errorf("%s reports %v consenters for channel %s, expected %v", name, size, channel, expected)
+		}
+		leaderID, err := snapshot.Value("consensus_smartbft_leader_id", labels)
+		if err != nil {
+			return err
+		}
+		leaderIDs[leaderID] = append(leaderIDs[leaderID], name)
+		if snapshot.Sum("consensus_smartbft_is_leader", labels) == 1 {
+			leaders = append(leaders, name)
+		}
+	}
+	if len(leaderIDs) != 1 {
+		return errors.Errorf("consenters of channel %s follow different leaders %v", channel, leaderIDs)
+	}
+	if len(leaders) != 1 {
+		return errors.Errorf("channel %s has %d leaders %v, expected 1", channel, len(leaders), leaders)
+	}
+	return nil
+}
+
+//CheckConsensus -- verifies that the etcdraft or BFT cluster of every channel is stable, waiting for it to stabilize
+func CheckConsensus(config networkspec.Config) error {
+
+	nodes, err := readNetworkNodes(config)
+	if err != nil {
+		return err
+	}
+	if len(nodes.orderers) == 0 {
+		return errors.New("no orderers found in the connection profiles")
+	}
+	for _, channel := range channelNames(config, true) {
+		err = skipUnservedChannel(waitForConsensus(nodes, channel))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package networkclient
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric-test/tools/operator/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRaftStatus(t *testing.T) {
+
+	snapshot := func(clusterSize, isLeader, activeNodes int) metrics.Metrics {
+		text := fmt.Sprintf(`# TYPE consensus_etcdraft_cluster_size gauge
+consensus_etcdraft_cluster_size{channel="testorgschannel0"} %d
+# TYPE consensus_etcdraft_is_leader gauge
+consensus_etcdraft_is_leader{channel="testorgschannel0"} %d
+# TYPE consensus_etcdraft_active_nodes gauge
+consensus_etcdraft_active_nodes{channel="testorgschannel0"} %d
+`, clusterSize, isLeader, activeNodes)
+		m, err := metrics.Parse(strings.NewReader(text))
+		require.NoError(t, err)
+		return m
+	}
+	consenters := []string{"orderer0-ordererorg", "orderer1-ordererorg", "orderer2-ordererorg"}
+
+	tests := []struct {
+		snapshots map[string]metrics.Metrics
+		err       string
+	}{
+		{
+			snapshots: map[string]metrics.Metrics{"orderer0-ordererorg": snapshot(3, 1, 3), "orderer1-ordererorg": snapshot(3, 0, 2), "orderer2-ordererorg": snapshot(3, 0, 0)},
+		},
+		{
+			snapshots: map[string]metrics.Metrics{"orderer0-ordererorg": snapshot(3, 1, 3), "orderer1-ordererorg": snapshot(3, 0, 3)},
+			err:       "no metrics from orderer2-ordererorg",
+		},
+		{
+			snapshots: map[string]metrics.Metrics{"orderer0-ordererorg": snapshot(3, 1, 3), "orderer1-ordererorg": snapshot(3, 0, 3), "orderer2-ordererorg": snapshot(2, 0, 0)},
+			err:       "orderer2-ordererorg reports 2 consenters for channel testorgschannel0, expected 3",
+		},
+		{
+			snapshots: map[string]metrics.Metrics{"orderer0-ordererorg": snapshot(3, 1, 3), "orderer1-ordererorg": snapshot(3, 1, 3), "orderer2-ordererorg": snapshot(3, 0, 0)},
+			err:       "channel testorgschannel0 has 2 leaders [orderer0-ordererorg orderer1-ordererorg], expected 1",
+		},
+		{
+			snapshots: map[string]metrics.Metrics{"orderer0-ordererorg": snapshot(3, 0, 2), "orderer1-ordererorg": snapshot(3, 1, 2), "orderer2-ordererorg": snapshot(3, 0, 0)},
+			err:       "leader orderer1-ordererorg of channel testorgschannel0 sees 2 active consenters, expected 3",
+		},
+	}
+	for i, test := range tests {
+		err := raftStatus("testorgschannel0", consenters, test.snapshots)
+		if test.err == "" {
+			assert.NoError(t, err, i)
+			continue
+		}
+		assert.EqualError(t, err, test.err, i)
+	}
+
+	err := raftStatus("testorgschannel1", consenters, map[string]metrics.Metrics{"orderer0-ordererorg": snapshot(3, 1, 3), "orderer1-ordererorg": snapshot(3, 0, 3), "orderer2-ordererorg": snapshot(3, 0, 3)})
+	assert.EqualError(t, err, "orderer0-ordererorg is not part of the raft cluster of channel testorgschannel1")
+}
+
+func TestBFTStatus(t *testing.T) {
+
+	snapshot := func(clusterSize, isLeader, leaderID int) metrics.Metrics {
+		text := fmt.Sprintf(`# TYPE consensus_smartbft_cluster_size gauge
+consensus_smartbft_cluster_size{channel="testorgschannel0"} %d
+# TYPE consensus_smartbft_is_leader gauge
+consensus_smartbft_is_leader{channel="testorgschannel0"} %d
+# TYPE consensus_smartbft_leader_id gauge
+consensus_smartbft_leader_id{channel="testorgschannel0"} %d
+`, clusterSize, isLeader, leaderID)
+		m, err := metrics.Parse(strings.NewReader(text))
+		require.NoError(t, err)
+		return m
+	}
+	consenters := []string{"orderer0-ordererorg", "orderer1-ordererorg", "orderer2-ordererorg", "orderer3-ordererorg"}
+
+	tests := []struct {
+		snapshots map[string]metrics.Metrics
+		err       string
+	}{
+		{
+			snapshots: map[string]metrics.Metrics{"orderer0-ordererorg": snapshot(4, 1, 1), "orderer1-ordererorg": snapshot(4, 0, 1), "orderer2-ordererorg": snapshot(4, 0, 1), "orderer3-ordererorg": snapshot(4, 0, 1)},
+		},
+		{
+			snapshots: map[string]metrics.Metrics{"orderer0-ordererorg": snapshot(4, 1, 1), "orderer1-ordererorg": snapshot(4, 0, 1), "orderer2-ordererorg": snapshot(4, 0, 1)},
+			err:       "no metrics from orderer3-ordererorg",
+		},
+		{
+			snapshots: map[string]metrics.Metrics{"orderer0-ordererorg": snapshot(4, 1, 1), "orderer1-ordererorg": snapshot(4, 0, 1), "orderer2-ordererorg": snapshot(4, 0, 1), "orderer3-ordererorg": snapshot(3, 0, 1)},
+			err:       "orderer3-ordererorg reports 3 consenters for channel testorgschannel0, expected 4",
+		},
+		{
+			snapshots: map[string]metrics.Metrics{"orderer0-ordererorg": snapshot(4, 1, 1), "orderer1-ordererorg": snapshot(4, 0, 1), "orderer2-ordererorg": snapshot(4, 0, 2), "orderer3-ordererorg": snapshot(4, 0, 2)},
+			err:       "consenters of channel testorgschannel0 follow different leaders map[1:[orderer0-ordererorg orderer1-ordererorg] 2:[orderer2-ordererorg orderer3-ordererorg]]",
+		},
+		{
+			snapshots: map[string]metrics.Metrics{"orderer0-ordererorg": snapshot(4, 0, 2), "orderer1-ordererorg": snapshot(4, 0, 2), "orderer2-ordererorg": snapshot(4, 0, 2), "orderer3-ordererorg": snapshot(4, 0, 2)},
+			err:       "channel testorgschannel0 has 0 leaders [], expected 1",
+		},
+	}
+	for i, test := range tests {
+		err := bftStatus("testorgschannel0", consenters, test.snapshots)
+		if test.err == "" {
+			assert.NoError(t, err, i)
+			continue
+		}
+		assert.EqualError(t, err, test.err, i)
+	}
+}
+
+func TestConsenterName(t *testing.T) {
+
+	nodes := networkNodes{orderers: []networkNode{
+		{name: "orderer0-ordererorg", url: "grpcs://127.0.0.1:41311"},
+		{name: "orderer1-ordererorg", url: "grpcs://127.0.0.1:41317"},
+	}}
+	assert.Equal(t, "orderer1-ordererorg", nodes.consenterName("orderer1-ordererorg", 30001))
+	assert.Equal(t, "orderer1-ordererorg", nodes.consenterName("127.0.0.1", 41317))
+	assert.Equal(t, "orderer0-ordererorg", nodes.consenterName("127.0.0.1", 41311))
+	assert.Equal(t, "127.0.0.1", nodes.consenterName("127.0.0.1", 41313))
+}
@@ -0,0 +1,207 @@
+package networkclient
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-test/tools/operator/channelconfig"
+	"github.com/hyperledger/fabric-test/tools/operator/fabricclient"
+	"github.com/hyperledger/fabric-test/tools/operator/fabricconfiguration"
+	"github.com/hyperledger/fabric-test/tools/operator/ledger"
+	"github.com/hyperledger/fabric-test/tools/operator/logger"
+	"github.com/hyperledger/fabric-test/tools/operator/networkspec"
+	"github.com/pkg/errors"
+)
+
+const peerBasePort = 31000
+
+//AddOrganizations -- adds the organizations of addOrg to the consortium and to every application channel, signed by
+//the existing peer organizations, and joins their peers to the channels. Their peers must be running and their
+//connection profiles generated
+func AddOrganizations(config networkspec.Config) error {
+
+	if len(config.AddOrganizations) == 0 {
+		return errors.New("no organizations found in addOrg")
+	}
+	nodes, err := readNetworkNodes(WithAddedOrganizations(config))
+	if err != nil {
+		return err
+	}
+	signerOrgs := peerOrgNames(config)
+	peerPorts := AddedOrganizationPeerPorts(config)
+	for i, org := range config.AddOrganizations {
+		organization, err := fabricconfiguration.PeerOrganization(config, org, uint32(peerPorts[i]))
+		if err != nil {
+			logger.ERROR("Failed to read the msp of organization ", org.Name)
+			return err
+		}
+		err = skipUnservedChannel(updateChannel(nodes, systemChannel, nil, channelconfig.AddConsortiumOrg(consortiumName, organization)))
+		if err != nil {
+			return err
+		}
+		for _, channel := range channelNames(config, false) {
+			err = skipUnservedChannel(updateChannel(nodes, channel, signerOrgs, channelconfig.AddApplicationOrg(organization)))
+			if err != nil {
+				return err
+			}
+		}
+		logger.INFO("Added organization ", org.Name, " to the consortium and all channels")
+	}
+	var newPeers []networkNode
+	for _, node := range nodes.peers {
+		for _, org := range config.AddOrganizations {
+			if node.org == org.Name {
+				newPeers = append(newPeers, node)
+			}
+		}
+	}
+	return joinChannels(nodes, newPeers, channelNames(config, false))
+}
+
+//RemoveOrganizations -- removes the organizations of removeOrg from every application channel and from the consortium,
+//signed by the remaining peer organizations
+func RemoveOrganizations(config networkspec.Config) error {
+
+	if len(config.RemoveOrganizations) == 0 {
+		return errors.New("no organizations found in removeOrg")
+	}
+	remaining, err := WithoutRemovedOrganizations(config)
+	if err != nil {
+		return err
+	}
+	if len(remaining.PeerOrganizations) == 0 {
+		return errors.New("cannot remove all peer organizations of the network")
+	}
+	nodes, err := readNetworkNodes(config)
+	if err != nil {
+		return err
+	}
+	signerOrgs := peerOrgNames(remaining)
+	for _, orgName := range config.RemoveOrganizations {
+		for _, channel := range channelNames(config, false) {
+			err = skipUnservedChannel(updateChannel(nodes, channel, signerOrgs, channelconfig.RemoveApplicationOrg(orgName)))
+			if err != nil {
+				return err
+			}
+		}
+		err = skipUnservedChannel(updateChannel(nodes, systemChannel, nil, channelconfig.RemoveConsortiumOrg(consortiumName, orgName)))
+		if err != nil {
+			return err
+		}
+		logger.INFO("Removed organization ", orgName, " from all channels and the consortium")
+	}
+	return nil
+}
+
+//WithAddedOrganizations -- the network spec with the organizations of addOrg appended to its peer organizations
+func WithAddedOrganizations(config networkspec.Config) networkspec.Config {
+
+	config.PeerOrganizations = append(append([]networkspec.PeerOrganizations{}, config.PeerOrganizations...), config.AddOrganizations...)
+	return config
+}
+
+//WithoutRemovedOrganizations -- the network spec without the peer organizations of removeOrg
+func WithoutRemovedOrganizations(config networkspec.Config) (networkspec.Config, error) {
+
+	var peerOrgs []networkspec.PeerOrganizations
+	for _, org := range config.PeerOrganizations {
+		if !contains(config.RemoveOrganizations, org.Name) {
+			peerOrgs = append(peerOrgs, org)
+		}
+	}
+	for _, orgName := range config.RemoveOrganizations {
+		if !contains(peerOrgNames(config), orgName) {
+			return config, errors.Errorf("organization %s of removeOrg is not a peer organization of the network", orgName)
+		}
+	}
+	config.PeerOrganizations = peerOrgs
+	return config, nil
+}
+
+//AddedOrganizationPeerPorts -- the port of the first peer of every organization of addOrg, following the ports of the
+//peers of peerOrganizations and addPeer
+func AddedOrganizationPeerPorts(config networkspec.Config) []int {
+
+	port := config.Port(peerBasePort)
+	for _, org := range append(append([]networkspec.PeerOrganizations{}, config.PeerOrganizations...), config.AddPeersToOrganization...) {
+		port += org.NumPeers
+	}
+	var ports []int
+	for _, org := range config.AddOrganizations {
+		ports = append(ports, port)
+		port += org.NumPeers
+	}
+	return ports
+}
+
+//joinChannels -- joins the peers to the channels served by the orderers using the genesis block of each channel
+func joinChannels(nodes networkNodes, peers []networkNode, channels []string) error {
+
+	source, err := nodes.orderers[0].blockSource(false)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+	for _, channel := range channels {
+		var genesisBlock []byte
+		err = source.Blocks(channel, 0, 0, func(block *common.Block) error {
+			genesisBlock, err = proto.Marshal(block)
+			return err
+		})
+		if _, ok := err.(*ledger.ChannelNotServedError); ok {
+			logger.INFO(fmt.Sprintf("Channel %s is not served by the orderers, skipping", channel))
+			continue
+		}
+		if err != nil {
+			return errors.Wrapf(err, "failed to fetch genesis block of channel %s", channel)
+		}
+		for _, peer := range peers {
+			err = joinChannel(peer, channel, genesisBlock)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func joinChannel(peer networkNode, channel string, genesisBlock []byte) error {
+
+	conn, err := fabricclient.Dial(peer.url, peer.sslTarget, peer.tlsCACert, peer.clientCertificates)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_, err = fabricclient.InvokeSystemChaincode(conn, peer.identity, "", "cscc", []byte("JoinChain"), genesisBlock)
+	if err != nil && strings.Contains(err.Error(), "already exists") {
+		logger.INFO(fmt.Sprintf("%s already joined channel %s", peer.name, channel))
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to join %s to channel %s", peer.name, channel)
+	}
+	logger.INFO(fmt.Sprintf("Joined %s to channel %s", peer.name, channel))
+	return nil
+}
+
+//skipUnservedChannel -- ignores config updates of channels that have not been created
+func skipUnservedChannel(err error) error {
+
+	var notServed *ledger.ChannelNotServedError
+	if errors.As(err, &notServed) {
+		logger.INFO(fmt.Sprintf("Channel %s is not served by %s, skipping", notServed.Channel, notServed.Node))
+		return nil
+	}
+	return err
+}
+
+func contains(list []string, item string) bool {
+	for _, element := range list {
+		if element == item {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,161 @@
+package networkclient
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-test/tools/operator/ledger"
+	"github.com/hyperledger/fabric-test/tools/operator/logger"
+	"github.com/hyperledger/fabric-test/tools/operator/networkspec"
+	"github.com/pkg/errors"
+)
+
+const (
+	catchUpTimeout      = 5 * time.Minute
+	catchUpPollInterval = 5 * time.Second
+)
+
+//UpgradeNode -- a peer or orderer replaced by a rolling upgrade
+type UpgradeNode struct {
+	Name      string
+	Org       string
+	Component string
+}
+
+//UpgradeGateError -- the node at which a rolling upgrade stopped, the gate it failed and the nodes left on their old
+//image
+type UpgradeGateError struct {
+	Node     string
+	Gate     string
+	Pending  []string
+	Upgraded []string
+	Err      error
+}
+
+func (e *UpgradeGateError) Error() string {
+	return fmt.Sprintf("rolling upgrade stopped at %s, which failed its %s gate: %s; upgraded %v, not upgraded %v", e.Node, e.Gate, e.Err, e.Upgraded, e.Pending)
+}
+
+func (e *UpgradeGateError) Unwrap() error {
+	return e.Err
+}
+
+//RollingUpgrade -- replaces the orderers and then the peers of the network one at a time with replace, which must
+//restart the node with the image of the spec and wait for its /healthz. After each node the consensus cluster of every
+//channel has to stabilize and the ledger of the node has to catch up with the other nodes before the next node is
+//replaced; the upgrade stops at the first node failing one of these gates
+func RollingUpgrade(config networkspec.Config, replace func(node UpgradeNode) error) error {
+
+	nodes, err := readNetworkNodes(config)
+	if err != nil {
+		return err
+	}
+	if len(nodes.orderers) == 0 {
+		return errors.New("no orderers found in the connection profiles")
+	}
+	var order []UpgradeNode
+	for _, node := range nodes.orderers {
+		order = append(order, UpgradeNode{Name: node.name, Org: node.org, Component: "orderer"})
+	}
+	for _, node := range nodes.peers {
+		order = append(order, UpgradeNode{Name: node.name, Org: node.org, Component: "peer"})
+	}
+	var upgraded []string
+	for i, node := range order {
+		logger.INFO(fmt.Sprintf("Upgrading %s, %d of %d", node.Name, i+1, len(order)))
+		gate, err := upgradeNode(config, nodes, node, replace)
+		if err != nil {
+			var pending []string
+			for _, next := range order[i+1:] {
+				pending = append(pending, next.Name)
+			}
+			return &UpgradeGateError{Node: node.Name, Gate: gate, Pending: pending, Upgraded: upgraded, Err: err}
+		}
+		upgraded = append(upgraded, node.Name)
+		logger.INFO("Upgraded ", node.Name)
+	}
+	logger.INFO("Upgraded all orderers and peers of the network")
+	return nil
+}
+
+//upgradeNode -- replaces a node and runs its gates, returning the gate that failed
+func upgradeNode(config networkspec.Config, nodes networkNodes, node UpgradeNode, replace func(node UpgradeNode) error) (string, error) {
+
+	err := replace(node)
+	if err != nil {
+		return "health", err
+	}
+	for _, channel := range channelNames(config, true) {
+		err = skipUnservedChannel(waitForConsensus(nodes, channel))
+		if err != nil {
+			return "consensus", err
+		}
+	}
+	var source ledger.BlockSource
+	var others []ledger.BlockSource
+	defer func() {
+		for _, other := range append(others, source) {
+			if other != nil {
+				other.Close()
+			}
+		}
+	}()
+	for _, other := range append(append([]networkNode{}, nodes.orderers...), nodes.peers...) {
+		peer := nodes.orderer(other.name) == nil
+		otherSource, err := other.blockSource(peer)
+		if err != nil {
+			return "ledger", err
+		}
+		if other.name == node.Name {
+			source = otherSource
+		} else {
+			others = append(others, otherSource)
+		}
+	}
+	for _, channel := range channelNames(config, node.Component == "orderer") {
+		err = catchUp(channel, source, others, catchUpTimeout, catchUpPollInterval)
+		if err != nil {
+			return "ledger", err
+		}
+	}
+	return "", nil
+}
+
+//catchUp -- waits until the height of the channel on source reaches the highest height of the other nodes, read
+//once when the wait starts so that the blocks cut by a running load do not move the target
+func catchUp(channel string, source ledger.BlockSource, others []ledger.BlockSource, timeout, interval time.Duration) error {
+
+	var target uint64
+	for _, other := range others {
+		height, err := other.Height(channel)
+		var notServed *ledger.ChannelNotServedError
+		if errors.As(err, &notServed) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if height > target {
+			target = height
+		}
+	}
+	if target == 0 {
+		logger.INFO(fmt.Sprintf("Channel %s is not served by any other node, skipping", channel))
+		return nil
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		height, err := source.Height(channel)
+		if err == nil && height >= target {
+			logger.INFO(fmt.Sprintf("%s caught up with height %d of channel %s", source.Name(), target, channel))
+			return nil
+		}
+		if time.Now().After(deadline) {
+			if err != nil {
+				return errors.Wrapf(err, "%s did not catch up with height %d of channel %s within %s", source.Name(), target, channel, timeout)
+			}
+			return errors.Errorf("%s is at height %d of channel %s after %s, expected %d", source.Name(), height, channel, timeout, target)
+		}
+		time.Sleep(interval)
+	}
+}
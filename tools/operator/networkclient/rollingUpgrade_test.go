@@ -0,0 +1,87 @@
+package networkclient
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-test/tools/operator/ledger"
+	"github.com/stretchr/testify/assert"
+)
+
+//fakeSource -- a node whose height grows by one block on every read, up to max
+type fakeSource struct {
+	name   string
+	height uint64
+	max    uint64
+	err    error
+}
+
+func (f *fakeSource) Name() string                { return f.name }
+func (f *fakeSource) ValidatesTransactions() bool { return false }
+func (f *fakeSource) Close() error                { return nil }
+func (f *fakeSource) Blocks(channel string, start, end uint64, handler func(*common.Block) error) error {
+	return nil
+}
+
+func (f *fakeSource) Height(channel string) (uint64, error) {
+	if f.err != nil {
+		return 0, f.err
+	}
+	height := f.height
+	if f.height < f.max {
+		f.height++
+	}
+	return height, nil
+}
+
+func TestCatchUp(t *testing.T) {
+
+	notServed := &ledger.ChannelNotServedError{Node: "peer0-org2", Channel: "testorgschannel0", Status: common.Status_NOT_FOUND}
+	tests := []struct {
+		source *fakeSource
+		others []ledger.BlockSource
+		err    string
+	}{
+		{
+			source: &fakeSource{name: "peer0-org1", height: 3, max: 10},
+			others: []ledger.BlockSource{&fakeSource{name: "orderer0-ordererorg1", height: 6}, &fakeSource{name: "peer0-org2", err: notServed}},
+		},
+		{
+			source: &fakeSource{name: "peer0-org1", err: notServed},
+			others: []ledger.BlockSource{&fakeSource{name: "peer0-org2", err: notServed}},
+		},
+		{
+			source: &fakeSource{name: "peer0-org1", height: 2, max: 4},
+			others: []ledger.BlockSource{&fakeSource{name: "orderer0-ordererorg1", height: 6}},
+			err:    "peer0-org1 is at height 4 of channel testorgschannel0 after 20ms, expected 6",
+		},
+		{
+			source: &fakeSource{name: "peer0-org1", height: 2},
+			others: []ledger.BlockSource{&fakeSource{name: "orderer0-ordererorg1", err: errors.New("connection refused")}},
+			err:    "connection refused",
+		},
+	}
+	for i, test := range tests {
+		err := catchUp("testorgschannel0", test.source, test.others, 20*time.Millisecond, time.Millisecond)
+		if test.err == "" {
+			assert.NoError(t, err, i)
+			continue
+		}
+		assert.EqualError(t, err, test.err, i)
+	}
+}
+
+func TestUpgradeGateError(t *testing.T) {
+
+	err := &UpgradeGateError{
+		Node:     "orderer1-ordererorg1",
+		Gate:     "consensus",
+		Upgraded: []string{"orderer0-ordererorg1"},
+		Pending:  []string{"orderer2-ordererorg1", "peer0-org1"},
+		Err:      errors.New("etcdraft cluster of channel testorgschannel0 did not stabilize within 6m0s"),
+	}
+	assert.EqualError(t, err, "rolling upgrade stopped at orderer1-ordererorg1, which failed its consensus gate: etcdraft cluster of channel testorgschannel0 did not stabilize within 6m0s; upgraded [orderer0-ordererorg1], not upgraded [orderer2-ordererorg1 peer0-org1]")
+	assert.True(t, errors.Is(err, err.Err))
+}
@@ -0,0 +1,367 @@
+package networkclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-protos-go/peer/lifecycle"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+
+	"github.com/hyperledger/fabric-test/tools/operator/fabricclient"
+	"github.com/hyperledger/fabric-test/tools/operator/metrics"
+	"github.com/hyperledger/fabric-test/tools/operator/networkspec"
+	"github.com/hyperledger/fabric-test/tools/operator/networkstate"
+)
+
+const statusTimeout = 5 * time.Second
+
+//NetworkStatus -- the live status of every component recorded in the state of a network, and the leader of the
+//consensus cluster of every channel
+type NetworkStatus struct {
+	NetworkName string            `json:"networkName,omitempty"`
+	Runtime     string            `json:"runtime"`
+	Components  []ComponentStatus `json:"components"`
+	Leaders     map[string]string `json:"leaders,omitempty"`
+}
+
+//ComponentStatus -- the state of the container, pod or process of a component, its /healthz and /version, and the
+//channels it joined. Queries a component does not answer are listed in Errors
+type ComponentStatus struct {
+	Name     string          `json:"name"`
+	Type     string          `json:"type"`
+	Org      string          `json:"org,omitempty"`
+	State    string          `json:"state"`
+	Health   string          `json:"health,omitempty"`
+	Version  string          `json:"version,omitempty"`
+	Channels []ChannelStatus `json:"channels,omitempty"`
+	Errors   []string        `json:"errors,omitempty"`
+}
+
+//ChannelStatus -- a channel joined by a peer or orderer: its block height, whether the orderer leads its consensus
+//cluster, and the chaincode definitions committed on it as seen by the peer
+type ChannelStatus struct {
+	Name       string   `json:"name"`
+	Height     uint64   `json:"height,omitempty"`
+	Leader     bool     `json:"leader,omitempty"`
+	Chaincodes []string `json:"chaincodes,omitempty"`
+}
+
+//versionInfo -- the response of the /version endpoint of a peer or orderer
+type versionInfo struct {
+	Version   string `json:"Version"`
+	CommitSHA string `json:"CommitSHA"`
+}
+
+//healthStatus -- the response of the /healthz endpoint of a peer or orderer
+type healthStatus struct {
+	Status       string `json:"status"`
+	FailedChecks []struct {
+		Component string `json:"component"`
+		Reason    string `json:"reason"`
+	} `json:"failed_checks"`
+}
+
+//Status -- queries every component recorded in the state of the network, together with runtimeStates, the states of
+//their containers, pods or processes. Components are queried at once, and what a component does not answer is
+//reported with it rather than failing the status
+func Status(config networkspec.Config, state networkstate.State, runtimeStates map[string]string) NetworkStatus {
+
+	nodes, nodesErr := readNetworkNodes(config)
+	status := NetworkStatus{NetworkName: state.NetworkName, Runtime: state.Runtime, Leaders: make(map[string]string)}
+	status.Components = make([]ComponentStatus, len(state.Components))
+	var wg sync.WaitGroup
+	for i, component := range state.Components {
+		wg.Add(1)
+		go func(i int, component networkstate.Component) {
+			defer wg.Done()
+			status.Components[i] = componentStatus(config, component, runtimeStates, nodes, nodesErr)
+		}(i, component)
+	}
+	wg.Wait()
+	for _, component := range status.Components {
+		for _, channel := range component.Channels {
+			if channel.Leader {
+				status.Leaders[channel.Name] = component.Name
+			}
+		}
+	}
+	return status
+}
+
+func componentStatus(config networkspec.Config, component networkstate.Component, runtimeStates map[string]string, nodes networkNodes, nodesErr error) ComponentStatus {
+
+	status := ComponentStatus{Name: component.Name, Type: component.Type, Org: component.Org, State: runtimeStates[component.Name]}
+	if status.State == "" {
+		status.State = "missing"
+	}
+	addError := func(err error) {
+		status.Errors = append(status.Errors, err.Error())
+	}
+	if component.Type != networkstate.Peer && component.Type != networkstate.Orderer {
+		return status
+	}
+	operationsURL := strings.TrimSuffix(component.Endpoints["operations"], "/")
+	if operationsURL == "" {
+		addError(errors.Errorf("no operations endpoint recorded for %s", component.Name))
+		return status
+	}
+	health, err := checkHealthz(operationsURL)
+	status.Health = health
+	if err != nil {
+		addError(err)
+	}
+	version, err := operationsVersion(operationsURL)
+	status.Version = version
+	if err != nil {
+		addError(err)
+	}
+	snapshot, err := metrics.Scrape(operationsURL)
+	if err != nil {
+		addError(err)
+	}
+	var channels []ChannelStatus
+	if component.Type == networkstate.Peer {
+		channels, err = peerChannels(component.Name, snapshot, nodes, nodesErr)
+	} else {
+		channels, err = ordererChannels(config, component.Name, snapshot, nodes, nodesErr)
+	}
+	if err != nil {
+		addError(err)
+	}
+	status.Channels = channels
+	return status
+}
+
+//checkHealthz -- OK when the /healthz endpoint reports the component healthy, or the checks that failed
+func checkHealthz(operationsURL string) (string, error) {
+
+	client := http.Client{Timeout: statusTimeout}
+	resp, err := client.Get(operationsURL + "/healthz")
+	if err != nil {
+		return "unreachable", errors.Wrap(err, "failed to query /healthz")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return "OK", nil
+	}
+	var health healthStatus
+	body, _ := ioutil.ReadAll(resp.Body)
+	if json.Unmarshal(body, &health) != nil || len(health.FailedChecks) == 0 {
+		return resp.Status, nil
+	}
+	var failed []string
+	for _, check := range health.FailedChecks {
+		failed = append(failed, fmt.Sprintf("%s: %s", check.Component, check.Reason))
+	}
+	return strings.Join(failed, "; "), nil
+}
+
+//operationsVersion -- the version of the binary reported by the /version endpoint
+func operationsVersion(operationsURL string) (string, error) {
+
+	client := http.Client{Timeout: statusTimeout}
+	resp, err := client.Get(operationsURL + "/version")
+	if err != nil {
+		return "", errors.Wrap(err, "failed to query /version")
+	}
+	defer resp.Body.Close()
+	var info versionInfo
+	err = json.NewDecoder(resp.Body).Decode(&info)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to decode /version")
+	}
+	return info.Version, nil
+}
+
+//peerChannels -- the channels the peer joined according to cscc, their heights from ledger_blockchain_height and the
+//chaincode definitions committed on them according to _lifecycle. Without the admin identity of the peer, the
+//channels are those of its metrics
+func peerChannels(name string, snapshot metrics.Metrics, nodes networkNodes, nodesErr error) ([]ChannelStatus, error) {
+
+	heights := make(map[string]uint64)
+	for _, sample := range snapshot.Find("ledger_blockchain_height", nil) {
+		heights[sample.Labels["channel"]] = uint64(sample.Value)
+	}
+	var node *networkNode
+	for i := range nodes.peers {
+		if nodes.peers[i].name == name {
+			node = &nodes.peers[i]
+		}
+	}
+	if node == nil {
+		if nodesErr == nil {
+			nodesErr = errors.Errorf("%s not found in the connection profiles", name)
+		}
+		return channelsOf(heights), nodesErr
+	}
+	conn, err := fabricclient.Dial(node.url, node.sslTarget, node.tlsCACert, node.clientCertificates)
+	if err != nil {
+		return channelsOf(heights), err
+	}
+	defer conn.Close()
+	payload, err := fabricclient.InvokeSystemChaincode(conn, node.identity, "", "cscc", []byte("GetChannels"))
+	if err != nil {
+		return channelsOf(heights), err
+	}
+	var joined peer.ChannelQueryResponse
+	err = proto.Unmarshal(payload, &joined)
+	if err != nil {
+		return channelsOf(heights), errors.Wrap(err, "failed to unmarshal the channels of cscc")
+	}
+	var channels []ChannelStatus
+	var errs []string
+	for _, info := range joined.Channels {
+		channel := ChannelStatus{Name: info.ChannelId, Height: heights[info.ChannelId]}
+		channel.Chaincodes, err = committedChaincodes(conn, node.identity, info.ChannelId)
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+		channels = append(channels, channel)
+	}
+	sort.Slice(channels, func(i, j int) bool { return channels[i].Name < channels[j].Name })
+	if len(errs) > 0 {
+		return channels, errors.New(strings.Join(errs, "; "))
+	}
+	return channels, nil
+}
+
+//committedChaincodes -- the chaincode definitions committed on a channel, as name:version and sequence
+func committedChaincodes(conn *grpc.ClientConn, identity *fabricclient.Identity, channel string) ([]string, error) {
+
+	args, err := proto.Marshal(&lifecycle.QueryChaincodeDefinitionsArgs{})
+	if err != nil {
+		return nil, err
+	}
+	payload, err := fabricclient.InvokeSystemChaincode(conn, identity, channel, "_lifecycle", []byte("QueryChaincodeDefinitions"), args)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to query the chaincode definitions of channel %s", channel)
+	}
+	var result lifecycle.QueryChaincodeDefinitionsResult
+	err = proto.Unmarshal(payload, &result)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal the chaincode definitions of channel %s", channel)
+	}
+	var definitions []string
+	for _, definition := range result.ChaincodeDefinitions {
+		definitions = append(definitions, fmt.Sprintf("%s:%s#%d", definition.Name, definition.Version, definition.Sequence))
+	}
+	sort.Strings(definitions)
+	return definitions, nil
+}
+
+//ordererChannels -- the channels the orderer is a member of with their heights, from the channel participation api
+//or else from the committed blocks of its consensus metrics, and whether it leads their consensus cluster
+func ordererChannels(config networkspec.Config, name string, snapshot metrics.Metrics, nodes networkNodes, nodesErr error) ([]ChannelStatus, error) {
+
+	heights := make(map[string]uint64)
+	for _, metricName := range []string{"consensus_etcdraft_committed_block_number", "consensus_smartbft_consensus_latest_seq"} {
+		for _, sample := range snapshot.Find(metricName, nil) {
+			heights[sample.Labels["channel"]] = uint64(sample.Value) + 1
+		}
+	}
+	var err error
+	node := nodes.orderer(name)
+	if node != nil && node.adminURL != "" {
+		var list participationChannelList
+		var client participationClient
+		client, err = newParticipationClient(config, *node)
+		if err == nil {
+			list, err = client.list()
+		}
+		if err == nil {
+			for _, channel := range list.Channels {
+				info, _, channelErr := client.channel(channel.Name)
+				if channelErr != nil {
+					err = channelErr
+					break
+				}
+				heights[channel.Name] = info.Height
+			}
+		}
+	} else if nodesErr != nil {
+		err = nodesErr
+	}
+	channels := channelsOf(heights)
+	for i := range channels {
+		labels := map[string]string{"channel": channels[i].Name}
+		channels[i].Leader = snapshot.Sum("consensus_etcdraft_is_leader", labels) == 1 || snapshot.Sum("consensus_smartbft_is_leader", labels) == 1
+	}
+	return channels, err
+}
+
+func channelsOf(heights map[string]uint64) []ChannelStatus {
+
+	var channels []ChannelStatus
+	for name, height := range heights {
+		if name != "" {
+			channels = append(channels, ChannelStatus{Name: name, Height: height})
+		}
+	}
+	sort.Slice(channels, func(i, j int) bool { return channels[i].Name < channels[j].Name })
+	return channels
+}
+
+//WriteTable -- writes the status as a table of the components followed by the leaders of the channels and the
+//queries the components did not answer
+func (s NetworkStatus) WriteTable(w io.Writer) error {
+
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "NAME\tTYPE\tORG\tSTATE\tHEALTH\tVERSION\tCHANNELS\tCHAINCODES")
+	for _, component := range s.Components {
+		var channels, chaincodes []string
+		for _, channel := range component.Channels {
+			entry := fmt.Sprintf("%s:%d", channel.Name, channel.Height)
+			if channel.Leader {
+				entry += "*"
+			}
+			channels = append(channels, entry)
+			for _, chaincode := range channel.Chaincodes {
+				chaincodes = append(chaincodes, fmt.Sprintf("%s/%s", channel.Name, chaincode))
+			}
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", component.Name, component.Type, component.Org, component.State, dash(component.Health), dash(component.Version), dash(strings.Join(channels, ",")), dash(strings.Join(chaincodes, ",")))
+	}
+	err := table.Flush()
+	if err != nil {
+		return err
+	}
+	var leaderChannels []string
+	for channel := range s.Leaders {
+		leaderChannels = append(leaderChannels, channel)
+	}
+	sort.Strings(leaderChannels)
+	if len(leaderChannels) > 0 {
+		fmt.Fprintln(w, "\nCONSENSUS LEADERS (* in CHANNELS)")
+		for _, channel := range leaderChannels {
+			fmt.Fprintf(w, "  %s: %s\n", channel, s.Leaders[channel])
+		}
+	}
+	var errs []string
+	for _, component := range s.Components {
+		for _, message := range component.Errors {
+			errs = append(errs, fmt.Sprintf("  %s: %s", component.Name, message))
+		}
+	}
+	if len(errs) > 0 {
+		fmt.Fprintf(w, "\nERRORS\n%s\n", strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
+func dash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
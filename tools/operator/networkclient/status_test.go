@@ -0,0 +1,65 @@
+package networkclient
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hyperledger/fabric-test/tools/operator/networkspec"
+	"github.com/hyperledger/fabric-test/tools/operator/networkstate"
+)
+
+func operationsServer(healthy bool, metrics string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/healthz":
+			if !healthy {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				fmt.Fprint(w, `{"status":"Service Unavailable","failed_checks":[{"component":"couchdb","reason":"failed to connect"}]}`)
+				return
+			}
+			fmt.Fprint(w, `{"status":"OK"}`)
+		case "/version":
+			fmt.Fprint(w, `{"CommitSHA":"abc","Version":"2.5.0"}`)
+		case "/metrics":
+			fmt.Fprint(w, metrics)
+		}
+	}))
+}
+
+func TestStatus(t *testing.T) {
+
+	orderer := operationsServer(true, "consensus_etcdraft_is_leader{channel=\"testorgschannel0\"} 1\nconsensus_etcdraft_committed_block_number{channel=\"testorgschannel0\"} 4\n")
+	defer orderer.Close()
+	peer := operationsServer(false, "ledger_blockchain_height{channel=\"testorgschannel0\"} 5\n")
+	defer peer.Close()
+
+	state := networkstate.State{NetworkName: "smoke", Runtime: "docker", Components: []networkstate.Component{
+		{Name: "orderer0-ordererorg1", Type: networkstate.Orderer, Org: "ordererorg1", Endpoints: map[string]string{"operations": orderer.URL}},
+		{Name: "peer0-org1", Type: networkstate.Peer, Org: "org1", Endpoints: map[string]string{"operations": peer.URL + "/"}},
+		{Name: "ca0-org1", Type: networkstate.CA, Org: "org1"},
+	}}
+	config := networkspec.Config{ArtifactsLocation: t.TempDir()}
+	status := Status(config, state, map[string]string{"orderer0-ordererorg1": "Up 5 minutes", "peer0-org1": "Up 5 minutes"})
+
+	assert.Equal(t, map[string]string{"testorgschannel0": "orderer0-ordererorg1"}, status.Leaders)
+	ordererStatus := status.Components[0]
+	assert.Equal(t, "OK", ordererStatus.Health)
+	assert.Equal(t, "2.5.0", ordererStatus.Version)
+	assert.Equal(t, []ChannelStatus{{Name: "testorgschannel0", Height: 5, Leader: true}}, ordererStatus.Channels)
+	peerStatus := status.Components[1]
+	assert.Equal(t, "couchdb: failed to connect", peerStatus.Health)
+	assert.Equal(t, []ChannelStatus{{Name: "testorgschannel0", Height: 5}}, peerStatus.Channels)
+	assert.NotEmpty(t, peerStatus.Errors)
+	assert.Equal(t, "missing", status.Components[2].State)
+
+	var table bytes.Buffer
+	assert.NoError(t, status.WriteTable(&table))
+	assert.Contains(t, table.String(), "testorgschannel0:5*")
+	assert.Contains(t, table.String(), "CONSENSUS LEADERS")
+	assert.Contains(t, table.String(), "  peer0-org1: no orderers found in the connection profiles")
+}
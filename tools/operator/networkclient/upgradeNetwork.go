@@ -3,22 +3,29 @@ package networkclient
 import (
 	"fmt"
 
+	"github.com/hyperledger/fabric-test/tools/operator/channelconfig"
 	"github.com/hyperledger/fabric-test/tools/operator/logger"
 	"github.com/hyperledger/fabric-test/tools/operator/networkspec"
+	"github.com/hyperledger/fabric-test/tools/operator/paths"
 )
 
 //UpgradeDB -  to upgrade db
 func UpgradeDB(config networkspec.Config, kubeConfigPath string) error {
 
 	for i := 0; i < len(config.PeerOrganizations); i++ {
+		orgName := config.PeerOrganizations[i].Name
 		for j := 0; j < config.PeerOrganizations[i].NumPeers; j++ {
-			args := []string{"upgradeDB",
-				config.PeerOrganizations[i].MSPID,
-				fmt.Sprintf("peer%d-%s", j, config.PeerOrganizations[i].Name),
-				fmt.Sprintf("%s", config.PeerOrganizations[i].Name),
-				config.ArtifactsLocation}
-			_, err := ExecuteCommand("./scripts/upgradeNetwork.sh", args, true)
+			peerName := fmt.Sprintf("peer%d-%s", j, orgName)
+			args := []string{"run", "--name", config.ContainerName("peer-cli"), "--rm",
+				"-e", fmt.Sprintf("CORE_PEER_LOCALMSPID=%s", config.PeerOrganizations[i].MSPID),
+				"-e", "CORE_PEER_TLS_ENABLED=true",
+				"-e", fmt.Sprintf("CORE_PEER_MSPCONFIGPATH=/etc/hyperledger/fabric/artifacts/users/Admin@%s/msp", orgName),
+				"-v", fmt.Sprintf("%s:/var/hyperledger/production/", paths.JoinPath(config.ArtifactsLocation, fmt.Sprintf("backup/%s", peerName))),
+				"-v", fmt.Sprintf("%s:/etc/hyperledger/fabric/artifacts/", paths.JoinPath(paths.PeerOrgsDir(config.ArtifactsLocation), orgName)),
+				config.Image("peer", orgName, peerName), "peer", "node", "upgrade-dbs"}
+			_, err := ExecuteCommand("docker", args, true)
 			if err != nil {
+				logger.ERROR("Failed to upgrade db of ", peerName)
 				return err
 			}
 		}
@@ -29,116 +36,67 @@ func UpgradeDB(config networkspec.Config, kubeConfigPath string) error {
 
 //UpdateCapability -  to update capability
 func UpdateCapability(config networkspec.Config, kubeConfigPath string) error {
-	capabilityGroup := "channel"
-	capabilityKey := config.ChannelCapabilities
-	args := []string{"configUpdate",
-		config.OrdererOrganizations[0].MSPID,
-		fmt.Sprintf("orderer0-%s", config.OrdererOrganizations[0].Name),
-		fmt.Sprintf("%s", config.OrdererOrganizations[0].Name),
-		config.ArtifactsLocation,
-		fmt.Sprintf("%d", config.NumChannels),
-		capabilityKey,
-		capabilityGroup,
-		config.PeerOrganizations[0].MSPID,
-		config.PeerOrganizations[0].Name}
-	_, err := ExecuteCommand("./scripts/upgradeNetwork.sh", args, true)
-	if err != nil {
-		return err
-	}
 
-	capabilityGroup = "orderer"
-	capabilityKey = config.OrdererCapabilities
-	args = []string{"configUpdate",
-		config.OrdererOrganizations[0].MSPID,
-		fmt.Sprintf("orderer0-%s", config.OrdererOrganizations[0].Name),
-		fmt.Sprintf("%s", config.OrdererOrganizations[0].Name),
-		config.ArtifactsLocation, fmt.Sprintf("0"),
-		capabilityKey,
-		capabilityGroup,
-		config.PeerOrganizations[0].MSPID,
-		config.PeerOrganizations[0].Name}
-	_, err = ExecuteCommand("./scripts/upgradeNetwork.sh", args, true)
+	nodes, err := readNetworkNodes(config)
 	if err != nil {
 		return err
 	}
-
-	capabilityGroup = "application"
-	capabilityKey = config.ApplicationCapabilities
-	args = []string{"configUpdate",
-		config.OrdererOrganizations[0].MSPID,
-		fmt.Sprintf("orderer0-%s", config.OrdererOrganizations[0].Name),
-		fmt.Sprintf("%s", config.OrdererOrganizations[0].Name),
-		config.ArtifactsLocation,
-		fmt.Sprintf("%d", config.NumChannels),
-		capabilityKey,
-		capabilityGroup,
-		config.PeerOrganizations[0].MSPID,
-		config.PeerOrganizations[0].Name}
-	_, err = ExecuteCommand("./scripts/upgradeNetwork.sh", args, true)
-	if err != nil {
-		return err
+	for _, channel := range channelNames(config, true) {
+		var changes []channelconfig.Change
+		var signerOrgs []string
+		if channel == systemChannel {
+			changes = capabilityChanges(changes, "orderer", config.OrdererCapabilities)
+		} else {
+			changes = capabilityChanges(changes, "application", config.ApplicationCapabilities)
+			signerOrgs = peerOrgNames(config)
+		}
+		changes = capabilityChanges(changes, "channel", config.ChannelCapabilities)
+		if len(changes) == 0 {
+			continue
+		}
+		err = updateChannel(nodes, channel, signerOrgs, changes...)
+		if err != nil {
+			logger.ERROR("Failed to update capabilities of channel ", channel)
+			return err
+		}
 	}
-
 	logger.INFO("Successfully updated capabilities")
 	return nil
 }
 
+func capabilityChanges(changes []channelconfig.Change, group, capability string) []channelconfig.Change {
+	if capability == "" {
+		return changes
+	}
+	return append(changes, channelconfig.AddCapability(group, capability))
+}
+
 //UpdatePolicy - to update policy
 func UpdatePolicy(config networkspec.Config, kubeConfigPath string) error {
-	for j := 0; j < len(config.PeerOrganizations); j++ {
-		policyGroup := "consortium"
-		args := []string{"configUpdate",
-			config.OrdererOrganizations[0].MSPID,
-			fmt.Sprintf("orderer0-%s", config.OrdererOrganizations[0].Name),
-			fmt.Sprintf("%s", config.OrdererOrganizations[0].Name),
-			config.ArtifactsLocation,
-			fmt.Sprintf("0"),
-			fmt.Sprintf("null"),
-			policyGroup,
-			config.PeerOrganizations[j].MSPID,
-			config.PeerOrganizations[j].Name}
-		_, err := ExecuteCommand("./scripts/upgradeNetwork.sh", args, true)
-		if err != nil {
-			return err
-		}
-	}
 
-	for j := 0; j < len(config.PeerOrganizations); j++ {
-		policyGroup := "organization"
-		args := []string{"configUpdate",
-			config.OrdererOrganizations[0].MSPID,
-			fmt.Sprintf("orderer0-%s", config.OrdererOrganizations[0].Name),
-			fmt.Sprintf("%s", config.OrdererOrganizations[0].Name),
-			config.ArtifactsLocation,
-			fmt.Sprintf("%d", config.NumChannels),
-			fmt.Sprintf("null"),
-			policyGroup,
-			config.PeerOrganizations[j].MSPID,
-			config.PeerOrganizations[j].Name}
-		_, err := ExecuteCommand("./scripts/upgradeNetwork.sh", args, true)
-		if err != nil {
-			return err
-		}
+	nodes, err := readNetworkNodes(config)
+	if err != nil {
+		return err
 	}
-
-	policyGroups := []string{"apppolicy", "acls"}
-	for _, policyGroup := range policyGroups {
-		args := []string{"configUpdate",
-			config.OrdererOrganizations[0].MSPID,
-			fmt.Sprintf("orderer0-%s", config.OrdererOrganizations[0].Name),
-			fmt.Sprintf("%s", config.OrdererOrganizations[0].Name),
-			config.ArtifactsLocation,
-			fmt.Sprintf("%d", config.NumChannels),
-			fmt.Sprintf("null"),
-			policyGroup,
-			config.PeerOrganizations[0].MSPID,
-			config.PeerOrganizations[0].Name}
-		_, err := ExecuteCommand("./scripts/upgradeNetwork.sh", args, true)
+	peerOrgs := peerOrgNames(config)
+	for _, channel := range channelNames(config, true) {
+		var changes []channelconfig.Change
+		for _, peerOrg := range config.PeerOrganizations {
+			if channel == systemChannel {
+				changes = append(changes, channelconfig.SetConsortiumOrgEndorsement(consortiumName, peerOrg.Name, peerOrg.MSPID))
+			} else {
+				changes = append(changes, channelconfig.SetApplicationOrgEndorsement(peerOrg.Name, peerOrg.MSPID))
+			}
+		}
+		if channel != systemChannel {
+			changes = append(changes, channelconfig.SetApplicationEndorsementPolicies(), channelconfig.AddLifecycleACLs())
+		}
+		err = updateChannel(nodes, channel, peerOrgs, changes...)
 		if err != nil {
+			logger.ERROR("Failed to update policies of channel ", channel)
 			return err
 		}
 	}
-
 	logger.INFO("Successfully updated policies")
 	return nil
 }
@@ -0,0 +1,82 @@
+package networkclient
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-test/tools/operator/ledger"
+	"github.com/hyperledger/fabric-test/tools/operator/logger"
+	"github.com/hyperledger/fabric-test/tools/operator/networkspec"
+)
+
+const systemChannel = "orderersystemchannel"
+
+//VerifyLedger -- compares the blocks of every channel across all peers and orderers using the deliver service. The
+//peers and orderers the connection profiles list for a channel, and the orderers for the system channel, have to serve it
+func VerifyLedger(config networkspec.Config) error {
+
+	nodes, err := readNetworkNodes(config)
+	if err != nil {
+		return err
+	}
+	var peers, orderers []ledger.BlockSource
+	defer func() {
+		for _, source := range append(orderers, peers...) {
+			source.Close()
+		}
+	}()
+	for _, node := range nodes.orderers {
+		source, err := node.blockSource(false)
+		if err != nil {
+			return err
+		}
+		orderers = append(orderers, source)
+	}
+	for _, node := range nodes.peers {
+		source, err := node.blockSource(true)
+		if err != nil {
+			return err
+		}
+		peers = append(peers, source)
+	}
+	for _, channel := range channelNames(config, true) {
+		sources := append(append([]ledger.BlockSource{}, orderers...), peers...)
+		expected := nodes.channels[channel]
+		if channel == systemChannel {
+			sources = orderers
+			expected = nil
+			if config.Orderer.BootstrapMethod != "none" {
+				for _, orderer := range nodes.orderers {
+					expected = append(expected, orderer.name)
+				}
+			}
+		}
+		result, err := ledger.VerifyChannel(channel, sources, expected)
+		if err != nil {
+			logger.ERROR("Ledger verification failed on channel ", channel)
+			return err
+		}
+		if len(result.Nodes) == 0 {
+			logger.INFO(fmt.Sprintf("Channel %s is not served by any node, skipping", channel))
+			continue
+		}
+		logger.INFO(fmt.Sprintf("Verified %d blocks of channel %s on %v", result.Height, channel, result.Nodes))
+	}
+	return nil
+}
+
+//channelNames -- names of the application channels of the network, preceded by the system channel if requested
+func channelNames(config networkspec.Config, withSystemChannel bool) []string {
+
+	var channels []string
+	if withSystemChannel {
+		channels = append(channels, systemChannel)
+	}
+	channelPrefix := config.ChannelPrefix
+	if channelPrefix == "" {
+		channelPrefix = "testorgschannel"
+	}
+	for i := 0; i < config.NumChannels; i++ {
+		channels = append(channels, fmt.Sprintf("%s%d", channelPrefix, i))
+	}
+	return channels
+}
@@ -0,0 +1,11 @@
+package networkspec
+
+//Endpoint -- the host and port the peer or orderer name is reached at, which is name and port unless the runtime set
+//another endpoint for it
+func (c Config) Endpoint(name string, port int) (string, int) {
+
+	if endpoint, ok := c.Endpoints[name]; ok {
+		return endpoint.Host, endpoint.Port
+	}
+	return name, port
+}
@@ -0,0 +1,180 @@
+package networkspec
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	tagVersion        = regexp.MustCompile(`(\d+)\.(\d+)`)
+	capabilityVersion = regexp.MustCompile(`^V(\d+)_(\d+)`)
+)
+
+//image -- the image of component, or "" if none is set
+func (d DockerImages) image(component string) string {
+	switch component {
+	case "ca":
+		return d.Ca
+	case "peer":
+		return d.Peer
+	case "orderer":
+		return d.Orderer
+	case "baseos":
+		return d.Baseos
+	case "ccenv":
+		return d.Ccenv
+	case "javaenv":
+		return d.Javaenv
+	case "nodeenv":
+		return d.Nodeenv
+	}
+	return ""
+}
+
+//Image -- the image of component (ca, peer, orderer, baseos, ccenv, javaenv or nodeenv) run by a node of an
+//organization. The images and tag of the node win over those of its organization, which win over those of the network;
+//at each level an image wins over <dockerOrg>/fabric-<component>:<dockerTag>
+func (c Config) Image(component, orgName, nodeName string) string {
+
+	var nodeLevels, orgLevels []Images
+	for _, orgs := range [][]PeerOrganizations{c.PeerOrganizations, c.AddPeersToOrganization, c.AddOrganizations} {
+		for _, org := range orgs {
+			if org.Name == orgName {
+				nodeLevels = append(nodeLevels, org.NodeImages[nodeName])
+				orgLevels = append(orgLevels, Images{DockerTag: org.DockerTag, DockerImages: org.DockerImages})
+			}
+		}
+	}
+	for _, orgs := range [][]OrdererOrganizations{c.OrdererOrganizations, c.AddOrderersToOrganization} {
+		for _, org := range orgs {
+			if org.Name == orgName {
+				nodeLevels = append(nodeLevels, org.NodeImages[nodeName])
+				orgLevels = append(orgLevels, Images{DockerTag: org.DockerTag, DockerImages: org.DockerImages})
+			}
+		}
+	}
+	levels := append(nodeLevels, orgLevels...)
+	levels = append(levels, Images{DockerTag: c.DockerTag, DockerImages: c.DockerImages})
+	for _, level := range levels {
+		if image := level.DockerImages.image(component); image != "" {
+			return image
+		}
+		if level.DockerTag != "" {
+			return fmt.Sprintf("%s/fabric-%s:%s", c.DockerOrg, component, level.DockerTag)
+		}
+	}
+	return fmt.Sprintf("%s/fabric-%s:%s", c.DockerOrg, component, c.DockerTag)
+}
+
+//node -- a peer or an orderer of the network spec
+type node struct {
+	component, orgName, name string
+}
+
+//nodes -- the peers and orderers of the network spec, including those of addPeer, addOrg and addOrderer
+func (c Config) nodes() []node {
+
+	var output []node
+	addNodes := func(component, orgName string, first, count int) {
+		for i := first; i < first+count; i++ {
+			output = append(output, node{component: component, orgName: orgName, name: fmt.Sprintf("%s%d-%s", component, i, orgName)})
+		}
+	}
+	for _, org := range c.PeerOrganizations {
+		addNodes("peer", org.Name, 0, org.NumPeers)
+		for _, added := range c.AddPeersToOrganization {
+			if added.Name == org.Name {
+				addNodes("peer", org.Name, org.NumPeers, added.NumPeers)
+			}
+		}
+	}
+	for _, org := range c.AddOrganizations {
+		addNodes("peer", org.Name, 0, org.NumPeers)
+	}
+	for _, org := range c.OrdererOrganizations {
+		addNodes("orderer", org.Name, 0, org.NumOrderers)
+		for _, added := range c.AddOrderersToOrganization {
+			if added.Name == org.Name {
+				addNodes("orderer", org.Name, org.NumOrderers, added.NumOrderers)
+			}
+		}
+	}
+	return output
+}
+
+//imageVersion -- the major and minor version in the tag of an image, such as 2.5 for hyperledger/fabric-peer:2.5.4;
+//ok is false for tags without a version, such as latest
+func imageVersion(image string) (version [2]int, ok bool) {
+
+	index := strings.LastIndex(image, ":")
+	if index < 0 || index < strings.LastIndex(image, "/") || strings.Contains(image, "@") {
+		return version, false
+	}
+	return parseVersion(tagVersion, image[index+1:])
+}
+
+func parseVersion(pattern *regexp.Regexp, value string) (version [2]int, ok bool) {
+
+	match := pattern.FindStringSubmatch(value)
+	if match == nil {
+		return version, false
+	}
+	version[0], _ = strconv.Atoi(match[1])
+	version[1], _ = strconv.Atoi(match[2])
+	return version, true
+}
+
+func olderThan(version, other [2]int) bool {
+	return version[0] < other[0] || (version[0] == other[0] && version[1] < other[1])
+}
+
+//CapabilityWarnings -- one warning for each capability level of the network that the oldest binary using it does not
+//support. Orderers use the orderer and channel capabilities, peers the channel and application capabilities, and a
+//capability Vx_y needs binaries of version x.y or later. Nodes whose image tag has no version are not checked
+func (c Config) CapabilityWarnings() []string {
+
+	capabilities := []struct {
+		name, value string
+		components  []string
+	}{
+		{"ordererCapabilities", c.OrdererCapabilities, []string{"orderer"}},
+		{"channelCapabilities", c.ChannelCapabilities, []string{"orderer", "peer"}},
+		{"applicationCapabilities", c.ApplicationCapabilities, []string{"peer"}},
+	}
+	nodes := c.nodes()
+	var warnings []string
+	for _, capability := range capabilities {
+		required, ok := parseVersion(capabilityVersion, capability.value)
+		if !ok {
+			continue
+		}
+		var oldest *node
+		var oldestImage string
+		var oldestVersion [2]int
+		for i, n := range nodes {
+			if !contains(capability.components, n.component) {
+				continue
+			}
+			image := c.Image(n.component, n.orgName, n.name)
+			version, ok := imageVersion(image)
+			if ok && (oldest == nil || olderThan(version, oldestVersion)) {
+				oldest, oldestImage, oldestVersion = &nodes[i], image, version
+			}
+		}
+		if oldest != nil && olderThan(oldestVersion, required) {
+			warnings = append(warnings, fmt.Sprintf("%s %s needs fabric %d.%d or later, but %s runs %s", capability.name, capability.value, required[0], required[1], oldest.name, oldestImage))
+		}
+	}
+	return warnings
+}
+
+func contains(list []string, item string) bool {
+	for _, element := range list {
+		if element == item {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package networkspec
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	yaml "gopkg.in/yaml.v2"
+)
+
+const mixedVersionSpec = `
+dockerOrg: hyperledger
+dockerTag: 2.5.0
+dockerImages:
+  ccenv: example/fabric-ccenv:2.5.0
+ordererCapabilities: V2_0
+channelCapabilities: V2_0
+applicationCapabilities: V2_5
+ordererOrganizations:
+- name: ordererorg1
+  numOrderers: 1
+peerOrganizations:
+- name: org1
+  numPeers: 2
+  nodeImages:
+    peer1-org1:
+      dockerImages:
+        peer: example/fabric-peer:latest
+- name: org2
+  numPeers: 1
+  dockerTag: 2.2.0
+addPeer:
+- name: org2
+  numPeers: 1
+  nodeImages:
+    peer1-org2:
+      dockerTag: 2.5.4
+`
+
+func TestImage(t *testing.T) {
+
+	var config Config
+	require.NoError(t, yaml.Unmarshal([]byte(mixedVersionSpec), &config))
+	for _, tc := range []struct {
+		component, orgName, nodeName, image string
+	}{
+		{"orderer", "ordererorg1", "orderer0-ordererorg1", "hyperledger/fabric-orderer:2.5.0"},
+		{"peer", "org1", "peer0-org1", "hyperledger/fabric-peer:2.5.0"},
+		{"peer", "org1", "peer1-org1", "example/fabric-peer:latest"},
+		{"ccenv", "org1", "peer1-org1", "example/fabric-ccenv:2.5.0"},
+		{"peer", "org2", "peer0-org2", "hyperledger/fabric-peer:2.2.0"},
+		{"ccenv", "org2", "peer0-org2", "hyperledger/fabric-ccenv:2.2.0"},
+		{"peer", "org2", "peer1-org2", "hyperledger/fabric-peer:2.5.4"},
+		{"ca", "org2", "ca0-org2", "hyperledger/fabric-ca:2.2.0"},
+		{"ccenv", "", "", "example/fabric-ccenv:2.5.0"},
+	} {
+		assert.Equal(t, tc.image, config.Image(tc.component, tc.orgName, tc.nodeName), "%s of %s", tc.component, tc.nodeName)
+	}
+}
+
+func TestCapabilityWarnings(t *testing.T) {
+
+	var config Config
+	require.NoError(t, yaml.Unmarshal([]byte(mixedVersionSpec), &config))
+	assert.Equal(t, []string{
+		"applicationCapabilities V2_5 needs fabric 2.5 or later, but peer0-org2 runs hyperledger/fabric-peer:2.2.0",
+	}, config.CapabilityWarnings())
+
+	config.ChannelCapabilities = "V3_0"
+	config.ApplicationCapabilities = "V2_0"
+	assert.Equal(t, []string{
+		"channelCapabilities V3_0 needs fabric 3.0 or later, but peer0-org2 runs hyperledger/fabric-peer:2.2.0",
+	}, config.CapabilityWarnings())
+
+	config.PeerOrganizations[1].DockerTag = "latest"
+	config.ChannelCapabilities = "V2_0"
+	assert.Empty(t, config.CapabilityWarnings())
+}
@@ -0,0 +1,82 @@
+package networkspec
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	//DefaultDockerNetwork -- the docker network of a network without networkName
+	DefaultDockerNetwork = "configfiles_default"
+	//DefaultPortBase -- the first port of a network without ports.base
+	DefaultPortBase = 30000
+	//PortRangeSize -- the ports a network uses from its base: orderers, peers, CAs, CouchDBs and their operations
+	//and admin ports all fall within it
+	PortRangeSize = 4000
+	maxPort       = 65535
+	//ChaincodeServerTLSDir -- the directory of a chaincode server holding the TLSDir of its ChaincodeServer
+	ChaincodeServerTLSDir = "/etc/hyperledger/chaincode/tls"
+)
+
+var networkNamePattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,38}[a-z0-9])?$`)
+
+//ValidateNetwork -- checks that networkName can name docker containers and networks and a k8s namespace, and that
+//the port range of ports.base fits the valid ports
+func (c Config) ValidateNetwork() error {
+
+	if c.NetworkName != "" && !networkNamePattern.MatchString(c.NetworkName) {
+		return errors.Errorf("invalid networkName %q: use at most 40 lowercase letters, digits and dashes, starting and ending with a letter or digit", c.NetworkName)
+	}
+	if c.Ports.Base != 0 && (c.Ports.Base < DefaultPortBase || c.Ports.Base+PortRangeSize-1 > maxPort) {
+		return errors.Errorf("invalid ports.base %d: the range of %d ports has to start at %d or above and end by %d", c.Ports.Base, PortRangeSize, DefaultPortBase, maxPort)
+	}
+	if c.ChaincodeRuntime != "" && c.ChaincodeRuntime != "docker" && !c.ChaincodeAsAService() {
+		return errors.Errorf("invalid chaincodeRuntime %q: it can be docker or ccaas", c.ChaincodeRuntime)
+	}
+	return nil
+}
+
+//PortBase -- the first port of the range of the network
+func (c Config) PortBase() int {
+	if c.Ports.Base == 0 {
+		return DefaultPortBase
+	}
+	return c.Ports.Base
+}
+
+//Port -- the port of the network replacing defaultPort, one of the ports of the network without ports.base
+func (c Config) Port(defaultPort int) int {
+	return defaultPort + c.PortBase() - DefaultPortBase
+}
+
+//ChaincodeAsAService -- whether the peers of the network run chaincode as a service only, without docker
+func (c Config) ChaincodeAsAService() bool {
+	return c.ChaincodeRuntime == "ccaas"
+}
+
+//DockerNetwork -- the docker network the containers and chaincode containers of the network join
+func (c Config) DockerNetwork() string {
+	if c.NetworkName == "" {
+		return DefaultDockerNetwork
+	}
+	return fmt.Sprintf("%s_default", c.NetworkName)
+}
+
+//ContainerName -- the docker container of a node. Container names are unique per docker daemon, so the ones of a
+//named network start with its name; the nodes still reach each other by node name on the docker network
+func (c Config) ContainerName(node string) string {
+	if c.NetworkName == "" {
+		return node
+	}
+	return fmt.Sprintf("%s-%s", c.NetworkName, node)
+}
+
+//ChaincodeNetworkID -- the network id of the peers, which prefixes the chaincode containers and images they build
+func (c Config) ChaincodeNetworkID() string {
+	if c.NetworkName == "" {
+		return "dev"
+	}
+	return c.NetworkName
+}
@@ -0,0 +1,39 @@
+package networkspec
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNetworkScope(t *testing.T) {
+
+	var config Config
+	assert.NoError(t, config.ValidateNetwork())
+	assert.Equal(t, 31000, config.Port(31000))
+	assert.Equal(t, "configfiles_default", config.DockerNetwork())
+	assert.Equal(t, "peer0-org1", config.ContainerName("peer0-org1"))
+	assert.Equal(t, "dev", config.ChaincodeNetworkID())
+
+	config = Config{NetworkName: "smoke", Ports: Ports{Base: 34000}}
+	assert.NoError(t, config.ValidateNetwork())
+	assert.Equal(t, 35000, config.Port(31000))
+	assert.Equal(t, "smoke_default", config.DockerNetwork())
+	assert.Equal(t, "smoke-peer0-org1", config.ContainerName("peer0-org1"))
+	assert.Equal(t, "smoke", config.ChaincodeNetworkID())
+
+	for _, invalid := range []Config{
+		{NetworkName: "Smoke"},
+		{NetworkName: "smoke-"},
+		{NetworkName: "smoke_test"},
+		{Ports: Ports{Base: 20000}},
+		{Ports: Ports{Base: 62000}},
+		{ChaincodeRuntime: "dind"},
+	} {
+		assert.Error(t, invalid.ValidateNetwork(), "%+v", invalid)
+	}
+	assert.NoError(t, Config{Ports: Ports{Base: 61535}}.ValidateNetwork())
+	assert.NoError(t, Config{ChaincodeRuntime: "ccaas"}.ValidateNetwork())
+	assert.True(t, Config{ChaincodeRuntime: "ccaas"}.ChaincodeAsAService())
+	assert.False(t, Config{ChaincodeRuntime: "docker"}.ChaincodeAsAService())
+}
@@ -0,0 +1,35 @@
+package networkspec
+
+//PeerOverrides -- the core.yaml overrides of a peer in the order they apply: network level, then organization level,
+//then node level
+func (c Config) PeerOverrides(orgName, peerName string) []map[string]interface{} {
+
+	output := []map[string]interface{}{c.Overrides.Peer}
+	var nodeOverrides []map[string]interface{}
+	for _, orgs := range [][]PeerOrganizations{c.PeerOrganizations, c.AddPeersToOrganization, c.AddOrganizations} {
+		for _, org := range orgs {
+			if org.Name == orgName {
+				output = append(output, org.Overrides)
+				nodeOverrides = append(nodeOverrides, org.NodeOverrides[peerName])
+			}
+		}
+	}
+	return append(output, nodeOverrides...)
+}
+
+//OrdererOverrides -- the orderer.yaml overrides of an orderer in the order they apply: network level, then
+//organization level, then node level
+func (c Config) OrdererOverrides(orgName, ordererName string) []map[string]interface{} {
+
+	output := []map[string]interface{}{c.Overrides.Orderer}
+	var nodeOverrides []map[string]interface{}
+	for _, orgs := range [][]OrdererOrganizations{c.OrdererOrganizations, c.AddOrderersToOrganization} {
+		for _, org := range orgs {
+			if org.Name == orgName {
+				output = append(output, org.Overrides)
+				nodeOverrides = append(nodeOverrides, org.NodeOverrides[ordererName])
+			}
+		}
+	}
+	return append(output, nodeOverrides...)
+}
@@ -8,32 +8,35 @@ import (
 	"time"
 
 	"github.com/hyperledger/fabric-protos-go/orderer/etcdraft"
+	"github.com/hyperledger/fabric-test/tools/operator/smartbft"
+	yaml "gopkg.in/yaml.v2"
 	corev1 "k8s.io/api/core/v1"
 )
 
 //Config --
 type Config struct {
-	DockerOrg    string `yaml:"dockerOrg,omitempty"`
-	DockerTag    string `yaml:"dockerTag,omitempty"`
-	DockerImages struct {
-		Ca      string `yaml:"ca,omitempty"`
-		Peer    string `yaml:"peer,omitempty"`
-		Orderer string `yaml:"orderer,omitempty"`
-		Baseos  string `yaml:"baseos,omitempty"`
-		Ccenv   string `yaml:"ccenv,omitempty"`
-		Javaenv string `yaml:"javaenv,omitempty"`
-		Nodeenv string `yaml:"nodeenv,omitempty"`
-	} `yaml:"dockerImages,omitempty"`
-	DBType                   string                 `yaml:"dbType,omitempty"`
-	PeerFabricLoggingSpec    string                 `yaml:"peerFabricLoggingSpec,omitempty"`
-	OrdererFabricLoggingSpec string                 `yaml:"ordererFabricLoggingSpec,omitempty"`
-	ArtifactsLocation        string                 `yaml:"artifactsLocation,omitempty"`
-	OrdererOrganizations     []OrdererOrganizations `yaml:"ordererOrganizations,omitempty"`
-	PeerOrganizations        []PeerOrganizations    `yaml:"peerOrganizations,omitempty"`
-	AddPeersToOrganization   []PeerOrganizations    `yaml:"addPeer,omitempty"`
-	Orderer                  struct {
-		OrdererType string `yaml:"ordererType,omitempty"`
-		BatchSize   struct {
+	NetworkName               string                 `yaml:"networkName,omitempty"`
+	Ports                     Ports                  `yaml:"ports,omitempty"`
+	DockerOrg                 string                 `yaml:"dockerOrg,omitempty"`
+	DockerTag                 string                 `yaml:"dockerTag,omitempty"`
+	DockerImages              DockerImages           `yaml:"dockerImages,omitempty"`
+	DBType                    string                 `yaml:"dbType,omitempty"`
+	PeerFabricLoggingSpec     string                 `yaml:"peerFabricLoggingSpec,omitempty"`
+	OrdererFabricLoggingSpec  string                 `yaml:"ordererFabricLoggingSpec,omitempty"`
+	ArtifactsLocation         string                 `yaml:"artifactsLocation,omitempty"`
+	OrdererOrganizations      []OrdererOrganizations `yaml:"ordererOrganizations,omitempty"`
+	PeerOrganizations         []PeerOrganizations    `yaml:"peerOrganizations,omitempty"`
+	AddPeersToOrganization    []PeerOrganizations    `yaml:"addPeer,omitempty"`
+	AddOrganizations          []PeerOrganizations    `yaml:"addOrg,omitempty"`
+	RemoveOrganizations       []string               `yaml:"removeOrg,omitempty"`
+	AddOrderersToOrganization []OrdererOrganizations `yaml:"addOrderer,omitempty"`
+	RemoveOrderers            []string               `yaml:"removeOrderer,omitempty"`
+	RotateOrdererCerts        []string               `yaml:"rotateOrdererCert,omitempty"`
+	RemoveChannels            []string               `yaml:"removeChannel,omitempty"`
+	Orderer                   struct {
+		OrdererType     string `yaml:"ordererType,omitempty"`
+		BootstrapMethod string `yaml:"bootstrapMethod,omitempty"`
+		BatchSize       struct {
 			MaxMessageCount   uint32 `yaml:"maxMessageCount,omitempty"`
 			AbsoluteMaxBytes  string `yaml:"absoluteMaxBytes,omitempty"`
 			PreferredMaxBytes string `yaml:"preferredMaxBytes,omitempty"`
@@ -46,6 +49,27 @@ type Config struct {
 			MaxInflightBlocks    uint32 `yaml:"maxInflightBlocks,omitempty"`
 			SnapshotIntervalSize string `yaml:"snapshotIntervalSize,omitempty"`
 		} `yaml:"etcdraftOptions,omitempty"`
+		SmartBFTOptions struct {
+			RequestBatchMaxCount      uint64 `yaml:"requestBatchMaxCount,omitempty"`
+			RequestBatchMaxBytes      string `yaml:"requestBatchMaxBytes,omitempty"`
+			RequestBatchMaxInterval   string `yaml:"requestBatchMaxInterval,omitempty"`
+			IncomingMessageBufferSize uint64 `yaml:"incomingMessageBufferSize,omitempty"`
+			RequestPoolSize           uint64 `yaml:"requestPoolSize,omitempty"`
+			RequestForwardTimeout     string `yaml:"requestForwardTimeout,omitempty"`
+			RequestComplainTimeout    string `yaml:"requestComplainTimeout,omitempty"`
+			RequestAutoRemoveTimeout  string `yaml:"requestAutoRemoveTimeout,omitempty"`
+			ViewChangeResendInterval  string `yaml:"viewChangeResendInterval,omitempty"`
+			ViewChangeTimeout         string `yaml:"viewChangeTimeout,omitempty"`
+			LeaderHeartbeatTimeout    string `yaml:"leaderHeartbeatTimeout,omitempty"`
+			LeaderHeartbeatCount      uint64 `yaml:"leaderHeartbeatCount,omitempty"`
+			CollectTimeout            string `yaml:"collectTimeout,omitempty"`
+			SyncOnStart               bool   `yaml:"syncOnStart,omitempty"`
+			SpeedUpViewChange         bool   `yaml:"speedUpViewChange,omitempty"`
+			LeaderRotation            string `yaml:"leaderRotation,omitempty"`
+			DecisionsPerLeader        uint64 `yaml:"decisionsPerLeader,omitempty"`
+			RequestMaxBytes           string `yaml:"requestMaxBytes,omitempty"`
+			RequestPoolSubmitTimeout  string `yaml:"requestPoolSubmitTimeout,omitempty"`
+		} `yaml:"smartbftOptions,omitempty"`
 	} `yaml:"orderer,omitempty"`
 	NumChannels             int           `yaml:"numChannels,omitempty"`
 	ChannelPrefix           string        `yaml:"channelPrefix,omitempty"`
@@ -57,7 +81,10 @@ type Config struct {
 	OrdererCapabilities     string        `yaml:"ordererCapabilities,omitempty"`
 	ChannelCapabilities     string        `yaml:"channelCapabilities,omitempty"`
 	ApplicationCapabilities string        `yaml:"applicationCapabilities,omitempty"`
-	K8s                     struct {
+	//ChaincodeRuntime -- docker, the default, lets the peers build and launch chaincode with docker; ccaas leaves
+	//docker out of the peers of k8s, which then only run chaincode installed as a service
+	ChaincodeRuntime string `yaml:"chaincodeRuntime,omitempty"`
+	K8s              struct {
 		Namespace       string                              `yaml:"namespace,omitempty"`
 		DataPersistence string                              `yaml:"dataPersistence,omitempty"`
 		ServiceType     string                              `yaml:"serviceType,omitempty"`
@@ -72,6 +99,63 @@ type Config struct {
 			Kafka    Resource `yaml:"kafka,omitempty"`
 		} `yaml:"resources,omitempty"`
 	} `yaml:"k8s,omitempty"`
+	ConfigUpdates []ConfigUpdate `yaml:"configUpdates,omitempty"`
+	Kafka         KafkaConfig    `yaml:"kafka,omitempty"`
+	NodeportIP    string         `yaml:"nodeportIP,omitempty"`
+	Overrides     Overrides      `yaml:"overrides,omitempty"`
+	Upgrade       Upgrade        `yaml:"upgrade,omitempty"`
+	//Endpoints -- the addresses of the peers and orderers of runtimes that do not reach them at <name>:<port of the
+	//docker and k8s networks>, set by the runtime rather than read from the network spec
+	Endpoints map[string]Endpoint `yaml:"-"`
+}
+
+//Ports -- the host ports of the network. Base is the first port of a range of PortRangeSize ports: it replaces the
+//30000 of the default ports and moves all of them by the same offset
+type Ports struct {
+	Base int `yaml:"base,omitempty"`
+}
+
+//Endpoint -- the host and port a peer or an orderer is reached at
+type Endpoint struct {
+	Host string
+	Port int
+}
+
+//Upgrade -- how upgradeNetwork replaces the nodes of the network. The default strategy recreates the whole network;
+//rolling replaces one orderer or peer at a time while invoking the test input file loadInput, if given
+type Upgrade struct {
+	Strategy  string `yaml:"strategy,omitempty"`
+	LoadInput string `yaml:"loadInput,omitempty"`
+}
+
+//DockerImages -- images of the fabric components, each replacing <dockerOrg>/fabric-<component>:<dockerTag>
+type DockerImages struct {
+	Ca      string `yaml:"ca,omitempty"`
+	Peer    string `yaml:"peer,omitempty"`
+	Orderer string `yaml:"orderer,omitempty"`
+	Baseos  string `yaml:"baseos,omitempty"`
+	Ccenv   string `yaml:"ccenv,omitempty"`
+	Javaenv string `yaml:"javaenv,omitempty"`
+	Nodeenv string `yaml:"nodeenv,omitempty"`
+}
+
+//Images -- the docker tag and images of a single node
+type Images struct {
+	DockerTag    string       `yaml:"dockerTag,omitempty"`
+	DockerImages DockerImages `yaml:"dockerImages,omitempty"`
+}
+
+//Overrides -- values of core.yaml and orderer.yaml keys, given as dotted paths such as peer.gossip.pvtData.pushAckTimeout
+type Overrides struct {
+	Peer    map[string]interface{} `yaml:"peer,omitempty"`
+	Orderer map[string]interface{} `yaml:"orderer,omitempty"`
+}
+
+//ConfigUpdate -- edits of the channel config applied by the configUpdate action
+type ConfigUpdate struct {
+	Channels []string      `yaml:"channels,omitempty"`
+	Signers  []string      `yaml:"signers,omitempty"`
+	Edits    yaml.MapSlice `yaml:"edits,omitempty"`
 }
 
 //Resource --
@@ -92,6 +176,13 @@ type OrdererOrganizations struct {
 	MSPID       string `yaml:"mspId,omitempty"`
 	NumOrderers int    `yaml:"numOrderers,omitempty"`
 	NumCA       int    `yaml:"numCa,omitempty"`
+	//Overrides and NodeOverrides are orderer.yaml keys; NodeOverrides is keyed by orderer name
+	Overrides     map[string]interface{}            `yaml:"overrides,omitempty"`
+	NodeOverrides map[string]map[string]interface{} `yaml:"nodeOverrides,omitempty"`
+	//DockerTag and DockerImages replace those of the network for the organization; NodeImages is keyed by orderer name
+	DockerTag    string            `yaml:"dockerTag,omitempty"`
+	DockerImages DockerImages      `yaml:"dockerImages,omitempty"`
+	NodeImages   map[string]Images `yaml:"nodeImages,omitempty"`
 }
 
 //KafkaConfig --
@@ -107,6 +198,13 @@ type PeerOrganizations struct {
 	MSPID    string `yaml:"mspId,omitempty"`
 	NumPeers int    `yaml:"numPeers,omitempty"`
 	NumCA    int    `yaml:"numCa,omitempty"`
+	//Overrides and NodeOverrides are core.yaml keys; NodeOverrides is keyed by peer name
+	Overrides     map[string]interface{}            `yaml:"overrides,omitempty"`
+	NodeOverrides map[string]map[string]interface{} `yaml:"nodeOverrides,omitempty"`
+	//DockerTag and DockerImages replace those of the network for the organization; NodeImages is keyed by peer name
+	DockerTag    string            `yaml:"dockerTag,omitempty"`
+	DockerImages DockerImages      `yaml:"dockerImages,omitempty"`
+	NodeImages   map[string]Images `yaml:"nodeImages,omitempty"`
 }
 
 //Orderer --
@@ -114,6 +212,7 @@ type Orderer struct {
 	MSPID       string `yaml:"mspid"`
 	URL         string `yaml:"url"`
 	MetricsURL  string `yaml:"metricsURL"`
+	AdminURL    string `yaml:"adminURL,omitempty"`
 	GrpcOptions struct {
 		SslTarget string `yaml:"ssl-target-name-override"`
 	} `yaml:"grpcOptions"`
@@ -128,6 +227,7 @@ type Orderer struct {
 type Peer struct {
 	URL         string `yaml:"url"`
 	MetricsURL  string `yaml:"metricsURL"`
+	AdminURL    string `yaml:"adminURL,omitempty"`
 	GrpcOptions struct {
 		SslTarget string `yaml:"ssl-target-name-override"`
 	} `yaml:"grpcOptions"`
@@ -200,6 +300,16 @@ type ConnectionProfile struct {
 	Organizations map[string]Organization         `yaml:"organizations"`
 }
 
+//ChaincodeServer -- a chaincode run as a service, which the peers reach at <Name>:<Port> on the docker network or in
+//the k8s namespace of the network. TLSDir, if set, is mounted at ChaincodeServerTLSDir
+type ChaincodeServer struct {
+	Name   string
+	Image  string
+	Port   int
+	Env    []string
+	TLSDir string
+}
+
 //ChaincodeID --
 type ChaincodeID struct {
 	Id      string `yaml:"id,omitempty"`
@@ -324,6 +434,8 @@ type CaliperConnectionProfile struct {
 
 const (
 	EtcdRaft = "etcdraft"
+	BFT      = "BFT"
+	Rolling  = "rolling"
 )
 
 type ConfigtxProfile struct {
@@ -369,16 +481,18 @@ type ConfigtxAnchorPeer struct {
 }
 
 type ConfigtxOrderer struct {
-	OrdererType   string                     `yaml:"OrdererType"`
-	Addresses     []string                   `yaml:"Addresses"`
-	BatchTimeout  time.Duration              `yaml:"BatchTimeout"`
-	BatchSize     ConfigtxBatchSize          `yaml:"BatchSize"`
-	Kafka         ConfigtxKafka              `yaml:"Kafka"`
-	EtcdRaft      *etcdraft.ConfigMetadata   `yaml:"EtcdRaft"`
-	Organizations []*ConfigtxOrganization    `yaml:"Organizations"`
-	MaxChannels   uint64                     `yaml:"MaxChannels"`
-	Capabilities  map[string]bool            `yaml:"Capabilities"`
-	Policies      map[string]*ConfigtxPolicy `yaml:"Policies"`
+	OrdererType      string                     `yaml:"OrdererType"`
+	Addresses        []string                   `yaml:"Addresses"`
+	BatchTimeout     time.Duration              `yaml:"BatchTimeout"`
+	BatchSize        ConfigtxBatchSize          `yaml:"BatchSize"`
+	Kafka            ConfigtxKafka              `yaml:"Kafka"`
+	EtcdRaft         *etcdraft.ConfigMetadata   `yaml:"EtcdRaft"`
+	SmartBFT         *smartbft.Options          `yaml:"SmartBFT"`
+	ConsenterMapping []*smartbft.Consenter      `yaml:"ConsenterMapping"`
+	Organizations    []*ConfigtxOrganization    `yaml:"Organizations"`
+	MaxChannels      uint64                     `yaml:"MaxChannels"`
+	Capabilities     map[string]bool            `yaml:"Capabilities"`
+	Policies         map[string]*ConfigtxPolicy `yaml:"Policies"`
 }
 
 type ConfigtxBatchSize struct {
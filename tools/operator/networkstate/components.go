@@ -0,0 +1,305 @@
+package networkstate
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/hyperledger/fabric-test/tools/operator/networkspec"
+	"github.com/hyperledger/fabric-test/tools/operator/paths"
+)
+
+//New -- the state of a network launched from config by the up action: its orderers, peers, CAs, CouchDBs and kafka
+//brokers and zookeepers, without those of addPeer, addOrg and addOrderer
+func New(config networkspec.Config, runtime string) State {
+
+	state := State{Version: Version, NetworkName: config.NetworkName, Runtime: runtime, Spec: Spec{config}}
+	for _, org := range config.OrdererOrganizations {
+		state.Add(orderers(config, org, 0, org.NumOrderers)...)
+	}
+	for _, org := range config.PeerOrganizations {
+		state.Add(peers(config, org, 0, org.NumPeers)...)
+		state.Add(cas(config, org.Name, org.NumCA)...)
+	}
+	for _, org := range config.OrdererOrganizations {
+		state.Add(cas(config, org.Name, org.NumCA)...)
+	}
+	if config.Orderer.OrdererType == "kafka" {
+		for i := 0; i < config.Kafka.NumKafka; i++ {
+			state.Add(Component{Name: fmt.Sprintf("kafka%d", i), Type: Kafka})
+		}
+		for i := 0; i < config.Kafka.NumZookeepers; i++ {
+			state.Add(Component{Name: fmt.Sprintf("zookeeper%d", i), Type: Zookeeper})
+		}
+	}
+	return state
+}
+
+func orderers(config networkspec.Config, org networkspec.OrdererOrganizations, first, count int) []Component {
+
+	var output []Component
+	for i := first; i < first+count; i++ {
+		name := fmt.Sprintf("orderer%d-%s", i, org.Name)
+		output = append(output, Component{Name: name, Type: Orderer, Org: org.Name, Image: config.Image("orderer", org.Name, name)})
+	}
+	return output
+}
+
+func peers(config networkspec.Config, org networkspec.PeerOrganizations, first, count int) []Component {
+
+	var output []Component
+	for i := first; i < first+count; i++ {
+		name := fmt.Sprintf("peer%d-%s", i, org.Name)
+		output = append(output, Component{Name: name, Type: Peer, Org: org.Name, Image: config.Image("peer", org.Name, name)})
+		if config.DBType == "couchdb" {
+			output = append(output, Component{Name: fmt.Sprintf("couchdb-%s", name), Type: CouchDB, Org: org.Name})
+		}
+	}
+	return output
+}
+
+func cas(config networkspec.Config, orgName string, count int) []Component {
+
+	var output []Component
+	for i := 0; i < count; i++ {
+		name := fmt.Sprintf("ca%d-%s", i, orgName)
+		output = append(output, Component{Name: name, Type: CA, Org: orgName, Image: config.Image("ca", orgName, name)})
+	}
+	return output
+}
+
+//Apply -- updates the state after action changed the network to the one of config: adds the nodes of addPeer, addOrg
+//and addOrderer, numbered after those of their organization as the launchers number them, removes the organizations
+//of removeOrg and the orderers of removeOrderer, and takes the images of config after upgradeNetwork
+func (s *State) Apply(action string, config networkspec.Config) {
+
+	switch action {
+	case "addPeer":
+		for _, added := range config.AddPeersToOrganization {
+			for _, org := range config.PeerOrganizations {
+				if org.Name == added.Name {
+					s.Add(peers(config, added, org.NumPeers, added.NumPeers)...)
+				}
+			}
+		}
+	case "addOrg":
+		for _, org := range config.AddOrganizations {
+			s.Add(peers(config, org, 0, org.NumPeers)...)
+			s.Add(cas(config, org.Name, org.NumCA)...)
+		}
+	case "addOrderer":
+		for _, added := range config.AddOrderersToOrganization {
+			for _, org := range config.OrdererOrganizations {
+				if org.Name == added.Name {
+					s.Add(orderers(config, added, org.NumOrderers, added.NumOrderers)...)
+				}
+			}
+		}
+	case "removeOrg":
+		for _, orgName := range config.RemoveOrganizations {
+			s.Remove(names(s.OfOrg(orgName))...)
+		}
+	case "removeOrderer":
+		s.Remove(config.RemoveOrderers...)
+	case "upgradeNetwork":
+		for i, component := range s.Components {
+			if component.Type == Peer || component.Type == Orderer || component.Type == CA {
+				s.Components[i].Image = config.Image(component.Type, component.Org, component.Name)
+			}
+		}
+	}
+	s.Spec = Spec{config}
+}
+
+//Add -- adds the components the state does not have yet
+func (s *State) Add(components ...Component) {
+	for _, component := range components {
+		if s.Component(component.Name) == nil {
+			s.Components = append(s.Components, component)
+		}
+	}
+}
+
+//Remove -- removes the components with the given names
+func (s *State) Remove(componentNames ...string) {
+
+	var output []Component
+	for _, component := range s.Components {
+		if !contains(componentNames, component.Name) {
+			output = append(output, component)
+		}
+	}
+	s.Components = output
+}
+
+//Component -- the component with the given name, or nil
+func (s *State) Component(name string) *Component {
+	for i := range s.Components {
+		if s.Components[i].Name == name {
+			return &s.Components[i]
+		}
+	}
+	return nil
+}
+
+//OfType -- the components of the given types, in the order of the state
+func (s State) OfType(types ...string) []Component {
+
+	var output []Component
+	for _, component := range s.Components {
+		if contains(types, component.Type) {
+			output = append(output, component)
+		}
+	}
+	return output
+}
+
+//OfOrg -- the components of an organization, of the given types or of all types
+func (s State) OfOrg(orgName string, types ...string) []Component {
+
+	var output []Component
+	for _, component := range s.Components {
+		if component.Org == orgName && (len(types) == 0 || contains(types, component.Type)) {
+			output = append(output, component)
+		}
+	}
+	return output
+}
+
+//Nodes -- the names of the orderers and then of the peers of the network
+func (s State) Nodes() []string {
+	return names(append(s.OfType(Orderer), s.OfType(Peer)...))
+}
+
+func names(components []Component) []string {
+	var output []string
+	for _, component := range components {
+		output = append(output, component.Name)
+	}
+	return output
+}
+
+//Discover -- takes the endpoints and ports of the peers, orderers and CAs, and the channels of the peers, from the
+//connection profiles of the network
+func (s *State) Discover() error {
+
+	connProfilesDir := paths.ConnectionProfilesDir(s.Spec.ArtifactsLocation)
+	files, err := ioutil.ReadDir(connProfilesDir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to read the connection profiles in %s", connProfilesDir)
+	}
+	channels := make(map[string][]string)
+	for _, file := range files {
+		if !strings.HasPrefix(file.Name(), "connection_profile_") {
+			continue
+		}
+		var connProfile networkspec.ConnectionProfile
+		contents, err := ioutil.ReadFile(paths.JoinPath(connProfilesDir, file.Name()))
+		if err != nil {
+			return errors.Wrapf(err, "failed to read the connection profile %s", file.Name())
+		}
+		err = yaml.Unmarshal(contents, &connProfile)
+		if err != nil {
+			return errors.Wrapf(err, "failed to unmarshal the connection profile %s", file.Name())
+		}
+		for name, peer := range connProfile.Peers {
+			s.setEndpoints(name, map[string]string{"listen": peer.URL, "operations": peer.MetricsURL, "admin": peer.AdminURL})
+		}
+		for name, orderer := range connProfile.Orderers {
+			s.setEndpoints(name, map[string]string{"listen": orderer.URL, "operations": orderer.MetricsURL, "admin": orderer.AdminURL})
+		}
+		for _, ca := range connProfile.CA {
+			s.setEndpoints(ca.CAName, map[string]string{"listen": ca.URL})
+		}
+		for channelName, channel := range connProfile.Channels {
+			for _, peerName := range channel.Peers {
+				if !contains(channels[peerName], channelName) {
+					channels[peerName] = append(channels[peerName], channelName)
+				}
+			}
+		}
+	}
+	for i, component := range s.Components {
+		if component.Type != Peer {
+			continue
+		}
+		for _, channelName := range channels[component.Name] {
+			if !contains(component.Channels, channelName) {
+				s.Components[i].Channels = append(s.Components[i].Channels, channelName)
+			}
+		}
+		sort.Strings(s.Components[i].Channels)
+	}
+	return nil
+}
+
+//setEndpoints -- sets the non empty endpoints of a component, and the ports they use
+func (s *State) setEndpoints(name string, endpoints map[string]string) {
+
+	component := s.Component(name)
+	if component == nil {
+		return
+	}
+	for key, endpoint := range endpoints {
+		if endpoint == "" {
+			continue
+		}
+		if component.Endpoints == nil {
+			component.Endpoints = make(map[string]string)
+		}
+		component.Endpoints[key] = endpoint
+		endpointURL, err := url.Parse(endpoint)
+		if err != nil {
+			continue
+		}
+		port, err := strconv.Atoi(endpointURL.Port())
+		if err == nil {
+			component.SetPort(key, port)
+		}
+	}
+}
+
+//SetPort -- sets the port of the component used by its listen, operations or admin endpoint
+func (c *Component) SetPort(key string, port int) {
+	if c.Ports == nil {
+		c.Ports = make(map[string]int)
+	}
+	c.Ports[key] = port
+}
+
+//Install -- records a chaincode installed on the given peers
+func (s *State) Install(chaincode Chaincode, peerNames []string) {
+
+	for _, peerName := range peerNames {
+		peer := s.Component(strings.TrimSpace(peerName))
+		if peer == nil || peer.Type != Peer {
+			continue
+		}
+		installed := false
+		for _, existing := range peer.Chaincodes {
+			installed = installed || existing == chaincode
+		}
+		if !installed {
+			peer.Chaincodes = append(peer.Chaincodes, chaincode)
+		}
+	}
+}
+
+func contains(list []string, item string) bool {
+	for _, element := range list {
+		if element == item {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,216 @@
+package networkstate
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/hyperledger/fabric-test/tools/operator/networkspec"
+	"github.com/hyperledger/fabric-test/tools/operator/paths"
+)
+
+const (
+	//Version -- the version of the network-state.json files the operator writes and reads
+	Version = 1
+	//FileName -- the file keeping the state of a network, in its artifactsLocation
+	FileName = "network-state.json"
+)
+
+//Component types
+const (
+	Peer      = "peer"
+	Orderer   = "orderer"
+	CA        = "ca"
+	CouchDB   = "couchdb"
+	Kafka     = "kafka"
+	Zookeeper = "zookeeper"
+)
+
+//State -- the inventory of a launched network: the network spec it runs and every component running it. Actions
+//read it instead of deriving the components from the network spec again, and update it as they change the network
+type State struct {
+	Version     int         `json:"version"`
+	NetworkName string      `json:"networkName,omitempty"`
+	Runtime     string      `json:"runtime"`
+	UpdatedAt   time.Time   `json:"updatedAt"`
+	Spec        Spec        `json:"spec"`
+	Components  []Component `json:"components"`
+}
+
+//Component -- a peer, orderer, CA, CouchDB, kafka broker or zookeeper of the network. Endpoints and ports are keyed by
+//listen, operations and admin; a component has a container ID, a pod name or a pid depending on the runtime
+type Component struct {
+	Name        string            `json:"name"`
+	Type        string            `json:"type"`
+	Org         string            `json:"org,omitempty"`
+	Image       string            `json:"image,omitempty"`
+	Endpoints   map[string]string `json:"endpoints,omitempty"`
+	Ports       map[string]int    `json:"ports,omitempty"`
+	ContainerID string            `json:"containerID,omitempty"`
+	PodName     string            `json:"podName,omitempty"`
+	Pid         int               `json:"pid,omitempty"`
+	Channels    []string          `json:"channels,omitempty"`
+	Chaincodes  []Chaincode       `json:"chaincodes,omitempty"`
+}
+
+//Chaincode -- a chaincode installed on a peer
+type Chaincode struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+//Spec -- the network spec of a state. It is kept with the keys of the network input file, so that the values of
+//overrides, which JSON cannot hold as they are parsed, survive
+type Spec struct {
+	networkspec.Config
+}
+
+//MarshalJSON -- marshals the network spec with the keys of the network input file
+func (s Spec) MarshalJSON() ([]byte, error) {
+
+	contents, err := yaml.Marshal(s.Config)
+	if err != nil {
+		return nil, err
+	}
+	var value interface{}
+	err = yaml.Unmarshal(contents, &value)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(jsonValue(value))
+}
+
+//UnmarshalJSON -- unmarshals a network spec marshalled by MarshalJSON
+func (s *Spec) UnmarshalJSON(contents []byte) error {
+
+	var value interface{}
+	err := json.Unmarshal(contents, &value)
+	if err != nil {
+		return err
+	}
+	contents, err = yaml.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return yaml.Unmarshal(contents, &s.Config)
+}
+
+//jsonValue -- a value parsed from YAML with the map[interface{}]interface{} of its nested maps made JSON maps
+func jsonValue(value interface{}) interface{} {
+
+	switch value := value.(type) {
+	case map[interface{}]interface{}:
+		output := make(map[string]interface{}, len(value))
+		for key, element := range value {
+			output[toString(key)] = jsonValue(element)
+		}
+		return output
+	case []interface{}:
+		output := make([]interface{}, len(value))
+		for i, element := range value {
+			output[i] = jsonValue(element)
+		}
+		return output
+	}
+	return value
+}
+
+func toString(value interface{}) string {
+	contents, _ := yaml.Marshal(value)
+	return strings.TrimSpace(string(contents))
+}
+
+//Path -- the state file of the network with the given artifactsLocation
+func Path(artifactsLocation string) string {
+	return paths.JoinPath(artifactsLocation, FileName)
+}
+
+//IsStateFile -- whether the input file given to the operator is the state file of a network rather than a network spec
+func IsStateFile(inputPath string) bool {
+	return filepath.Ext(inputPath) == ".json"
+}
+
+//Read -- reads a state file
+func Read(statePath string) (State, error) {
+
+	var state State
+	contents, err := ioutil.ReadFile(statePath)
+	if err != nil {
+		return state, errors.Wrapf(err, "failed to read the state of the network from %s", statePath)
+	}
+	err = json.Unmarshal(contents, &state)
+	if err != nil {
+		return state, errors.Wrapf(err, "failed to unmarshal the state of the network in %s", statePath)
+	}
+	if state.Version < 1 || state.Version > Version {
+		return state, errors.Errorf("unsupported version %d of the state of the network in %s, this operator reads version %d", state.Version, statePath, Version)
+	}
+	return state, nil
+}
+
+//Load -- the state of the network of config. Networks launched without a state file get one derived from config
+func Load(config networkspec.Config, runtime string) (State, error) {
+
+	state, err := Read(Path(config.ArtifactsLocation))
+	if os.IsNotExist(errors.Cause(err)) {
+		return New(config, runtime), nil
+	}
+	return state, err
+}
+
+//Locate -- the state file of the network a connection profile, or directory of connection profiles, of a test input
+//file belongs to; ok is false when the network has none
+func Locate(connProfilePath string) (statePath string, ok bool) {
+
+	connProfilesDir := strings.TrimSuffix(connProfilePath, "/")
+	if ext := filepath.Ext(connProfilesDir); ext == ".yaml" || ext == ".yml" || ext == ".json" {
+		connProfilesDir = filepath.Dir(connProfilesDir)
+	}
+	statePath = Path(filepath.Dir(connProfilesDir))
+	_, err := os.Stat(statePath)
+	return statePath, err == nil
+}
+
+//Write -- writes the state to the state file in the artifactsLocation of its network. The file is replaced at once so
+//that actions reading it never see a partial state
+func (s *State) Write() error {
+
+	s.Version = Version
+	s.UpdatedAt = time.Now().UTC()
+	sort.SliceStable(s.Components, func(i, j int) bool {
+		return typeOrder(s.Components[i].Type) < typeOrder(s.Components[j].Type)
+	})
+	contents, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the state of the network")
+	}
+	statePath := Path(s.Spec.ArtifactsLocation)
+	err = os.MkdirAll(filepath.Dir(statePath), 0755)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create the directory of %s", statePath)
+	}
+	tempPath := statePath + ".tmp"
+	err = ioutil.WriteFile(tempPath, contents, 0644)
+	if err != nil {
+		return errors.Wrapf(err, "failed to write %s", tempPath)
+	}
+	return errors.Wrapf(os.Rename(tempPath, statePath), "failed to write %s", statePath)
+}
+
+//typeOrder -- the order of the components of a type in the state file
+func typeOrder(componentType string) int {
+	types := []string{Orderer, Peer, CA, CouchDB, Kafka, Zookeeper}
+	for i, name := range types {
+		if name == componentType {
+			return i
+		}
+	}
+	return len(types)
+}
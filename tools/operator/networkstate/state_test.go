@@ -0,0 +1,117 @@
+package networkstate
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/hyperledger/fabric-test/tools/operator/networkspec"
+)
+
+const stateSpec = `
+networkName: smoke
+dockerOrg: hyperledger
+dockerTag: 2.5.0
+dbType: couchdb
+overrides:
+  peer:
+    peer.gossip.pvtData.pushAckTimeout: 10s
+    peer.limits.concurrency:
+      endorserService: 5000
+ordererOrganizations:
+- name: ordererorg1
+  numOrderers: 1
+  numCa: 0
+peerOrganizations:
+- name: org1
+  numPeers: 1
+  numCa: 1
+- name: org2
+  numPeers: 1
+addPeer:
+- name: org1
+  numPeers: 1
+`
+
+const stateConnProfile = `
+peers:
+  peer0-org1:
+    url: grpcs://localhost:31000
+    metricsURL: http://localhost:31100
+orderers:
+  orderer0-ordererorg1:
+    url: grpcs://localhost:30000
+    adminURL: https://localhost:30200
+certificateAuthorities:
+  ca0:
+    url: https://localhost:32000
+    caName: ca0-org1
+channels:
+  testorgschannel0:
+    peers:
+    - peer0-org1
+`
+
+func TestState(t *testing.T) {
+
+	var config networkspec.Config
+	require.NoError(t, yaml.Unmarshal([]byte(stateSpec), &config))
+	config.ArtifactsLocation = t.TempDir()
+
+	state := New(config, "docker")
+	assert.Equal(t, []string{"orderer0-ordererorg1", "peer0-org1", "peer0-org2"}, state.Nodes())
+	assert.Equal(t, []string{"peer0-org1", "couchdb-peer0-org1", "ca0-org1"}, names(state.OfOrg("org1")))
+	assert.Equal(t, "hyperledger/fabric-peer:2.5.0", state.Component("peer0-org1").Image)
+
+	state.Apply("addPeer", config)
+	assert.Equal(t, []string{"peer0-org1", "peer1-org1"}, names(state.OfOrg("org1", Peer)))
+	config.RemoveOrganizations = []string{"org2"}
+	state.Apply("removeOrg", config)
+	assert.Empty(t, state.OfOrg("org2"))
+
+	connProfilesDir := filepath.Join(config.ArtifactsLocation, "connection-profile")
+	require.NoError(t, os.MkdirAll(connProfilesDir, 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(connProfilesDir, "connection_profile_org1.yaml"), []byte(stateConnProfile), 0644))
+	require.NoError(t, state.Discover())
+	peer := state.Component("peer0-org1")
+	assert.Equal(t, map[string]int{"listen": 31000, "operations": 31100}, peer.Ports)
+	assert.Equal(t, "grpcs://localhost:31000", peer.Endpoints["listen"])
+	assert.Equal(t, []string{"testorgschannel0"}, peer.Channels)
+	assert.Equal(t, 30200, state.Component("orderer0-ordererorg1").Ports["admin"])
+	assert.Equal(t, 32000, state.Component("ca0-org1").Ports["listen"])
+	state.Install(Chaincode{Name: "samplecc", Version: "v1"}, []string{"peer0-org1", " peer1-org1", "orderer0-ordererorg1"})
+	state.Install(Chaincode{Name: "samplecc", Version: "v1"}, []string{"peer0-org1"})
+	assert.Equal(t, []Chaincode{{Name: "samplecc", Version: "v1"}}, state.Component("peer0-org1").Chaincodes)
+	assert.Len(t, state.Component("peer1-org1").Chaincodes, 1)
+	assert.Empty(t, state.Component("orderer0-ordererorg1").Chaincodes)
+
+	require.NoError(t, state.Write())
+	statePath, ok := Locate(filepath.Join(connProfilesDir, "connection_profile_org1.yaml"))
+	require.True(t, ok)
+	assert.Equal(t, Path(config.ArtifactsLocation), statePath)
+	_, ok = Locate(connProfilesDir + "/")
+	assert.True(t, ok)
+	read, err := Read(statePath)
+	require.NoError(t, err)
+	assert.Equal(t, Version, read.Version)
+	assert.Equal(t, "smoke", read.NetworkName)
+	assert.Equal(t, "docker", read.Runtime)
+	assert.Equal(t, config, read.Spec.Config)
+	assert.Equal(t, state.Components, read.Components)
+	loaded, err := Load(config, "docker")
+	require.NoError(t, err)
+	assert.Equal(t, read.Components, loaded.Components)
+
+	require.NoError(t, ioutil.WriteFile(statePath, []byte(`{"version": 2}`), 0644))
+	_, err = Read(statePath)
+	assert.EqualError(t, err, "unsupported version 2 of the state of the network in "+statePath+", this operator reads version 1")
+	require.NoError(t, os.Remove(statePath))
+	loaded, err = Load(config, "docker")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"orderer0-ordererorg1", "peer0-org1", "peer0-org2"}, loaded.Nodes())
+}
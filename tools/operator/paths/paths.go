@@ -39,18 +39,6 @@ func PeerOrgsDir(artifactsLocation string) string {
 	return componentPath(CryptoConfigDir(artifactsLocation), "peerOrganizations")
 }
 
-//YTTPath --
-func YTTPath() string {
-	currentDir, err := GetCurrentDir()
-	if err != nil {
-		logger.ERROR("YTTPath function is failed in getting current directory")
-	}
-	if strings.Contains(currentDir, "regression") {
-		return componentPath(currentDir, "../../tools/operator/ytt")
-	}
-	return componentPath(currentDir, "ytt")
-}
-
 //TemplatesDir --
 func TemplatesDir() string {
 	currentDir, err := GetCurrentDir()
@@ -75,48 +63,39 @@ func ScriptsDir() string {
 	return componentPath(currentDir, "scripts")
 }
 
-//TemplateFilePath --
-func TemplateFilePath(fileName string) string {
-	templateFiles := map[string]string{
-		"crypto-config":        "crypto-config.yaml",
-		"crypto-config-extend": "crypto-config-extend.yaml",
-		"configtx":             "configtx.yaml",
-		"docker":               "docker/docker-compose.yaml",
-		"peer-extend":          "docker/peer-extend.yaml",
-		"input":                "input.yaml",
-	}
-	return JoinPath(TemplatesDir(), templateFiles[fileName])
-}
-
-//ConfigFilesDir --
-func ConfigFilesDir(extend bool) string {
+//ConfigFilesDir -- the directory of the configuration files of a network, configFiles-<networkName> for a named one
+func ConfigFilesDir(networkName string, extend bool) string {
 	currentDir, err := GetCurrentDir()
 	if err != nil {
 		logger.ERROR("ConfigFilesDir function is failed in getting current directory")
 	}
 	configDirName := "configFiles"
+	if networkName != "" {
+		configDirName = "configFiles-" + networkName
+	}
 	if strings.Contains(currentDir, "regression") {
-		configDirName = "../../tools/operator/configFiles"
+		configDirName = "../../tools/operator/" + configDirName
 	}
 	if extend {
-		configDirName = "configFiles/extend"
-		if strings.Contains(currentDir, "regression") {
-			configDirName = "../../tools/operator/configFiles/extend"
-		}
+		configDirName = configDirName + "/extend"
 	}
 	return componentPath(currentDir, configDirName)
 }
 
 //ConfigFilePath --
-func ConfigFilePath(fileName string) string {
+func ConfigFilePath(networkName, fileName string) string {
 	configFiles := map[string]string{
-		"crypto-config":        "crypto-config.yaml",
-		"crypto-config-extend": "extend/crypto-config-extend.yaml",
-		"configtx":             "configtx.yaml",
-		"docker":               "docker-compose.yaml",
-		"peer-extend":          "extend/peer-extend.yaml",
-	}
-	return JoinPath(ConfigFilesDir(false), configFiles[fileName])
+		"crypto-config":            "crypto-config.yaml",
+		"crypto-config-extend":     "extend/crypto-config-extend.yaml",
+		"configtx":                 "configtx.yaml",
+		"docker":                   "docker-compose.yaml",
+		"peer-extend":              "extend/peer-extend.yaml",
+		"crypto-config-addorg":     "addorg/crypto-config-addorg.yaml",
+		"org-extend":               "addorg/org-extend.yaml",
+		"crypto-config-addorderer": "addorderer/crypto-config-addorderer.yaml",
+		"orderer-extend":           "addorderer/orderer-extend.yaml",
+	}
+	return JoinPath(ConfigFilesDir(networkName, false), configFiles[fileName])
 }
 
 //GetCurrentDir --
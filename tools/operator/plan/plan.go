@@ -0,0 +1,166 @@
+package plan
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+//Plan -- what an action would do, recorded by --dry-run instead of doing it: the config files it would render, the
+//commands it would run, the containers, processes and k8s objects it would create, the channel transactions it would
+//generate or submit and the chaincode lifecycle calls it would make
+type Plan struct {
+	Action              string               `json:"action"`
+	Runtime             string               `json:"runtime,omitempty"`
+	NetworkName         string               `json:"networkName,omitempty"`
+	Files               []File               `json:"files,omitempty"`
+	Commands            []Command            `json:"commands,omitempty"`
+	Containers          []Container          `json:"containers,omitempty"`
+	Processes           []Process            `json:"processes,omitempty"`
+	K8sObjects          []K8sObject          `json:"k8sObjects,omitempty"`
+	ChannelTransactions []ChannelTransaction `json:"channelTransactions,omitempty"`
+	LifecycleCalls      []LifecycleCall      `json:"lifecycleCalls,omitempty"`
+}
+
+//File -- a config file the action would write, with the size of its rendered contents
+type File struct {
+	Path string `json:"path"`
+	Size int    `json:"size"`
+}
+
+//Command -- a command line the action would run
+type Command struct {
+	Name string   `json:"name"`
+	Args []string `json:"args,omitempty"`
+}
+
+//Container -- a docker container the action would create
+type Container struct {
+	Name  string   `json:"name"`
+	Image string   `json:"image"`
+	Ports []string `json:"ports,omitempty"`
+}
+
+//Process -- a local process the action would start, and the binary it would run
+type Process struct {
+	Name   string `json:"name"`
+	Binary string `json:"binary"`
+}
+
+//K8sObject -- a kubernetes object the action would create
+type K8sObject struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	Image     string `json:"image,omitempty"`
+}
+
+//ChannelTransaction -- a channel artifact the action would generate, such as a genesis block, a channel creation
+//transaction or an anchor peers update, or a channel transaction it would submit, such as create, join or update
+type ChannelTransaction struct {
+	Channel string `json:"channel"`
+	Kind    string `json:"kind"`
+	Org     string `json:"org,omitempty"`
+	Path    string `json:"path,omitempty"`
+}
+
+//LifecycleCall -- a chaincode lifecycle call the action would make: install, instantiate, upgrade, approve or commit
+type LifecycleCall struct {
+	Call      string   `json:"call"`
+	SDK       string   `json:"sdk,omitempty"`
+	Chaincode string   `json:"chaincode"`
+	Version   string   `json:"version,omitempty"`
+	Sequence  string   `json:"sequence,omitempty"`
+	Channel   string   `json:"channel,omitempty"`
+	Orgs      []string `json:"orgs,omitempty"`
+	Peers     []string `json:"peers,omitempty"`
+	Policy    string   `json:"policy,omitempty"`
+}
+
+//AddFile -- records a config file the action would write. It has the signature of the functions writing config files
+//so that it can replace them
+func (p *Plan) AddFile(path string, contents []byte) error {
+	p.Files = append(p.Files, File{Path: path, Size: len(contents)})
+	return nil
+}
+
+//AddCommand -- records a command line the action would run
+func (p *Plan) AddCommand(name string, args ...string) {
+	p.Commands = append(p.Commands, Command{Name: name, Args: args})
+}
+
+//String -- the command line, with the arguments quoted where a shell would need it
+func (c Command) String() string {
+
+	words := []string{c.Name}
+	for _, arg := range c.Args {
+		if arg == "" || strings.ContainsAny(arg, " \t\n\"'{}[]()$&|;<>*?") {
+			arg = strconv.Quote(arg)
+		}
+		words = append(words, arg)
+	}
+	return strings.Join(words, " ")
+}
+
+//Write -- writes the plan as a section per kind of change, skipping the kinds the action would not make
+func (p Plan) Write(w io.Writer) error {
+
+	fmt.Fprintf(w, "Plan of action %s", p.Action)
+	if p.NetworkName != "" {
+		fmt.Fprintf(w, " on network %s", p.NetworkName)
+	}
+	if p.Runtime != "" {
+		fmt.Fprintf(w, " (%s)", p.Runtime)
+	}
+	fmt.Fprintln(w)
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	section := func(title string, count int, header string) {
+		if count > 0 {
+			fmt.Fprintf(table, "\n%s (%d)\n%s\n", title, count, header)
+		}
+	}
+	section("CONFIG FILES", len(p.Files), "PATH\tBYTES")
+	for _, file := range p.Files {
+		fmt.Fprintf(table, "%s\t%d\n", file.Path, file.Size)
+	}
+	section("CONTAINERS", len(p.Containers), "NAME\tIMAGE\tPORTS")
+	for _, container := range p.Containers {
+		fmt.Fprintf(table, "%s\t%s\t%s\n", container.Name, container.Image, strings.Join(container.Ports, ","))
+	}
+	section("PROCESSES", len(p.Processes), "NAME\tBINARY")
+	for _, process := range p.Processes {
+		fmt.Fprintf(table, "%s\t%s\n", process.Name, process.Binary)
+	}
+	section("K8S OBJECTS", len(p.K8sObjects), "KIND\tNAMESPACE\tNAME\tIMAGE")
+	for _, object := range p.K8sObjects {
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\n", object.Kind, object.Namespace, object.Name, object.Image)
+	}
+	section("CHANNEL TRANSACTIONS", len(p.ChannelTransactions), "CHANNEL\tKIND\tORG\tPATH")
+	for _, tx := range p.ChannelTransactions {
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\n", tx.Channel, tx.Kind, tx.Org, tx.Path)
+	}
+	section("LIFECYCLE CALLS", len(p.LifecycleCalls), "CALL\tSDK\tCHAINCODE\tCHANNEL\tORGS\tPEERS\tPOLICY")
+	for _, call := range p.LifecycleCalls {
+		chaincode := call.Chaincode
+		if call.Version != "" {
+			chaincode += ":" + call.Version
+		}
+		if call.Sequence != "" {
+			chaincode += " (sequence " + call.Sequence + ")"
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", call.Call, call.SDK, chaincode, call.Channel, strings.Join(call.Orgs, ","), strings.Join(call.Peers, ","), call.Policy)
+	}
+	err := table.Flush()
+	if err != nil {
+		return err
+	}
+	if len(p.Commands) > 0 {
+		fmt.Fprintf(w, "\nCOMMANDS (%d)\n", len(p.Commands))
+		for _, command := range p.Commands {
+			fmt.Fprintln(w, command.String())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package plan
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommandString(t *testing.T) {
+
+	command := Command{Name: "peer", Args: []string{"lifecycle", "chaincode", "package", "--label", "mapcc_v1", "--signature-policy", "OR('Org1MSP.member')", ""}}
+	assert.Equal(t, `peer lifecycle chaincode package --label mapcc_v1 --signature-policy "OR('Org1MSP.member')" ""`, command.String())
+}
+
+func TestWrite(t *testing.T) {
+
+	p := Plan{Action: "up", Runtime: "docker", NetworkName: "smoke"}
+	require.NoError(t, p.AddFile("configFiles-smoke/crypto-config.yaml", []byte("OrdererOrgs:\n")))
+	p.Containers = []Container{{Name: "smoke-peer0-org1", Image: "hyperledger/fabric-peer:2.5", Ports: []string{"7051", "35000:35000"}}}
+	p.ChannelTransactions = []ChannelTransaction{{Channel: "testorgschannel0", Kind: "channel creation tx", Path: "smoke/channel-artifacts/testorgschannel0.tx"}}
+	p.AddCommand("cryptogen", "generate", "--config", "configFiles-smoke/crypto-config.yaml")
+
+	var out bytes.Buffer
+	require.NoError(t, p.Write(&out))
+	expected := `Plan of action up on network smoke (docker)
+
+CONFIG FILES (1)
+PATH                                  BYTES
+configFiles-smoke/crypto-config.yaml  13
+
+CONTAINERS (1)
+NAME              IMAGE                        PORTS
+smoke-peer0-org1  hyperledger/fabric-peer:2.5  7051,35000:35000
+
+CHANNEL TRANSACTIONS (1)
+CHANNEL           KIND                 ORG  PATH
+testorgschannel0  channel creation tx       smoke/channel-artifacts/testorgschannel0.tx
+
+COMMANDS (1)
+cryptogen generate --config configFiles-smoke/crypto-config.yaml
+`
+	assert.Equal(t, expected, out.String())
+}
@@ -0,0 +1,250 @@
+package policy
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+//SyntaxError -- an error in a policy expression, at the 1-based column of the character where it was found
+type SyntaxError struct {
+	Expression string
+	Column     int
+	Message    string
+}
+
+//Error -- the message of the error with the expression and a caret below the column where it was found
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("invalid policy at column %d: %s\n  %s\n  %s^", e.Column, e.Message, e.Expression, strings.Repeat(" ", e.Column-1))
+}
+
+//token kinds of the lexer
+const (
+	tokenEnd = iota
+	tokenName
+	tokenString
+	tokenOpen
+	tokenClose
+	tokenComma
+)
+
+type token struct {
+	kind  int
+	text  string
+	start int
+}
+
+//describe -- the token as it is named in syntax errors
+func (t token) describe() string {
+	switch t.kind {
+	case tokenEnd:
+		return "end of policy"
+	case tokenString:
+		return fmt.Sprintf("principal '%s'", t.text)
+	}
+	return fmt.Sprintf("'%s'", t.text)
+}
+
+type parser struct {
+	expression string
+	tokens     []token
+	next       int
+}
+
+//isNameChar -- whether c can be part of an unquoted name: a gate, a number or an organization
+func isNameChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-' || c == '.'
+}
+
+func (p *parser) errorAt(offset int, format string, args ...interface{}) error {
+	return &SyntaxError{Expression: p.expression, Column: offset + 1, Message: fmt.Sprintf(format, args...)}
+}
+
+//lex -- splits the expression into names, quoted principals, parentheses and commas
+func (p *parser) lex() error {
+
+	expression := p.expression
+	for i := 0; i < len(expression); {
+		c := expression[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(':
+			p.tokens = append(p.tokens, token{kind: tokenOpen, text: "(", start: i})
+			i++
+		case c == ')':
+			p.tokens = append(p.tokens, token{kind: tokenClose, text: ")", start: i})
+			i++
+		case c == ',':
+			p.tokens = append(p.tokens, token{kind: tokenComma, text: ",", start: i})
+			i++
+		case c == '\'' || c == '"':
+			end := strings.IndexByte(expression[i+1:], c)
+			if end < 0 {
+				return p.errorAt(i, "unterminated principal, missing closing %c", c)
+			}
+			p.tokens = append(p.tokens, token{kind: tokenString, text: expression[i+1 : i+1+end], start: i})
+			i += end + 2
+		case isNameChar(c):
+			start := i
+			for i < len(expression) && isNameChar(expression[i]) {
+				i++
+			}
+			p.tokens = append(p.tokens, token{kind: tokenName, text: expression[start:i], start: start})
+		default:
+			return p.errorAt(i, "unexpected character %q", c)
+		}
+	}
+	p.tokens = append(p.tokens, token{kind: tokenEnd, start: len(expression)})
+	return nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.next]
+}
+
+func (p *parser) take() token {
+	t := p.tokens[p.next]
+	if t.kind != tokenEnd {
+		p.next++
+	}
+	return t
+}
+
+func (p *parser) expect(kind int, what string) (token, error) {
+	t := p.take()
+	if t.kind != kind {
+		return t, p.errorAt(t.start, "expected %s, found %s", what, t.describe())
+	}
+	return t, nil
+}
+
+//Parse -- parses a signature policy expression. It takes the Fabric policy DSL, AND(...), OR(...) and OutOf(N, ...)
+//of quoted principals '<org>.<role>' with the role member, admin, client, peer or orderer, the NofX(...) short form
+//of the test inputs for OutOf(N, ...), and unquoted principals <org> or <org>.<role>, members of org when no role is
+//given. Gates are not case sensitive and may be nested, and org is an organization name or an MSP ID. A single
+//principal is taken as the OR of it
+func Parse(expression string) (Policy, error) {
+
+	p := &parser{expression: expression}
+	err := p.lex()
+	if err != nil {
+		return Policy{}, err
+	}
+	if p.peek().kind == tokenEnd {
+		return Policy{}, p.errorAt(0, "empty policy")
+	}
+	policy, err := p.parsePolicy()
+	if err != nil {
+		return Policy{}, err
+	}
+	if t := p.peek(); t.kind != tokenEnd {
+		return Policy{}, p.errorAt(t.start, "unexpected %s after the end of the policy", t.describe())
+	}
+	if policy.Principal != nil {
+		policy = Policy{N: 1, Rules: []Policy{policy}}
+	}
+	return policy, nil
+}
+
+//parsePolicy -- parses a gate or a principal
+func (p *parser) parsePolicy() (Policy, error) {
+
+	t := p.take()
+	switch t.kind {
+	case tokenString:
+		principal, err := parsePrincipal(t.text, true)
+		if err != nil {
+			return Policy{}, p.errorAt(t.start+1, "%s", err)
+		}
+		return Policy{Principal: &principal}, nil
+	case tokenName:
+		if p.peek().kind == tokenOpen {
+			return p.parseGate(t)
+		}
+		principal, err := parsePrincipal(t.text, false)
+		if err != nil {
+			return Policy{}, p.errorAt(t.start, "%s", err)
+		}
+		return Policy{Principal: &principal}, nil
+	}
+	return Policy{}, p.errorAt(t.start, "expected a gate or a principal, found %s", t.describe())
+}
+
+//parseGate -- parses the sub-policies of an AND, OR, OutOf or NofX gate
+func (p *parser) parseGate(gate token) (Policy, error) {
+
+	var n int
+	var err error
+	name := strings.ToLower(gate.text)
+	switch {
+	case name == "and" || name == "or":
+	case name == "outof":
+	case strings.HasSuffix(name, "of") && len(name) > 2:
+		n, err = strconv.Atoi(name[:len(name)-2])
+		if err != nil || n < 1 {
+			return Policy{}, p.errorAt(gate.start, "expected a number of signatures before 'of', found '%s'", gate.text[:len(name)-2])
+		}
+	default:
+		return Policy{}, p.errorAt(gate.start, "unknown gate '%s', expected AND, OR, OutOf or NofX", gate.text)
+	}
+	p.take()
+	if name == "outof" {
+		t, err := p.expect(tokenName, "the number of signatures of OutOf")
+		if err != nil {
+			return Policy{}, err
+		}
+		n, err = strconv.Atoi(t.text)
+		if err != nil || n < 1 {
+			return Policy{}, p.errorAt(t.start, "expected a positive number of signatures, found '%s'", t.text)
+		}
+		_, err = p.expect(tokenComma, "',' after the number of signatures")
+		if err != nil {
+			return Policy{}, err
+		}
+	}
+	var rules []Policy
+	for {
+		rule, err := p.parsePolicy()
+		if err != nil {
+			return Policy{}, err
+		}
+		rules = append(rules, rule)
+		t := p.take()
+		if t.kind == tokenClose {
+			break
+		}
+		if t.kind != tokenComma {
+			return Policy{}, p.errorAt(t.start, "expected ',' or ')', found %s", t.describe())
+		}
+	}
+	switch name {
+	case "and":
+		n = len(rules)
+	case "or":
+		n = 1
+	}
+	if n > len(rules) {
+		return Policy{}, p.errorAt(gate.start, "%s requires %d signatures of only %d sub-policies", gate.text, n, len(rules))
+	}
+	return Policy{N: n, Rules: rules}, nil
+}
+
+//parsePrincipal -- parses <org>.<role>. The role of quoted principals is required, as in the Fabric policy DSL
+func parsePrincipal(text string, quoted bool) (Principal, error) {
+
+	dot := strings.LastIndexByte(text, '.')
+	if dot >= 0 && roles[text[dot+1:]] {
+		if dot == 0 {
+			return Principal{}, fmt.Errorf("principal '%s' has no organization", text)
+		}
+		return Principal{Org: text[:dot], Role: text[dot+1:]}, nil
+	}
+	if quoted {
+		if dot < 0 {
+			return Principal{}, fmt.Errorf("principal '%s' has no role, expected '<org>.<role>'", text)
+		}
+		return Principal{}, fmt.Errorf("unknown role '%s' of principal '%s', expected member, admin, client, peer or orderer", text[dot+1:], text)
+	}
+	return Principal{Org: text, Role: "member"}, nil
+}
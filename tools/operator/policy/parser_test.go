@@ -0,0 +1,127 @@
+package policy
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-protos-go/msp"
+	"github.com/hyperledger/fabric/common/policydsl"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var mspIDs = map[string]string{"org1": "Org1MSP", "org2": "Org2MSP", "org3": "Org3MSP"}
+
+func resolve(org string) (string, error) {
+	if mspID, ok := mspIDs[org]; ok {
+		return mspID, nil
+	}
+	if org == "unknown" {
+		return "", errors.New("no connection profile of organization unknown")
+	}
+	return org, nil
+}
+
+//describe -- a rule of an envelope with its identities in place of their indexes, as fabric numbers the identities in
+//the order it evaluates the policy rather than in the order they appear
+func describe(t *testing.T, envelope *common.SignaturePolicyEnvelope, rule *common.SignaturePolicy) string {
+
+	if nOutOf := rule.GetNOutOf(); nOutOf != nil {
+		var rules []string
+		for _, rule := range nOutOf.Rules {
+			rules = append(rules, describe(t, envelope, rule))
+		}
+		return fmt.Sprintf("%d-of(%s)", nOutOf.N, strings.Join(rules, ","))
+	}
+	require.Equal(t, msp.MSPPrincipal_ROLE, envelope.Identities[rule.GetSignedBy()].PrincipalClassification)
+	role := &msp.MSPRole{}
+	require.NoError(t, proto.Unmarshal(envelope.Identities[rule.GetSignedBy()].Principal, role))
+	return fmt.Sprintf("%s.%s", role.MspIdentifier, role.Role)
+}
+
+func TestParse(t *testing.T) {
+
+	tests := []struct {
+		name       string
+		expression string
+		expected   string
+	}{
+		{"short form", "1of(org1)", "OR('Org1MSP.member')"},
+		{"short form of many", "2of(org1,org2, org3)", "OutOf(2, 'Org1MSP.member', 'Org2MSP.member', 'Org3MSP.member')"},
+		{"short form of more than 9", "10of(o1,o2,o3,o4,o5,o6,o7,o8,o9,o10,o11)", "OutOf(10, 'o1.member', 'o2.member', 'o3.member', 'o4.member', 'o5.member', 'o6.member', 'o7.member', 'o8.member', 'o9.member', 'o10.member', 'o11.member')"},
+		{"all of short form", "2of(org1,org2)", "AND('Org1MSP.member', 'Org2MSP.member')"},
+		{"principal", "'org1.peer'", "OR('Org1MSP.peer')"},
+		{"nested gates", "AND('org1.member', OR('org2.peer','org3.admin'))", "AND('Org1MSP.member', OR('Org2MSP.peer', 'Org3MSP.admin'))"},
+		{"nested outof", "OutOf(2, 'org1.member', OutOf(1, 'org2.client', 'org3.orderer'), 'org3.peer')", "OutOf(2, 'Org1MSP.member', OR('Org2MSP.client', 'Org3MSP.orderer'), 'Org3MSP.peer')"},
+		{"gates not case sensitive", "and(\"org1.admin\", or(org2, org3.peer))", "AND('Org1MSP.admin', OR('Org2MSP.member', 'Org3MSP.peer'))"},
+		{"msp ids", "OR('Org4MSP.member', 'org.example.com.admin')", "OR('Org4MSP.member', 'org.example.com.admin')"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			parsed, err := Parse(test.expression)
+			require.NoError(t, err)
+			resolved, err := parsed.Resolve(resolve)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, resolved.String())
+
+			envelope, err := resolved.Envelope()
+			require.NoError(t, err)
+			expected, err := policydsl.FromString(test.expected)
+			require.NoError(t, err)
+			assert.Equal(t, describe(t, expected, expected.Rule), describe(t, envelope, envelope.Rule))
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+
+	tests := []struct {
+		name       string
+		expression string
+		column     int
+		message    string
+	}{
+		{"empty", "  ", 1, "empty policy"},
+		{"missing comma", "AND('org1.member' 'org2.member')", 19, "expected ',' or ')', found principal 'org2.member'"},
+		{"missing parenthesis", "OR('org1.member', 'org2.member'", 32, "expected ',' or ')', found end of policy"},
+		{"trailing text", "OR('org1.member'))", 18, "unexpected ')' after the end of the policy"},
+		{"unterminated principal", "OR('org1.member)", 4, "unterminated principal, missing closing '"},
+		{"unknown role", "OR('org1.member', 'org2.owner')", 20, "unknown role 'owner' of principal 'org2.owner', expected member, admin, client, peer or orderer"},
+		{"quoted principal without role", "AND('org1')", 6, "principal 'org1' has no role, expected '<org>.<role>'"},
+		{"unknown gate", "ANY('org1.member')", 1, "unknown gate 'ANY', expected AND, OR, OutOf or NofX"},
+		{"bad short form", "twoof(org1,org2)", 1, "expected a number of signatures before 'of', found 'two'"},
+		{"outof without number", "OutOf('org1.member')", 7, "expected the number of signatures of OutOf, found principal 'org1.member'"},
+		{"outof of too many", "OutOf(3, 'org1.member', 'org2.member')", 1, "OutOf requires 3 signatures of only 2 sub-policies"},
+		{"short form of too many", "3of(org1,org2)", 1, "3of requires 3 signatures of only 2 sub-policies"},
+		{"no sub-policies", "OR()", 4, "expected a gate or a principal, found ')'"},
+		{"unexpected character", "OR('org1.member'; 'org2.member')", 17, "unexpected character ';'"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Parse(test.expression)
+			require.Error(t, err)
+			syntaxError, ok := err.(*SyntaxError)
+			require.True(t, ok, "error %s is not a syntax error", err)
+			assert.Equal(t, test.column, syntaxError.Column)
+			assert.Equal(t, test.message, syntaxError.Message)
+		})
+	}
+}
+
+func TestSyntaxError(t *testing.T) {
+
+	_, err := Parse("AND('org1.member' 'org2.member')")
+	assert.EqualError(t, err, "invalid policy at column 19: expected ',' or ')', found principal 'org2.member'\n  AND('org1.member' 'org2.member')\n                    ^")
+}
+
+func TestResolveError(t *testing.T) {
+
+	parsed, err := Parse("AND(org1, unknown)")
+	require.NoError(t, err)
+	_, err = parsed.Resolve(resolve)
+	assert.EqualError(t, err, "no connection profile of organization unknown")
+}
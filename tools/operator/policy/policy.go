@@ -0,0 +1,143 @@
+package policy
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-protos-go/msp"
+	"github.com/pkg/errors"
+)
+
+//roles -- the roles of principals, and their MSP roles
+var roles = map[string]bool{"member": true, "admin": true, "client": true, "peer": true, "orderer": true}
+
+var mspRoles = map[string]msp.MSPRole_MSPRoleType{
+	"member":  msp.MSPRole_MEMBER,
+	"admin":   msp.MSPRole_ADMIN,
+	"client":  msp.MSPRole_CLIENT,
+	"peer":    msp.MSPRole_PEER,
+	"orderer": msp.MSPRole_ORDERER,
+}
+
+//Principal -- an identity that can sign: the members, admins, clients, peers or orderers of an organization
+type Principal struct {
+	Org  string
+	Role string
+}
+
+//Policy -- a signature policy: either a principal that must sign, or N of its rules that must be satisfied
+type Policy struct {
+	Principal *Principal
+	N         int
+	Rules     []Policy
+}
+
+//Resolve -- the policy with the organization of every principal replaced by its MSP ID
+func (p Policy) Resolve(mspID func(org string) (string, error)) (Policy, error) {
+
+	if p.Principal != nil {
+		id, err := mspID(p.Principal.Org)
+		if err != nil {
+			return p, err
+		}
+		return Policy{Principal: &Principal{Org: id, Role: p.Principal.Role}}, nil
+	}
+	resolved := Policy{N: p.N}
+	for _, rule := range p.Rules {
+		rule, err := rule.Resolve(mspID)
+		if err != nil {
+			return p, err
+		}
+		resolved.Rules = append(resolved.Rules, rule)
+	}
+	return resolved, nil
+}
+
+//String -- the policy in the Fabric policy DSL, as taken by the --signature-policy flag of the peer cli
+func (p Policy) String() string {
+
+	if p.Principal != nil {
+		return fmt.Sprintf("'%s.%s'", p.Principal.Org, p.Principal.Role)
+	}
+	var rules []string
+	for _, rule := range p.Rules {
+		rules = append(rules, rule.String())
+	}
+	switch {
+	case p.N == len(p.Rules) && p.N > 1:
+		return fmt.Sprintf("AND(%s)", strings.Join(rules, ", "))
+	case p.N == 1:
+		return fmt.Sprintf("OR(%s)", strings.Join(rules, ", "))
+	}
+	return fmt.Sprintf("OutOf(%d, %s)", p.N, strings.Join(rules, ", "))
+}
+
+//Identities -- the distinct principals of the policy, in the order they first appear
+func (p Policy) Identities() []Principal {
+
+	var identities []Principal
+	p.walk(func(principal Principal) {
+		for _, identity := range identities {
+			if identity == principal {
+				return
+			}
+		}
+		identities = append(identities, principal)
+	})
+	return identities
+}
+
+func (p Policy) walk(visit func(Principal)) {
+
+	if p.Principal != nil {
+		visit(*p.Principal)
+		return
+	}
+	for _, rule := range p.Rules {
+		rule.walk(visit)
+	}
+}
+
+//SignedBy -- the index of a principal in the identities of the policy
+func SignedBy(identities []Principal, principal Principal) int {
+
+	for i, identity := range identities {
+		if identity == principal {
+			return i
+		}
+	}
+	return -1
+}
+
+//Envelope -- the policy as the SignaturePolicyEnvelope of a chaincode definition. Its principals must be resolved to
+//MSP IDs
+func (p Policy) Envelope() (*common.SignaturePolicyEnvelope, error) {
+
+	identities := p.Identities()
+	envelope := &common.SignaturePolicyEnvelope{Version: 0, Rule: p.signaturePolicy(identities)}
+	for _, identity := range identities {
+		role, err := proto.Marshal(&msp.MSPRole{MspIdentifier: identity.Org, Role: mspRoles[identity.Role]})
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to marshal principal %s.%s", identity.Org, identity.Role)
+		}
+		envelope.Identities = append(envelope.Identities, &msp.MSPPrincipal{
+			PrincipalClassification: msp.MSPPrincipal_ROLE,
+			Principal:               role,
+		})
+	}
+	return envelope, nil
+}
+
+func (p Policy) signaturePolicy(identities []Principal) *common.SignaturePolicy {
+
+	if p.Principal != nil {
+		return &common.SignaturePolicy{Type: &common.SignaturePolicy_SignedBy{SignedBy: int32(SignedBy(identities, *p.Principal))}}
+	}
+	var rules []*common.SignaturePolicy
+	for _, rule := range p.Rules {
+		rules = append(rules, rule.signaturePolicy(identities))
+	}
+	return &common.SignaturePolicy{Type: &common.SignaturePolicy_NOutOf_{NOutOf: &common.SignaturePolicy_NOutOf{N: int32(p.N), Rules: rules}}}
+}
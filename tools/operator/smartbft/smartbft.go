@@ -0,0 +1,89 @@
+//Package smartbft holds the channel config messages of the BFT consensus type of fabric v3, which the vendored
+//fabric-protos-go predates. They mirror orderer/smartbft/configuration.proto and the Orderers and Consenter messages
+//of common/configuration.proto, field numbers included, so that they marshal to the same bytes
+package smartbft
+
+import (
+	"github.com/golang/protobuf/proto"
+)
+
+const (
+	//ConsensusType -- the consensus type of BFT channels
+	ConsensusType = "BFT"
+	//OrderersKey -- the key of the consenter mapping value in the orderer group
+	OrderersKey = "Orderers"
+)
+
+//LeaderRotation -- whether the leader of a BFT cluster rotates
+type LeaderRotation int32
+
+const (
+	//RotationUnspecified -- leaves leader rotation to the default of the orderer
+	RotationUnspecified LeaderRotation = 0
+	//RotationOff -- the leader only changes on a view change
+	RotationOff LeaderRotation = 1
+	//RotationOn -- the leader changes every decisionsPerLeader blocks
+	RotationOn LeaderRotation = 2
+)
+
+//Options -- the SmartBFT options, serialized as the metadata of the consensus type of BFT channels
+type Options struct {
+	RequestBatchMaxCount      uint64         `protobuf:"varint,1,opt,name=request_batch_max_count,json=requestBatchMaxCount,proto3" json:"request_batch_max_count,omitempty"`
+	RequestBatchMaxBytes      uint64         `protobuf:"varint,2,opt,name=request_batch_max_bytes,json=requestBatchMaxBytes,proto3" json:"request_batch_max_bytes,omitempty"`
+	RequestBatchMaxInterval   string         `protobuf:"bytes,3,opt,name=request_batch_max_interval,json=requestBatchMaxInterval,proto3" json:"request_batch_max_interval,omitempty"`
+	IncomingMessageBufferSize uint64         `protobuf:"varint,4,opt,name=incoming_message_buffer_size,json=incomingMessageBufferSize,proto3" json:"incoming_message_buffer_size,omitempty"`
+	RequestPoolSize           uint64         `protobuf:"varint,5,opt,name=request_pool_size,json=requestPoolSize,proto3" json:"request_pool_size,omitempty"`
+	RequestForwardTimeout     string         `protobuf:"bytes,6,opt,name=request_forward_timeout,json=requestForwardTimeout,proto3" json:"request_forward_timeout,omitempty"`
+	RequestComplainTimeout    string         `protobuf:"bytes,7,opt,name=request_complain_timeout,json=requestComplainTimeout,proto3" json:"request_complain_timeout,omitempty"`
+	RequestAutoRemoveTimeout  string         `protobuf:"bytes,8,opt,name=request_auto_remove_timeout,json=requestAutoRemoveTimeout,proto3" json:"request_auto_remove_timeout,omitempty"`
+	ViewChangeResendInterval  string         `protobuf:"bytes,9,opt,name=view_change_resend_interval,json=viewChangeResendInterval,proto3" json:"view_change_resend_interval,omitempty"`
+	ViewChangeTimeout         string         `protobuf:"bytes,10,opt,name=view_change_timeout,json=viewChangeTimeout,proto3" json:"view_change_timeout,omitempty"`
+	LeaderHeartbeatTimeout    string         `protobuf:"bytes,11,opt,name=leader_heartbeat_timeout,json=leaderHeartbeatTimeout,proto3" json:"leader_heartbeat_timeout,omitempty"`
+	LeaderHeartbeatCount      uint64         `protobuf:"varint,12,opt,name=leader_heartbeat_count,json=leaderHeartbeatCount,proto3" json:"leader_heartbeat_count,omitempty"`
+	CollectTimeout            string         `protobuf:"bytes,13,opt,name=collect_timeout,json=collectTimeout,proto3" json:"collect_timeout,omitempty"`
+	SyncOnStart               bool           `protobuf:"varint,14,opt,name=sync_on_start,json=syncOnStart,proto3" json:"sync_on_start,omitempty"`
+	SpeedUpViewChange         bool           `protobuf:"varint,15,opt,name=speed_up_view_change,json=speedUpViewChange,proto3" json:"speed_up_view_change,omitempty"`
+	LeaderRotation            LeaderRotation `protobuf:"varint,16,opt,name=leader_rotation,json=leaderRotation,proto3" json:"leader_rotation,omitempty"`
+	DecisionsPerLeader        uint64         `protobuf:"varint,17,opt,name=decisions_per_leader,json=decisionsPerLeader,proto3" json:"decisions_per_leader,omitempty"`
+	RequestMaxBytes           uint64         `protobuf:"varint,18,opt,name=request_max_bytes,json=requestMaxBytes,proto3" json:"request_max_bytes,omitempty"`
+	RequestPoolSubmitTimeout  string         `protobuf:"bytes,19,opt,name=request_pool_submit_timeout,json=requestPoolSubmitTimeout,proto3" json:"request_pool_submit_timeout,omitempty"`
+}
+
+func (m *Options) Reset()         { *m = Options{} }
+func (m *Options) String() string { return proto.CompactTextString(m) }
+func (*Options) ProtoMessage()    {}
+
+//Consenter -- a consenter of a BFT channel, identified by its id, enrollment certificate and tls certificates
+type Consenter struct {
+	Id            uint32 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Host          string `protobuf:"bytes,2,opt,name=host,proto3" json:"host,omitempty"`
+	Port          uint32 `protobuf:"varint,3,opt,name=port,proto3" json:"port,omitempty"`
+	MspId         string `protobuf:"bytes,4,opt,name=msp_id,json=mspId,proto3" json:"msp_id,omitempty"`
+	Identity      []byte `protobuf:"bytes,5,opt,name=identity,proto3" json:"identity,omitempty"`
+	ClientTlsCert []byte `protobuf:"bytes,6,opt,name=client_tls_cert,json=clientTlsCert,proto3" json:"client_tls_cert,omitempty"`
+	ServerTlsCert []byte `protobuf:"bytes,7,opt,name=server_tls_cert,json=serverTlsCert,proto3" json:"server_tls_cert,omitempty"`
+}
+
+func (m *Consenter) Reset()         { *m = Consenter{} }
+func (m *Consenter) String() string { return proto.CompactTextString(m) }
+func (*Consenter) ProtoMessage()    {}
+
+//Orderers -- the consenter mapping of a BFT channel, the value of OrderersKey in the orderer group
+type Orderers struct {
+	ConsenterMapping []*Consenter `protobuf:"bytes,1,rep,name=consenter_mapping,json=consenterMapping,proto3" json:"consenter_mapping,omitempty"`
+}
+
+func (m *Orderers) Reset()         { *m = Orderers{} }
+func (m *Orderers) String() string { return proto.CompactTextString(m) }
+func (*Orderers) ProtoMessage()    {}
+
+//MaxFaulty -- the number of faulty consenters f a BFT cluster of n consenters tolerates, floor((n-1)/3). Consenters
+//beyond 3f+1 raise the quorum without tolerating more faults
+func MaxFaulty(consenters int) int {
+	return (consenters - 1) / 3
+}
+
+//ValidClusterSize -- whether a BFT cluster of n consenters tolerates at least one faulty consenter, i.e. n >= 4
+func ValidClusterSize(consenters int) bool {
+	return MaxFaulty(consenters) >= 1
+}
@@ -0,0 +1,45 @@
+package smartbft
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshal(t *testing.T) {
+
+	consenter := &Consenter{Id: 1, Host: "orderer0-ordererorg1", Port: 30000, MspId: "OrdererOrgExampleCom"}
+	raw, err := proto.Marshal(consenter)
+	require.NoError(t, err)
+	expected := append([]byte{0x08, 0x01, 0x12, 0x14}, "orderer0-ordererorg1"...)
+	expected = append(expected, 0x18, 0xb0, 0xea, 0x01, 0x22, 0x14)
+	expected = append(expected, "OrdererOrgExampleCom"...)
+	assert.Equal(t, expected, raw)
+
+	orderers := &Orderers{ConsenterMapping: []*Consenter{consenter, {Id: 2, Host: "orderer1-ordererorg1"}}}
+	raw, err = proto.Marshal(orderers)
+	require.NoError(t, err)
+	decoded := &Orderers{}
+	require.NoError(t, proto.Unmarshal(raw, decoded))
+	assert.True(t, proto.Equal(orderers, decoded))
+
+	options := &Options{RequestBatchMaxCount: 100, RequestBatchMaxInterval: "50ms", SyncOnStart: true, LeaderRotation: RotationOn, RequestPoolSubmitTimeout: "5s"}
+	raw, err = proto.Marshal(options)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0x80, 0x01, 0x02, 0x9a, 0x01, 0x02, '5', 's'}, raw[len(raw)-8:])
+	decodedOptions := &Options{}
+	require.NoError(t, proto.Unmarshal(raw, decodedOptions))
+	assert.True(t, proto.Equal(options, decodedOptions))
+}
+
+func TestValidClusterSize(t *testing.T) {
+
+	for consenters, valid := range map[int]bool{0: false, 1: false, 3: false, 4: true, 5: true, 6: true, 7: true, 10: true} {
+		assert.Equal(t, valid, ValidClusterSize(consenters), "%d consenters", consenters)
+	}
+	for consenters, faulty := range map[int]int{1: 0, 3: 0, 4: 1, 5: 1, 6: 1, 7: 2, 9: 2, 10: 3} {
+		assert.Equal(t, faulty, MaxFaulty(consenters), "%d consenters", consenters)
+	}
+}
@@ -0,0 +1,539 @@
+package templates
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hyperledger/fabric-test/tools/operator/networkspec"
+)
+
+const (
+	caPort             = 32000
+	couchDBPort        = 33000
+	peerPort           = 31000
+	peerHealthPort     = 31100
+	ordererPort        = 30000
+	ordererHealthPort  = 30100
+	ordererAdminPort   = 30200
+	kafkaPort          = 9092
+	zookeeperPort      = 2181
+	zookeeperPortStep  = 1000
+	containerMSPDir    = "/etc/hyperledger/fabric/artifacts/msp"
+	containerCryptoDir = containerMSPDir + "/crypto-config"
+)
+
+//Labels of the containers, by which the docker launcher finds the containers of the network and their ports
+const (
+	NetworkLabel    = "fabric-test.network"
+	NodeLabel       = "fabric-test.node"
+	ListenPortLabel = "fabric-test.port.listen"
+	HealthPortLabel = "fabric-test.port.health"
+	AdminPortLabel  = "fabric-test.port.admin"
+)
+
+//compose -- the services of a docker compose file. External files join the network of the docker-compose.yaml file
+type compose struct {
+	External bool
+	Network  string
+	Services []Service
+}
+
+//Service -- a docker compose service, named after its container. The labels identify the containers of the network and
+//the container ports of their listen, health and admin addresses
+type Service struct {
+	Name          string
+	ContainerName string
+	Image         string
+	Command       string
+	WorkingDir    string
+	Environment   []string
+	Expose        []int
+	Ports         []string
+	Volumes       []string
+	DependsOn     []string
+	Labels        map[string]string
+}
+
+//labels -- the labels of the container of node name, with ports alternating the label and the container port of an
+//address
+func labels(config networkspec.Config, name string, ports ...string) map[string]string {
+	output := map[string]string{NetworkLabel: config.DockerNetwork(), NodeLabel: name}
+	for i := 0; i+1 < len(ports); i += 2 {
+		output[ports[i]] = ports[i+1] + "/tcp"
+	}
+	return output
+}
+
+//ports -- the next free host ports of each kind of node
+type ports struct {
+	ca, couchDB, peer, peerHealth, orderer, ordererHealth, ordererAdmin int
+}
+
+//firstPorts -- the ports of the first node of each kind, moved to the port range of the network
+func firstPorts(config networkspec.Config) *ports {
+	return &ports{
+		ca:            config.Port(caPort),
+		couchDB:       config.Port(couchDBPort),
+		peer:          config.Port(peerPort),
+		peerHealth:    config.Port(peerHealthPort),
+		orderer:       config.Port(ordererPort),
+		ordererHealth: config.Port(ordererHealthPort),
+		ordererAdmin:  config.Port(ordererAdminPort),
+	}
+}
+
+func tlsEnabled(config networkspec.Config) string {
+	if config.TLS == "mutual" {
+		return "true"
+	}
+	return config.TLS
+}
+
+//overrideEnv -- sets the environment variables of overrides on env, named as fabric maps config keys to environment
+//variables: peer.gossip.pvtData.pushAckTimeout of core.yaml is CORE_PEER_GOSSIP_PVTDATA_PUSHACKTIMEOUT
+func overrideEnv(env []string, prefix string, overrides []map[string]interface{}) []string {
+
+	values := make(map[string]string)
+	var names []string
+	var set func(name string, value interface{})
+	set = func(name string, value interface{}) {
+		name = strings.ToUpper(name)
+		switch value := value.(type) {
+		case map[interface{}]interface{}:
+			for key, child := range value {
+				set(fmt.Sprintf("%s_%v", name, key), child)
+			}
+			return
+		case []interface{}:
+			items := make([]string, len(value))
+			for i, item := range value {
+				items[i] = fmt.Sprint(item)
+			}
+			values[name] = fmt.Sprintf("[%s]", strings.Join(items, ", "))
+		default:
+			values[name] = fmt.Sprint(value)
+		}
+		names = append(names, name)
+	}
+	for _, levelOverrides := range overrides {
+		for key, value := range levelOverrides {
+			set(prefix+"_"+strings.ReplaceAll(key, ".", "_"), value)
+		}
+	}
+	output := make([]string, 0, len(env)+len(values))
+	for _, variable := range env {
+		name := strings.SplitN(variable, "=", 2)[0]
+		if _, ok := values[name]; !ok {
+			output = append(output, variable)
+		}
+	}
+	sort.Strings(names)
+	for i, name := range names {
+		if i > 0 && names[i-1] == name {
+			continue
+		}
+		output = append(output, fmt.Sprintf("%s=%s", name, values[name]))
+	}
+	return output
+}
+
+//clientRootCAs -- the ca certificates of the organizations trusted by nodes requiring mutual tls
+func clientRootCAs(config networkspec.Config, peerOrgs []networkspec.PeerOrganizations) []string {
+	var output []string
+	for _, org := range peerOrgs {
+		output = append(output, fmt.Sprintf("%s/peerOrganizations/%s/ca/ca.%s-cert.pem", containerCryptoDir, org.Name, org.Name))
+	}
+	for _, org := range config.OrdererOrganizations {
+		output = append(output, fmt.Sprintf("%s/ordererOrganizations/%s/ca/ca.%s-cert.pem", containerCryptoDir, org.Name, org.Name))
+	}
+	return output
+}
+
+func caService(config networkspec.Config, name, orgType, orgName string, port int) Service {
+	return Service{
+		Name:          name,
+		ContainerName: config.ContainerName(name),
+		Image:         config.Image("ca", orgName, name),
+		Command:       "sh -c 'fabric-ca-server start -b admin:adminpw -d'",
+		Environment: []string{
+			"FABRIC_CA_HOME=/etc/hyperledger/fabric-ca-server",
+			fmt.Sprintf("FABRIC_CA_SERVER_CA_NAME=%s", name),
+			fmt.Sprintf("FABRIC_CA_SERVER_CA_CERTFILE=/etc/hyperledger/fabric-ca-server-config/ca/ca.%s-cert.pem", orgName),
+			"FABRIC_CA_SERVER_CA_KEYFILE=/etc/hyperledger/fabric-ca-server-config/ca/ca-priv_sk",
+			fmt.Sprintf("FABRIC_CA_SERVER_TLS_ENABLED=%s", tlsEnabled(config)),
+			fmt.Sprintf("FABRIC_CA_SERVER_TLS_CERTFILE=/etc/hyperledger/fabric-ca-server-config/tlsca/tlsca.%s-cert.pem", orgName),
+			"FABRIC_CA_SERVER_TLS_KEYFILE=/etc/hyperledger/fabric-ca-server-config/tlsca/tlsca-priv_sk",
+		},
+		Ports:  []string{fmt.Sprintf("%d:7054", port)},
+		Labels: labels(config, name, ListenPortLabel, "7054"),
+		Volumes: []string{
+			fmt.Sprintf("%s:/etc/hyperledger/fabric-ca-server-config/ca", artifactsPath(config, fmt.Sprintf("crypto-config/%sOrganizations/%s/ca/", orgType, orgName))),
+			fmt.Sprintf("%s:/etc/hyperledger/fabric-ca-server-config/tlsca", artifactsPath(config, fmt.Sprintf("crypto-config/%sOrganizations/%s/tlsca/", orgType, orgName))),
+		},
+	}
+}
+
+func couchDBService(config networkspec.Config, peerName string, port int) Service {
+	name := fmt.Sprintf("couchdb-%s", peerName)
+	return Service{
+		Name:          name,
+		ContainerName: config.ContainerName(name),
+		Image:         "couchdb:3.3.2",
+		Environment:   []string{"COUCHDB_USER=admin", "COUCHDB_PASSWORD=adminpw"},
+		Ports:         []string{fmt.Sprintf("%d:5984", port)},
+		Labels:        labels(config, name, ListenPortLabel, "5984"),
+	}
+}
+
+func peerService(config networkspec.Config, org networkspec.PeerOrganizations, index int, next *ports, rootCAs []string) Service {
+
+	name := fmt.Sprintf("peer%d-%s", index, org.Name)
+	peerDir := fmt.Sprintf("%s/peerOrganizations/%s/peers/%s.%s", containerCryptoDir, org.Name, name, org.Name)
+	env := []string{
+		"CORE_VM_ENDPOINT=unix:///host/var/run/docker.sock",
+		fmt.Sprintf("FABRIC_LOGGING_SPEC=%s", config.PeerFabricLoggingSpec),
+		fmt.Sprintf("CORE_VM_DOCKER_HOSTCONFIG_NETWORKMODE=%s", config.DockerNetwork()),
+		"CORE_LEDGER_STATE_COUCHDBCONFIG_USERNAME=admin",
+		"CORE_LEDGER_STATE_COUCHDBCONFIG_PASSWORD=adminpw",
+	}
+	if config.NetworkName != "" {
+		env = append(env, fmt.Sprintf("CORE_PEER_NETWORKID=%s", config.ChaincodeNetworkID()))
+	}
+	if config.GossipEnable {
+		env = append(env, "CORE_PEER_GOSSIP_STATE_ENABLED=true", "CORE_PEER_GOSSIP_ORGLEADER=false", "CORE_PEER_GOSSIP_USELEADERELECTION=true")
+	} else {
+		env = append(env, "CORE_PEER_GOSSIP_STATE_ENABLED=false", "CORE_PEER_GOSSIP_ORGLEADER=true", "CORE_PEER_GOSSIP_USELEADERELECTION=false")
+	}
+	env = append(env,
+		fmt.Sprintf("CORE_PEER_GOSSIP_BOOTSTRAP=127.0.0.1:%d", next.peer),
+		fmt.Sprintf("CORE_PEER_GOSSIP_ENDPOINT=%s:%d", name, next.peer),
+		fmt.Sprintf("CORE_PEER_LISTENADDRESS=0.0.0.0:%d", next.peer),
+		"CORE_PEER_CHAINCODELISTENADDRESS=0.0.0.0:7052",
+		"CORE_CHAINCODE_EXECUTETIMEOUT=1500s",
+		fmt.Sprintf("CORE_PEER_ID=%s", name),
+		fmt.Sprintf("CORE_PEER_MSPCONFIGPATH=%s/msp", peerDir),
+		fmt.Sprintf("CORE_PEER_LOCALMSPID=%s", org.MSPID),
+		fmt.Sprintf("CORE_PEER_ADDRESS=%s:%d", name, next.peer),
+		"CORE_OPERATIONS_LISTENADDRESS=0.0.0.0:9443",
+		fmt.Sprintf("CORE_PEER_CHAINCODEADDRESS=%s:7052", name),
+		fmt.Sprintf("CORE_PEER_GOSSIP_EXTERNALENDPOINT=%s:%d", name, next.peer),
+		"CORE_OPERATIONS_TLS_ENABLED=false",
+		"CORE_METRICS_PROVIDER=prometheus",
+	)
+	if config.TLS == "mutual" {
+		env = append(env, fmt.Sprintf("CORE_PEER_TLS_CLIENTROOTCAS_FILES=%s", strings.Join(rootCAs, " ")), "CORE_PEER_TLS_CLIENTAUTHREQUIRED=true")
+	}
+	env = append(env,
+		fmt.Sprintf("CORE_PEER_TLS_ENABLED=%s", tlsEnabled(config)),
+		fmt.Sprintf("CORE_PEER_TLS_CERT_FILE=%s/tls/server.crt", peerDir),
+		fmt.Sprintf("CORE_PEER_TLS_KEY_FILE=%s/tls/server.key", peerDir),
+		fmt.Sprintf("CORE_PEER_TLS_ROOTCERT_FILE=%s/tls/ca.crt", peerDir),
+		fmt.Sprintf("CORE_CHAINCODE_BUILDER=%s", config.Image("ccenv", org.Name, name)),
+		fmt.Sprintf("CORE_CHAINCODE_GOLANG_RUNTIME=%s", config.Image("baseos", org.Name, name)),
+		fmt.Sprintf("CORE_CHAINCODE_JAVA_RUNTIME=%s", config.Image("javaenv", org.Name, name)),
+		fmt.Sprintf("CORE_CHAINCODE_NODE_RUNTIME=%s", config.Image("nodeenv", org.Name, name)),
+	)
+	peer := Service{
+		Name:          name,
+		ContainerName: config.ContainerName(name),
+		Image:         config.Image("peer", org.Name, name),
+		Command:       "peer node start",
+		WorkingDir:    "/opt/gopath/src/github.com/hyperledger/fabric/peer",
+		Ports:         []string{"7051", fmt.Sprintf("%d:%d", next.peer, next.peer), fmt.Sprintf("%d:9443", next.peerHealth)},
+		Volumes: []string{
+			fmt.Sprintf("%s:%s/", artifactsPath(config, ""), containerMSPDir),
+			"/var/run/docker.sock:/host/var/run/docker.sock",
+			fmt.Sprintf("%s:/var/hyperledger/production", artifactsPath(config, "backup/"+name)),
+		},
+		Labels: labels(config, name, ListenPortLabel, fmt.Sprint(next.peer), HealthPortLabel, "9443"),
+	}
+	if config.DBType == "couchdb" {
+		env = append(env, "CORE_LEDGER_STATE_STATEDATABASE=CouchDB", fmt.Sprintf("CORE_LEDGER_STATE_COUCHDBCONFIG_COUCHDBADDRESS=couchdb-%s:5984", name))
+		peer.DependsOn = []string{fmt.Sprintf("couchdb-%s", name)}
+	}
+	peer.Environment = overrideEnv(env, "CORE", config.PeerOverrides(org.Name, name))
+	next.peer++
+	next.peerHealth++
+	return peer
+}
+
+//ChaincodeServerService -- the container of a chaincode server on the docker network of the network, reached by the
+//peers at the name of the server. It publishes no port, as only the peers connect to it
+func ChaincodeServerService(config networkspec.Config, server networkspec.ChaincodeServer) Service {
+
+	service := Service{
+		Name:          server.Name,
+		ContainerName: config.ContainerName(server.Name),
+		Image:         server.Image,
+		Environment:   server.Env,
+		Expose:        []int{server.Port},
+		Labels:        labels(config, server.Name),
+	}
+	if server.TLSDir != "" {
+		service.Volumes = []string{fmt.Sprintf("%s:%s:ro", server.TLSDir, networkspec.ChaincodeServerTLSDir)}
+	}
+	return service
+}
+
+//ordererService -- an orderer of the network, bootstrapped by the given environment variables
+func ordererService(config networkspec.Config, org networkspec.OrdererOrganizations, index int, next *ports, bootstrap []string) Service {
+
+	name := fmt.Sprintf("orderer%d-%s", index, org.Name)
+	ordererDir := fmt.Sprintf("%s/ordererOrganizations/%s/orderers/%s.%s", containerCryptoDir, org.Name, name, org.Name)
+	env := []string{
+		fmt.Sprintf("FABRIC_LOGGING_SPEC=%s", config.OrdererFabricLoggingSpec),
+		"ORDERER_GENERAL_LISTENADDRESS=0.0.0.0",
+		fmt.Sprintf("ORDERER_GENERAL_LISTENPORT=%d", next.orderer),
+	}
+	env = append(env, bootstrap...)
+	env = append(env,
+		fmt.Sprintf("ORDERER_GENERAL_LOCALMSPID=%s", org.MSPID),
+		fmt.Sprintf("ORDERER_GENERAL_LOCALMSPDIR=%s/msp", ordererDir),
+	)
+	if config.TLS == "mutual" {
+		env = append(env, fmt.Sprintf("ORDERER_GENERAL_TLS_CLIENTROOTCAS=[%s]", strings.Join(clientRootCAs(config, config.PeerOrganizations), ", ")), "ORDERER_GENERAL_TLS_CLIENTAUTHREQUIRED=true")
+	}
+	env = append(env,
+		fmt.Sprintf("ORDERER_GENERAL_TLS_ENABLED=%s", tlsEnabled(config)),
+		"ORDERER_OPERATIONS_TLS_ENABLED=false",
+		"ORDERER_METRICS_PROVIDER=prometheus",
+		"ORDERER_OPERATIONS_LISTENADDRESS=0.0.0.0:8443",
+		fmt.Sprintf("ORDERER_GENERAL_TLS_PRIVATEKEY=%s/tls/server.key", ordererDir),
+		fmt.Sprintf("ORDERER_GENERAL_TLS_CERTIFICATE=%s/tls/server.crt", ordererDir),
+		fmt.Sprintf("ORDERER_GENERAL_TLS_ROOTCAS=[%s/tls/server.crt]", ordererDir),
+		fmt.Sprintf("ORDERER_GENERAL_CLUSTER_CLIENTPRIVATEKEY=%s/tls/server.key", ordererDir),
+		fmt.Sprintf("ORDERER_GENERAL_CLUSTER_CLIENTCERTIFICATE=%s/tls/server.crt", ordererDir),
+	)
+	if config.Orderer.OrdererType == networkspec.BFT {
+		env = append(env, "ORDERER_GENERAL_CLUSTER_REPLICATIONPOLICY=consensus", "ORDERER_CONSENSUS_WALDIR=/var/hyperledger/production/orderer/smartbft/wal")
+	}
+	env = append(env, "ORDERER_ADMIN_LISTENADDRESS=0.0.0.0:9443")
+	if tls := strings.ToLower(config.TLS); tls == "true" || tls == "mutual" {
+		env = append(env,
+			"ORDERER_ADMIN_TLS_ENABLED=true",
+			"ORDERER_ADMIN_TLS_CLIENTAUTHREQUIRED=true",
+			fmt.Sprintf("ORDERER_ADMIN_TLS_CLIENTROOTCAS=[%s/ordererOrganizations/%s/tlsca/tlsca.%s-cert.pem]", containerCryptoDir, org.Name, org.Name),
+			fmt.Sprintf("ORDERER_ADMIN_TLS_PRIVATEKEY=%s/tls/server.key", ordererDir),
+			fmt.Sprintf("ORDERER_ADMIN_TLS_CERTIFICATE=%s/tls/server.crt", ordererDir),
+		)
+	}
+	orderer := Service{
+		Name:          name,
+		ContainerName: config.ContainerName(name),
+		Image:         config.Image("orderer", org.Name, name),
+		Command:       "orderer",
+		WorkingDir:    "/opt/gopath/src/github.com/hyperledger/fabric",
+		Environment:   overrideEnv(env, "ORDERER", config.OrdererOverrides(org.Name, name)),
+		Ports:         []string{fmt.Sprintf("%d:%d", next.orderer, next.orderer), fmt.Sprintf("%d:8443", next.ordererHealth), fmt.Sprintf("%d:9443", next.ordererAdmin)},
+		Volumes: []string{
+			fmt.Sprintf("%s:%s/", artifactsPath(config, ""), containerMSPDir),
+			fmt.Sprintf("%s:/var/hyperledger/production/orderer", artifactsPath(config, "backup/"+name)),
+		},
+		Labels: labels(config, name, ListenPortLabel, fmt.Sprint(next.orderer), HealthPortLabel, "8443", AdminPortLabel, "9443"),
+	}
+	next.orderer++
+	next.ordererHealth++
+	next.ordererAdmin++
+	return orderer
+}
+
+//kafkaServices -- the zookeepers and kafka brokers of kafka orderers, and the names of the brokers
+func kafkaServices(config networkspec.Config) ([]Service, []string) {
+
+	kafka := config.Kafka
+	var services []Service
+	var zookeepers, connect, servers, brokers []string
+	for i := 0; i < kafka.NumZookeepers; i++ {
+		port := zookeeperPort + i*zookeeperPortStep
+		servers = append(servers, fmt.Sprintf("server.%d=zookeeper%d:%d:%d:participant", i+1, i, port+1, port+2))
+	}
+	for i := 0; i < kafka.NumZookeepers; i++ {
+		name := fmt.Sprintf("zookeeper%d", i)
+		port := zookeeperPort + i*zookeeperPortStep
+		zookeepers = append(zookeepers, name)
+		connect = append(connect, fmt.Sprintf("%s:%d", name, port))
+		services = append(services, Service{
+			Name:          name,
+			ContainerName: config.ContainerName(name),
+			Image:         "hyperledger/fabric-zookeeper",
+			Environment:   []string{fmt.Sprintf("ZOO_MY_ID=%d", i+1), fmt.Sprintf("ZOO_PORT=%d", port), fmt.Sprintf("ZOO_SERVERS=%s", strings.Join(servers, " "))},
+			Expose:        []int{port, port + 1, port + 2},
+			Labels:        labels(config, name, ListenPortLabel, fmt.Sprint(port)),
+		})
+	}
+	for i := 0; i < kafka.NumKafka; i++ {
+		name := fmt.Sprintf("kafka%d", i)
+		brokers = append(brokers, name)
+		services = append(services, Service{
+			Name:          name,
+			ContainerName: config.ContainerName(name),
+			Image:         "hyperledger/fabric-kafka",
+			Environment: []string{
+				fmt.Sprintf("KAFKA_BROKER_ID=%d", i),
+				fmt.Sprintf("KAFKA_DEFAULT_REPLICATION_FACTOR=%d", kafka.NumKafkaReplications),
+				"KAFKA_MESSAGE_MAX_BYTES=103809024",
+				"KAFKA_REPLICA_FETCH_MAX_BYTES=103809024",
+				fmt.Sprintf("KAFKA_ZOOKEEPER_CONNECT=%s", strings.Join(connect, ",")),
+				"KAFKA_MIN_INSYNC_REPLICAS=2",
+				"KAFKA_UNCLEAN_LEADER_ELECTION_ENABLE=false",
+			},
+			Ports:     []string{fmt.Sprintf("%d:%d", config.Port(kafkaPort)+i, kafkaPort)},
+			DependsOn: zookeepers,
+			Labels:    labels(config, name, ListenPortLabel, fmt.Sprint(kafkaPort)),
+		})
+	}
+	return services, brokers
+}
+
+func numPeers(orgs []networkspec.PeerOrganizations) int {
+	total := 0
+	for _, org := range orgs {
+		total += org.NumPeers
+	}
+	return total
+}
+
+//dockerCompose -- the cas, kafka brokers, couchdbs, peers and orderers of the network
+func dockerCompose(config networkspec.Config) compose {
+
+	output := compose{Network: config.DockerNetwork()}
+	next := firstPorts(config)
+	for _, org := range config.PeerOrganizations {
+		for j := 0; j < org.NumCA; j++ {
+			output.Services = append(output.Services, caService(config, fmt.Sprintf("ca%d-%s", j, org.Name), "peer", org.Name, next.ca))
+			next.ca++
+		}
+	}
+	for _, org := range config.OrdererOrganizations {
+		for j := 0; j < org.NumCA; j++ {
+			output.Services = append(output.Services, caService(config, fmt.Sprintf("ca%d-%s", j, org.Name), "orderer", org.Name, next.ca))
+			next.ca++
+		}
+	}
+	var brokers []string
+	if config.Orderer.OrdererType == "kafka" {
+		var services []Service
+		services, brokers = kafkaServices(config)
+		output.Services = append(output.Services, services...)
+	}
+	if config.DBType == "couchdb" {
+		for _, org := range config.PeerOrganizations {
+			for j := 0; j < org.NumPeers; j++ {
+				output.Services = append(output.Services, couchDBService(config, fmt.Sprintf("peer%d-%s", j, org.Name), next.couchDB))
+				next.couchDB++
+			}
+		}
+	}
+	rootCAs := clientRootCAs(config, config.PeerOrganizations)
+	for _, org := range config.PeerOrganizations {
+		for j := 0; j < org.NumPeers; j++ {
+			output.Services = append(output.Services, peerService(config, org, j, next, rootCAs))
+		}
+	}
+	bootstrap := []string{"ORDERER_GENERAL_GENESISMETHOD=file", fmt.Sprintf("ORDERER_GENERAL_GENESISFILE=%s/channel-artifacts/genesis.block", containerMSPDir)}
+	if config.Orderer.BootstrapMethod == "none" {
+		bootstrap = []string{"ORDERER_GENERAL_BOOTSTRAPMETHOD=none", "ORDERER_CHANNELPARTICIPATION_ENABLED=true"}
+	}
+	for _, org := range config.OrdererOrganizations {
+		for j := 0; j < org.NumOrderers; j++ {
+			orderer := ordererService(config, org, j, next, bootstrap)
+			orderer.DependsOn = brokers
+			output.Services = append(output.Services, orderer)
+		}
+	}
+	return output
+}
+
+//peerExtend -- the couchdbs and peers of addPeer, on the ports following the ones of the existing peers
+func peerExtend(config networkspec.Config) compose {
+
+	output := compose{External: true, Network: config.DockerNetwork()}
+	existing := numPeers(config.PeerOrganizations)
+	next := firstPorts(config)
+	next.couchDB += existing
+	next.peer += existing
+	next.peerHealth += existing
+	rootCAs := clientRootCAs(config, config.PeerOrganizations)
+	for _, org := range config.PeerOrganizations {
+		for _, added := range config.AddPeersToOrganization {
+			if added.Name != org.Name {
+				continue
+			}
+			for j := org.NumPeers; j < org.NumPeers+added.NumPeers; j++ {
+				if config.DBType == "couchdb" {
+					output.Services = append(output.Services, couchDBService(config, fmt.Sprintf("peer%d-%s", j, org.Name), next.couchDB))
+					next.couchDB++
+				}
+				output.Services = append(output.Services, peerService(config, added, j, next, rootCAs))
+			}
+		}
+	}
+	return output
+}
+
+//orgExtend -- the cas, couchdbs and peers of the organizations of addOrg
+func orgExtend(config networkspec.Config) compose {
+
+	output := compose{External: true, Network: config.DockerNetwork()}
+	existing := numPeers(config.PeerOrganizations) + numPeers(config.AddPeersToOrganization)
+	next := firstPorts(config)
+	next.couchDB += existing
+	next.peer += existing
+	next.peerHealth += existing
+	for _, org := range config.PeerOrganizations {
+		next.ca += org.NumCA
+	}
+	for _, org := range config.OrdererOrganizations {
+		next.ca += org.NumCA
+	}
+	rootCAs := clientRootCAs(config, append(append([]networkspec.PeerOrganizations{}, config.PeerOrganizations...), config.AddOrganizations...))
+	for _, org := range config.AddOrganizations {
+		for j := 0; j < org.NumCA; j++ {
+			output.Services = append(output.Services, caService(config, fmt.Sprintf("ca%d-%s", j, org.Name), "peer", org.Name, next.ca))
+			next.ca++
+		}
+		for j := 0; j < org.NumPeers; j++ {
+			if config.DBType == "couchdb" {
+				output.Services = append(output.Services, couchDBService(config, fmt.Sprintf("peer%d-%s", j, org.Name), next.couchDB))
+				next.couchDB++
+			}
+			output.Services = append(output.Services, peerService(config, org, j, next, rootCAs))
+		}
+	}
+	return output
+}
+
+//ordererExtend -- the orderers of addOrderer, started from the config block of the system channel, or without
+//bootstrap block to be joined to the channels through the channel participation api with bootstrapMethod none
+func ordererExtend(config networkspec.Config) compose {
+
+	output := compose{External: true, Network: config.DockerNetwork()}
+	existing := 0
+	for _, org := range config.OrdererOrganizations {
+		existing += org.NumOrderers
+	}
+	next := firstPorts(config)
+	next.orderer += existing
+	next.ordererHealth += existing
+	next.ordererAdmin += existing
+	for _, added := range config.AddOrderersToOrganization {
+		for _, org := range config.OrdererOrganizations {
+			if added.Name != org.Name {
+				continue
+			}
+			for j := org.NumOrderers; j < org.NumOrderers+added.NumOrderers; j++ {
+				bootstrap := []string{
+					"ORDERER_GENERAL_GENESISMETHOD=file",
+					fmt.Sprintf("ORDERER_GENERAL_GENESISFILE=%s/channel-artifacts/orderer%d-%s.block", containerMSPDir, j, org.Name),
+					"ORDERER_GENERAL_CLUSTER_REPLICATIONBACKGROUNDREFRESHINTERVAL=30s",
+				}
+				if config.Orderer.BootstrapMethod == "none" {
+					bootstrap = []string{"ORDERER_GENERAL_BOOTSTRAPMETHOD=none", "ORDERER_CHANNELPARTICIPATION_ENABLED=true"}
+				}
+				output.Services = append(output.Services, ordererService(config, org, j, next, bootstrap))
+			}
+		}
+	}
+	return output
+}
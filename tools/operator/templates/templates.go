@@ -0,0 +1,154 @@
+//Package templates renders the configuration files of a network from the templates embedded in the operator, so
+//that no template engine has to be installed or downloaded on the machine launching the network
+package templates
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/hyperledger/fabric-test/tools/operator/networkspec"
+	"github.com/pkg/errors"
+)
+
+//go:embed *.tmpl
+var files embed.FS
+
+var parsed = template.Must(template.New("templates").Funcs(template.FuncMap{"quote": strconv.Quote}).ParseFS(files, "*.tmpl"))
+
+const localIP = "127.0.0.1"
+
+//cryptoConfig -- the organizations of a crypto-config file, as read by cryptogen
+type cryptoConfig struct {
+	OrdererOrgs []cryptoOrg
+	PeerOrgs    []cryptoOrg
+}
+
+//cryptoOrg -- an organization of a crypto-config file with the hostnames of its nodes
+type cryptoOrg struct {
+	Name          string
+	EnableNodeOUs bool
+	Users         int
+	Hostnames     []string
+	SANS          []string
+}
+
+//Render -- renders the configuration file named as in paths.ConfigFilePath from the network spec. The output only
+//depends on config, so rendering the same spec twice gives the same bytes
+func Render(configFile string, config networkspec.Config) ([]byte, error) {
+
+	var templateName string
+	var data interface{}
+	switch configFile {
+	case "crypto-config":
+		templateName, data = "crypto-config.yaml.tmpl", cryptoConfigOrgs(config)
+	case "crypto-config-extend":
+		templateName, data = "crypto-config.yaml.tmpl", cryptoConfigExtendOrgs(config)
+	case "crypto-config-addorg":
+		templateName, data = "crypto-config.yaml.tmpl", cryptoConfigAddOrgOrgs(config)
+	case "crypto-config-addorderer":
+		templateName, data = "crypto-config.yaml.tmpl", cryptoConfigAddOrdererOrgs(config)
+	case "docker", "peer-extend", "org-extend", "orderer-extend":
+		templateName, data = "docker-compose.yaml.tmpl", composeFile(configFile, config)
+	default:
+		return nil, errors.Errorf("no template for configuration file %s", configFile)
+	}
+	var output bytes.Buffer
+	err := parsed.ExecuteTemplate(&output, templateName, data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to render %s", configFile)
+	}
+	return output.Bytes(), nil
+}
+
+//composeFile -- the services of the docker compose file named as in paths.ConfigFilePath
+func composeFile(configFile string, config networkspec.Config) compose {
+	switch configFile {
+	case "peer-extend":
+		return peerExtend(config)
+	case "org-extend":
+		return orgExtend(config)
+	case "orderer-extend":
+		return ordererExtend(config)
+	}
+	return dockerCompose(config)
+}
+
+//Services -- the containers of the docker compose file named as in paths.ConfigFilePath, in the order they start in
+func Services(configFile string, config networkspec.Config) ([]Service, error) {
+	switch configFile {
+	case "docker", "peer-extend", "org-extend", "orderer-extend":
+		return composeFile(configFile, config).Services, nil
+	}
+	return nil, errors.Errorf("%s is not a docker compose file", configFile)
+}
+
+func sans(config networkspec.Config) []string {
+	if config.NodeportIP != "" {
+		return []string{localIP, config.NodeportIP}
+	}
+	return []string{localIP}
+}
+
+func newCryptoOrg(config networkspec.Config, name, component string, first, count, users int) cryptoOrg {
+	org := cryptoOrg{Name: name, EnableNodeOUs: config.EnableNodeOUs, Users: users, SANS: sans(config)}
+	for i := first; i < first+count; i++ {
+		org.Hostnames = append(org.Hostnames, fmt.Sprintf("%s%d-%s", component, i, name))
+	}
+	return org
+}
+
+//cryptoConfigOrgs -- all the organizations of the network
+func cryptoConfigOrgs(config networkspec.Config) cryptoConfig {
+	var output cryptoConfig
+	for _, org := range config.OrdererOrganizations {
+		output.OrdererOrgs = append(output.OrdererOrgs, newCryptoOrg(config, org.Name, "orderer", 0, org.NumOrderers, 0))
+	}
+	for _, org := range config.PeerOrganizations {
+		output.PeerOrgs = append(output.PeerOrgs, newCryptoOrg(config, org.Name, "peer", 0, org.NumPeers, 1))
+	}
+	return output
+}
+
+//cryptoConfigExtendOrgs -- the peers of addPeer, numbered after the existing peers of their organization
+func cryptoConfigExtendOrgs(config networkspec.Config) cryptoConfig {
+	var output cryptoConfig
+	for _, org := range config.PeerOrganizations {
+		for _, added := range config.AddPeersToOrganization {
+			if added.Name == org.Name {
+				output.PeerOrgs = append(output.PeerOrgs, newCryptoOrg(config, added.Name, "peer", org.NumPeers, added.NumPeers, 1))
+			}
+		}
+	}
+	return output
+}
+
+//cryptoConfigAddOrgOrgs -- the organizations of addOrg
+func cryptoConfigAddOrgOrgs(config networkspec.Config) cryptoConfig {
+	var output cryptoConfig
+	for _, org := range config.AddOrganizations {
+		output.PeerOrgs = append(output.PeerOrgs, newCryptoOrg(config, org.Name, "peer", 0, org.NumPeers, 1))
+	}
+	return output
+}
+
+//cryptoConfigAddOrdererOrgs -- the orderers of addOrderer, numbered after the existing orderers of their organization
+func cryptoConfigAddOrdererOrgs(config networkspec.Config) cryptoConfig {
+	var output cryptoConfig
+	for _, added := range config.AddOrderersToOrganization {
+		for _, org := range config.OrdererOrganizations {
+			if added.Name == org.Name {
+				output.OrdererOrgs = append(output.OrdererOrgs, newCryptoOrg(config, added.Name, "orderer", org.NumOrderers, added.NumOrderers, 0))
+			}
+		}
+	}
+	return output
+}
+
+//artifactsPath -- the path of a file or directory under artifactsLocation on the docker host
+func artifactsPath(config networkspec.Config, path string) string {
+	return strings.TrimSuffix(config.ArtifactsLocation, "/") + "/" + path
+}
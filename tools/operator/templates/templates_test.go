@@ -0,0 +1,119 @@
+package templates
+
+import (
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/hyperledger/fabric-test/tools/operator/networkspec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	yaml "gopkg.in/yaml.v2"
+)
+
+var update = flag.Bool("update", false, "update the golden files of testdata")
+
+const networkSpec = `
+dockerOrg: hyperledger
+dockerTag: 2.5.0
+dockerImages:
+  ccenv: example/fabric-ccenv:latest
+dbType: couchdb
+peerFabricLoggingSpec: error
+ordererFabricLoggingSpec: info
+artifactsLocation: /tmp/artifacts/
+nodeportIP: 10.0.0.1
+tls: mutual
+gossipEnable: true
+enableNodeOUs: true
+overrides:
+  peer:
+    peer.gossip.pvtData.pushAckTimeout: 5s
+    chaincode.executeTimeout: 300s
+  orderer:
+    General.Cluster.SendBufferSize: 50
+orderer:
+  ordererType: etcdraft
+ordererOrganizations:
+- name: ordererorg1
+  mspId: OrdererOrgExampleCom
+  numOrderers: 3
+  numCa: 1
+peerOrganizations:
+- name: org1
+  mspId: Org1ExampleCom
+  numPeers: 2
+  numCa: 1
+  nodeOverrides:
+    peer1-org1:
+      chaincode.executeTimeout: 600s
+  nodeImages:
+    peer1-org1:
+      dockerImages:
+        peer: example/fabric-peer:2.5.4-debug
+- name: org2
+  mspId: Org2ExampleCom
+  numPeers: 1
+  numCa: 0
+  dockerTag: 2.2.0
+addPeer:
+- name: org1
+  mspId: Org1ExampleCom
+  numPeers: 1
+- name: org2
+  mspId: Org2ExampleCom
+  numPeers: 1
+`
+
+func TestRender(t *testing.T) {
+
+	var config networkspec.Config
+	require.NoError(t, yaml.Unmarshal([]byte(networkSpec), &config))
+	for configFile, golden := range map[string]string{
+		"crypto-config": "crypto-config.yaml",
+		"docker":        "docker-compose.yaml",
+		"peer-extend":   "peer-extend.yaml",
+	} {
+		output, err := Render(configFile, config)
+		require.NoError(t, err)
+		again, err := Render(configFile, config)
+		require.NoError(t, err)
+		assert.Equal(t, output, again, "%s should render the same bytes every time", configFile)
+
+		var parsed interface{}
+		assert.NoError(t, yaml.Unmarshal(output, &parsed), "%s should be valid yaml", configFile)
+
+		goldenPath := filepath.Join("testdata", golden)
+		if *update {
+			require.NoError(t, ioutil.WriteFile(goldenPath, output, 0644))
+		}
+		expected, err := ioutil.ReadFile(goldenPath)
+		require.NoError(t, err)
+		assert.Equal(t, string(expected), string(output), "%s differs from %s", configFile, goldenPath)
+	}
+
+	_, err := Render("configtx", config)
+	assert.EqualError(t, err, "no template for configuration file configtx")
+}
+
+func TestOrdererExtend(t *testing.T) {
+
+	var config networkspec.Config
+	require.NoError(t, yaml.Unmarshal([]byte(networkSpec), &config))
+	config.AddOrderersToOrganization = []networkspec.OrdererOrganizations{{Name: "ordererorg1", NumOrderers: 1}}
+
+	services := ordererExtend(config).Services
+	require.Len(t, services, 1)
+	assert.Equal(t, "orderer3-ordererorg1", services[0].ContainerName)
+	assert.Contains(t, services[0].Environment, "ORDERER_GENERAL_GENESISFILE=/etc/hyperledger/fabric/artifacts/msp/channel-artifacts/orderer3-ordererorg1.block")
+
+	config.Orderer.BootstrapMethod = "none"
+	services = ordererExtend(config).Services
+	require.Len(t, services, 1)
+	assert.Contains(t, services[0].Environment, "ORDERER_GENERAL_BOOTSTRAPMETHOD=none")
+	assert.Contains(t, services[0].Environment, "ORDERER_CHANNELPARTICIPATION_ENABLED=true")
+	for _, variable := range services[0].Environment {
+		assert.NotContains(t, variable, "ORDERER_GENERAL_GENESIS")
+	}
+}
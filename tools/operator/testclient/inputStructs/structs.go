@@ -61,6 +61,12 @@ type InstallCC struct {
 	Language         string `yaml:"language,omitempty"`
 	MetadataPath     string `yaml:"metadataPath,omitempty"`
 	TargetPeers      string `yaml:"targetPeers,omitempty"`
+	//Type ccaas installs the chaincode as a service: Image runs its server on Port, 9999 by default, with TLS
+	//unless TLSDisabled
+	Type        string `yaml:"type,omitempty"`
+	Image       string `yaml:"image,omitempty"`
+	Port        int    `yaml:"port,omitempty"`
+	TLSDisabled bool   `yaml:"tlsDisabled,omitempty"`
 }
 
 //InstantiateCC --
@@ -79,6 +85,19 @@ type InstantiateCC struct {
 	TimeOutOpt        TimeOutOptions `yaml:"timeoutOpt,omitempty"`
 	Sequence          string         `yaml:"sequence,omitempty"`
 	TargetPeers       string         `yaml:"targetPeers,omitempty"`
+	Approvals         []Approval     `yaml:"approvals,omitempty"`
+	ExpectCommit      string         `yaml:"expectCommit,omitempty"`
+}
+
+//Approval -- what some organizations approve instead of the chaincode definition to commit, to test divergent
+//approvals. Fields that are not set are those of the definition
+type Approval struct {
+	Organizations     string `yaml:"organizations,omitempty"`
+	ChainCodeVersion  string `yaml:"version,omitempty"`
+	Sequence          string `yaml:"sequence,omitempty"`
+	EndorsementPolicy string `yaml:"endorsementPolicy,omitempty"`
+	CollectionPath    string `yaml:"collectionPath,omitempty"`
+	Skip              bool   `yaml:"skip,omitempty"`
 }
 
 //TimeOutOptions --
@@ -110,6 +129,15 @@ type InvokeQuery struct {
 	PeerOpt          PeerOptions          `yaml:"peerOptions,omitempty"`
 	OrdererOpt       OrdererOptions       `yaml:"ordererOptions,omitempty"`
 	SnapshotOpt      SnapshotOptions      `yaml:"snapshotOptions,omitempty"`
+	SLO              SLO                  `yaml:"slo,omitempty"`
+}
+
+//SLO -- thresholds an invoke/query run has to meet, rates are fractions between 0 and 1 and latencies are in milliseconds
+type SLO struct {
+	MinTPS              float64 `yaml:"minTps,omitempty"`
+	MaxP99CommitLatency float64 `yaml:"maxP99CommitLatency,omitempty"`
+	MaxErrorRate        float64 `yaml:"maxErrorRate,omitempty"`
+	MaxMVCCConflictRate float64 `yaml:"maxMvccConflictRate,omitempty"`
 }
 
 //TransactionOptions --
@@ -0,0 +1,161 @@
+package operations
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/hyperledger/fabric-test/tools/operator/launcher/dockercompose"
+	"github.com/hyperledger/fabric-test/tools/operator/launcher/k8s"
+	"github.com/hyperledger/fabric-test/tools/operator/lifecycle"
+	"github.com/hyperledger/fabric-test/tools/operator/logger"
+	"github.com/hyperledger/fabric-test/tools/operator/networkspec"
+	"github.com/hyperledger/fabric-test/tools/operator/networkstate"
+	"github.com/hyperledger/fabric-test/tools/operator/paths"
+	"github.com/hyperledger/fabric-test/tools/operator/plan"
+	"github.com/hyperledger/fabric-test/tools/operator/testclient/inputStructs"
+	"github.com/pkg/errors"
+)
+
+const defaultChaincodeServerPort = 9999
+
+//ChaincodeService -- how an install of type ccaas runs the server of the chaincode
+type ChaincodeService struct {
+	Image       string
+	Port        int
+	TLSDisabled bool
+}
+
+var invalidServerNameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+//chaincodeService -- the chaincode server of a chaincode object of type ccaas, nil for other chaincode objects
+func chaincodeService(ccObject inputStructs.InstallCC) (*ChaincodeService, error) {
+
+	switch ccObject.Type {
+	case "":
+		return nil, nil
+	case "ccaas":
+	default:
+		return nil, errors.Errorf("invalid type %q of chaincode %s, it can only be ccaas", ccObject.Type, ccObject.ChainCodeName)
+	}
+	if ccObject.SDK != "cli" {
+		return nil, errors.Errorf("chaincode %s of type ccaas needs the cli sdk", ccObject.ChainCodeName)
+	}
+	if ccObject.Image == "" {
+		return nil, errors.Errorf("chaincode %s of type ccaas needs the image of its server", ccObject.ChainCodeName)
+	}
+	service := &ChaincodeService{Image: ccObject.Image, Port: ccObject.Port, TLSDisabled: ccObject.TLSDisabled}
+	if service.Port == 0 {
+		service.Port = defaultChaincodeServerPort
+	}
+	return service, nil
+}
+
+//chaincodeServerName -- the host name of the server of a chaincode version, valid for docker and k8s
+func chaincodeServerName(chaincodeID, version string) string {
+	name := invalidServerNameChars.ReplaceAllString(strings.ToLower(fmt.Sprintf("%s-%s", chaincodeID, version)), "-")
+	return strings.Trim(name, "-")
+}
+
+//chaincodeServerEnv -- the environment of a chaincode server: the address it listens on, the package ID the peers
+//know it by and, with TLS, the files of its ServiceTLS. CORE_CHAINCODE_ID_NAME is the ID for shims reading that one
+func chaincodeServerEnv(service ChaincodeService, packageID string) []string {
+
+	env := []string{
+		fmt.Sprintf("CHAINCODE_SERVER_ADDRESS=0.0.0.0:%d", service.Port),
+		fmt.Sprintf("CHAINCODE_ID=%s", packageID),
+		fmt.Sprintf("CORE_CHAINCODE_ID_NAME=%s", packageID),
+	}
+	if service.TLSDisabled {
+		return append(env, "CHAINCODE_TLS_DISABLED=true")
+	}
+	return append(env,
+		"CHAINCODE_TLS_DISABLED=false",
+		fmt.Sprintf("CHAINCODE_TLS_KEY=%s/server.key", networkspec.ChaincodeServerTLSDir),
+		fmt.Sprintf("CHAINCODE_TLS_CERT=%s/server.crt", networkspec.ChaincodeServerTLSDir),
+		fmt.Sprintf("CHAINCODE_CLIENT_CA_CERT=%s/ca.crt", networkspec.ChaincodeServerTLSDir),
+	)
+}
+
+//servicePackage -- packages a chaincode object of type ccaas and starts its server on the runtime of the network of
+//its connection profile, from the network-state.json of the network. The TLS of the server is kept in the artifacts of
+//the network, so that the package and its ID stay the same when the chaincode is installed again
+func servicePackage(installObject InstallCCUIObject, connProfilePath string) (lifecycle.Package, error) {
+
+	statePath, ok := networkstate.Locate(connProfilePath)
+	if !ok {
+		return lifecycle.Package{}, errors.Errorf("chaincode %s of type ccaas needs the network-state.json of the network of %s", installObject.ChainCodeID, connProfilePath)
+	}
+	state, err := networkstate.Read(statePath)
+	if err != nil {
+		return lifecycle.Package{}, err
+	}
+	service := *installObject.Service
+	server := networkspec.ChaincodeServer{
+		Name:  chaincodeServerName(installObject.ChainCodeID, installObject.ChainCodeVer),
+		Image: service.Image,
+		Port:  service.Port,
+	}
+	address := fmt.Sprintf("%s:%d", server.Name, server.Port)
+	connection := lifecycle.Connection{Address: address, DialTimeout: "10s"}
+	if !service.TLSDisabled {
+		server.TLSDir, err = filepath.Abs(paths.JoinPath(state.Spec.ArtifactsLocation, fmt.Sprintf("chaincodes/%s", server.Name)))
+		if err != nil {
+			return lifecycle.Package{}, err
+		}
+		serviceTLS, err := lifecycle.LoadServiceTLS(server.TLSDir, server.Name)
+		if err != nil {
+			return lifecycle.Package{}, err
+		}
+		connection = serviceTLS.Connection(address)
+	}
+	pkg, err := lifecycle.NewServicePackage(ccLabel(installObject.ChainCodeID, installObject.ChainCodeVer), connection)
+	if err != nil {
+		return pkg, err
+	}
+	server.Env = chaincodeServerEnv(service, pkg.ID())
+	logger.INFO(fmt.Sprintf("Starting chaincode server %s of package %s on %s", address, pkg.ID(), state.Runtime))
+	switch state.Runtime {
+	case "docker":
+		err = dockercompose.StartChaincodeServer(state.Spec.Config, server)
+	case "k8s":
+		err = k8s.K8s{KubeConfigPath: os.Getenv("KUBECONFIG"), Config: state.Spec.Config}.StartChaincodeServer(server)
+	default:
+		err = errors.Errorf("chaincode as a service runs on docker and k8s networks, not on %s", state.Runtime)
+	}
+	if err != nil {
+		return pkg, errors.Wrapf(err, "failed to start the server of chaincode %s", installObject.ChainCodeID)
+	}
+	return pkg, nil
+}
+
+//planChaincodeServer -- records in p the container or the k8s objects that would run the server of a chaincode object
+//of type ccaas, when the network of its connection profile has a network-state.json to tell its runtime
+func planChaincodeServer(installObject InstallCCUIObject, connProfilePath string, p *plan.Plan) error {
+
+	statePath, ok := networkstate.Locate(connProfilePath)
+	if !ok {
+		return nil
+	}
+	state, err := networkstate.Read(statePath)
+	if err != nil {
+		return err
+	}
+	name := chaincodeServerName(installObject.ChainCodeID, installObject.ChainCodeVer)
+	switch state.Runtime {
+	case "docker":
+		p.Containers = append(p.Containers, plan.Container{Name: state.Spec.ContainerName(name), Image: installObject.Service.Image})
+	case "k8s":
+		namespace := state.Spec.K8s.Namespace
+		if !installObject.Service.TLSDisabled {
+			p.K8sObjects = append(p.K8sObjects, plan.K8sObject{Kind: "Secret", Namespace: namespace, Name: fmt.Sprintf("%s-tls", name)})
+		}
+		p.K8sObjects = append(p.K8sObjects,
+			plan.K8sObject{Kind: "Deployment", Namespace: namespace, Name: name, Image: installObject.Service.Image},
+			plan.K8sObject{Kind: "Service", Namespace: namespace, Name: name},
+		)
+	}
+	return nil
+}
@@ -1,19 +1,18 @@
 package operations
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"strconv"
 	"strings"
 	"sync"
-	"time"
 
 	"github.com/davecgh/go-spew/spew"
 	"github.com/hyperledger/fabric-test/tools/operator/connectionprofile"
 	"github.com/hyperledger/fabric-test/tools/operator/logger"
 	"github.com/hyperledger/fabric-test/tools/operator/networkclient"
 	"github.com/hyperledger/fabric-test/tools/operator/paths"
+	"github.com/hyperledger/fabric-test/tools/operator/plan"
 	"github.com/hyperledger/fabric-test/tools/operator/testclient/inputStructs"
 )
 
@@ -38,40 +37,70 @@ type ChannelOptions struct {
 func (c ChannelUIObject) ChannelConfigs(config inputStructs.Config, tls, action string) error {
 
 	var err error
-	var channelUIObjects, channelObjects []ChannelUIObject
-	var configObjects []inputStructs.Channel
-	var channelConfigObjects []interface{}
-	switch action {
-	case "create":
-		configObjects = config.CreateChannel
-	case "join":
-		configObjects = config.JoinChannel
-	case "anchorpeer":
-		configObjects = config.AnchorPeerUpdate
-	}
+	configObjects := channelConfigObjects(config, action)
 
 	// print action (in bold) and input
 	fmt.Printf("\033[1m\nAction:%s\nInput:\033[0m\n%s\n", action, spew.Sdump(configObjects))
 
-	for i := 0; i < len(configObjects); i++ {
-		channelObjects = c.generateChannelUIObjects(configObjects[i], config.Organizations, tls, action, config.OrdererSystemChannel)
-		if len(channelObjects) > 0 {
-			channelUIObjects = append(channelUIObjects, channelObjects...)
-		}
-		channelConfigObjects = append(channelConfigObjects, &configObjects[i])
+	var ccConfigObjects []interface{}
+	for i := range configObjects {
+		ccConfigObjects = append(ccConfigObjects, &configObjects[i])
 	}
-	err = c.doChannelAction(channelUIObjects)
+	err = c.doChannelAction(c.channelUIObjects(config, configObjects, tls, action))
 	if err != nil {
 		return err
 	}
 	var connProfileObject connectionprofile.ConnProfile
-	err = connProfileObject.UpdateConnectionProfiles(channelConfigObjects, config.Organizations, action)
+	err = connProfileObject.UpdateConnectionProfiles(ccConfigObjects, config.Organizations, action)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+//PlanChannelConfigs -- records in p the channel transactions of create, join or anchorpeer and the commands that
+//would submit them
+func (c ChannelUIObject) PlanChannelConfigs(config inputStructs.Config, tls, action string, p *plan.Plan) error {
+
+	for i, channelObject := range c.channelUIObjects(config, channelConfigObjects(config, action), tls, action) {
+		tx := plan.ChannelTransaction{Channel: channelObject.ChannelOpt.Name, Kind: channelObject.ChannelOpt.Action, Org: strings.Join(channelObject.ChannelOpt.OrgName, ",")}
+		if channelObject.ChannelOpt.Action != "join" {
+			tx.Path = channelObject.ChannelOpt.ChannelTX
+		}
+		p.ChannelTransactions = append(p.ChannelTransactions, tx)
+		args, err := pteArgs(i, channelObject)
+		if err != nil {
+			return err
+		}
+		p.AddCommand("node", args...)
+	}
+	return nil
+}
+
+//channelConfigObjects -- the channels of the test input for create, join or anchorpeer
+func channelConfigObjects(config inputStructs.Config, action string) []inputStructs.Channel {
+
+	switch action {
+	case "create":
+		return config.CreateChannel
+	case "join":
+		return config.JoinChannel
+	case "anchorpeer":
+		return config.AnchorPeerUpdate
+	}
+	return nil
+}
+
+//channelUIObjects -- the channel user input objects of every channel of create, join or anchorpeer
+func (c ChannelUIObject) channelUIObjects(config inputStructs.Config, configObjects []inputStructs.Channel, tls, action string) []ChannelUIObject {
+
+	var channelUIObjects []ChannelUIObject
+	for i := 0; i < len(configObjects); i++ {
+		channelUIObjects = append(channelUIObjects, c.generateChannelUIObjects(configObjects[i], config.Organizations, tls, action, config.OrdererSystemChannel)...)
+	}
+	return channelUIObjects
+}
+
 //generateChannelUIObjects -- To generate channel user input objects for all the channels
 func (c ChannelUIObject) generateChannelUIObjects(channel inputStructs.Channel, organizations []inputStructs.Organization, tls, action, ordererChannel string) []ChannelUIObject {
 
@@ -155,18 +184,12 @@ func (c ChannelUIObject) channelConfig(action, channelName string, args []string
 //doChannelAction -- To perform channel operations including create, anchorpeer update and join channel
 func (c ChannelUIObject) doChannelAction(channelUIObjects []ChannelUIObject) error {
 
-	var err error
-	var jsonObject []byte
 	var wg sync.WaitGroup
-	pteMainPath := paths.PTEPath()
-	var args []string
 	for i, channelObject := range channelUIObjects {
-		jsonObject, err = json.Marshal(channelObject)
+		args, err := pteArgs(i, channelObject)
 		if err != nil {
 			return err
 		}
-		startTime := time.Now().String()
-		args = []string{pteMainPath, strconv.Itoa(i), string(jsonObject), startTime}
 		wg.Add(1)
 		go c.channelConfig(channelObject.ChannelOpt.Action, channelObject.ChannelOpt.Name, args, &wg)
 	}
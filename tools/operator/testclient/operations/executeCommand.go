@@ -1,7 +1,12 @@
 package operations
 
 import (
+	"encoding/json"
+	"strconv"
+	"time"
+
 	"github.com/hyperledger/fabric-test/tools/operator/networkclient"
+	"github.com/hyperledger/fabric-test/tools/operator/paths"
 	"github.com/hyperledger/fabric-test/tools/operator/testclient/inputStructs"
 )
 
@@ -19,3 +24,15 @@ func DoCommandAction(config inputStructs.Config) error {
 	}
 	return nil
 }
+
+//pteArgs -- the arguments of the node command running the PTE main script for the object of a channel or chaincode
+//operation
+func pteArgs(index int, object interface{}) ([]string, error) {
+
+	jsonObject, err := json.Marshal(object)
+	if err != nil {
+		return nil, err
+	}
+	startTime := time.Now().String()
+	return []string{paths.PTEPath(), strconv.Itoa(index), string(jsonObject), startTime}, nil
+}
@@ -1,19 +1,18 @@
 package operations
 
 import (
-	"encoding/json"
 	"fmt"
-	"net/url"
 	"os"
 	"path/filepath"
-	"strconv"
 	"strings"
-	"time"
+	"sync"
 
 	"github.com/davecgh/go-spew/spew"
+	"github.com/hyperledger/fabric-test/tools/operator/lifecycle"
 	"github.com/hyperledger/fabric-test/tools/operator/logger"
 	"github.com/hyperledger/fabric-test/tools/operator/networkclient"
 	"github.com/hyperledger/fabric-test/tools/operator/paths"
+	"github.com/hyperledger/fabric-test/tools/operator/plan"
 	"github.com/hyperledger/fabric-test/tools/operator/testclient/inputStructs"
 )
 
@@ -28,6 +27,7 @@ type InstallCCUIObject struct {
 	DeployOpt       InstallCCDeployOpt `json:"deploy,omitempty"`
 	ConnProfilePath string             `json:"ConnProfilePath,omitempty"`
 	TargetPeers     []string           `json:"targetPeers,omitempty"`
+	Service         *ChaincodeService  `json:"-"`
 }
 
 //InstallCCDeployOpt --
@@ -45,7 +45,10 @@ func (i InstallCCUIObject) InstallCC(config inputStructs.Config, tls string) err
 
 	var installCCObjects []InstallCCUIObject
 	for index := 0; index < len(config.InstallCC); index++ {
-		ccObjects := i.createInstallCCObjects(config.InstallCC[index], config.Organizations, tls)
+		ccObjects, err := i.createInstallCCObjects(config.InstallCC[index], config.Organizations, tls)
+		if err != nil {
+			return err
+		}
 		installCCObjects = append(installCCObjects, ccObjects...)
 	}
 	err := i.installCC(installCCObjects)
@@ -55,10 +58,63 @@ func (i InstallCCUIObject) InstallCC(config inputStructs.Config, tls string) err
 	return nil
 }
 
+//PlanInstallCC -- records in p the chaincode installs and the commands that would make them. Installs of the cli sdk
+//run in process and have no command; those of type ccaas record the chaincode server they would start
+func (i InstallCCUIObject) PlanInstallCC(config inputStructs.Config, tls string, p *plan.Plan) error {
+
+	for index := 0; index < len(config.InstallCC); index++ {
+		installObjects, err := i.createInstallCCObjects(config.InstallCC[index], config.Organizations, tls)
+		if err != nil {
+			return err
+		}
+		for _, installObject := range installObjects {
+			if installObject.Service != nil {
+				err = planChaincodeServer(installObject, orgConnProfilePath(installObject.ConnProfilePath, strings.TrimSpace(installObject.ChannelOpt.OrgName[0])), p)
+				if err != nil {
+					return err
+				}
+			}
+			p.LifecycleCalls = append(p.LifecycleCalls, plan.LifecycleCall{
+				Call:      "install",
+				SDK:       installObject.SDK,
+				Chaincode: installObject.ChainCodeID,
+				Version:   installObject.ChainCodeVer,
+				Orgs:      installObject.ChannelOpt.OrgName,
+				Peers:     peerNames(installObject.TargetPeers),
+			})
+			if installObject.SDK == "cli" {
+				continue
+			}
+			args, err := pteArgs(index, installObject)
+			if err != nil {
+				return err
+			}
+			p.AddCommand("node", args...)
+		}
+	}
+	return nil
+}
+
+//peerNames -- the target peers of a chaincode object, without the empty name of a test input without targetPeers
+func peerNames(targetPeers []string) []string {
+
+	var output []string
+	for _, peerName := range targetPeers {
+		if strings.TrimSpace(peerName) != "" {
+			output = append(output, strings.TrimSpace(peerName))
+		}
+	}
+	return output
+}
+
 //createInstallCCObjects -- To create chaincode objects for install
-func (i InstallCCUIObject) createInstallCCObjects(ccObject inputStructs.InstallCC, organizations []inputStructs.Organization, tls string) []InstallCCUIObject {
+func (i InstallCCUIObject) createInstallCCObjects(ccObject inputStructs.InstallCC, organizations []inputStructs.Organization, tls string) ([]InstallCCUIObject, error) {
 
 	var installCCObjects []InstallCCUIObject
+	service, err := chaincodeService(ccObject)
+	if err != nil {
+		return nil, err
+	}
 	orgNames := strings.Split(ccObject.Organizations, ",")
 	targetPeers := strings.Split(ccObject.TargetPeers, ",")
 	i = InstallCCUIObject{
@@ -77,9 +133,10 @@ func (i InstallCCUIObject) createInstallCCObjects(ccObject inputStructs.InstallC
 		},
 		TargetPeers:     targetPeers,
 		ConnProfilePath: paths.GetConnProfilePath(orgNames, organizations),
+		Service:         service,
 	}
 	installCCObjects = append(installCCObjects, i)
-	return installCCObjects
+	return installCCObjects, nil
 }
 
 //SetEnvForCLI -- sets environment variables for running peer cli commands
@@ -103,110 +160,103 @@ func SetEnvForCLI(orgName, peerName, connProfilePath, tls, currentDir string) er
 	return nil
 }
 
-//packageCC -- package cc using cli
-func (i InstallCCUIObject) packageCC(installObject InstallCCUIObject) error {
-	currentDir, err := paths.GetCurrentDir()
+//installCCusingLifecycle -- packages the chaincode in process and installs it with the admin of every organization on
+//its target peers, all organizations in parallel. The server of chaincode of type ccaas starts before the install
+func (i InstallCCUIObject) installCCusingLifecycle(installObject InstallCCUIObject) error {
+
+	pkg, err := i.chaincodePackage(installObject)
 	if err != nil {
 		return err
 	}
-	peerName := fmt.Sprintf("peer0-%s", installObject.ChannelOpt.OrgName[0])
-	err = SetEnvForCLI(installObject.ChannelOpt.OrgName[0], peerName, installObject.ConnProfilePath, installObject.TLS, currentDir)
-	if err != nil {
-		return err
+	logger.INFO(fmt.Sprintf("Packaged chaincode %s with package ID %s", installObject.ChainCodeID, pkg.ID()))
+	errs := make([]error, len(installObject.ChannelOpt.OrgName))
+	var wg sync.WaitGroup
+	for index, orgName := range installObject.ChannelOpt.OrgName {
+		wg.Add(1)
+		go func(index int, orgName string) {
+			defer wg.Done()
+			org, err := lifecycle.Connect(orgConnProfilePath(installObject.ConnProfilePath, orgName), orgName, orgPeerNames(installObject.TargetPeers, orgName), installObject.TLS == "clientauth")
+			if err != nil {
+				errs[index] = err
+				return
+			}
+			defer org.Close()
+			installed, err := org.Install(pkg)
+			for _, chaincode := range installed {
+				logger.INFO(fmt.Sprintf("Installed chaincode %s on %s", chaincode.PackageID, chaincode.Peer))
+			}
+			errs[index] = err
+		}(index, strings.TrimSpace(orgName))
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
 	}
+	return nil
+}
+
+//chaincodePackage -- the package of a chaincode object: its code, or the connection to its server for type ccaas
+func (i InstallCCUIObject) chaincodePackage(installObject InstallCCUIObject) (lifecycle.Package, error) {
 
-	relativePath := fmt.Sprintf("%s/../../%s", currentDir, installObject.DeployOpt.ChainCodePath)
-	chaincodePath, err := filepath.Abs(relativePath)
+	if installObject.Service != nil {
+		return servicePackage(installObject, orgConnProfilePath(installObject.ConnProfilePath, strings.TrimSpace(installObject.ChannelOpt.OrgName[0])))
+	}
+	currentDir, err := paths.GetCurrentDir()
 	if err != nil {
-		return err
+		return lifecycle.Package{}, err
 	}
-	args := []string{"lifecycle",
-		"chaincode",
-		"package",
-		"cc.tgz",
-		"--lang", strings.ToLower(installObject.DeployOpt.Language),
-		"--path", chaincodePath,
-		"--label", fmt.Sprintf("%s_%s", installObject.ChainCodeID, installObject.ChainCodeVer)}
-	_, err = networkclient.ExecuteCommand("peer", args, true)
-	return err
+	chaincodePath, err := filepath.Abs(fmt.Sprintf("%s/../../%s", currentDir, installObject.DeployOpt.ChainCodePath))
+	if err != nil {
+		return lifecycle.Package{}, err
+	}
+	return lifecycle.NewPackage(ccLabel(installObject.ChainCodeID, installObject.ChainCodeVer), installObject.DeployOpt.Language, chaincodePath)
 }
 
-//installCCusingCLI -- installing cc using cli
-func (i InstallCCUIObject) installCCusingCLI(installObject InstallCCUIObject) error {
+//orgConnProfilePath -- the connection profile of an organization, given the connection profile or directory of
+//connection profiles of a chaincode object
+func orgConnProfilePath(connProfilePath, orgName string) string {
 
-	for _, orgName := range installObject.ChannelOpt.OrgName {
-		currentDir, err := paths.GetCurrentDir()
-		if err != nil {
-			return err
-		}
-		var connProfilePath string
-		if strings.Contains(installObject.ConnProfilePath, ".yaml") || strings.Contains(installObject.ConnProfilePath, ".json") {
-			connProfilePath = installObject.ConnProfilePath
-		} else {
-			connProfilePath = fmt.Sprintf("%s/connection_profile_%s.yaml", installObject.ConnProfilePath, orgName)
-		}
-		for _, peerName := range installObject.TargetPeers {
-			peerOrgName := strings.Split(peerName, "-")
-			if peerOrgName[1] == orgName {
-				connProfConfig, err := ConnProfileInformationForOrg(connProfilePath, orgName)
-				if err != nil {
-					return err
-				}
-				peerURL, err := url.Parse(connProfConfig.Peers[peerName].URL)
-				if err != nil {
-					logger.ERROR("Failed to get peer url from connection profile")
-					return err
-				}
-				peerAddress := peerURL.Host
-				err = SetEnvForCLI(orgName, peerName, connProfilePath, installObject.TLS, currentDir)
-				if err != nil {
-					return err
-				}
-				args := []string{"lifecycle",
-					"chaincode",
-					"install",
-					"cc.tgz",
-					"--peerAddresses",
-					peerAddress,
-					"--tlsRootCertFiles",
-					fmt.Sprintf("%s/crypto-config/peerOrganizations/%s/peers/%s.%s/tls/ca.crt", currentDir, orgName, peerName, orgName),
-				}
-				if installObject.TLS == "clientauth" {
-					args = append(args, "--tls")
-				}
-				_, err = networkclient.ExecuteCommand("peer", args, true)
-				if err != nil {
-					return err
-				}
-			}
+	if strings.Contains(connProfilePath, ".yaml") || strings.Contains(connProfilePath, ".json") {
+		return connProfilePath
+	}
+	return fmt.Sprintf("%s/connection_profile_%s.yaml", connProfilePath, orgName)
+}
+
+//ccLabel -- the label of the package of a chaincode version
+func ccLabel(chaincodeID, version string) string {
+	return fmt.Sprintf("%s_%s", chaincodeID, version)
+}
+
+//orgPeerNames -- the target peers of an organization, which are named <peer>-<organization>
+func orgPeerNames(targetPeers []string, orgName string) []string {
+
+	var output []string
+	for _, peerName := range peerNames(targetPeers) {
+		if strings.HasSuffix(peerName, "-"+orgName) {
+			output = append(output, peerName)
 		}
 	}
-	return nil
+	return output
 }
 
 //installCC -- To install chaincode
 func (i InstallCCUIObject) installCC(installCCObjects []InstallCCUIObject) error {
 
 	var err error
-	var jsonObject []byte
-	pteMainPath := paths.PTEPath()
+	var args []string
 	for j := 0; j < len(installCCObjects); j++ {
 		if installCCObjects[j].SDK == "cli" {
-			err = i.packageCC(installCCObjects[j])
-			if err != nil {
-				return err
-			}
-			err = i.installCCusingCLI(installCCObjects[j])
+			err = i.installCCusingLifecycle(installCCObjects[j])
 			if err != nil {
 				return err
 			}
 		} else {
-			jsonObject, err = json.Marshal(installCCObjects[j])
+			args, err = pteArgs(j, installCCObjects[j])
 			if err != nil {
 				return err
 			}
-			startTime := fmt.Sprintf("%s", time.Now())
-			args := []string{pteMainPath, strconv.Itoa(j), string(jsonObject), startTime}
 			_, err = networkclient.ExecuteCommand("node", args, true)
 			if err != nil {
 				return err
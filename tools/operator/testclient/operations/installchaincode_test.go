@@ -0,0 +1,54 @@
+package operations
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-test/tools/operator/testclient/inputStructs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChaincodeService(t *testing.T) {
+
+	var i InstallCCUIObject
+	organizations := []inputStructs.Organization{{Name: "org1", ConnProfilePath: "connection-profile"}, {Name: "org2", ConnProfilePath: "connection-profile"}}
+	ccObject := inputStructs.InstallCC{SDK: "cli", ChainCodeName: "samplecc", ChainCodeVersion: "V1.0", Organizations: "org1,org2", Type: "ccaas", Image: "samplecc:v1"}
+	installObjects, err := i.createInstallCCObjects(ccObject, organizations, "clientauth")
+	require.NoError(t, err)
+	require.Len(t, installObjects, 1)
+	assert.Equal(t, &ChaincodeService{Image: "samplecc:v1", Port: 9999}, installObjects[0].Service)
+	assert.Equal(t, "samplecc-v1-0", chaincodeServerName(ccObject.ChainCodeName, ccObject.ChainCodeVersion))
+
+	assert.Equal(t, []string{
+		"CHAINCODE_SERVER_ADDRESS=0.0.0.0:9999",
+		"CHAINCODE_ID=samplecc_V1.0:0123",
+		"CORE_CHAINCODE_ID_NAME=samplecc_V1.0:0123",
+		"CHAINCODE_TLS_DISABLED=false",
+		"CHAINCODE_TLS_KEY=/etc/hyperledger/chaincode/tls/server.key",
+		"CHAINCODE_TLS_CERT=/etc/hyperledger/chaincode/tls/server.crt",
+		"CHAINCODE_CLIENT_CA_CERT=/etc/hyperledger/chaincode/tls/ca.crt",
+	}, chaincodeServerEnv(*installObjects[0].Service, "samplecc_V1.0:0123"))
+	assert.Equal(t, []string{
+		"CHAINCODE_SERVER_ADDRESS=0.0.0.0:7052",
+		"CHAINCODE_ID=samplecc_V1.0:0123",
+		"CORE_CHAINCODE_ID_NAME=samplecc_V1.0:0123",
+		"CHAINCODE_TLS_DISABLED=true",
+	}, chaincodeServerEnv(ChaincodeService{Port: 7052, TLSDisabled: true}, "samplecc_V1.0:0123"))
+
+	ccObject.Type = ""
+	installObjects, err = i.createInstallCCObjects(ccObject, organizations, "clientauth")
+	require.NoError(t, err)
+	assert.Nil(t, installObjects[0].Service)
+
+	ccObject.Type = "ccaas"
+	ccObject.SDK = "node"
+	_, err = i.createInstallCCObjects(ccObject, organizations, "clientauth")
+	assert.EqualError(t, err, "chaincode samplecc of type ccaas needs the cli sdk")
+	ccObject.SDK = "cli"
+	ccObject.Image = ""
+	_, err = i.createInstallCCObjects(ccObject, organizations, "clientauth")
+	assert.EqualError(t, err, "chaincode samplecc of type ccaas needs the image of its server")
+	ccObject.Type = "external"
+	_, err = i.createInstallCCObjects(ccObject, organizations, "clientauth")
+	assert.EqualError(t, err, `invalid type "external" of chaincode samplecc, it can only be ccaas`)
+}
@@ -4,17 +4,20 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
-	"net/url"
 	"strconv"
 	"strings"
-	"time"
+	"sync"
 
 	"github.com/davecgh/go-spew/spew"
 	"github.com/hyperledger/fabric-test/tools/operator/connectionprofile"
+	"github.com/hyperledger/fabric-test/tools/operator/lifecycle"
 	"github.com/hyperledger/fabric-test/tools/operator/logger"
 	"github.com/hyperledger/fabric-test/tools/operator/networkclient"
 	"github.com/hyperledger/fabric-test/tools/operator/paths"
+	"github.com/hyperledger/fabric-test/tools/operator/plan"
+	"github.com/hyperledger/fabric-test/tools/operator/policy"
 	"github.com/hyperledger/fabric-test/tools/operator/testclient/inputStructs"
+	"github.com/pkg/errors"
 	yaml "gopkg.in/yaml.v2"
 )
 
@@ -31,6 +34,19 @@ type InstantiateCCUIObject struct {
 	TimeOutOpt          TimeOutOptions              `json:"timeoutOpt,omitempty"`
 	Sequence            string                      `json:"sequence,omitempty"`
 	TargetPeers         []string                    `json:"targetPeers,omitempty"`
+	Approvals           []ApprovalOverride          `json:"-"`
+	ExpectCommit        string                      `json:"-"`
+}
+
+//ApprovalOverride -- what some organizations approve instead of the chaincode definition to commit, with the
+//endorsement policy resolved to MSP IDs
+type ApprovalOverride struct {
+	OrgNames              []string
+	ChainCodeVer          string
+	Sequence              string
+	SignaturePolicy       string
+	CollectionsConfigPath string
+	Skip                  bool
 }
 
 //InstantiateDeployOptions --
@@ -54,9 +70,21 @@ type EndorsementPolicy struct {
 	SignaturePolicy string              `json:"signaturePolicy,omitempty"`
 }
 
-//Policy --
+//Policy -- a rule of an endorsement policy of PTE: a signature of the identity at index SignedBy, or when it has
+//rules, N of them
 type Policy struct {
-	SignedBy int `json:"signed-by"`
+	SignedBy int      `json:"signed-by"`
+	N        int      `json:"-"`
+	Rules    []Policy `json:"-"`
+}
+
+//MarshalJSON -- {"signed-by": i} for a signature and {"N-of": [rules]} for N of the rules
+func (p Policy) MarshalJSON() ([]byte, error) {
+
+	if p.Rules == nil {
+		return json.Marshal(map[string]int{"signed-by": p.SignedBy})
+	}
+	return json.Marshal(map[string][]Policy{fmt.Sprintf("%d-of", p.N): p.Rules})
 }
 
 //Identity --
@@ -87,14 +115,6 @@ type ConnProfileOptions struct {
 	}
 }
 
-//InstalledCC --
-type InstalledCC struct {
-	CC []struct {
-		PackageID string `json:"package_id,omitempty"`
-		Label     string `json:"label,omitempty"`
-	} `json:"installed_chaincodes,omitempty"`
-}
-
 //InstantiateCC -- To instantiate/upgrade chaincode with the objects created and to update connection profile
 func (i InstantiateCCUIObject) InstantiateCC(config inputStructs.Config, tls, action string) error {
 
@@ -113,7 +133,9 @@ func (i InstantiateCCUIObject) InstantiateCC(config inputStructs.Config, tls, ac
 		if err != nil {
 			return err
 		}
-		ccConfigObjects = append(ccConfigObjects, &configObjects[index])
+		if configObjects[index].ExpectCommit != "rejected" {
+			ccConfigObjects = append(ccConfigObjects, &configObjects[index])
+		}
 		instantiateCCObjects = append(instantiateCCObjects, ccObjects...)
 	}
 	err := i.instantiateCC(instantiateCCObjects)
@@ -128,6 +150,129 @@ func (i InstantiateCCUIObject) InstantiateCC(config inputStructs.Config, tls, ac
 	return nil
 }
 
+//PlanInstantiateCC -- records in p the chaincode lifecycle calls of instantiate or upgrade and the commands that would
+//make them. With the cli sdk every organization approves the definition, or the one of its approval override, and the
+//first one checks the commit readiness and commits it, in process and without commands
+func (i InstantiateCCUIObject) PlanInstantiateCC(config inputStructs.Config, tls, action string, p *plan.Plan) error {
+
+	configObjects := config.InstantiateCC
+	if action == "upgrade" {
+		configObjects = config.UpgradeCC
+	}
+	var index int
+	for _, ccObject := range configObjects {
+		if ccObject.EndorsementPolicy != "" {
+			_, err := policy.Parse(ccObject.EndorsementPolicy)
+			if err != nil {
+				return errors.Wrapf(err, "endorsement policy of chaincode %s", ccObject.ChainCodeName)
+			}
+		}
+		ccObjects, err := i.generateInstantiateCCObjects(ccObject, config.Organizations, tls, action)
+		if err != nil {
+			logger.WARNING(fmt.Sprintf("Planning %s of chaincode %s from the test input only: %s", action, ccObject.ChainCodeName, err.Error()))
+			ccObjects = inputInstantiateCCObjects(ccObject, action)
+		}
+		for _, instantiateObject := range ccObjects {
+			call := plan.LifecycleCall{
+				Call:      action,
+				SDK:       instantiateObject.SDK,
+				Chaincode: instantiateObject.ChainCodeID,
+				Version:   instantiateObject.ChainCodeVer,
+				Sequence:  instantiateObject.Sequence,
+				Channel:   instantiateObject.ChannelOpt.Name,
+				Orgs:      instantiateObject.ChannelOpt.OrgName,
+				Peers:     peerNames(instantiateObject.TargetPeers),
+				Policy:    ccObject.EndorsementPolicy,
+			}
+			if instantiateObject.SDK != "cli" {
+				p.LifecycleCalls = append(p.LifecycleCalls, call)
+				if err != nil {
+					continue
+				}
+				args, err := pteArgs(index, instantiateObject)
+				if err != nil {
+					return err
+				}
+				p.AddCommand("node", args...)
+				index++
+				continue
+			}
+			for _, orgName := range instantiateObject.ChannelOpt.OrgName {
+				approve := call
+				approve.Call = "approveformyorg"
+				approve.Orgs = []string{strings.TrimSpace(orgName)}
+				approve.Peers = orgPeerNames(instantiateObject.TargetPeers, approve.Orgs[0])
+				if approval, ok := inputApproval(ccObject.Approvals, approve.Orgs[0]); ok {
+					if approval.Skip {
+						continue
+					}
+					if approval.ChainCodeVersion != "" {
+						approve.Version = approval.ChainCodeVersion
+					}
+					if approval.Sequence != "" {
+						approve.Sequence = approval.Sequence
+					}
+					if approval.EndorsementPolicy != "" {
+						approve.Policy = approval.EndorsementPolicy
+					}
+				}
+				p.LifecycleCalls = append(p.LifecycleCalls, approve)
+			}
+			call.Call = "checkcommitreadiness"
+			p.LifecycleCalls = append(p.LifecycleCalls, call)
+			call.Call = "commit"
+			if ccObject.ExpectCommit == "rejected" {
+				call.Call = "commit (expect rejected)"
+			}
+			p.LifecycleCalls = append(p.LifecycleCalls, call)
+			index++
+		}
+	}
+	return nil
+}
+
+//inputApproval -- the approval override of an organization in the test input
+func inputApproval(approvals []inputStructs.Approval, orgName string) (inputStructs.Approval, bool) {
+
+	for _, approval := range approvals {
+		for _, name := range strings.Split(approval.Organizations, ",") {
+			if strings.TrimSpace(name) == orgName {
+				return approval, true
+			}
+		}
+	}
+	return inputStructs.Approval{}, false
+}
+
+//inputInstantiateCCObjects -- the chaincode objects of instantiation/upgrade per channel, with only the fields of the
+//test input, for the plan of a network whose connection profiles do not exist yet
+func inputInstantiateCCObjects(ccObject inputStructs.InstantiateCC, action string) []InstantiateCCUIObject {
+
+	channelNames := []string{ccObject.ChannelName}
+	if ccObject.ChannelPrefix != "" && ccObject.NumChannels > 0 {
+		channelNames = nil
+		for j := 0; j < ccObject.NumChannels; j++ {
+			channelNames = append(channelNames, fmt.Sprintf("%s%d", ccObject.ChannelPrefix, j))
+		}
+	}
+	var instantiateCCObjects []InstantiateCCUIObject
+	for _, channelName := range channelNames {
+		instantiateCCObjects = append(instantiateCCObjects, InstantiateCCUIObject{
+			SDK:          ccObject.SDK,
+			TransType:    action,
+			ChainCodeID:  ccObject.ChainCodeName,
+			ChainCodeVer: ccObject.ChainCodeVersion,
+			TargetPeers:  strings.Split(ccObject.TargetPeers, ","),
+			Sequence:     ccObject.Sequence,
+			ChannelOpt: ChannelOptions{
+				Name:    channelName,
+				OrgName: strings.Split(ccObject.Organizations, ","),
+			},
+		})
+	}
+	return instantiateCCObjects
+}
+
 //generateInstantiateCCObjects -- To generate chaincode objects for instantiation/upgrade
 func (i InstantiateCCUIObject) generateInstantiateCCObjects(ccObject inputStructs.InstantiateCC, organizations []inputStructs.Organization, tls, action string) ([]InstantiateCCUIObject, error) {
 
@@ -179,25 +324,60 @@ func (i InstantiateCCUIObject) createInstantiateCCObjects(orgNames []string, cha
 		i.TimeOutOpt = TimeOutOptions{PreConfig: "600000", Request: "600000"}
 	}
 	if ccObject.EndorsementPolicy != "" {
-		if ccObject.SDK != "cli" || strings.Contains(ccObject.EndorsementPolicy, "of") {
-			endorsementPolicy, err := i.getEndorsementPolicy(orgConnectionProfilePaths, ccObject.EndorsementPolicy)
-			if err != nil {
-				logger.ERROR("Failed to get the endorsement policy")
-				return instantiateCCObjects, err
-			}
-			i.DeployOpt.Endorsement = endorsementPolicy
-		} else {
-			endorsementPolicy := &EndorsementPolicy{SignaturePolicy: ccObject.EndorsementPolicy}
-			i.DeployOpt.Endorsement = endorsementPolicy
+		endorsementPolicy, err := i.getEndorsementPolicy(orgConnectionProfilePaths, ccObject.EndorsementPolicy, ccObject.SDK)
+		if err != nil {
+			logger.ERROR("Failed to get the endorsement policy")
+			return instantiateCCObjects, err
 		}
+		i.DeployOpt.Endorsement = endorsementPolicy
 	}
 	if ccObject.CollectionPath != "" {
 		i.DeployOpt.CollectionsConfigPath = ccObject.CollectionPath
 	}
+	var err error
+	i.Approvals, err = i.approvalOverrides(ccObject, orgConnectionProfilePaths)
+	if err != nil {
+		return instantiateCCObjects, err
+	}
+	i.ExpectCommit = ccObject.ExpectCommit
 	instantiateCCObjects = append(instantiateCCObjects, i)
 	return instantiateCCObjects, nil
 }
 
+//approvalOverrides -- the divergent approvals of a chaincode object, which only the cli sdk can make
+func (i InstantiateCCUIObject) approvalOverrides(ccObject inputStructs.InstantiateCC, organizations []inputStructs.Organization) ([]ApprovalOverride, error) {
+
+	switch ccObject.ExpectCommit {
+	case "", "committed", "rejected":
+	default:
+		return nil, errors.Errorf("invalid expectCommit %q of chaincode %s, it can be committed or rejected", ccObject.ExpectCommit, ccObject.ChainCodeName)
+	}
+	if (len(ccObject.Approvals) > 0 || ccObject.ExpectCommit != "") && ccObject.SDK != "cli" {
+		return nil, errors.Errorf("approvals and expectCommit of chaincode %s need the cli sdk", ccObject.ChainCodeName)
+	}
+	var overrides []ApprovalOverride
+	for _, approval := range ccObject.Approvals {
+		override := ApprovalOverride{
+			ChainCodeVer:          approval.ChainCodeVersion,
+			Sequence:              approval.Sequence,
+			CollectionsConfigPath: approval.CollectionPath,
+			Skip:                  approval.Skip,
+		}
+		for _, orgName := range strings.Split(approval.Organizations, ",") {
+			override.OrgNames = append(override.OrgNames, strings.TrimSpace(orgName))
+		}
+		if approval.EndorsementPolicy != "" {
+			endorsementPolicy, err := i.getEndorsementPolicy(organizations, approval.EndorsementPolicy, "cli")
+			if err != nil {
+				return nil, errors.Wrapf(err, "endorsement policy of the approval of %s", approval.Organizations)
+			}
+			override.SignaturePolicy = endorsementPolicy.SignaturePolicy
+		}
+		overrides = append(overrides, override)
+	}
+	return overrides, nil
+}
+
 //createInstantiateCCObjectIfChanPrefix -- To create chaincode objects if channel prefix and number of channels are given
 func (i InstantiateCCUIObject) createInstantiateCCObjectIfChanPrefix(ccObject inputStructs.InstantiateCC, organizations []inputStructs.Organization, tls, action string) ([]InstantiateCCUIObject, error) {
 
@@ -215,46 +395,77 @@ func (i InstantiateCCUIObject) createInstantiateCCObjectIfChanPrefix(ccObject in
 	return instantiateCCObjects, nil
 }
 
-//getEndorsementPolicy -- To get the endorsement policy
-func (i InstantiateCCUIObject) getEndorsementPolicy(organizations []inputStructs.Organization, policy string) (*EndorsementPolicy, error) {
-
-	var endorsementPolicy *EndorsementPolicy
-	var identities []Identity
-	var policies []Policy
-	var identity Identity
-	args := strings.Split(policy, "(")
-	orgs := args[len(args)-1]
-	orgs = orgs[:len(orgs)-1]
-	orgNames := strings.Split(orgs, ",")
+//getEndorsementPolicy -- To get the endorsement policy from a signature policy expression, with its organization
+//names resolved to the MSP IDs of their connection profiles. The cli sdk takes it in the Fabric policy DSL and PTE
+//as identities and rules
+func (i InstantiateCCUIObject) getEndorsementPolicy(organizations []inputStructs.Organization, expression, sdk string) (*EndorsementPolicy, error) {
 
-	for _, orgName := range orgNames {
-		orgName = strings.TrimSpace(orgName)
-		connProfilePath := paths.GetConnProfilePath([]string{orgName}, organizations)
-		connProfConfig, err := ConnProfileInformationForOrg(connProfilePath, orgName)
-		if err != nil {
-			return endorsementPolicy, err
-		}
-		mspID := connProfConfig.Organizations[orgName].MSPID
-		identity.Role.Name = "member"
-		identity.Role.MSPID = mspID
-		identities = append(identities, identity)
+	parsed, err := policy.Parse(expression)
+	if err != nil {
+		return nil, err
 	}
-	numPolicies, err := strconv.Atoi(args[0][0:1])
+	mspIDs := make(map[string]string)
+	resolved, err := parsed.Resolve(func(org string) (string, error) {
+		return orgMSPID(organizations, org, mspIDs)
+	})
 	if err != nil {
-		logger.ERROR("Failed to convert string to integer")
-		return endorsementPolicy, err
+		return nil, err
+	}
+	if sdk == "cli" {
+		return &EndorsementPolicy{SignaturePolicy: resolved.String()}, nil
 	}
-	key := fmt.Sprintf("%d-of", numPolicies)
-	for i := 0; i < len(orgNames); i++ {
-		policy := Policy{SignedBy: i}
-		policies = append(policies, policy)
+	identities := resolved.Identities()
+	endorsementPolicy := &EndorsementPolicy{}
+	for _, principal := range identities {
+		var identity Identity
+		identity.Role.Name = principal.Role
+		identity.Role.MSPID = principal.Org
+		endorsementPolicy.Identities = append(endorsementPolicy.Identities, identity)
 	}
-	policyMap := make(map[string][]Policy)
-	policyMap[key] = policies
-	endorsementPolicy = &EndorsementPolicy{Identities: identities, Policy: policyMap}
+	rule := ptePolicy(resolved, identities)
+	endorsementPolicy.Policy = map[string][]Policy{fmt.Sprintf("%d-of", rule.N): rule.Rules}
 	return endorsementPolicy, nil
 }
 
+//ptePolicy -- the rule of PTE of a resolved policy
+func ptePolicy(p policy.Policy, identities []policy.Principal) Policy {
+
+	if p.Principal != nil {
+		return Policy{SignedBy: policy.SignedBy(identities, *p.Principal)}
+	}
+	rule := Policy{N: p.N, Rules: []Policy{}}
+	for _, subPolicy := range p.Rules {
+		rule.Rules = append(rule.Rules, ptePolicy(subPolicy, identities))
+	}
+	return rule
+}
+
+//orgMSPID -- the MSP ID of an organization of the test input, from its connection profile. Names that are not those
+//of an organization of the test input are taken as MSP IDs
+func orgMSPID(organizations []inputStructs.Organization, org string, mspIDs map[string]string) (string, error) {
+
+	if mspID, ok := mspIDs[org]; ok {
+		return mspID, nil
+	}
+	mspID := org
+	for _, organization := range organizations {
+		if organization.Name != org {
+			continue
+		}
+		connProfConfig, err := ConnProfileInformationForOrg(paths.GetConnProfilePath([]string{org}, organizations), org)
+		if err != nil {
+			return "", err
+		}
+		if connProfConfig.Organizations[org].MSPID == "" {
+			return "", errors.Errorf("no MSP ID of organization %s in its connection profile", org)
+		}
+		mspID = connProfConfig.Organizations[org].MSPID
+		break
+	}
+	mspIDs[org] = mspID
+	return mspID, nil
+}
+
 //ConnProfileInformationForOrg -- To get the MSP ID for an organization
 func ConnProfileInformationForOrg(connProfilePath, orgName string) (ConnProfileOptions, error) {
 
@@ -284,129 +495,116 @@ func ConnProfileInformationForOrg(connProfilePath, orgName string) (ConnProfileO
 	return config, nil
 }
 
-func fetchOrdererInformation(currentDir string) ([]string, error) {
-
-	files, err := ioutil.ReadDir(fmt.Sprintf("%s/crypto-config/ordererOrganizations/", currentDir))
-	if err != nil {
-		logger.ERROR("failed opening directory")
-		return nil, err
-	}
-	ordererDirectory, err := ioutil.ReadDir(fmt.Sprintf("%s/crypto-config/ordererOrganizations/%s/orderers/", currentDir, files[0].Name()))
-	if err != nil {
-		logger.ERROR("failed opening orderer directory")
-		return nil, err
-	}
-	ordererName := strings.Split(ordererDirectory[0].Name(), ".")
-	return ordererName, nil
-}
-
-//queryInstalledusingCLI -- querying installed cc using cli
-func (i InstantiateCCUIObject) queryInstalledusingCLI(orgName, peerName, peerAddress, TLS string) (InstalledCC, error) {
+//ccDefinition -- the chaincode definition organizations approve and commit for an instantiate or upgrade object
+func ccDefinition(instantiateObject InstantiateCCUIObject) (lifecycle.Definition, error) {
 
-	var queryInstalled InstalledCC
-	currentDir, err := paths.GetCurrentDir()
+	definition := lifecycle.Definition{Name: instantiateObject.ChainCodeID, Version: instantiateObject.ChainCodeVer}
+	sequence, err := strconv.ParseInt(strings.TrimSpace(instantiateObject.Sequence), 10, 64)
 	if err != nil {
-		return queryInstalled, err
+		return definition, errors.Wrapf(err, "invalid sequence %q of chaincode %s", instantiateObject.Sequence, instantiateObject.ChainCodeID)
 	}
-	args := []string{"lifecycle",
-		"chaincode",
-		"queryinstalled",
-		"--peerAddresses", peerAddress,
-		"--tlsRootCertFiles", fmt.Sprintf("%s/crypto-config/peerOrganizations/%s/peers/%s.%s/tls/ca.crt", currentDir, orgName, peerName, orgName),
-		"--output",
-		"json"}
-
-	if TLS == "clientauth" {
-		args = append(args, "--tls")
+	definition.Sequence = sequence
+	if instantiateObject.DeployOpt.Endorsement != nil {
+		definition.Policy = instantiateObject.DeployOpt.Endorsement.SignaturePolicy
 	}
-	installedCC, err := networkclient.ExecuteCommand("peer", args, true)
-	if err != nil {
-		return queryInstalled, err
-	}
-	err = json.Unmarshal([]byte(installedCC), &queryInstalled)
-	if err != nil {
-		return queryInstalled, err
+	if instantiateObject.DeployOpt.CollectionsConfigPath != "" {
+		definition.Collections, err = lifecycle.Collections(instantiateObject.DeployOpt.CollectionsConfigPath)
+		if err != nil {
+			return definition, err
+		}
 	}
-	return queryInstalled, nil
+	return definition, nil
 }
 
-//approveCCusingCLI -- approving cc for organization using CLI
-func (i InstantiateCCUIObject) approveCCusingCLI(instantiateObject InstantiateCCUIObject) error {
+//connectOrgs -- connects the admins of the organizations of an instantiate or upgrade object to their target peers
+func connectOrgs(instantiateObject InstantiateCCUIObject) ([]*lifecycle.Org, error) {
 
-	var packageID string
+	var orgs []*lifecycle.Org
 	for _, orgName := range instantiateObject.ChannelOpt.OrgName {
-		currentDir, err := paths.GetCurrentDir()
-		if err != nil {
-			return err
-		}
-
 		orgName = strings.TrimSpace(orgName)
 		connProfilePath := paths.GetConnProfilePath([]string{orgName}, instantiateObject.OrgConnProfilePaths)
-		connProfConfig, err := ConnProfileInformationForOrg(connProfilePath, orgName)
+		org, err := lifecycle.Connect(connProfilePath, orgName, orgPeerNames(instantiateObject.TargetPeers, orgName), instantiateObject.TLS == "clientauth")
 		if err != nil {
-			return err
+			closeOrgs(orgs)
+			return nil, err
 		}
-		ordererName, err := fetchOrdererInformation(currentDir)
-		if err != nil {
-			return err
+		orgs = append(orgs, org)
+	}
+	return orgs, nil
+}
+
+func closeOrgs(orgs []*lifecycle.Org) {
+	for _, org := range orgs {
+		org.Close()
+	}
+}
+
+//orgDefinition -- the chaincode definition an organization approves: the definition to commit, or the one of the
+//approval override of the organization. It is false when the organization does not approve
+func orgDefinition(instantiateObject InstantiateCCUIObject, definition lifecycle.Definition, orgName string) (lifecycle.Definition, bool, error) {
+
+	for _, override := range instantiateObject.Approvals {
+		if !contains(override.OrgNames, orgName) {
+			continue
 		}
-		ordererURL, err := url.Parse(connProfConfig.Orderers[ordererName[0]].URL)
-		if err != nil {
-			logger.ERROR("Failed to get orderer url from connection profile")
-			return err
+		if override.Skip {
+			return definition, false, nil
 		}
-		ordererAddress := ordererURL.Host
-		args := []string{
-			"lifecycle",
-			"chaincode",
-			"approveformyorg",
-			"--channelID", instantiateObject.ChannelOpt.Name,
-			"--name", instantiateObject.ChainCodeID,
-			"--version", instantiateObject.ChainCodeVer,
-			"--sequence", instantiateObject.Sequence,
-			"--waitForEvent",
-			"--orderer", ordererAddress,
-			"--cafile", fmt.Sprintf("%s/crypto-config/ordererOrganizations/%s/orderers/%s.%s/tls/ca.crt", currentDir, ordererName[1], ordererName[0], ordererName[1]),
-			// "--connectionProfile",
-			// instantiateObject.ConnProfilePath,
+		if override.ChainCodeVer != "" {
+			definition.Version = override.ChainCodeVer
 		}
-		for _, peerName := range instantiateObject.TargetPeers {
-			peerOrgName := strings.Split(peerName, "-")
-			if peerOrgName[1] == orgName {
-				peerURL, err := url.Parse(connProfConfig.Peers[strings.TrimSpace(peerName)].URL)
-				if err != nil {
-					logger.ERROR("Failed to get peer url from connection profile")
-					return err
-				}
-				peerAddress := peerURL.Host
-				err = SetEnvForCLI(orgName, peerName, connProfilePath, instantiateObject.TLS, currentDir)
-				if err != nil {
-					return err
-				}
-				queryInstalled, _ := i.queryInstalledusingCLI(orgName, peerName, peerAddress, instantiateObject.TLS)
-				for j := 0; j < len(queryInstalled.CC); j++ {
-					if queryInstalled.CC[j].Label == fmt.Sprintf("%s_%s", instantiateObject.ChainCodeID, instantiateObject.ChainCodeVer) {
-						packageID = queryInstalled.CC[j].PackageID
-						break
-					}
-				}
-				args = append(args,
-					"--peerAddresses", peerAddress,
-					"--tlsRootCertFiles", fmt.Sprintf("%s/crypto-config/peerOrganizations/%s/peers/%s.%s/tls/ca.crt", currentDir, orgName, peerName, orgName),
-				)
+		if override.Sequence != "" {
+			sequence, err := strconv.ParseInt(strings.TrimSpace(override.Sequence), 10, 64)
+			if err != nil {
+				return definition, false, errors.Wrapf(err, "invalid sequence %q of the approval of %s", override.Sequence, orgName)
 			}
+			definition.Sequence = sequence
 		}
-		args = append(args, "--package-id", packageID)
-		if instantiateObject.TLS == "clientauth" {
-			args = append(args, "--tls")
-		}
-		if instantiateObject.DeployOpt.Endorsement != nil && instantiateObject.SDK == "cli" {
-			args = append(args, "--signature-policy", instantiateObject.DeployOpt.Endorsement.SignaturePolicy)
+		if override.SignaturePolicy != "" {
+			definition.Policy = override.SignaturePolicy
 		}
-		if instantiateObject.DeployOpt.CollectionsConfigPath != "" {
-			args = append(args, "--collections-config", instantiateObject.DeployOpt.CollectionsConfigPath)
+		if override.CollectionsConfigPath != "" {
+			collections, err := lifecycle.Collections(override.CollectionsConfigPath)
+			if err != nil {
+				return definition, false, err
+			}
+			definition.Collections = collections
 		}
-		_, err = networkclient.ExecuteCommand("peer", args, true)
+		break
+	}
+	return definition, true, nil
+}
+
+//approveCC -- approves the chaincode definition for every organization in parallel, for the package its peers have
+//installed with the label of the chaincode version. Organizations with an approval override approve its definition
+func (i InstantiateCCUIObject) approveCC(instantiateObject InstantiateCCUIObject, orgs []*lifecycle.Org, definition lifecycle.Definition) error {
+
+	errs := make([]error, len(orgs))
+	var wg sync.WaitGroup
+	for index, org := range orgs {
+		wg.Add(1)
+		go func(index int, org *lifecycle.Org) {
+			defer wg.Done()
+			definition, approve, err := orgDefinition(instantiateObject, definition, org.Name)
+			if err != nil || !approve {
+				errs[index] = err
+				return
+			}
+			packageID, err := org.PackageID(ccLabel(instantiateObject.ChainCodeID, definition.Version))
+			if err != nil {
+				errs[index] = err
+				return
+			}
+			approval, err := org.Approve(instantiateObject.ChannelOpt.Name, definition, packageID)
+			if err != nil {
+				errs[index] = err
+				return
+			}
+			logger.INFO(fmt.Sprintf("Approved chaincode %s on channel %s for %s in transaction %s", definition, instantiateObject.ChannelOpt.Name, approval.MSPID, approval.TxID))
+		}(index, org)
+	}
+	wg.Wait()
+	for _, err := range errs {
 		if err != nil {
 			return err
 		}
@@ -414,104 +612,75 @@ func (i InstantiateCCUIObject) approveCCusingCLI(instantiateObject InstantiateCC
 	return nil
 }
 
-//commitCCusingCLI -- committing cc using CLI
-func (i InstantiateCCUIObject) commitCCusingCLI(instantiateObject InstantiateCCUIObject) error {
-	ordererOrgName := instantiateObject.ChannelOpt.OrgName[0]
-	ordererOrgName = strings.TrimSpace(ordererOrgName)
-	ordererConnProfilePath := paths.GetConnProfilePath([]string{ordererOrgName}, instantiateObject.OrgConnProfilePaths)
-	ordererConnProfConfig, err := ConnProfileInformationForOrg(ordererConnProfilePath, ordererOrgName)
+//commitCC -- commits the chaincode definition with the first organization, endorsed by the target peers of all of them
+func (i InstantiateCCUIObject) commitCC(instantiateObject InstantiateCCUIObject, orgs []*lifecycle.Org, definition lifecycle.Definition) error {
+
+	var peers []*lifecycle.Peer
+	for _, org := range orgs {
+		peers = append(peers, org.Peers...)
+	}
+	committed, err := orgs[0].Commit(instantiateObject.ChannelOpt.Name, definition, peers)
 	if err != nil {
 		return err
 	}
-	currentDir, err := paths.GetCurrentDir()
+	logger.INFO(fmt.Sprintf("Committed chaincode %s on channel %s in transaction %s", definition, committed.Channel, committed.TxID))
+	return nil
+}
+
+//instantiateCCusingLifecycle -- approves the chaincode definition for every organization and commits it, leaving it to
+//the LifecycleEndorsement policy of the channel whether the approvals allow the commit; a failed commit is reported with
+//the commit readiness of the organizations. A commit expected to be rejected fails when it is not rejected
+func (i InstantiateCCUIObject) instantiateCCusingLifecycle(instantiateObject InstantiateCCUIObject) error {
+
+	definition, err := ccDefinition(instantiateObject)
 	if err != nil {
 		return err
 	}
-	ordererName, err := fetchOrdererInformation(currentDir)
+	orgs, err := connectOrgs(instantiateObject)
 	if err != nil {
 		return err
 	}
-	ordererURL, err := url.Parse(ordererConnProfConfig.Orderers[ordererName[0]].URL)
+	defer closeOrgs(orgs)
+	err = i.approveCC(instantiateObject, orgs, definition)
 	if err != nil {
-		logger.ERROR("Failed to get orderer url from connection profile")
 		return err
 	}
-	ordererAddress := ordererURL.Host
-
-	args := []string{"lifecycle",
-		"chaincode",
-		"commit",
-		"--channelID", instantiateObject.ChannelOpt.Name,
-		"--name", instantiateObject.ChainCodeID,
-		"--version", instantiateObject.ChainCodeVer,
-		"--sequence", instantiateObject.Sequence,
-		"--waitForEvent",
-		"--orderer", ordererAddress,
-		"--cafile", fmt.Sprintf("%s/crypto-config/ordererOrganizations/%s/orderers/%s.%s/tls/ca.crt", currentDir, ordererName[1], ordererName[0], ordererName[1]),
-		// "--connectionProfile",
-		// instantiateObject.ConnProfilePath,
+	readiness, err := lifecycle.CheckReadiness(orgs, instantiateObject.ChannelOpt.Name, definition)
+	if err != nil {
+		return err
 	}
-
-	for _, peerName := range instantiateObject.TargetPeers {
-		peerOrgName := strings.Split(peerName, "-")[1]
-
-		connProfilePath := paths.GetConnProfilePath([]string{peerOrgName}, instantiateObject.OrgConnProfilePaths)
-		connProfConfig, err := ConnProfileInformationForOrg(connProfilePath, peerOrgName)
-		if err != nil {
-			return err
+	logger.INFO(readiness.String())
+	if instantiateObject.ExpectCommit == "rejected" {
+		err = i.commitCC(instantiateObject, orgs, definition)
+		if err == nil {
+			return errors.Errorf("commit of chaincode %s on channel %s was expected to be rejected but succeeded\n%s", definition, instantiateObject.ChannelOpt.Name, readiness)
 		}
-		peerURL, err := url.Parse(connProfConfig.Peers[peerName].URL)
-		if err != nil {
-			logger.ERROR("Failed to get peer url from connection profile")
-			return err
-		}
-		peerAddress := peerURL.Host
-		args = append(args,
-			"--peerAddresses", peerAddress,
-			"--tlsRootCertFiles", fmt.Sprintf("%s/crypto-config/peerOrganizations/%s/peers/%s.%s/tls/ca.crt", currentDir, peerOrgName, peerName, peerOrgName),
-		)
-	}
-	if instantiateObject.TLS == "clientauth" {
-		args = append(args, "--tls")
+		logger.INFO(fmt.Sprintf("Commit of chaincode %s on channel %s was rejected as expected: %s", definition, instantiateObject.ChannelOpt.Name, err.Error()))
+		return nil
 	}
-	if instantiateObject.DeployOpt.Endorsement != nil && instantiateObject.SDK == "cli" {
-		args = append(args, "--signature-policy", instantiateObject.DeployOpt.Endorsement.SignaturePolicy)
-	}
-	if instantiateObject.DeployOpt.CollectionsConfigPath != "" {
-		args = append(args, "--collections-config", instantiateObject.DeployOpt.CollectionsConfigPath)
-	}
-
-	err = SetEnvForCLI(ordererOrgName, instantiateObject.TargetPeers[0], ordererConnProfilePath, instantiateObject.TLS, currentDir)
+	err = i.commitCC(instantiateObject, orgs, definition)
 	if err != nil {
-		return err
+		return errors.Errorf("chaincode %s cannot be committed on channel %s: %s\n%s", definition, instantiateObject.ChannelOpt.Name, err, readiness)
 	}
-	_, err = networkclient.ExecuteCommand("peer", args, true)
-	return err
+	return nil
 }
 
 //instantiateCC -- To instantiate chaincode
 func (i InstantiateCCUIObject) instantiateCC(instantiateChainCodeObjects []InstantiateCCUIObject) error {
 
 	var err error
-	var jsonObject []byte
-	pteMainPath := paths.PTEPath()
+	var args []string
 	for j := 0; j < len(instantiateChainCodeObjects); j++ {
 		if instantiateChainCodeObjects[j].SDK == "cli" {
-			err = i.approveCCusingCLI(instantiateChainCodeObjects[j])
-			if err != nil {
-				return err
-			}
-			err = i.commitCCusingCLI(instantiateChainCodeObjects[j])
+			err = i.instantiateCCusingLifecycle(instantiateChainCodeObjects[j])
 			if err != nil {
 				return err
 			}
 		} else {
-			jsonObject, err = json.Marshal(instantiateChainCodeObjects[j])
+			args, err = pteArgs(j, instantiateChainCodeObjects[j])
 			if err != nil {
 				return err
 			}
-			startTime := fmt.Sprintf("%s", time.Now())
-			args := []string{pteMainPath, strconv.Itoa(j), string(jsonObject), startTime}
 			_, err = networkclient.ExecuteCommand("node", args, true)
 			if err != nil {
 				return err
@@ -0,0 +1,82 @@
+package operations
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hyperledger/fabric-test/tools/operator/testclient/inputStructs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetEndorsementPolicy(t *testing.T) {
+
+	var i InstantiateCCUIObject
+	expression := "AND('Org1MSP.member', OR('Org2MSP.peer', 'Org3MSP.admin', 'Org1MSP.member'))"
+	endorsementPolicy, err := i.getEndorsementPolicy(nil, expression, "cli")
+	require.NoError(t, err)
+	assert.Equal(t, &EndorsementPolicy{SignaturePolicy: "AND('Org1MSP.member', OR('Org2MSP.peer', 'Org3MSP.admin', 'Org1MSP.member'))"}, endorsementPolicy)
+
+	endorsementPolicy, err = i.getEndorsementPolicy(nil, expression, "node")
+	require.NoError(t, err)
+	contents, err := json.Marshal(endorsementPolicy)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"identities": [
+			{"role": {"name": "member", "mspId": "Org1MSP"}},
+			{"role": {"name": "peer", "mspId": "Org2MSP"}},
+			{"role": {"name": "admin", "mspId": "Org3MSP"}}
+		],
+		"policy": {"2-of": [{"signed-by": 0}, {"1-of": [{"signed-by": 1}, {"signed-by": 2}, {"signed-by": 0}]}]}
+	}`, string(contents))
+
+	_, err = i.getEndorsementPolicy(nil, "2of(Org1MSP)", "node")
+	assert.EqualError(t, err, "invalid policy at column 1: 2of requires 2 signatures of only 1 sub-policies\n  2of(Org1MSP)\n  ^")
+}
+
+func TestApprovalOverrides(t *testing.T) {
+
+	var i InstantiateCCUIObject
+	ccObject := inputStructs.InstantiateCC{
+		SDK:               "cli",
+		ChainCodeName:     "samplecc",
+		ChainCodeVersion:  "v1",
+		Sequence:          "1",
+		EndorsementPolicy: "2of(Org1MSP,Org2MSP)",
+		Approvals: []inputStructs.Approval{
+			{Organizations: "org2, org3", EndorsementPolicy: "Org2MSP", Sequence: "2"},
+			{Organizations: "org4", Skip: true},
+		},
+		ExpectCommit: "rejected",
+	}
+	overrides, err := i.approvalOverrides(ccObject, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []ApprovalOverride{
+		{OrgNames: []string{"org2", "org3"}, Sequence: "2", SignaturePolicy: "OR('Org2MSP.member')"},
+		{OrgNames: []string{"org4"}, Skip: true},
+	}, overrides)
+
+	instantiateObject := InstantiateCCUIObject{ChainCodeID: "samplecc", ChainCodeVer: "v1", Sequence: "1", Approvals: overrides}
+	definition, err := ccDefinition(instantiateObject)
+	require.NoError(t, err)
+	orgDef, approve, err := orgDefinition(instantiateObject, definition, "org1")
+	require.NoError(t, err)
+	assert.True(t, approve)
+	assert.Equal(t, definition, orgDef)
+	orgDef, approve, err = orgDefinition(instantiateObject, definition, "org3")
+	require.NoError(t, err)
+	assert.True(t, approve)
+	assert.Equal(t, int64(2), orgDef.Sequence)
+	assert.Equal(t, "OR('Org2MSP.member')", orgDef.Policy)
+	_, approve, err = orgDefinition(instantiateObject, definition, "org4")
+	require.NoError(t, err)
+	assert.False(t, approve)
+
+	ccObject.SDK = "node"
+	_, err = i.approvalOverrides(ccObject, nil)
+	assert.EqualError(t, err, "approvals and expectCommit of chaincode samplecc need the cli sdk")
+	ccObject.SDK = "cli"
+	ccObject.ExpectCommit = "maybe"
+	_, err = i.approvalOverrides(ccObject, nil)
+	assert.EqualError(t, err, `invalid expectCommit "maybe" of chaincode samplecc, it can be committed or rejected`)
+}
@@ -1,13 +1,9 @@
 package operations
 
 import (
-	"encoding/json"
 	"fmt"
-	"io/ioutil"
-	"net/http"
 	"net/url"
 	"os"
-	"regexp"
 	"strconv"
 	"strings"
 	"sync"
@@ -17,11 +13,15 @@ import (
 
 	"github.com/davecgh/go-spew/spew"
 	"github.com/hyperledger/fabric-test/tools/operator/logger"
+	"github.com/hyperledger/fabric-test/tools/operator/metrics"
 	"github.com/hyperledger/fabric-test/tools/operator/networkclient"
 	"github.com/hyperledger/fabric-test/tools/operator/paths"
 	"github.com/hyperledger/fabric-test/tools/operator/testclient/inputStructs"
 )
 
+//defaultFcn -- the chaincode function of invokes and queries without fcn, as in the PTE client
+const defaultFcn = "invoke"
+
 //InvokeQueryUIObject --
 type InvokeQueryUIObject struct {
 	LogLevel        string                `json:"logLevel,omitempty"`
@@ -52,6 +52,7 @@ type InvokeQueryUIObject struct {
 	FailoverOpt     PeerOptions           `json:"failoverOpt,omitempty"`
 	OrdererOpt      OrdererOptions        `json:"ordererOpt,omitempty"`
 	Snapshot        SnapshotOptions       `json:"snapshot,omitempty"`
+	SLO             inputStructs.SLO      `json:"slo,omitempty"`
 }
 
 type SnapshotOptions struct {
@@ -133,6 +134,12 @@ type Result struct {
 
 //InvokeQuery -- To perform invoke/query with the objects created
 func (i InvokeQueryUIObject) InvokeQuery(config inputStructs.Config, tls, action string) error {
+	_, err := i.InvokeQueryWithResults(config, tls, action)
+	return err
+}
+
+//InvokeQueryWithResults -- To perform invoke/query with the objects created and return the results of every driver process
+func (i InvokeQueryUIObject) InvokeQueryWithResults(config inputStructs.Config, tls, action string) ([]TransactionResult, error) {
 	var invokeQueryObjects []InvokeQueryUIObject
 	configObjects := config.Invoke
 	if action == "Query" {
@@ -146,7 +153,19 @@ func (i InvokeQueryUIObject) InvokeQuery(config inputStructs.Config, tls, action
 		invkQueryObjects := i.generateInvokeQueryObjects(configObjects[key], config.Organizations, tls, action)
 		invokeQueryObjects = append(invokeQueryObjects, invkQueryObjects...)
 	}
-	return i.invokeQueryTransactions(invokeQueryObjects)
+	for _, invokeQueryObject := range invokeQueryObjects {
+		err := validateTransactionDriver(invokeQueryObject)
+		if err != nil {
+			return nil, err
+		}
+	}
+	runMetricsSnapshot(config, action, "before")
+	results, err := i.invokeQueryTransactions(invokeQueryObjects)
+	runMetricsSnapshot(config, action, "after")
+	if reportErr := WritePerformanceReport(action, reportDir(config.Organizations), results); reportErr != nil {
+		logger.ERROR("Failed to write performance report ", reportErr.Error())
+	}
+	return results, err
 }
 
 //generateInvokeQueryObjects -- To generate objects for invoke/query
@@ -207,6 +226,7 @@ func (i InvokeQueryUIObject) createInvokeQueryObjectForOrg(orgNames []string, ac
 			PeerName:  invkQueryObject.SnapshotOpt.SnapshotPeer,
 		},
 		ConnProfilePath: paths.GetConnProfilePath(orgNames, organizations),
+		SLO:             invkQueryObject.SLO,
 	}
 	if strings.EqualFold("DISCOVERY", invkQueryObject.TargetPeers) {
 		localHost := strings.ToUpper(strconv.FormatBool(invkQueryObject.DiscoveryOptions.Localhost))
@@ -222,12 +242,16 @@ func (i InvokeQueryUIObject) createInvokeQueryObjectForOrg(orgNames []string, ac
 		i.InvokeType = action
 		i.CCOpt = CCOptions{KeyIdx: invkQueryObject.CCOptions.KeyIdx, KeyStart: strconv.Itoa(invkQueryObject.CCOptions.KeyStart)}
 	}
+	fcn := invkQueryObject.Fcn
+	if fcn == "" {
+		fcn = defaultFcn
+	}
 	invokeParams["move"] = Parameters{
-		Fcn:  invkQueryObject.Fcn,
+		Fcn:  fcn,
 		Args: strings.Split(invkQueryObject.Args, ","),
 	}
 	invokeParams["query"] = Parameters{
-		Fcn:  invkQueryObject.Fcn,
+		Fcn:  fcn,
 		Args: strings.Split(invkQueryObject.Args, ","),
 	}
 	i.Parameters = invokeParams
@@ -246,7 +270,7 @@ func (i InvokeQueryUIObject) createInvokeQueryObjectForOrg(orgNames []string, ac
 		}
 	}
 	for key := range invkQueryObject.TxnOptions {
-		mode := invkQueryObject.TxnOptions[key].Mode
+		mode := strings.ToLower(invkQueryObject.TxnOptions[key].Mode)
 		options := invkQueryObject.TxnOptions[key].Options
 		i.TransMode = mode
 		switch mode {
@@ -262,33 +286,26 @@ func (i InvokeQueryUIObject) createInvokeQueryObjectForOrg(orgNames []string, ac
 	return invokeQueryObjects
 }
 
-func (i InvokeQueryUIObject) invokeConfig(channelName string, args []string) error {
-
-	_, err := networkclient.ExecuteCommand("node", args, true)
-	if err != nil {
-		return errors.Errorf(fmt.Sprintf("Failed to perform invoke/query on %s channel: %v", channelName, err))
-	}
-	return nil
-}
-
 //invokeQueryTransactions -- To invoke/query transactions
-func (i InvokeQueryUIObject) invokeQueryTransactions(invokeQueryObjects []InvokeQueryUIObject) error {
+func (i InvokeQueryUIObject) invokeQueryTransactions(invokeQueryObjects []InvokeQueryUIObject) ([]TransactionResult, error) {
 	var wg sync.WaitGroup
-	pteMainPath := paths.PTEPath()
+	var results []TransactionResult
+	var sloViolations []string
+	resultsMutex := sync.Mutex{}
 	errCh := make(chan error, 1)
 	channelBlockchainCount := make(map[string]map[int]map[string]BlockchainCount)
 	for key := range invokeQueryObjects {
-		jsonObject, err := json.Marshal(invokeQueryObjects[key])
-		if err != nil {
-			return err
-		}
-		args := []string{pteMainPath, strconv.Itoa(key), string(jsonObject), time.Now().String()}
 		wg.Add(1)
 		go func(invokeQueryObjectIndex int, wg *sync.WaitGroup, errCh chan error) {
 			defer wg.Done()
-			err := i.invokeConfig(invokeQueryObjects[invokeQueryObjectIndex].ChannelOpt.Name, args)
+			driverResults, err := i.runTransactionDriver(invokeQueryObjects[invokeQueryObjectIndex])
+			violations := checkSLO(invokeQueryObjects[invokeQueryObjectIndex], driverResults)
+			resultsMutex.Lock()
+			results = append(results, driverResults...)
+			sloViolations = append(sloViolations, violations...)
+			resultsMutex.Unlock()
 			if err != nil {
-				logger.ERROR("Failed to complete invokes/queries " + err.Error())
+				logger.ERROR("Failed to complete invokes/queries on channel " + invokeQueryObjects[invokeQueryObjectIndex].ChannelOpt.Name + ": " + err.Error())
 				checkAndPushError(errCh)
 			}
 			count := 0
@@ -333,13 +350,17 @@ func (i InvokeQueryUIObject) invokeQueryTransactions(invokeQueryObjects []Invoke
 			checkAndPushError(errCh)
 		}
 	}
+	if len(sloViolations) > 0 {
+		close(errCh)
+		return results, errors.Errorf("SLO violated:\n%s", strings.Join(sloViolations, "\n"))
+	}
 	select {
 	case err := <-errCh:
 		close(errCh)
-		return err
+		return results, err
 	default:
 		close(errCh)
-		return nil
+		return results, nil
 	}
 }
 
@@ -384,29 +405,22 @@ func (i InvokeQueryUIObject) fetchMetrics(invokeQueryObject InvokeQueryUIObject,
 		}
 		for _, peerName := range connProfConfig.Channels[channelName].Peers {
 			if connProfConfig.Peers[peerName].MetricsURL != "" {
-				metricsURL, err := url.Parse(connProfConfig.Peers[peerName].MetricsURL)
-				if err != nil {
-					logger.ERROR("Failed to get peer url from connection profile")
-					return nil, err
-				}
-				resp, err := http.Get(fmt.Sprintf("%s/metrics", metricsURL))
+				peerMetrics, err := metrics.Scrape(connProfConfig.Peers[peerName].MetricsURL)
 				if err != nil {
 					logger.ERROR("Error while hitting the endpoint")
 					return nil, err
 				}
-				defer resp.Body.Close()
-				bodyBytes, err := ioutil.ReadAll(resp.Body)
+				height, err := peerMetrics.Value("ledger_blockchain_height", map[string]string{"channel": channelName})
 				if err != nil {
-					return nil, err
+					return nil, errors.Wrapf(err, "failed to get blockchain height of %s", peerName)
+				}
+				var count float64
+				validTransactions := map[string]string{"channel": channelName, "transaction_type": "ENDORSER_TRANSACTION", "validation_code": "VALID"}
+				for _, sample := range peerMetrics.Find("ledger_transaction_count", validTransactions) {
+					if strings.HasPrefix(sample.Labels["chaincode"], invokeQueryObject.ChaincodeID+":") {
+						count += sample.Value
+					}
 				}
-				metrics := string(bodyBytes)
-				blockHeight := strings.Split(metrics, fmt.Sprintf(`ledger_blockchain_height{channel="%s"}`, channelName))
-				height := strings.Split(blockHeight[1], "\n")[0]
-				num, _ := strconv.Atoi(strings.TrimSpace(height))
-				regex := regexp.MustCompile(fmt.Sprintf(`ledger_transaction_count{chaincode="%s:[0-9A-Za-z]+",channel="%s",transaction_type="ENDORSER_TRANSACTION",validation_code="VALID"}`, invokeQueryObject.ChaincodeID, channelName))
-				transactionCount := strings.Split(metrics, fmt.Sprintf(`%s`, regex.FindString(metrics)))
-				trxnCount := strings.Split(transactionCount[1], "\n")[0]
-				count, _ := strconv.Atoi(strings.TrimSpace(trxnCount))
 				peerURL, err := url.Parse(connProfConfig.Peers[peerName].URL)
 				if err != nil {
 					return nil, fmt.Errorf("Failed to get peer url from connection profile")
@@ -414,8 +428,8 @@ func (i InvokeQueryUIObject) fetchMetrics(invokeQueryObject InvokeQueryUIObject,
 				peerAddress := peerURL.Host
 				blockHash, _ := i.fetchBlockHash(peerAddress, orgName[index], peerName, channelName, connProfilePath, invokeQueryObject.TLS)
 				channelBlockchainCount[peerName] = BlockchainCount{
-					peerBlockchainHeight: num,
-					peerTransactionCount: count,
+					peerBlockchainHeight: int(height),
+					peerTransactionCount: int(count),
 					peerBlockHash:        blockHash,
 				}
 			}
@@ -3,17 +3,20 @@ package operations
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/url"
 	"os"
 	"strings"
 
 	"github.com/davecgh/go-spew/spew"
 	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/client"
 	"github.com/hyperledger/fabric-test/tools/operator/connectionprofile"
 	"github.com/hyperledger/fabric-test/tools/operator/logger"
 	"github.com/hyperledger/fabric-test/tools/operator/networkclient"
 	"github.com/hyperledger/fabric-test/tools/operator/paths"
+	"github.com/hyperledger/fabric-test/tools/operator/templates"
 	"github.com/hyperledger/fabric-test/tools/operator/testclient/inputStructs"
 )
 
@@ -116,7 +119,7 @@ func (j JoinBySnapshotUIObject) joinBySnapshotCLI(joinBySnapshotObject JoinBySna
 				}
 				os.Setenv("CORE_PEER_ADDRESS", peerAddress)
 				if os.Getenv("KUBECONFIG") != "" || strings.Contains(peerAddress, "127.0.0.1") {
-					err = j.copySnapshotDirectoryDocker(peerName, joinBySnapshotObject)
+					err = j.copySnapshotDirectoryDocker(peerName, peerAddress, joinBySnapshotObject)
 					if err != nil {
 						return err
 					}
@@ -138,14 +141,52 @@ func (j JoinBySnapshotUIObject) joinBySnapshotCLI(joinBySnapshotObject JoinBySna
 	return nil
 }
 
-func (j JoinBySnapshotUIObject) copySnapshotDirectoryDocker(peer string, joinBySnapshotObject JoinBySnapshotUIObject) error {
+//dockerContainers -- the containers of peer and of the snapshot peer. The operator names the containers of a named
+//network after the network, so they are found by the node label of the container publishing the port of peerAddress
+//and of its docker network; containers without labels are named after their node
+func (j JoinBySnapshotUIObject) dockerContainers(ctx context.Context, cli *client.Client, peer, peerAddress, snapshotPeer string) (string, string, error) {
+
+	_, port, err := net.SplitHostPort(peerAddress)
+	if err != nil {
+		return peer, snapshotPeer, nil
+	}
+	containers, err := cli.ContainerList(ctx, types.ContainerListOptions{Filters: filters.NewArgs(filters.Arg("label", fmt.Sprintf("%s=%s", templates.NodeLabel, peer)))})
+	if err != nil {
+		return "", "", err
+	}
+	for _, found := range containers {
+		for _, published := range found.Ports {
+			if fmt.Sprint(published.PublicPort) != port || len(found.Names) == 0 {
+				continue
+			}
+			snapshots, err := cli.ContainerList(ctx, types.ContainerListOptions{Filters: filters.NewArgs(
+				filters.Arg("label", fmt.Sprintf("%s=%s", templates.NetworkLabel, found.Labels[templates.NetworkLabel])),
+				filters.Arg("label", fmt.Sprintf("%s=%s", templates.NodeLabel, snapshotPeer)),
+			)})
+			if err != nil {
+				return "", "", err
+			}
+			if len(snapshots) == 0 || len(snapshots[0].Names) == 0 {
+				return "", "", fmt.Errorf("no container of the network of %s runs %s", peer, snapshotPeer)
+			}
+			return strings.TrimPrefix(found.Names[0], "/"), strings.TrimPrefix(snapshots[0].Names[0], "/"), nil
+		}
+	}
+	return peer, snapshotPeer, nil
+}
+
+func (j JoinBySnapshotUIObject) copySnapshotDirectoryDocker(peerName, peerAddress string, joinBySnapshotObject JoinBySnapshotUIObject) error {
 
 	ctx := context.Background()
 	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
 	if err != nil {
 		return err
 	}
-	content, _, err := cli.CopyFromContainer(ctx, joinBySnapshotObject.SnapshotPeer, fmt.Sprintf("/var/hyperledger/production/snapshots/completed/%s/%s", joinBySnapshotObject.ChannelOpt.Name, joinBySnapshotObject.SnapshotPath))
+	peer, snapshotPeer, err := j.dockerContainers(ctx, cli, peerName, peerAddress, joinBySnapshotObject.SnapshotPeer)
+	if err != nil {
+		return err
+	}
+	content, _, err := cli.CopyFromContainer(ctx, snapshotPeer, fmt.Sprintf("/var/hyperledger/production/snapshots/completed/%s/%s", joinBySnapshotObject.ChannelOpt.Name, joinBySnapshotObject.SnapshotPath))
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,115 @@
+package operations
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-test/tools/operator/fabricclient"
+	"github.com/hyperledger/fabric-test/tools/operator/logger"
+	"github.com/hyperledger/fabric-test/tools/operator/metrics"
+	"github.com/hyperledger/fabric-test/tools/operator/paths"
+	"github.com/hyperledger/fabric-test/tools/operator/testclient/inputStructs"
+	"github.com/pkg/errors"
+)
+
+//MetricsSnapshot -- metrics of every peer and orderer of the network at a point in time
+type MetricsSnapshot struct {
+	Timestamp  time.Time                  `json:"timestamp"`
+	Components map[string]metrics.Metrics `json:"components"`
+	Errors     map[string]string          `json:"errors,omitempty"`
+}
+
+//metricsURLs -- the metricsURL of every peer and orderer in the connection profiles of the organizations, by name
+func metricsURLs(organizations []inputStructs.Organization) (map[string]string, error) {
+
+	urls := make(map[string]string)
+	for _, organization := range organizations {
+		connProfile, err := fabricclient.ConnectionProfile(organization.ConnProfilePath, organization.Name)
+		if err != nil {
+			return nil, err
+		}
+		for peerName, peer := range connProfile.Peers {
+			if peer.MetricsURL != "" {
+				urls[peerName] = peer.MetricsURL
+			}
+		}
+		for ordererName, orderer := range connProfile.Orderers {
+			if orderer.MetricsURL != "" {
+				urls[ordererName] = orderer.MetricsURL
+			}
+		}
+	}
+	return urls, nil
+}
+
+//TakeMetricsSnapshot -- scrapes the metricsURL of every peer and orderer in the connection profiles
+func TakeMetricsSnapshot(config inputStructs.Config) (MetricsSnapshot, error) {
+
+	snapshot := MetricsSnapshot{
+		Timestamp:  time.Now(),
+		Components: make(map[string]metrics.Metrics),
+		Errors:     make(map[string]string),
+	}
+	urls, err := metricsURLs(config.Organizations)
+	if err != nil {
+		return snapshot, err
+	}
+	for component, metricsURL := range urls {
+		componentMetrics, err := metrics.Scrape(metricsURL)
+		if err != nil {
+			logger.WARNING("Failed to scrape metrics of ", component, ": ", err.Error())
+			snapshot.Errors[component] = err.Error()
+			continue
+		}
+		snapshot.Components[component] = componentMetrics
+	}
+	if len(snapshot.Errors) > 0 {
+		return snapshot, errors.Errorf("failed to scrape metrics of %d of %d components", len(snapshot.Errors), len(urls))
+	}
+	return snapshot, nil
+}
+
+//WriteMetricsSnapshot -- takes a metrics snapshot and writes it to a timestamped file next to the connection profiles
+func WriteMetricsSnapshot(config inputStructs.Config) error {
+	return writeMetricsSnapshot(config, "metrics-snapshot")
+}
+
+//writeMetricsSnapshot -- takes a metrics snapshot and writes it to <name>-<timestamp>.json next to the connection
+//profiles
+func writeMetricsSnapshot(config inputStructs.Config, name string) error {
+
+	snapshot, scrapeErr := TakeMetricsSnapshot(config)
+	if len(snapshot.Components) == 0 && scrapeErr != nil {
+		return scrapeErr
+	}
+	fileName := paths.JoinPath(reportDir(config.Organizations), fmt.Sprintf("%s-%s.json", name, snapshot.Timestamp.Format("20060102-150405")))
+	jsonBytes, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		logger.ERROR("Failed to convert the metrics snapshot to json")
+		return err
+	}
+	err = ioutil.WriteFile(fileName, jsonBytes, 0644)
+	if err != nil {
+		logger.ERROR("Failed to write ", fileName)
+		return err
+	}
+	logger.INFO("Successfully created metrics snapshot ", fileName)
+	return scrapeErr
+}
+
+//runMetricsSnapshot -- writes the metrics snapshot of an invoke/query run at a stage, before or after, when the
+//connection profiles have metrics URLs. Failures are only logged, the run does not depend on its snapshots
+func runMetricsSnapshot(config inputStructs.Config, action, stage string) {
+
+	urls, err := metricsURLs(config.Organizations)
+	if err != nil || len(urls) == 0 {
+		return
+	}
+	err = writeMetricsSnapshot(config, fmt.Sprintf("%s-metrics-%s", strings.ToLower(action), stage))
+	if err != nil {
+		logger.WARNING("Failed to take the metrics snapshot ", stage, " the ", strings.ToLower(action), " run: ", err.Error())
+	}
+}
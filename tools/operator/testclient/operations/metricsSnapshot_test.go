@@ -0,0 +1,52 @@
+package operations
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hyperledger/fabric-test/tools/operator/testclient/inputStructs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunMetricsSnapshot(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "ledger_blockchain_height{channel=\"testorgschannel0\"} 7")
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "metrics")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	connProfile := fmt.Sprintf("peers:\n  peer0-org1:\n    url: grpcs://peer0-org1:7051\n    metricsURL: %s\n", server.URL)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "connection_profile_org1.yaml"), []byte(connProfile), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "connection_profile_org2.yaml"), []byte("peers:\n  peer0-org2:\n    url: grpcs://peer0-org2:7051\n"), 0644))
+
+	config := inputStructs.Config{Organizations: []inputStructs.Organization{{Name: "org2", ConnProfilePath: dir}}}
+	runMetricsSnapshot(config, "Invoke", "before")
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	require.NoError(t, err)
+	assert.Empty(t, files, "no snapshot without metrics URLs")
+
+	config.Organizations = append(config.Organizations, inputStructs.Organization{Name: "org1", ConnProfilePath: dir})
+	runMetricsSnapshot(config, "Invoke", "before")
+	runMetricsSnapshot(config, "Invoke", "after")
+	for _, stage := range []string{"before", "after"} {
+		files, err := filepath.Glob(filepath.Join(dir, "invoke-metrics-"+stage+"-*.json"))
+		require.NoError(t, err)
+		require.Len(t, files, 1, stage)
+		contents, err := ioutil.ReadFile(files[0])
+		require.NoError(t, err)
+		var snapshot MetricsSnapshot
+		require.NoError(t, json.Unmarshal(contents, &snapshot))
+		assert.Contains(t, snapshot.Components, "peer0-org1", stage)
+		assert.Empty(t, snapshot.Errors, stage)
+	}
+}
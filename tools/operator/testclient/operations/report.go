@@ -0,0 +1,178 @@
+package operations
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-test/tools/operator/logger"
+	"github.com/hyperledger/fabric-test/tools/operator/paths"
+	"github.com/hyperledger/fabric-test/tools/operator/testclient/inputStructs"
+)
+
+//LatencySummary -- latency percentiles in milliseconds
+type LatencySummary struct {
+	P50 float64 `json:"p50"`
+	P90 float64 `json:"p90"`
+	P99 float64 `json:"p99"`
+	Max float64 `json:"max"`
+}
+
+//TPSSample -- number of successful transactions completed in one second of a run
+type TPSSample struct {
+	Second int `json:"second"`
+	TPS    int `json:"tps"`
+}
+
+//PerformanceReport -- report of an invoke/query run
+type PerformanceReport struct {
+	Action      string              `json:"action"`
+	GeneratedAt time.Time           `json:"generatedAt"`
+	Results     []TransactionResult `json:"results"`
+}
+
+//summarize -- computes latency percentiles and tps of the driver process once it is done
+func (w *txWorker) summarize() {
+
+	w.result.EndorsementLatency = summarizeLatencies(w.endorsementLatencies)
+	w.result.CommitLatency = summarizeLatencies(w.commitLatencies)
+	w.result.commitLatencies = w.commitLatencies
+	duration := w.result.EndTime.Sub(w.result.StartTime).Seconds()
+	if duration > 0 {
+		w.result.TPS = float64(w.result.Succeeded) / duration
+	}
+	var seconds []int
+	for second := range w.completions {
+		seconds = append(seconds, second)
+	}
+	sort.Ints(seconds)
+	w.result.TPSOverTime = nil
+	for _, second := range seconds {
+		w.result.TPSOverTime = append(w.result.TPSOverTime, TPSSample{Second: second, TPS: w.completions[second]})
+	}
+}
+
+//summarizeLatencies -- computes the p50/p90/p99/max of the latencies in milliseconds
+func summarizeLatencies(latencies []time.Duration) LatencySummary {
+
+	if len(latencies) == 0 {
+		return LatencySummary{}
+	}
+	sorted := append([]time.Duration{}, latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	percentile := func(p float64) float64 {
+		index := int(p*float64(len(sorted))+0.5) - 1
+		if index < 0 {
+			index = 0
+		}
+		if index >= len(sorted) {
+			index = len(sorted) - 1
+		}
+		return milliseconds(sorted[index])
+	}
+	return LatencySummary{
+		P50: percentile(0.50),
+		P90: percentile(0.90),
+		P99: percentile(0.99),
+		Max: milliseconds(sorted[len(sorted)-1]),
+	}
+}
+
+func milliseconds(duration time.Duration) float64 {
+	return float64(duration) / float64(time.Millisecond)
+}
+
+//reportDir -- directory of the connection profiles where the reports are written
+func reportDir(organizations []inputStructs.Organization) string {
+
+	if len(organizations) == 0 {
+		return "."
+	}
+	connProfilePath := organizations[0].ConnProfilePath
+	if strings.HasSuffix(connProfilePath, "yaml") || strings.HasSuffix(connProfilePath, "yml") || strings.HasSuffix(connProfilePath, "json") {
+		return filepath.Dir(connProfilePath)
+	}
+	return connProfilePath
+}
+
+//WritePerformanceReport -- writes the results of a run as json and csv files into dir
+func WritePerformanceReport(action, dir string, results []TransactionResult) error {
+
+	generatedAt := time.Now()
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].ChannelName != results[j].ChannelName {
+			return results[i].ChannelName < results[j].ChannelName
+		}
+		if results[i].OrgName != results[j].OrgName {
+			return results[i].OrgName < results[j].OrgName
+		}
+		return results[i].Process < results[j].Process
+	})
+	baseName := paths.JoinPath(dir, fmt.Sprintf("%s-report-%s", strings.ToLower(action), generatedAt.Format("20060102-150405")))
+
+	report := PerformanceReport{Action: action, GeneratedAt: generatedAt, Results: results}
+	jsonBytes, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		logger.ERROR("Failed to convert the performance report to json")
+		return err
+	}
+	err = ioutil.WriteFile(baseName+".json", jsonBytes, 0644)
+	if err != nil {
+		logger.ERROR("Failed to write ", baseName+".json")
+		return err
+	}
+
+	summary := [][]string{{"channel", "chaincode", "org", "process", "transMode", "invokeType", "submitted", "endorsed", "committed", "succeeded", "failed",
+		"endorseP50Ms", "endorseP90Ms", "endorseP99Ms", "endorseMaxMs", "commitP50Ms", "commitP90Ms", "commitP99Ms", "commitMaxMs", "tps"}}
+	tpsOverTime := [][]string{{"channel", "org", "process", "second", "tps"}}
+	for _, result := range results {
+		summary = append(summary, []string{
+			result.ChannelName, result.ChaincodeName, result.OrgName, strconv.Itoa(result.Process), result.TransMode, result.InvokeType,
+			strconv.Itoa(result.Sent), strconv.Itoa(result.Endorsed), strconv.Itoa(result.Committed), strconv.Itoa(result.Succeeded), strconv.Itoa(result.Failed),
+			formatFloat(result.EndorsementLatency.P50), formatFloat(result.EndorsementLatency.P90), formatFloat(result.EndorsementLatency.P99), formatFloat(result.EndorsementLatency.Max),
+			formatFloat(result.CommitLatency.P50), formatFloat(result.CommitLatency.P90), formatFloat(result.CommitLatency.P99), formatFloat(result.CommitLatency.Max),
+			formatFloat(result.TPS),
+		})
+		for _, sample := range result.TPSOverTime {
+			tpsOverTime = append(tpsOverTime, []string{result.ChannelName, result.OrgName, strconv.Itoa(result.Process), strconv.Itoa(sample.Second), strconv.Itoa(sample.TPS)})
+		}
+	}
+	err = writeCSV(baseName+".csv", summary)
+	if err != nil {
+		return err
+	}
+	err = writeCSV(baseName+"-tps.csv", tpsOverTime)
+	if err != nil {
+		return err
+	}
+	logger.INFO("Successfully created performance report ", baseName+".json")
+	return nil
+}
+
+func writeCSV(fileName string, records [][]string) error {
+
+	file, err := os.Create(fileName)
+	if err != nil {
+		logger.ERROR("Failed to create ", fileName)
+		return err
+	}
+	defer file.Close()
+	writer := csv.NewWriter(file)
+	err = writer.WriteAll(records)
+	if err != nil {
+		logger.ERROR("Failed to write content to ", fileName)
+		return err
+	}
+	return nil
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', 2, 64)
+}
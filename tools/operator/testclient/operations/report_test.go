@@ -0,0 +1,138 @@
+package operations
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummarizeLatencies(t *testing.T) {
+
+	var hundred []time.Duration
+	for i := 100; i >= 1; i-- {
+		hundred = append(hundred, time.Duration(i)*time.Millisecond)
+	}
+	var ten []time.Duration
+	for i := 1; i <= 10; i++ {
+		ten = append(ten, time.Duration(i)*time.Millisecond)
+	}
+
+	tests := []struct {
+		name      string
+		latencies []time.Duration
+		summary   LatencySummary
+	}{
+		{
+			name:    "empty",
+			summary: LatencySummary{},
+		},
+		{
+			name:      "single sample",
+			latencies: []time.Duration{1500 * time.Microsecond},
+			summary:   LatencySummary{P50: 1.5, P90: 1.5, P99: 1.5, Max: 1.5},
+		},
+		{
+			name:      "ten samples",
+			latencies: ten,
+			summary:   LatencySummary{P50: 5, P90: 9, P99: 10, Max: 10},
+		},
+		{
+			name:      "hundred unsorted samples",
+			latencies: hundred,
+			summary:   LatencySummary{P50: 50, P90: 90, P99: 99, Max: 100},
+		},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.summary, summarizeLatencies(test.latencies), test.name)
+	}
+	assert.Equal(t, 100*time.Millisecond, hundred[0], "the latencies are not sorted in place")
+}
+
+func TestWritePerformanceReport(t *testing.T) {
+
+	start := time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC)
+	results := []TransactionResult{
+		{
+			ChannelName: "testorgschannel1", ChaincodeName: "samplecc", OrgName: "org1", Process: 0, TransMode: "constant", InvokeType: "Move",
+			Sent: 3, Endorsed: 3, Committed: 3, Succeeded: 3, TPS: 1.5, StartTime: start, EndTime: start.Add(2 * time.Second),
+		},
+		{
+			ChannelName: "testorgschannel0", ChaincodeName: "samplecc", OrgName: "org2", Process: 1, TransMode: "constant", InvokeType: "Move",
+			Sent: 4, Endorsed: 4, Committed: 4, Succeeded: 3, Failed: 1, MVCCConflicts: 1,
+			EndorsementLatency: LatencySummary{P50: 1.25, P90: 2, P99: 2, Max: 2},
+			CommitLatency:      LatencySummary{P50: 100, P90: 150.5, P99: 150.5, Max: 150.5},
+			TPS:                1.5, TPSOverTime: []TPSSample{{Second: 0, TPS: 2}, {Second: 1, TPS: 1}},
+			StartTime: start, EndTime: start.Add(2 * time.Second), Errors: []string{"transaction abc failed with MVCC_READ_CONFLICT"},
+		},
+		{
+			ChannelName: "testorgschannel0", ChaincodeName: "samplecc", OrgName: "org2", Process: 0, TransMode: "constant", InvokeType: "Move",
+			StartTime: start, EndTime: start,
+		},
+	}
+
+	tests := []struct {
+		name    string
+		results []TransactionResult
+		summary [][]string
+		tps     [][]string
+	}{
+		{
+			name: "empty run",
+		},
+		{
+			name:    "sorted by channel, org and process",
+			results: results,
+			summary: [][]string{
+				{"testorgschannel0", "samplecc", "org2", "0", "constant", "Move", "0", "0", "0", "0", "0", "0.00", "0.00", "0.00", "0.00", "0.00", "0.00", "0.00", "0.00", "0.00"},
+				{"testorgschannel0", "samplecc", "org2", "1", "constant", "Move", "4", "4", "4", "3", "1", "1.25", "2.00", "2.00", "2.00", "100.00", "150.50", "150.50", "150.50", "1.50"},
+				{"testorgschannel1", "samplecc", "org1", "0", "constant", "Move", "3", "3", "3", "3", "0", "0.00", "0.00", "0.00", "0.00", "0.00", "0.00", "0.00", "0.00", "1.50"},
+			},
+			tps: [][]string{
+				{"testorgschannel0", "org2", "1", "0", "2"},
+				{"testorgschannel0", "org2", "1", "1", "1"},
+			},
+		},
+	}
+	for _, test := range tests {
+		dir, err := ioutil.TempDir("", "report")
+		require.NoError(t, err)
+		defer os.RemoveAll(dir)
+		require.NoError(t, WritePerformanceReport("Invoke", dir, test.results), test.name)
+
+		jsonFiles, err := filepath.Glob(filepath.Join(dir, "invoke-report-*.json"))
+		require.NoError(t, err)
+		require.Len(t, jsonFiles, 1, test.name)
+		contents, err := ioutil.ReadFile(jsonFiles[0])
+		require.NoError(t, err)
+		var report PerformanceReport
+		require.NoError(t, json.Unmarshal(contents, &report), test.name)
+		assert.Equal(t, "Invoke", report.Action, test.name)
+		assert.False(t, report.GeneratedAt.IsZero(), test.name)
+		assert.Equal(t, test.results, report.Results, test.name)
+
+		baseName := strings.TrimSuffix(jsonFiles[0], ".json")
+		summaryHeader := []string{"channel", "chaincode", "org", "process", "transMode", "invokeType", "submitted", "endorsed", "committed", "succeeded", "failed",
+			"endorseP50Ms", "endorseP90Ms", "endorseP99Ms", "endorseMaxMs", "commitP50Ms", "commitP90Ms", "commitP99Ms", "commitMaxMs", "tps"}
+		assert.Equal(t, append([][]string{summaryHeader}, test.summary...), readCSV(t, baseName+".csv"), test.name)
+		tpsHeader := []string{"channel", "org", "process", "second", "tps"}
+		assert.Equal(t, append([][]string{tpsHeader}, test.tps...), readCSV(t, baseName+"-tps.csv"), test.name)
+	}
+}
+
+func readCSV(t *testing.T, fileName string) [][]string {
+
+	file, err := os.Open(fileName)
+	require.NoError(t, err)
+	defer file.Close()
+	records, err := csv.NewReader(file).ReadAll()
+	require.NoError(t, err)
+	return records
+}
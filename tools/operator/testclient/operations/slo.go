@@ -0,0 +1,70 @@
+package operations
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-test/tools/operator/testclient/inputStructs"
+)
+
+//sloSet -- whether any threshold of the slo is set
+func sloSet(slo inputStructs.SLO) bool {
+	return slo.MinTPS > 0 || slo.MaxP99CommitLatency > 0 || slo.MaxErrorRate > 0 || slo.MaxMVCCConflictRate > 0
+}
+
+//checkSLO -- compares the combined results of all driver processes of an object against its slo and returns the violated thresholds
+func checkSLO(invokeQueryObject InvokeQueryUIObject, results []TransactionResult) []string {
+
+	var violations []string
+	var sent, succeeded, failed, mvccConflicts int
+	var startTime, endTime time.Time
+	var commitLatencies []time.Duration
+	slo := invokeQueryObject.SLO
+	if !sloSet(slo) {
+		return nil
+	}
+	for _, result := range results {
+		sent += result.Sent
+		succeeded += result.Succeeded
+		failed += result.Failed
+		mvccConflicts += result.MVCCConflicts
+		commitLatencies = append(commitLatencies, result.commitLatencies...)
+		if startTime.IsZero() || result.StartTime.Before(startTime) {
+			startTime = result.StartTime
+		}
+		if result.EndTime.After(endTime) {
+			endTime = result.EndTime
+		}
+	}
+	run := fmt.Sprintf("channel %s, chaincode %s, mode %s", invokeQueryObject.ChannelOpt.Name, invokeQueryObject.ChaincodeID, invokeQueryObject.TransMode)
+	if slo.MinTPS > 0 {
+		var tps float64
+		if duration := endTime.Sub(startTime).Seconds(); duration > 0 {
+			tps = float64(succeeded) / duration
+		}
+		if tps < slo.MinTPS {
+			violations = append(violations, fmt.Sprintf("%s: TPS %.2f is below minTps %.2f", run, tps, slo.MinTPS))
+		}
+	}
+	if slo.MaxP99CommitLatency > 0 {
+		if len(commitLatencies) == 0 {
+			violations = append(violations, fmt.Sprintf("%s: no commit latencies recorded to check maxP99CommitLatency %.2fms, set eventOpt", run, slo.MaxP99CommitLatency))
+		} else if p99 := summarizeLatencies(commitLatencies).P99; p99 > slo.MaxP99CommitLatency {
+			violations = append(violations, fmt.Sprintf("%s: p99 commit latency %.2fms exceeds maxP99CommitLatency %.2fms", run, p99, slo.MaxP99CommitLatency))
+		}
+	}
+	if sent == 0 {
+		return violations
+	}
+	if slo.MaxErrorRate > 0 {
+		if errorRate := float64(failed) / float64(sent); errorRate > slo.MaxErrorRate {
+			violations = append(violations, fmt.Sprintf("%s: error rate %.4f (%d of %d) exceeds maxErrorRate %.4f", run, errorRate, failed, sent, slo.MaxErrorRate))
+		}
+	}
+	if slo.MaxMVCCConflictRate > 0 {
+		if conflictRate := float64(mvccConflicts) / float64(sent); conflictRate > slo.MaxMVCCConflictRate {
+			violations = append(violations, fmt.Sprintf("%s: MVCC conflict rate %.4f (%d of %d) exceeds maxMvccConflictRate %.4f", run, conflictRate, mvccConflicts, sent, slo.MaxMVCCConflictRate))
+		}
+	}
+	return violations
+}
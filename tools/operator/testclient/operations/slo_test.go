@@ -0,0 +1,81 @@
+package operations
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-test/tools/operator/testclient/inputStructs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckSLO(t *testing.T) {
+
+	start := time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC)
+	var latencies []time.Duration
+	for i := 1; i <= 100; i++ {
+		latencies = append(latencies, time.Duration(i)*10*time.Millisecond)
+	}
+	results := []TransactionResult{
+		{Sent: 500, Succeeded: 490, Failed: 10, MVCCConflicts: 5, StartTime: start, EndTime: start.Add(5 * time.Second), commitLatencies: latencies[:50]},
+		{Sent: 500, Succeeded: 500, StartTime: start.Add(time.Second), EndTime: start.Add(10 * time.Second), commitLatencies: latencies[50:]},
+	}
+	run := "channel testorgschannel0, chaincode samplecc, mode constant: "
+
+	tests := []struct {
+		name       string
+		slo        inputStructs.SLO
+		results    []TransactionResult
+		violations []string
+	}{
+		{
+			name:    "no slo",
+			results: results,
+		},
+		{
+			name:    "all thresholds met",
+			slo:     inputStructs.SLO{MinTPS: 99, MaxP99CommitLatency: 990, MaxErrorRate: 0.01, MaxMVCCConflictRate: 0.005},
+			results: results,
+		},
+		{
+			name:    "all thresholds violated",
+			slo:     inputStructs.SLO{MinTPS: 100, MaxP99CommitLatency: 980, MaxErrorRate: 0.009, MaxMVCCConflictRate: 0.004},
+			results: results,
+			violations: []string{
+				run + "TPS 99.00 is below minTps 100.00",
+				run + "p99 commit latency 990.00ms exceeds maxP99CommitLatency 980.00ms",
+				run + "error rate 0.0100 (10 of 1000) exceeds maxErrorRate 0.0090",
+				run + "MVCC conflict rate 0.0050 (5 of 1000) exceeds maxMvccConflictRate 0.0040",
+			},
+		},
+		{
+			name:    "no commit latencies",
+			slo:     inputStructs.SLO{MaxP99CommitLatency: 3000},
+			results: []TransactionResult{{Sent: 10, Succeeded: 10, StartTime: start, EndTime: start.Add(time.Second)}},
+			violations: []string{
+				run + "no commit latencies recorded to check maxP99CommitLatency 3000.00ms, set eventOpt",
+			},
+		},
+		{
+			name:    "nothing sent",
+			slo:     inputStructs.SLO{MinTPS: 1, MaxErrorRate: 0.01, MaxMVCCConflictRate: 0.01},
+			results: []TransactionResult{{StartTime: start, EndTime: start}},
+			violations: []string{
+				run + "TPS 0.00 is below minTps 1.00",
+			},
+		},
+	}
+	for _, test := range tests {
+		object := InvokeQueryUIObject{ChaincodeID: "samplecc", TransMode: "constant", SLO: test.slo}
+		object.ChannelOpt.Name = "testorgschannel0"
+		assert.Equal(t, test.violations, checkSLO(object, test.results), test.name)
+	}
+}
+
+func TestSLOSet(t *testing.T) {
+
+	assert.False(t, sloSet(inputStructs.SLO{}))
+	assert.True(t, sloSet(inputStructs.SLO{MinTPS: 10}))
+	assert.True(t, sloSet(inputStructs.SLO{MaxP99CommitLatency: 3000}))
+	assert.True(t, sloSet(inputStructs.SLO{MaxErrorRate: 0.01}))
+	assert.True(t, sloSet(inputStructs.SLO{MaxMVCCConflictRate: 0.001}))
+}
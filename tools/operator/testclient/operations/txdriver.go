@@ -0,0 +1,737 @@
+package operations
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-protos-go/orderer"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-test/tools/operator/fabricclient"
+	"github.com/hyperledger/fabric-test/tools/operator/logger"
+	"github.com/hyperledger/fabric-test/tools/operator/networkspec"
+	"github.com/hyperledger/fabric-test/tools/operator/paths"
+	"github.com/hyperledger/fabric/protoutil"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+)
+
+const (
+	defaultRequestTimeout = 45 * time.Second
+	defaultEventTimeout   = 120 * time.Second
+	maxRecordedErrors     = 10
+	payloadCharacters     = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+)
+
+//TransactionResult -- outcome of the transactions submitted by one driver process
+type TransactionResult struct {
+	ChannelName         string         `json:"channelName"`
+	ChaincodeName       string         `json:"chaincodeName"`
+	OrgName             string         `json:"orgName"`
+	Process             int            `json:"process"`
+	TransMode           string         `json:"transMode"`
+	InvokeType          string         `json:"invokeType"`
+	Sent                int            `json:"sent"`
+	Endorsed            int            `json:"endorsed"`
+	Committed           int            `json:"committed"`
+	Succeeded           int            `json:"succeeded"`
+	Failed              int            `json:"failed"`
+	EndorsementFailures int            `json:"endorsementFailures"`
+	BroadcastFailures   int            `json:"broadcastFailures"`
+	CommitFailures      int            `json:"commitFailures"`
+	InvokeCheckFailures int            `json:"invokeCheckFailures"`
+	MVCCConflicts       int            `json:"mvccConflicts"`
+	EndorsementLatency  LatencySummary `json:"endorsementLatency"`
+	CommitLatency       LatencySummary `json:"commitLatency"`
+	TPS                 float64        `json:"tps"`
+	TPSOverTime         []TPSSample    `json:"tpsOverTime,omitempty"`
+	StartTime           time.Time      `json:"startTime"`
+	EndTime             time.Time      `json:"endTime"`
+	Errors              []string       `json:"errors,omitempty"`
+	commitLatencies     []time.Duration
+}
+
+//endorser -- a target peer of the driver
+type endorser struct {
+	name   string
+	conn   *grpc.ClientConn
+	client peer.EndorserClient
+}
+
+//txWorker -- state of a single driver process
+type txWorker struct {
+	object               InvokeQueryUIObject
+	orgName              string
+	procID               int
+	identity             *fabricclient.Identity
+	clientCertificates   []tls.Certificate
+	tlsCertHash          []byte
+	endorsers            []endorser
+	ordererConns         []*grpc.ClientConn
+	ordererIndex         int
+	broadcastStream      orderer.AtomicBroadcast_BroadcastClient
+	eventWaiters         map[string]chan peer.TxValidationCode
+	eventMutex           sync.Mutex
+	cancelEvents         context.CancelFunc
+	requestTimeout       time.Duration
+	eventTimeout         time.Duration
+	random               *rand.Rand
+	fixedPayload         string
+	endorsementLatencies []time.Duration
+	commitLatencies      []time.Duration
+	completions          map[int]int
+	result               TransactionResult
+}
+
+//argumentGenerators -- the PTE chaincode argument generators (ccType) of the driver. ccchecker, also used without
+//ccType, sets the keyIdx arguments to the key of the transaction and, for invokes, the keyPayLoad arguments to a
+//payload of payLoadMin to payLoadMax characters, generated once when payLoadType is fixed
+var argumentGenerators = []string{"ccchecker"}
+
+//transModes -- the transMode values of the driver. latency sends a transaction once the previous one is committed
+var transModes = []string{"constant", "burst", "mix", "latency"}
+
+//validateTransactionDriver -- checks that the driver runs the workload of the object as PTE would, rejecting the PTE
+//options it does not implement rather than running a different workload
+func validateTransactionDriver(object InvokeQueryUIObject) error {
+
+	if object.CCType != "" && !contains(argumentGenerators, object.CCType) {
+		return errors.Errorf("ccType %s of chaincode %s is not supported by the transaction driver, it can be %s", object.CCType, object.ChaincodeID, strings.Join(argumentGenerators, ", "))
+	}
+	if !contains(transModes, object.TransMode) {
+		return errors.Errorf("transMode %s on channel %s is not supported by the transaction driver, it can be %s", object.TransMode, object.ChannelOpt.Name, strings.Join(transModes, ", "))
+	}
+	eventType := strings.ToUpper(object.EventOpt.Type)
+	if object.TransMode == "latency" && object.InvokeType != "Query" && (eventType == "" || eventType == "NONE") {
+		return errors.Errorf("transMode latency on channel %s needs eventOpt to wait for the commit of every transaction", object.ChannelOpt.Name)
+	}
+	if object.Snapshot.Enabled {
+		return errors.Errorf("snapshotOptions on channel %s are not supported by the transaction driver, use the snapshotChannel action", object.ChannelOpt.Name)
+	}
+	return nil
+}
+
+//runTransactionDriver -- runs nProcPerOrg driver processes for every organization of the object and collects their results
+func (i InvokeQueryUIObject) runTransactionDriver(invokeQueryObject InvokeQueryUIObject) ([]TransactionResult, error) {
+
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	var results []TransactionResult
+	var driverErr error
+	nProcPerOrg, err := strconv.Atoi(invokeQueryObject.NProcPerOrg)
+	if err != nil || nProcPerOrg < 1 {
+		nProcPerOrg = 1
+	}
+	for _, orgName := range invokeQueryObject.ChannelOpt.OrgName {
+		orgName = strings.TrimSpace(orgName)
+		for procID := 0; procID < nProcPerOrg; procID++ {
+			wg.Add(1)
+			go func(orgName string, procID int) {
+				defer wg.Done()
+				worker, err := newTxWorker(invokeQueryObject, orgName, procID)
+				if err != nil {
+					logger.ERROR("Failed to start transaction driver for ", orgName, " process ", strconv.Itoa(procID), ": ", err.Error())
+					mutex.Lock()
+					driverErr = err
+					mutex.Unlock()
+					return
+				}
+				defer worker.close()
+				result := worker.run()
+				mutex.Lock()
+				results = append(results, result)
+				mutex.Unlock()
+			}(orgName, procID)
+		}
+	}
+	wg.Wait()
+	if driverErr != nil {
+		return results, driverErr
+	}
+	for _, result := range results {
+		if result.InvokeCheckFailures > 0 {
+			return results, errors.Errorf("invoke check failed on channel %s for organization %s: %s", result.ChannelName, result.OrgName, result.Errors[len(result.Errors)-1])
+		}
+	}
+	// with an slo, failures are tolerated and checkSLO decides whether the run passes
+	if sloSet(invokeQueryObject.SLO) {
+		return results, nil
+	}
+	for _, result := range results {
+		if result.Failed > 0 {
+			return results, errors.Errorf("%d of %d transactions failed on channel %s for organization %s", result.Failed, result.Sent, result.ChannelName, result.OrgName)
+		}
+	}
+	return results, nil
+}
+
+//newTxWorker -- connects a driver process to its target peers, orderers and event source
+func newTxWorker(object InvokeQueryUIObject, orgName string, procID int) (*txWorker, error) {
+
+	connProfile, err := fabricclient.ConnectionProfile(object.ConnProfilePath, orgName)
+	if err != nil {
+		return nil, err
+	}
+	identity, err := fabricclient.OrganizationIdentity(connProfile.Organizations[orgName])
+	if err != nil {
+		return nil, err
+	}
+	w := &txWorker{
+		object:         object,
+		orgName:        orgName,
+		procID:         procID,
+		identity:       identity,
+		eventWaiters:   make(map[string]chan peer.TxValidationCode),
+		completions:    make(map[int]int),
+		requestTimeout: durationFromMilliseconds(object.TimeOutOpt.Request, defaultRequestTimeout),
+		eventTimeout:   durationFromMilliseconds(object.EventOpt.TimeOut, defaultEventTimeout),
+		random:         rand.New(rand.NewSource(time.Now().UnixNano() + int64(procID))),
+		result: TransactionResult{
+			ChannelName:   object.ChannelOpt.Name,
+			ChaincodeName: object.ChaincodeID,
+			OrgName:       orgName,
+			Process:       procID,
+			TransMode:     object.TransMode,
+			InvokeType:    object.InvokeType,
+		},
+	}
+	if object.TLS == "clientauth" {
+		currentDir, err := paths.GetCurrentDir()
+		if err != nil {
+			return nil, err
+		}
+		w.clientCertificates, err = fabricclient.ClientTLSCertificate(fmt.Sprintf("%s/crypto-config/peerOrganizations/%s/users/Admin@%s/tls", currentDir, orgName, orgName))
+		if err != nil {
+			return nil, err
+		}
+		w.tlsCertHash = fabricclient.TLSCertHash(w.clientCertificates)
+	}
+	targetPeers, err := w.selectTargetPeers(connProfile)
+	if err != nil {
+		return nil, err
+	}
+	for _, peerName := range targetPeers {
+		peerInfo := connProfile.Peers[peerName]
+		conn, err := fabricclient.Dial(peerInfo.URL, peerInfo.GrpcOptions.SslTarget, peerInfo.TLSCACerts.Pem, w.clientCertificates)
+		if err != nil {
+			w.close()
+			return nil, err
+		}
+		w.endorsers = append(w.endorsers, endorser{name: peerName, conn: conn, client: peer.NewEndorserClient(conn)})
+	}
+	if object.InvokeType == "Query" {
+		return w, nil
+	}
+	ordererNames := connProfile.Channels[object.ChannelOpt.Name].Orderers
+	if len(ordererNames) == 0 {
+		for ordererName := range connProfile.Orderers {
+			ordererNames = append(ordererNames, ordererName)
+		}
+	}
+	for _, ordererName := range ordererNames {
+		ordererInfo := connProfile.Orderers[ordererName]
+		conn, err := fabricclient.Dial(ordererInfo.URL, ordererInfo.GrpcOptions.SslTarget, ordererInfo.TLSCACerts.Pem, w.clientCertificates)
+		if err != nil {
+			logger.WARNING("Skipping orderer ", ordererName, ": ", err.Error())
+			continue
+		}
+		w.ordererConns = append(w.ordererConns, conn)
+	}
+	if len(w.ordererConns) == 0 {
+		w.close()
+		return nil, errors.Errorf("no orderer reachable for channel %s", object.ChannelOpt.Name)
+	}
+	w.ordererIndex = procID % len(w.ordererConns)
+	if err := w.connectBroadcast(); err != nil {
+		w.close()
+		return nil, err
+	}
+	if w.waitForEvents() {
+		if err := w.startEventListener(); err != nil {
+			w.close()
+			return nil, err
+		}
+	}
+	return w, nil
+}
+
+//selectTargetPeers -- resolves targetPeers of the object to the names of peers in the connection profile
+func (w *txWorker) selectTargetPeers(connProfile networkspec.ConnectionProfile) ([]string, error) {
+
+	var targetPeers []string
+	channelPeers := connProfile.Channels[w.object.ChannelOpt.Name].Peers
+	orgPeers := func(orgName string) []string {
+		var peers []string
+		for _, peerName := range connProfile.Organizations[orgName].Peers {
+			if len(channelPeers) == 0 || contains(channelPeers, peerName) {
+				peers = append(peers, peerName)
+			}
+		}
+		return peers
+	}
+	switch strings.ToUpper(w.object.TargetPeers) {
+	case "ORGPEERS":
+		targetPeers = orgPeers(w.orgName)
+	case "ALLPEERS":
+		for _, orgName := range w.object.ChannelOpt.OrgName {
+			targetPeers = append(targetPeers, orgPeers(strings.TrimSpace(orgName))...)
+		}
+	case "ALLANCHORS":
+		for _, orgName := range w.object.ChannelOpt.OrgName {
+			if peers := orgPeers(strings.TrimSpace(orgName)); len(peers) > 0 {
+				targetPeers = append(targetPeers, peers[0])
+			}
+		}
+	case "ROUNDROBIN":
+		if peers := orgPeers(w.orgName); len(peers) > 0 {
+			targetPeers = append(targetPeers, peers[w.procID%len(peers)])
+		}
+	case "LIST":
+		for _, peers := range w.object.ListOpt {
+			targetPeers = append(targetPeers, peers...)
+		}
+	case "DISCOVERY":
+		logger.WARNING("Service discovery is not supported by the go transaction driver, using AllAnchors")
+		for _, orgName := range w.object.ChannelOpt.OrgName {
+			if peers := orgPeers(strings.TrimSpace(orgName)); len(peers) > 0 {
+				targetPeers = append(targetPeers, peers[0])
+			}
+		}
+	default:
+		if peers := orgPeers(w.orgName); len(peers) > 0 {
+			targetPeers = append(targetPeers, peers[0])
+		}
+	}
+	for _, peerName := range targetPeers {
+		if _, ok := connProfile.Peers[peerName]; !ok {
+			return nil, errors.Errorf("peer %s not found in connection profile of %s", peerName, w.orgName)
+		}
+	}
+	if len(targetPeers) == 0 {
+		return nil, errors.Errorf("no target peers found for organization %s on channel %s", w.orgName, w.object.ChannelOpt.Name)
+	}
+	return targetPeers, nil
+}
+
+func (w *txWorker) waitForEvents() bool {
+	eventType := strings.ToUpper(w.object.EventOpt.Type)
+	return eventType != "" && eventType != "NONE"
+}
+
+//connectBroadcast -- opens the broadcast stream to the current orderer
+func (w *txWorker) connectBroadcast() error {
+	stream, err := orderer.NewAtomicBroadcastClient(w.ordererConns[w.ordererIndex]).Broadcast(context.Background())
+	if err != nil {
+		return errors.Wrap(err, "failed to open broadcast stream")
+	}
+	w.broadcastStream = stream
+	return nil
+}
+
+//startEventListener -- listens for filtered blocks on the first target peer and notifies waiting transactions
+func (w *txWorker) startEventListener() error {
+
+	seekInfo := &orderer.SeekInfo{
+		Start:    &orderer.SeekPosition{Type: &orderer.SeekPosition_Newest{Newest: &orderer.SeekNewest{}}},
+		Stop:     &orderer.SeekPosition{Type: &orderer.SeekPosition_Specified{Specified: &orderer.SeekSpecified{Number: ^uint64(0)}}},
+		Behavior: orderer.SeekInfo_BLOCK_UNTIL_READY,
+	}
+	envelope, err := protoutil.CreateSignedEnvelopeWithTLSBinding(common.HeaderType_DELIVER_SEEK_INFO, w.object.ChannelOpt.Name, w.identity, seekInfo, 0, 0, w.tlsCertHash)
+	if err != nil {
+		return errors.Wrap(err, "failed to create deliver envelope")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := peer.NewDeliverClient(w.endorsers[0].conn).DeliverFiltered(ctx)
+	if err != nil {
+		cancel()
+		return errors.Wrapf(err, "failed to connect to event service of %s", w.endorsers[0].name)
+	}
+	if err := stream.Send(envelope); err != nil {
+		cancel()
+		return errors.Wrapf(err, "failed to register for events on %s", w.endorsers[0].name)
+	}
+	w.cancelEvents = cancel
+	go func() {
+		for {
+			response, err := stream.Recv()
+			if err != nil {
+				return
+			}
+			filteredBlock, ok := response.Type.(*peer.DeliverResponse_FilteredBlock)
+			if !ok {
+				continue
+			}
+			w.eventMutex.Lock()
+			for _, tx := range filteredBlock.FilteredBlock.FilteredTransactions {
+				if waiter, ok := w.eventWaiters[tx.Txid]; ok {
+					waiter <- tx.TxValidationCode
+					delete(w.eventWaiters, tx.Txid)
+				}
+			}
+			w.eventMutex.Unlock()
+		}
+	}()
+	return nil
+}
+
+//run -- submits transactions until nRequest transactions are sent or runDur seconds have elapsed
+func (w *txWorker) run() TransactionResult {
+
+	nRequest, _ := strconv.Atoi(w.object.NRequest)
+	runDur, _ := strconv.Atoi(w.object.RunDur)
+	if nRequest <= 0 && runDur <= 0 {
+		nRequest = 1
+	}
+	keyStart, _ := strconv.Atoi(w.object.CCOpt.KeyStart)
+	w.result.StartTime = time.Now()
+	deadline := w.result.StartTime.Add(time.Duration(runDur) * time.Second)
+	lastKey := -1
+	for n := 0; ; n++ {
+		if nRequest > 0 && n >= nRequest {
+			break
+		}
+		if nRequest <= 0 && time.Now().After(deadline) {
+			break
+		}
+		key := keyStart + n
+		if w.object.InvokeType == "Query" {
+			w.record(w.query(key))
+		} else {
+			w.record(w.invoke(key))
+			lastKey = key
+			if w.object.TransMode == "mix" {
+				time.Sleep(w.interval())
+				w.record(w.query(key))
+			}
+		}
+		time.Sleep(w.interval())
+	}
+	w.result.EndTime = time.Now()
+	if w.object.InvokeCheck == "TRUE" && lastKey >= 0 {
+		w.invokeCheck(lastKey)
+	}
+	w.summarize()
+	return w.result
+}
+
+//interval -- time to wait before the next transaction based on transMode
+func (w *txWorker) interval() time.Duration {
+
+	millis := func(value string) int {
+		v, _ := strconv.Atoi(value)
+		return v
+	}
+	switch w.object.TransMode {
+	case "constant":
+		freq := millis(w.object.ConstOpt.ConstFreq)
+		if dev := millis(w.object.ConstOpt.DevFreq); dev > 0 {
+			freq += w.random.Intn(2*dev+1) - dev
+		}
+		if freq < 0 {
+			freq = 0
+		}
+		return time.Duration(freq) * time.Millisecond
+	case "burst":
+		dur0 := millis(w.object.BurstOpt.BurstDur0)
+		dur1 := millis(w.object.BurstOpt.BurstDur1)
+		if dur0+dur1 <= 0 {
+			return time.Duration(millis(w.object.BurstOpt.BurstFreq0)) * time.Millisecond
+		}
+		elapsed := int(time.Since(w.result.StartTime)/time.Millisecond) % (dur0 + dur1)
+		if elapsed < dur0 {
+			return time.Duration(millis(w.object.BurstOpt.BurstFreq0)) * time.Millisecond
+		}
+		return time.Duration(millis(w.object.BurstOpt.BurstFreq1)) * time.Millisecond
+	case "mix":
+		return time.Duration(millis(w.object.MixOpt.MixFreq)) * time.Millisecond
+	}
+	return 0
+}
+
+//txError -- a failed transaction together with the stage it failed in
+type txError struct {
+	stage string
+	err   error
+}
+
+func (w *txWorker) record(txErr *txError) {
+	w.result.Sent++
+	if txErr == nil {
+		w.result.Succeeded++
+		w.completions[int(time.Since(w.result.StartTime)/time.Second)]++
+		return
+	}
+	w.result.Failed++
+	switch txErr.stage {
+	case "endorse":
+		w.result.EndorsementFailures++
+	case "broadcast":
+		w.result.BroadcastFailures++
+	case "commit":
+		w.result.CommitFailures++
+	}
+	if len(w.result.Errors) < maxRecordedErrors {
+		w.result.Errors = append(w.result.Errors, txErr.err.Error())
+	}
+}
+
+//invokeCheck -- queries the key of the last invoke once the invokes are done, as invokeCheck of PTE does
+func (w *txWorker) invokeCheck(key int) {
+
+	proposal, _, err := w.createProposal(w.transactionArgs("query", key))
+	if err == nil {
+		_, err = w.endorse(proposal, []endorser{w.endorsers[key%len(w.endorsers)]})
+	}
+	if err != nil {
+		w.result.InvokeCheckFailures++
+		w.result.Errors = append(w.result.Errors, fmt.Sprintf("invoke check of key %d: %s", key, err))
+	}
+}
+
+//transactionArgs -- builds the chaincode arguments for the n-th transaction with the ccchecker argument generator
+func (w *txWorker) transactionArgs(parameterName string, key int) [][]byte {
+
+	parameters := w.object.Parameters[parameterName]
+	args := append([]string{}, parameters.Args...)
+	for _, index := range w.object.CCOpt.KeyIdx {
+		if index >= 0 && index < len(args) {
+			args[index] = fmt.Sprintf("key_%s_%d_%d", w.orgName, w.procID, key)
+		}
+	}
+	if parameterName != "query" {
+		for _, index := range w.object.CCOpt.KeyPayLoad {
+			if index >= 0 && index < len(args) {
+				args[index] = w.payload()
+			}
+		}
+	}
+	ccArgs := [][]byte{[]byte(parameters.Fcn)}
+	for _, arg := range args {
+		ccArgs = append(ccArgs, []byte(arg))
+	}
+	return ccArgs
+}
+
+//payload -- random payload sized according to payLoadMin, payLoadMax and payLoadType
+func (w *txWorker) payload() string {
+
+	fixed := strings.EqualFold(w.object.CCOpt.PayLoadType, "fixed")
+	if fixed && w.fixedPayload != "" {
+		return w.fixedPayload
+	}
+	payLoadMin, _ := strconv.Atoi(w.object.CCOpt.PayLoadMin)
+	payLoadMax, _ := strconv.Atoi(w.object.CCOpt.PayLoadMax)
+	size := payLoadMin
+	if !fixed && payLoadMax > payLoadMin {
+		size = payLoadMin + w.random.Intn(payLoadMax-payLoadMin+1)
+	}
+	payload := make([]byte, size)
+	for index := range payload {
+		payload[index] = payloadCharacters[w.random.Intn(len(payloadCharacters))]
+	}
+	if fixed {
+		w.fixedPayload = string(payload)
+	}
+	return string(payload)
+}
+
+//createProposal -- creates a chaincode proposal for the given arguments. Peers do not use the type of the chaincode
+//spec of an invocation, so it is GOLANG whatever the language of the chaincode
+func (w *txWorker) createProposal(args [][]byte) (*peer.Proposal, string, error) {
+
+	invocationSpec := &peer.ChaincodeInvocationSpec{
+		ChaincodeSpec: &peer.ChaincodeSpec{
+			Type:        peer.ChaincodeSpec_GOLANG,
+			ChaincodeId: &peer.ChaincodeID{Name: w.object.ChaincodeID},
+			Input:       &peer.ChaincodeInput{Args: args},
+		},
+	}
+	creator, err := w.identity.Serialize()
+	if err != nil {
+		return nil, "", err
+	}
+	return protoutil.CreateChaincodeProposal(common.HeaderType_ENDORSER_TRANSACTION, w.object.ChannelOpt.Name, invocationSpec, creator)
+}
+
+//endorse -- sends the signed proposal to the given endorsers and validates their responses
+func (w *txWorker) endorse(proposal *peer.Proposal, endorsers []endorser) ([]*peer.ProposalResponse, error) {
+
+	signedProposal, err := protoutil.GetSignedProposal(proposal, w.identity)
+	if err != nil {
+		return nil, err
+	}
+	responses := make([]*peer.ProposalResponse, len(endorsers))
+	errs := make([]error, len(endorsers))
+	var wg sync.WaitGroup
+	for index := range endorsers {
+		wg.Add(1)
+		go func(index int) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), w.requestTimeout)
+			defer cancel()
+			response, err := endorsers[index].client.ProcessProposal(ctx, signedProposal)
+			if err != nil {
+				errs[index] = errors.Wrapf(err, "endorsement failed on %s", endorsers[index].name)
+				return
+			}
+			if response.Response == nil || response.Response.Status < 200 || response.Response.Status >= 400 {
+				errs[index] = errors.Errorf("endorsement failed on %s: %v", endorsers[index].name, response.Response)
+				return
+			}
+			responses[index] = response
+		}(index)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return responses, nil
+}
+
+//invoke -- endorses, orders and optionally waits for the commit of a single transaction
+func (w *txWorker) invoke(key int) *txError {
+
+	startTime := time.Now()
+	proposal, txID, err := w.createProposal(w.transactionArgs("move", key))
+	if err != nil {
+		return &txError{stage: "endorse", err: err}
+	}
+	responses, err := w.endorse(proposal, w.endorsers)
+	if err != nil {
+		return &txError{stage: "endorse", err: err}
+	}
+	w.result.Endorsed++
+	w.endorsementLatencies = append(w.endorsementLatencies, time.Since(startTime))
+	envelope, err := protoutil.CreateSignedTx(proposal, w.identity, responses...)
+	if err != nil {
+		return &txError{stage: "endorse", err: err}
+	}
+	var waiter chan peer.TxValidationCode
+	if w.waitForEvents() {
+		waiter = make(chan peer.TxValidationCode, 1)
+		w.eventMutex.Lock()
+		w.eventWaiters[txID] = waiter
+		w.eventMutex.Unlock()
+	}
+	broadcastTime := time.Now()
+	if err := w.broadcast(envelope); err != nil {
+		w.removeWaiter(txID)
+		return &txError{stage: "broadcast", err: err}
+	}
+	if waiter == nil {
+		return nil
+	}
+	select {
+	case code := <-waiter:
+		if code == peer.TxValidationCode_MVCC_READ_CONFLICT {
+			w.result.MVCCConflicts++
+		}
+		if code != peer.TxValidationCode_VALID {
+			return &txError{stage: "commit", err: errors.Errorf("transaction %s committed with status %s", txID, code)}
+		}
+		w.result.Committed++
+		w.commitLatencies = append(w.commitLatencies, time.Since(broadcastTime))
+	case <-time.After(w.eventTimeout):
+		w.removeWaiter(txID)
+		return &txError{stage: "commit", err: errors.Errorf("timed out waiting for commit of transaction %s", txID)}
+	}
+	return nil
+}
+
+//query -- evaluates a query on one of the target peers
+func (w *txWorker) query(key int) *txError {
+
+	startTime := time.Now()
+	proposal, _, err := w.createProposal(w.transactionArgs("query", key))
+	if err != nil {
+		return &txError{stage: "endorse", err: err}
+	}
+	target := w.endorsers[key%len(w.endorsers)]
+	if _, err := w.endorse(proposal, []endorser{target}); err != nil {
+		return &txError{stage: "endorse", err: err}
+	}
+	w.result.Endorsed++
+	w.endorsementLatencies = append(w.endorsementLatencies, time.Since(startTime))
+	return nil
+}
+
+//broadcast -- sends the envelope to the orderer, failing over to the next orderer when ordererFailover is enabled
+func (w *txWorker) broadcast(envelope *common.Envelope) error {
+
+	err := w.sendEnvelope(envelope)
+	if err == nil || w.object.OrdererFailover != "TRUE" {
+		return err
+	}
+	for attempt := 1; attempt < len(w.ordererConns); attempt++ {
+		w.ordererIndex = (w.ordererIndex + 1) % len(w.ordererConns)
+		logger.WARNING("Broadcast failed, failing over to orderer ", strconv.Itoa(w.ordererIndex))
+		if err = w.connectBroadcast(); err != nil {
+			continue
+		}
+		if err = w.sendEnvelope(envelope); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+func (w *txWorker) sendEnvelope(envelope *common.Envelope) error {
+	if err := w.broadcastStream.Send(envelope); err != nil {
+		return errors.Wrap(err, "failed to send transaction to orderer")
+	}
+	response, err := w.broadcastStream.Recv()
+	if err != nil {
+		return errors.Wrap(err, "failed to receive broadcast response")
+	}
+	if response.Status != common.Status_SUCCESS {
+		return errors.Errorf("orderer returned %s: %s", response.Status, response.Info)
+	}
+	return nil
+}
+
+func (w *txWorker) removeWaiter(txID string) {
+	w.eventMutex.Lock()
+	delete(w.eventWaiters, txID)
+	w.eventMutex.Unlock()
+}
+
+//close -- closes all the connections of the driver process
+func (w *txWorker) close() {
+	if w.cancelEvents != nil {
+		w.cancelEvents()
+	}
+	if w.broadcastStream != nil {
+		w.broadcastStream.CloseSend()
+	}
+	for _, e := range w.endorsers {
+		e.conn.Close()
+	}
+	for _, conn := range w.ordererConns {
+		conn.Close()
+	}
+}
+
+func durationFromMilliseconds(value string, defaultDuration time.Duration) time.Duration {
+	millis, err := strconv.Atoi(value)
+	if err != nil || millis <= 0 {
+		return defaultDuration
+	}
+	return time.Duration(millis) * time.Millisecond
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
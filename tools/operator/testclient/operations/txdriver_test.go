@@ -0,0 +1,334 @@
+package operations
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	cryptorand "crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-protos-go/orderer"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-test/tools/operator/fabricclient"
+	"github.com/hyperledger/fabric-test/tools/operator/testclient/inputStructs"
+	"github.com/hyperledger/fabric/protoutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+func TestTransactionArgsDefaultFcn(t *testing.T) {
+
+	var i InvokeQueryUIObject
+	organizations := []inputStructs.Organization{{Name: "org1", ConnProfilePath: "connection-profile"}}
+	invokeObject := inputStructs.InvokeQuery{
+		ChannelName:   "testorgschannel0",
+		ChaincodeName: "samplecc",
+		Organizations: "org1",
+		TxnOptions:    []inputStructs.TransactionOptions{{Mode: "constant"}},
+		CCOptions:     inputStructs.CCOptions{CCType: "ccchecker", KeyIdx: []int{1}},
+		Args:          "put,a1,1",
+	}
+	objects := i.createInvokeQueryObjectForOrg([]string{"org1"}, "Move", "clientauth", organizations, invokeObject)
+	require.Len(t, objects, 1)
+	w := &txWorker{object: objects[0], orgName: "org1", random: rand.New(rand.NewSource(1))}
+	assert.Equal(t, [][]byte{[]byte("invoke"), []byte("put"), []byte("key_org1_0_5"), []byte("1")}, w.transactionArgs("move", 5))
+	assert.Equal(t, [][]byte{[]byte("invoke"), []byte("put"), []byte("key_org1_0_5"), []byte("1")}, w.transactionArgs("query", 5))
+
+	invokeObject.Fcn = "move"
+	objects = i.createInvokeQueryObjectForOrg([]string{"org1"}, "Move", "clientauth", organizations, invokeObject)
+	w.object = objects[0]
+	assert.Equal(t, []byte("move"), w.transactionArgs("move", 5)[0])
+}
+
+func TestValidateTransactionDriver(t *testing.T) {
+
+	object := InvokeQueryUIObject{ChaincodeID: "samplecc", TransMode: "constant", CCType: "ccchecker", ChannelOpt: ChannelOptions{Name: "testorgschannel0"}}
+	assert.NoError(t, validateTransactionDriver(object))
+	object.CCType = ""
+	assert.NoError(t, validateTransactionDriver(object))
+
+	object.CCType = "marblescc"
+	assert.EqualError(t, validateTransactionDriver(object), "ccType marblescc of chaincode samplecc is not supported by the transaction driver, it can be ccchecker")
+	object.CCType = "ccchecker"
+	object.TransMode = "Constant"
+	assert.EqualError(t, validateTransactionDriver(object), "transMode Constant on channel testorgschannel0 is not supported by the transaction driver, it can be constant, burst, mix, latency")
+	object.TransMode = "latency"
+	assert.EqualError(t, validateTransactionDriver(object), "transMode latency on channel testorgschannel0 needs eventOpt to wait for the commit of every transaction")
+	object.EventOpt.Type = "FilteredBlock"
+	assert.NoError(t, validateTransactionDriver(object))
+	object.Snapshot.Enabled = true
+	assert.EqualError(t, validateTransactionDriver(object), "snapshotOptions on channel testorgschannel0 are not supported by the transaction driver, use the snapshotChannel action")
+}
+
+func TestTransactionArgs(t *testing.T) {
+
+	w := &txWorker{orgName: "org2", procID: 1, random: rand.New(rand.NewSource(1))}
+	w.object.Parameters = map[string]Parameters{
+		"move":  {Fcn: "invoke", Args: []string{"put", "a1", "1"}},
+		"query": {Fcn: "invoke", Args: []string{"get", "a1"}},
+	}
+	w.object.CCOpt = CCOptions{KeyIdx: []int{1, 5}, KeyPayLoad: []int{2}, PayLoadMin: "4", PayLoadMax: "8"}
+	for key := 0; key < 20; key++ {
+		args := w.transactionArgs("move", key)
+		require.Len(t, args, 4)
+		assert.Equal(t, fmt.Sprintf("key_org2_1_%d", key), string(args[2]))
+		assert.True(t, len(args[3]) >= 4 && len(args[3]) <= 8, "payload %q", args[3])
+	}
+	assert.Equal(t, [][]byte{[]byte("invoke"), []byte("get"), []byte("key_org2_1_7")}, w.transactionArgs("query", 7))
+	assert.Equal(t, []string{"put", "a1", "1"}, w.object.Parameters["move"].Args)
+
+	w.object.CCOpt.PayLoadType = "Fixed"
+	payload := w.transactionArgs("move", 0)[3]
+	assert.Len(t, payload, 4)
+	assert.Equal(t, payload, w.transactionArgs("move", 1)[3])
+}
+
+func TestInterval(t *testing.T) {
+
+	w := &txWorker{random: rand.New(rand.NewSource(1))}
+	w.result.StartTime = time.Now()
+	w.object.TransMode = "constant"
+	w.object.ConstOpt = ConstantOptions{ConstFreq: "100"}
+	assert.Equal(t, 100*time.Millisecond, w.interval())
+	w.object.ConstOpt.DevFreq = "20"
+	for n := 0; n < 20; n++ {
+		interval := w.interval()
+		assert.True(t, interval >= 80*time.Millisecond && interval <= 120*time.Millisecond, "interval %s", interval)
+	}
+	w.object.ConstOpt = ConstantOptions{ConstFreq: "10", DevFreq: "50"}
+	for n := 0; n < 20; n++ {
+		assert.True(t, w.interval() >= 0)
+	}
+
+	w.object.TransMode = "burst"
+	w.object.BurstOpt = BurstOptions{BurstFreq0: "10", BurstDur0: "60000", BurstFreq1: "500", BurstDur1: "60000"}
+	assert.Equal(t, 10*time.Millisecond, w.interval())
+	w.result.StartTime = time.Now().Add(-90 * time.Second)
+	assert.Equal(t, 500*time.Millisecond, w.interval())
+	w.object.BurstOpt = BurstOptions{BurstFreq0: "30"}
+	assert.Equal(t, 30*time.Millisecond, w.interval())
+
+	w.object.TransMode = "mix"
+	w.object.MixOpt = MixOptions{MixFreq: "250"}
+	assert.Equal(t, 250*time.Millisecond, w.interval())
+	w.object.TransMode = "latency"
+	assert.Equal(t, time.Duration(0), w.interval())
+}
+
+func TestDurationFromMilliseconds(t *testing.T) {
+
+	for _, test := range []struct {
+		value    string
+		expected time.Duration
+	}{
+		{"", time.Minute},
+		{"abc", time.Minute},
+		{"0", time.Minute},
+		{"-5", time.Minute},
+		{"250", 250 * time.Millisecond},
+		{"240000", 4 * time.Minute},
+	} {
+		assert.Equal(t, test.expected, durationFromMilliseconds(test.value, time.Minute), test.value)
+	}
+}
+
+func TestRecord(t *testing.T) {
+
+	w := &txWorker{completions: make(map[int]int)}
+	w.result.StartTime = time.Now()
+	w.record(nil)
+	w.record(nil)
+	w.record(&txError{stage: "endorse", err: errors.New("endorsement failed")})
+	w.record(&txError{stage: "broadcast", err: errors.New("broadcast failed")})
+	w.record(&txError{stage: "commit", err: errors.New("commit failed")})
+	assert.Equal(t, 5, w.result.Sent)
+	assert.Equal(t, 2, w.result.Succeeded)
+	assert.Equal(t, 3, w.result.Failed)
+	assert.Equal(t, 1, w.result.EndorsementFailures)
+	assert.Equal(t, 1, w.result.BroadcastFailures)
+	assert.Equal(t, 1, w.result.CommitFailures)
+	assert.Equal(t, map[int]int{0: 2}, w.completions)
+	assert.Equal(t, []string{"endorsement failed", "broadcast failed", "commit failed"}, w.result.Errors)
+
+	for n := 0; n < 2*maxRecordedErrors; n++ {
+		w.record(&txError{stage: "commit", err: errors.New("commit failed")})
+	}
+	assert.Len(t, w.result.Errors, maxRecordedErrors)
+	assert.Equal(t, 23, w.result.Failed)
+}
+
+//fakeEndorser -- endorses every proposal with status, recording the chaincode arguments of the proposals
+type fakeEndorser struct {
+	identity *fabricclient.Identity
+	status   int32
+	mutex    sync.Mutex
+	args     [][]string
+}
+
+func (e *fakeEndorser) ProcessProposal(ctx context.Context, signedProposal *peer.SignedProposal, opts ...grpc.CallOption) (*peer.ProposalResponse, error) {
+
+	proposal, err := protoutil.UnmarshalProposal(signedProposal.ProposalBytes)
+	if err != nil {
+		return nil, err
+	}
+	proposalPayload, err := protoutil.UnmarshalChaincodeProposalPayload(proposal.Payload)
+	if err != nil {
+		return nil, err
+	}
+	invocationSpec := &peer.ChaincodeInvocationSpec{}
+	if err := proto.Unmarshal(proposalPayload.Input, invocationSpec); err != nil {
+		return nil, err
+	}
+	var args []string
+	for _, arg := range invocationSpec.ChaincodeSpec.Input.Args {
+		args = append(args, string(arg))
+	}
+	e.mutex.Lock()
+	e.args = append(e.args, args)
+	e.mutex.Unlock()
+	if e.status >= 400 {
+		return &peer.ProposalResponse{Response: &peer.Response{Status: e.status, Message: "chaincode error"}}, nil
+	}
+	return protoutil.CreateProposalResponse(proposal.Header, proposal.Payload, &peer.Response{Status: e.status}, nil, nil, invocationSpec.ChaincodeSpec.ChaincodeId, e.identity)
+}
+
+//fakeBroadcast -- accepts every envelope and commits its transaction with the next of codes
+type fakeBroadcast struct {
+	grpc.ClientStream
+	worker *txWorker
+	codes  []peer.TxValidationCode
+}
+
+func (b *fakeBroadcast) Send(envelope *common.Envelope) error {
+
+	payload, err := protoutil.UnmarshalPayload(envelope.Payload)
+	if err != nil {
+		return err
+	}
+	channelHeader, err := protoutil.UnmarshalChannelHeader(payload.Header.ChannelHeader)
+	if err != nil {
+		return err
+	}
+	code := b.codes[0]
+	b.codes = b.codes[1:]
+	b.worker.eventMutex.Lock()
+	defer b.worker.eventMutex.Unlock()
+	if waiter, ok := b.worker.eventWaiters[channelHeader.TxId]; ok {
+		waiter <- code
+		delete(b.worker.eventWaiters, channelHeader.TxId)
+	}
+	return nil
+}
+
+func (b *fakeBroadcast) Recv() (*orderer.BroadcastResponse, error) {
+	return &orderer.BroadcastResponse{Status: common.Status_SUCCESS}, nil
+}
+
+func testIdentity(t *testing.T) *fabricclient.Identity {
+
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), cryptorand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "Admin@org1"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(cryptorand.Reader, template, template, &privateKey.PublicKey, privateKey)
+	require.NoError(t, err)
+	keyBytes, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	require.NoError(t, err)
+	identity, err := fabricclient.NewIdentity("org1-mspid",
+		string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyBytes})))
+	require.NoError(t, err)
+	return identity
+}
+
+func testWorker(t *testing.T, object InvokeQueryUIObject, endorserClient peer.EndorserClient, codes ...peer.TxValidationCode) *txWorker {
+
+	w := &txWorker{
+		object:         object,
+		orgName:        "org1",
+		identity:       testIdentity(t),
+		endorsers:      []endorser{{name: "peer0-org1", client: endorserClient}},
+		eventWaiters:   make(map[string]chan peer.TxValidationCode),
+		completions:    make(map[int]int),
+		requestTimeout: time.Second,
+		eventTimeout:   time.Second,
+		random:         rand.New(rand.NewSource(1)),
+	}
+	w.broadcastStream = &fakeBroadcast{worker: w, codes: codes}
+	return w
+}
+
+func TestTxWorkerRun(t *testing.T) {
+
+	object := InvokeQueryUIObject{
+		ChaincodeID: "samplecc",
+		InvokeCheck: "TRUE",
+		InvokeType:  "Move",
+		TransMode:   "constant",
+		NRequest:    "3",
+		ChannelOpt:  ChannelOptions{Name: "testorgschannel0"},
+		EventOpt:    EventOptions{Type: "FilteredBlock"},
+		CCOpt:       CCOptions{KeyIdx: []int{1}, KeyStart: "10"},
+		Parameters: map[string]Parameters{
+			"move":  {Fcn: "invoke", Args: []string{"put", "a1", "1"}},
+			"query": {Fcn: "invoke", Args: []string{"get", "a1"}},
+		},
+	}
+	identity := testIdentity(t)
+	endorserClient := &fakeEndorser{identity: identity, status: 200}
+	w := testWorker(t, object, endorserClient, peer.TxValidationCode_VALID, peer.TxValidationCode_MVCC_READ_CONFLICT, peer.TxValidationCode_VALID)
+	result := w.run()
+	assert.Equal(t, 3, result.Sent)
+	assert.Equal(t, 3, result.Endorsed)
+	assert.Equal(t, 2, result.Committed)
+	assert.Equal(t, 2, result.Succeeded)
+	assert.Equal(t, 1, result.Failed)
+	assert.Equal(t, 1, result.CommitFailures)
+	assert.Equal(t, 1, result.MVCCConflicts)
+	assert.Equal(t, 0, result.InvokeCheckFailures)
+	assert.Len(t, w.commitLatencies, 2)
+	require.Len(t, result.Errors, 1)
+	assert.Contains(t, result.Errors[0], "committed with status MVCC_READ_CONFLICT")
+	assert.Equal(t, [][]string{
+		{"invoke", "put", "key_org1_0_10", "1"},
+		{"invoke", "put", "key_org1_0_11", "1"},
+		{"invoke", "put", "key_org1_0_12", "1"},
+		{"invoke", "get", "key_org1_0_12"},
+	}, endorserClient.args)
+
+	endorserClient = &fakeEndorser{identity: identity, status: 500}
+	w = testWorker(t, object, endorserClient)
+	result = w.run()
+	assert.Equal(t, 3, result.Sent)
+	assert.Equal(t, 0, result.Endorsed)
+	assert.Equal(t, 3, result.EndorsementFailures)
+	assert.Equal(t, 1, result.InvokeCheckFailures)
+	assert.True(t, strings.HasPrefix(result.Errors[len(result.Errors)-1], "invoke check of key 12: endorsement failed on peer0-org1"), result.Errors[len(result.Errors)-1])
+
+	object.EventOpt = EventOptions{}
+	object.InvokeCheck = "FALSE"
+	endorserClient = &fakeEndorser{identity: identity, status: 200}
+	w = testWorker(t, object, endorserClient, peer.TxValidationCode_VALID, peer.TxValidationCode_VALID, peer.TxValidationCode_VALID)
+	result = w.run()
+	assert.Equal(t, 3, result.Succeeded)
+	assert.Equal(t, 0, result.Committed)
+	assert.Len(t, endorserClient.args, 3)
+}
@@ -6,6 +6,8 @@ import (
 	"strings"
 
 	"github.com/hyperledger/fabric-test/tools/operator/logger"
+	"github.com/hyperledger/fabric-test/tools/operator/networkstate"
+	"github.com/hyperledger/fabric-test/tools/operator/plan"
 	"github.com/hyperledger/fabric-test/tools/operator/testclient/inputStructs"
 	"github.com/hyperledger/fabric-test/tools/operator/testclient/operations"
 	"github.com/pkg/errors"
@@ -37,29 +39,38 @@ func GetInputData(inputFilePath string) (inputStructs.Config, error) {
 	return config, nil
 }
 
-func doAction(action string, config inputStructs.Config, testInputFilePath string) error {
+//tlsMode -- To find whether the network uses tls from the connection profile of the first organization
+func tlsMode(config inputStructs.Config) (string, error) {
 
-	var actions []string
 	var err error
 	var connectionProfileFileContents []byte
 	tls := "disabled"
-	supportedActions := "create|anchorpeer|join|joinBySnapshot|install|instantiate|upgrade|invoke|query|command|snapshot"
 	if strings.HasSuffix(config.Organizations[0].ConnProfilePath, "yaml") || strings.HasSuffix(config.Organizations[0].ConnProfilePath, "yml") {
 		connectionProfileFileContents, err = ioutil.ReadFile(config.Organizations[0].ConnProfilePath)
 	} else {
 		files, err := ioutil.ReadDir(config.Organizations[0].ConnProfilePath)
 		if err != nil {
-			return errors.Errorf("Failed to read the connection profiles directory; Error: %s", err)
+			return tls, errors.Errorf("Failed to read the connection profiles directory; Error: %s", err)
 		}
 		connectionProfileFileContents, err = ioutil.ReadFile(filepath.Join(config.Organizations[0].ConnProfilePath, files[0].Name()))
 	}
 	if err != nil {
-		return errors.Errorf("Failed to read the connection profile file; Error: %s", err)
+		return tls, errors.Errorf("Failed to read the connection profile file; Error: %s", err)
 	}
 	if strings.Contains(string(connectionProfileFileContents), "grpcs") {
 		tls = "clientauth"
 	}
+	return tls, nil
+}
+
+func doAction(action string, config inputStructs.Config, testInputFilePath string) error {
 
+	var actions []string
+	supportedActions := "create|anchorpeer|join|joinBySnapshot|install|instantiate|upgrade|invoke|query|command|snapshot|metricsSnapshot"
+	tls, err := tlsMode(config)
+	if err != nil {
+		return err
+	}
 	if action == "all" {
 		actions = append(actions, []string{"create", "anchorpeer", "join", "install", "instantiate"}...)
 	} else {
@@ -103,6 +114,11 @@ func doAction(action string, config inputStructs.Config, testInputFilePath strin
 			if err != nil {
 				return err
 			}
+		case "metricsSnapshot":
+			err := operations.WriteMetricsSnapshot(config)
+			if err != nil {
+				return err
+			}
 		case "command":
 			err := operations.DoCommandAction(config)
 			if err != nil {
@@ -138,5 +154,117 @@ func Testclient(action, testInputFilePath string) error {
 		logger.ERROR("Failed to perform ", action, " action, testInputFilePath = ", testInputFilePath)
 		return err
 	}
+	err = recordState(action, config)
+	if err != nil {
+		logger.ERROR("Failed to record the state of the network after ", action, " action")
+		return err
+	}
 	return nil
 }
+
+//Plan -- the channel transactions and chaincode lifecycle calls an action would make, and the commands that would
+//make them, without running them
+func Plan(action, testInputFilePath string) (plan.Plan, error) {
+
+	p := plan.Plan{Action: action}
+	err := validateArguments(testInputFilePath)
+	if err != nil {
+		return p, err
+	}
+	config, err := GetInputData(testInputFilePath)
+	if err != nil {
+		return p, err
+	}
+	if action == "" || action == "all" {
+		p.Action = "all"
+	}
+	var tls string
+	if len(config.Organizations) > 0 {
+		tls, err = tlsMode(config)
+		if err != nil {
+			logger.WARNING(err.Error())
+			tls = ""
+		}
+	}
+	actions := []string{p.Action}
+	if p.Action == "all" {
+		actions = []string{"create", "anchorpeer", "join", "install", "instantiate"}
+	}
+	for _, action := range actions {
+		switch action {
+		case "create", "join", "anchorpeer":
+			var channelUIObject operations.ChannelUIObject
+			err = channelUIObject.PlanChannelConfigs(config, tls, action, &p)
+		case "install":
+			var installCCUIObject operations.InstallCCUIObject
+			err = installCCUIObject.PlanInstallCC(config, tls, &p)
+		case "instantiate", "upgrade":
+			var instantiateCCUIObject operations.InstantiateCCUIObject
+			err = instantiateCCUIObject.PlanInstantiateCC(config, tls, action, &p)
+		default:
+			return p, errors.Errorf("dry run of action %s is not supported, only of create, anchorpeer, join, install, instantiate, upgrade and all", action)
+		}
+		if err != nil {
+			return p, err
+		}
+	}
+	return p, nil
+}
+
+//recordState -- records the channels the peers joined and the chaincodes installed on them in the network-state.json
+//of the network of the connection profiles of the test input, if it has one
+func recordState(action string, config inputStructs.Config) error {
+
+	if len(config.Organizations) == 0 || !(action == "all" || action == "join" || action == "joinBySnapshot" || action == "install") {
+		return nil
+	}
+	statePath, ok := networkstate.Locate(config.Organizations[0].ConnProfilePath)
+	if !ok {
+		return nil
+	}
+	state, err := networkstate.Read(statePath)
+	if err != nil {
+		return err
+	}
+	err = state.Discover()
+	if err != nil {
+		return err
+	}
+	if action == "all" || action == "install" {
+		for _, installCC := range config.InstallCC {
+			var peerNames []string
+			if installCC.TargetPeers != "" {
+				peerNames = strings.Split(installCC.TargetPeers, ",")
+			} else {
+				for _, orgName := range strings.Split(installCC.Organizations, ",") {
+					for _, peer := range state.OfOrg(strings.TrimSpace(orgName), networkstate.Peer) {
+						peerNames = append(peerNames, peer.Name)
+					}
+				}
+			}
+			state.Install(networkstate.Chaincode{Name: installCC.ChainCodeName, Version: installCC.ChainCodeVersion}, peerNames)
+		}
+	}
+	return state.Write()
+}
+
+//InvokeQuery -- To perform invoke/query and return the results of every transaction driver process
+func InvokeQuery(action, testInputFilePath string) ([]operations.TransactionResult, error) {
+
+	err := validateArguments(testInputFilePath)
+	if err != nil {
+		logger.ERROR("Failed to validate arguments")
+		return nil, err
+	}
+	config, err := GetInputData(testInputFilePath)
+	if err != nil {
+		logger.ERROR("Failed to get configuration data from testInputFilePath = ", testInputFilePath)
+		return nil, err
+	}
+	tls, err := tlsMode(config)
+	if err != nil {
+		return nil, err
+	}
+	var invokeQueryUIObject operations.InvokeQueryUIObject
+	return invokeQueryUIObject.InvokeQueryWithResults(config, tls, strings.Title(action))
+}